@@ -81,6 +81,16 @@ const (
 	// SecretProviderASM is to show secret provider being ASM
 	SecretProviderASM = "asm"
 
+	// SecretProviderLocal is to show secret provider being the local secrets of the instance, the secrets
+	// whose valueFrom has a local secret scheme are retrieved from it whatever their provider
+	SecretProviderLocal = "local"
+
+	// LocalSecretFileScheme is the valueFrom scheme of the secrets of the local encrypted secrets file
+	LocalSecretFileScheme = "local-file://"
+
+	// LocalSecretHTTPScheme is the valueFrom scheme of the secrets of the local secrets endpoint
+	LocalSecretHTTPScheme = "local-http://"
+
 	// SecretTypeEnv is to show secret type being ENVIRONMENT_VARIABLE
 	SecretTypeEnv = "ENVIRONMENT_VARIABLE"
 
@@ -223,6 +233,11 @@ type Container struct {
 	StartTimeout uint
 	// StopTimeout specifies the time value to be passed as StopContainer api call
 	StopTimeout uint
+	// RestartPolicy is the policy the container is restarted in place with when it exits. It is
+	// only set for the non-essential containers that are restarted.
+	RestartPolicy *RestartPolicy `json:"restartPolicyConfig,omitempty"`
+	// RestartTracker tracks the restarts of the container under its RestartPolicy
+	RestartTracker *RestartTracker `json:"restartTracker,omitempty"`
 
 	// lock is used for fields that are accessed and updated concurrently
 	lock sync.RWMutex
@@ -388,6 +403,15 @@ type Secret struct {
 	Target        string `json:"target"`
 }
 
+// GetProvider returns the provider the secret is retrieved from, which is the local provider if the
+// valueFrom of the secret has a local secret scheme
+func (s *Secret) GetProvider() string {
+	if strings.HasPrefix(s.ValueFrom, LocalSecretFileScheme) || strings.HasPrefix(s.ValueFrom, LocalSecretHTTPScheme) {
+		return SecretProviderLocal
+	}
+	return s.Provider
+}
+
 // GetSecretResourceCacheKey returns the key required to access the secret
 // from the ssmsecret resource
 func (s *Secret) GetSecretResourceCacheKey() string {
@@ -1013,7 +1037,7 @@ func (c *Container) ShouldCreateWithSSMSecret() bool {
 	}
 
 	for _, secret := range c.Secrets {
-		if secret.Provider == SecretProviderSSM {
+		if secret.GetProvider() == SecretProviderSSM {
 			return true
 		}
 	}
@@ -1032,7 +1056,21 @@ func (c *Container) ShouldCreateWithASMSecret() bool {
 	}
 
 	for _, secret := range c.Secrets {
-		if secret.Provider == SecretProviderASM {
+		if secret.GetProvider() == SecretProviderASM {
+			return true
+		}
+	}
+	return false
+}
+
+// ShouldCreateWithLocalSecret returns true if this container needs to get secret
+// value from the local secrets of the instance
+func (c *Container) ShouldCreateWithLocalSecret() bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	for _, secret := range c.Secrets {
+		if secret.GetProvider() == SecretProviderLocal {
 			return true
 		}
 	}
@@ -319,6 +319,32 @@ func TestShouldCreateWithSSMSecret(t *testing.T) {
 	}
 }
 
+func TestShouldCreateWithLocalSecret(t *testing.T) {
+	cases := []struct {
+		name      string
+		valueFrom string
+		local     bool
+	}{
+		{name: "local file", valueFrom: "local-file://db/password", local: true},
+		{name: "local endpoint", valueFrom: "local-http://secret/db#password", local: true},
+		{name: "ssm parameter", valueFrom: "/test/secretName", local: false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			secret := Secret{Provider: SecretProviderSSM, Name: "secret", ValueFrom: c.valueFrom}
+			container := &Container{Name: "myName", Secrets: []Secret{secret}}
+			assert.Equal(t, c.local, container.ShouldCreateWithLocalSecret())
+			assert.Equal(t, !c.local, container.ShouldCreateWithSSMSecret())
+			if c.local {
+				assert.Equal(t, SecretProviderLocal, secret.GetProvider())
+			} else {
+				assert.Equal(t, SecretProviderSSM, secret.GetProvider())
+			}
+		})
+	}
+}
+
 func TestMergeEnvironmentVariables(t *testing.T) {
 	cases := []struct {
 		Name                   string
@@ -0,0 +1,196 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package container
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
+)
+
+const (
+	// RestartPolicyNever is the ACS restart policy of the containers that are not restarted.
+	RestartPolicyNever = "NEVER"
+	// RestartPolicyOnFailure is the ACS restart policy of the containers that are restarted when
+	// they exit with a non-zero exit code.
+	RestartPolicyOnFailure = "ON_FAILURE"
+	// RestartPolicyUnlessTaskStopped is the ACS restart policy of the containers that are
+	// restarted whenever they exit, until their task is stopped.
+	RestartPolicyUnlessTaskStopped = "UNLESS_TASK_STOPPED"
+
+	// RestartIgnoredExitCodesLabel is the Docker label of a container that sets the comma separated
+	// exit codes that the container is not restarted on, e.g. "0,143". ACS doesn't send ignored exit
+	// codes with the restart policy, so they are set in the container Docker labels.
+	RestartIgnoredExitCodesLabel = "com.amazonaws.ecs.restart-ignored-exit-codes"
+	// RestartAttemptPeriodLabel is the Docker label of a container that sets the restart attempt
+	// period of the container in seconds, e.g. "60". ACS doesn't send an attempt period with the
+	// restart policy, so it is set in the container Docker labels.
+	RestartAttemptPeriodLabel = "com.amazonaws.ecs.restart-attempt-period"
+
+	// DefaultRestartAttemptPeriod is the time a container must run for before it can be restarted,
+	// when its RestartAttemptPeriodLabel isn't set.
+	DefaultRestartAttemptPeriod = 5 * time.Minute
+)
+
+// RestartPolicy is the policy that a non-essential container is restarted in place with when it
+// exits, without stopping its task.
+type RestartPolicy struct {
+	// IgnoredExitCodes are the exit codes that the container is not restarted on.
+	IgnoredExitCodes []int `json:"ignoredExitCodes,omitempty"`
+	// RestartAttemptPeriod is the time the container must run for, since it was started or last
+	// restarted, before it can be restarted. It keeps a crash looping container stopped.
+	RestartAttemptPeriod time.Duration `json:"restartAttemptPeriod"`
+	// MaxAttempts is the maximum number of restarts of the container, 0 for no limit.
+	MaxAttempts int `json:"maxAttempts,omitempty"`
+}
+
+// NewRestartPolicy creates the restart policy of a container from the restartPolicy and
+// restartMaxAttempts fields of its ACS definition, and the ignored exit codes and attempt period
+// set by its RestartIgnoredExitCodesLabel and RestartAttemptPeriodLabel Docker labels. It returns
+// nil when the container is not restarted. ON_FAILURE always ignores the 0 exit code.
+func NewRestartPolicy(policy string, maxAttempts int, labels map[string]string) (*RestartPolicy, error) {
+	restartPolicy := &RestartPolicy{
+		RestartAttemptPeriod: DefaultRestartAttemptPeriod,
+		MaxAttempts:          maxAttempts,
+	}
+	switch policy {
+	case "", RestartPolicyNever:
+		return nil, nil
+	case RestartPolicyOnFailure:
+		restartPolicy.IgnoredExitCodes = []int{0}
+	case RestartPolicyUnlessTaskStopped:
+	default:
+		return nil, fmt.Errorf("invalid restart policy: %s", policy)
+	}
+	if maxAttempts < 0 {
+		return nil, fmt.Errorf("invalid restart max attempts: %d", maxAttempts)
+	}
+	if value, ok := labels[RestartIgnoredExitCodesLabel]; ok {
+		for _, code := range strings.Split(value, ",") {
+			exitCode, err := strconv.Atoi(strings.TrimSpace(code))
+			if err != nil {
+				return nil, fmt.Errorf("invalid restart ignored exit codes: %s", value)
+			}
+			if !restartPolicy.ignores(exitCode) {
+				restartPolicy.IgnoredExitCodes = append(restartPolicy.IgnoredExitCodes, exitCode)
+			}
+		}
+	}
+	if value, ok := labels[RestartAttemptPeriodLabel]; ok {
+		seconds, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || seconds <= 0 {
+			return nil, fmt.Errorf("invalid restart attempt period: %s", value)
+		}
+		restartPolicy.RestartAttemptPeriod = time.Duration(seconds) * time.Second
+	}
+	return restartPolicy, nil
+}
+
+// ignores returns whether the container is not restarted when it exits with exitCode.
+func (policy *RestartPolicy) ignores(exitCode int) bool {
+	for _, ignored := range policy.IgnoredExitCodes {
+		if exitCode == ignored {
+			return true
+		}
+	}
+	return false
+}
+
+// RestartPolicyEnabled returns true if the container is restarted in place when it exits.
+func (c *Container) RestartPolicyEnabled() bool {
+	return c.RestartPolicy != nil && c.RestartTracker != nil
+}
+
+// RestartTracker tracks the restarts of a container under its restart policy. It is saved with
+// the container in the agent state, so the counts survive agent restarts.
+type RestartTracker struct {
+	// RestartCount is the number of times the container was restarted.
+	RestartCount int `json:"restartCount,omitempty"`
+	// LastRestartAt is the time the container was last restarted.
+	LastRestartAt time.Time `json:"lastRestartAt,omitempty"`
+	// LastExitCode is the exit code the container exited with before it was last restarted.
+	LastExitCode *int `json:"lastExitCode,omitempty"`
+
+	lock sync.RWMutex
+}
+
+// NewRestartTracker creates the tracker of the restarts of a container.
+func NewRestartTracker() *RestartTracker {
+	return &RestartTracker{}
+}
+
+// GetRestartCount returns the number of times the container was restarted.
+func (rt *RestartTracker) GetRestartCount() int {
+	rt.lock.RLock()
+	defer rt.lock.RUnlock()
+	return rt.RestartCount
+}
+
+// GetLastRestartAt returns the time the container was last restarted.
+func (rt *RestartTracker) GetLastRestartAt() time.Time {
+	rt.lock.RLock()
+	defer rt.lock.RUnlock()
+	return rt.LastRestartAt
+}
+
+// RecordRestart records a restart of the container after it exited with exitCode.
+func (rt *RestartTracker) RecordRestart(exitCode *int, restartedAt time.Time) {
+	rt.lock.Lock()
+	defer rt.lock.Unlock()
+	rt.RestartCount++
+	rt.LastRestartAt = restartedAt
+	rt.LastExitCode = nil
+	if exitCode != nil {
+		code := *exitCode
+		rt.LastExitCode = &code
+	}
+}
+
+// ShouldRestart returns whether a container that exited with exitCode should be restarted under
+// the policy, along with the reason when it should not. startedAt is the time the container was
+// last started at.
+func (rt *RestartTracker) ShouldRestart(policy *RestartPolicy, exitCode *int, startedAt time.Time,
+	desiredStatus apicontainerstatus.ContainerStatus, now time.Time) (bool, string) {
+	if policy == nil {
+		return false, "the container has no restart policy"
+	}
+	if desiredStatus.Terminal() {
+		return false, "the container is desired to be stopped"
+	}
+	if exitCode == nil {
+		return false, "the exit code of the container is unknown"
+	}
+	if policy.ignores(*exitCode) {
+		return false, fmt.Sprintf("the exit code %d is ignored by the restart policy", *exitCode)
+	}
+
+	rt.lock.RLock()
+	defer rt.lock.RUnlock()
+	if policy.MaxAttempts > 0 && rt.RestartCount >= policy.MaxAttempts {
+		return false, fmt.Sprintf("the container was restarted %d times already", rt.RestartCount)
+	}
+	runningSince := startedAt
+	if rt.LastRestartAt.After(runningSince) {
+		runningSince = rt.LastRestartAt
+	}
+	if runningFor := now.Sub(runningSince); !runningSince.IsZero() && runningFor < policy.RestartAttemptPeriod {
+		return false, fmt.Sprintf("the container ran for %s, less than the restart attempt period of %s",
+			runningFor.Round(time.Second), policy.RestartAttemptPeriod)
+	}
+	return true, ""
+}
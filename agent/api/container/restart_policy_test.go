@@ -0,0 +1,228 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package container
+
+import (
+	"testing"
+	"time"
+
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRestartPolicy(t *testing.T) {
+	testCases := []struct {
+		name          string
+		policy        string
+		maxAttempts   int
+		labels        map[string]string
+		expected      *RestartPolicy
+		expectedError bool
+	}{
+		{
+			name:   "no policy",
+			policy: "",
+		},
+		{
+			name:   "never",
+			policy: RestartPolicyNever,
+		},
+		{
+			name:        "on failure ignores exit code 0",
+			policy:      RestartPolicyOnFailure,
+			maxAttempts: 3,
+			expected: &RestartPolicy{
+				IgnoredExitCodes:     []int{0},
+				RestartAttemptPeriod: DefaultRestartAttemptPeriod,
+				MaxAttempts:          3,
+			},
+		},
+		{
+			name:   "unless task stopped",
+			policy: RestartPolicyUnlessTaskStopped,
+			expected: &RestartPolicy{
+				RestartAttemptPeriod: DefaultRestartAttemptPeriod,
+			},
+		},
+		{
+			name:        "on failure with ignored exit codes and attempt period labels",
+			policy:      RestartPolicyOnFailure,
+			maxAttempts: 3,
+			labels: map[string]string{
+				RestartIgnoredExitCodesLabel: "143, 0,137",
+				RestartAttemptPeriodLabel:    "60",
+			},
+			expected: &RestartPolicy{
+				IgnoredExitCodes:     []int{0, 143, 137},
+				RestartAttemptPeriod: time.Minute,
+				MaxAttempts:          3,
+			},
+		},
+		{
+			name:   "unless task stopped with ignored exit codes label",
+			policy: RestartPolicyUnlessTaskStopped,
+			labels: map[string]string{RestartIgnoredExitCodesLabel: "1"},
+			expected: &RestartPolicy{
+				IgnoredExitCodes:     []int{1},
+				RestartAttemptPeriod: DefaultRestartAttemptPeriod,
+			},
+		},
+		{
+			name:          "invalid ignored exit codes label",
+			policy:        RestartPolicyOnFailure,
+			labels:        map[string]string{RestartIgnoredExitCodesLabel: "1,two"},
+			expectedError: true,
+		},
+		{
+			name:          "invalid attempt period label",
+			policy:        RestartPolicyOnFailure,
+			labels:        map[string]string{RestartAttemptPeriodLabel: "0"},
+			expectedError: true,
+		},
+		{
+			name:          "invalid policy",
+			policy:        "ALWAYS",
+			expectedError: true,
+		},
+		{
+			name:          "negative max attempts",
+			policy:        RestartPolicyOnFailure,
+			maxAttempts:   -1,
+			expectedError: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			policy, err := NewRestartPolicy(tc.policy, tc.maxAttempts, tc.labels)
+			if tc.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, policy)
+		})
+	}
+}
+
+func TestShouldRestart(t *testing.T) {
+	now := time.Now()
+	policy := &RestartPolicy{
+		IgnoredExitCodes:     []int{0},
+		RestartAttemptPeriod: time.Minute,
+		MaxAttempts:          2,
+	}
+	exitCode := func(code int) *int { return &code }
+
+	testCases := []struct {
+		name          string
+		policy        *RestartPolicy
+		exitCode      *int
+		startedAt     time.Time
+		lastRestartAt time.Time
+		restartCount  int
+		desiredStatus apicontainerstatus.ContainerStatus
+		expected      bool
+	}{
+		{
+			name:          "restarts on failure",
+			policy:        policy,
+			exitCode:      exitCode(1),
+			startedAt:     now.Add(-2 * time.Minute),
+			desiredStatus: apicontainerstatus.ContainerRunning,
+			expected:      true,
+		},
+		{
+			name:          "restarts without a start time",
+			policy:        policy,
+			exitCode:      exitCode(1),
+			desiredStatus: apicontainerstatus.ContainerRunning,
+			expected:      true,
+		},
+		{
+			name:          "no policy",
+			exitCode:      exitCode(1),
+			desiredStatus: apicontainerstatus.ContainerRunning,
+		},
+		{
+			name:          "desired stopped",
+			policy:        policy,
+			exitCode:      exitCode(1),
+			desiredStatus: apicontainerstatus.ContainerStopped,
+		},
+		{
+			name:          "unknown exit code",
+			policy:        policy,
+			desiredStatus: apicontainerstatus.ContainerRunning,
+		},
+		{
+			name:          "ignored exit code",
+			policy:        policy,
+			exitCode:      exitCode(0),
+			desiredStatus: apicontainerstatus.ContainerRunning,
+		},
+		{
+			name:          "max attempts reached",
+			policy:        policy,
+			exitCode:      exitCode(1),
+			restartCount:  2,
+			desiredStatus: apicontainerstatus.ContainerRunning,
+		},
+		{
+			name:          "ran for less than the attempt period",
+			policy:        policy,
+			exitCode:      exitCode(1),
+			startedAt:     now.Add(-30 * time.Second),
+			desiredStatus: apicontainerstatus.ContainerRunning,
+		},
+		{
+			name:          "restarted less than the attempt period ago",
+			policy:        policy,
+			exitCode:      exitCode(1),
+			startedAt:     now.Add(-2 * time.Minute),
+			lastRestartAt: now.Add(-30 * time.Second),
+			restartCount:  1,
+			desiredStatus: apicontainerstatus.ContainerRunning,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tracker := &RestartTracker{
+				RestartCount:  tc.restartCount,
+				LastRestartAt: tc.lastRestartAt,
+			}
+			restart, reason := tracker.ShouldRestart(tc.policy, tc.exitCode, tc.startedAt, tc.desiredStatus, now)
+			assert.Equal(t, tc.expected, restart)
+			if !tc.expected {
+				assert.NotEmpty(t, reason)
+			}
+		})
+	}
+}
+
+func TestRecordRestart(t *testing.T) {
+	tracker := NewRestartTracker()
+	now := time.Now()
+	exitCode := 137
+	tracker.RecordRestart(&exitCode, now)
+	tracker.RecordRestart(nil, now.Add(time.Minute))
+
+	assert.Equal(t, 2, tracker.GetRestartCount())
+	assert.Equal(t, now.Add(time.Minute), tracker.GetLastRestartAt())
+	assert.Nil(t, tracker.LastExitCode)
+}
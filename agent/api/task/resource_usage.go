@@ -0,0 +1,136 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package task
+
+import (
+	"time"
+)
+
+const (
+	// bytesInGB is the number of bytes in a GB of memory, in the same units as the memory of a task
+	// definition (1 GB = 1024 MiB).
+	bytesInGB = 1024 * 1024 * 1024
+	// nanosecondsInSecond is the number of nanoseconds of CPU time in a CPU-second.
+	nanosecondsInSecond = 1e9
+)
+
+// ResourceUsageSample holds the cumulative usage counters of a container, or of the network namespace
+// of a task, at the time they were sampled.
+type ResourceUsageSample struct {
+	Timestamp           time.Time `json:"Timestamp"`
+	CPUUsageNanoseconds uint64    `json:"CPUUsageNanoseconds"`
+	MemoryUsageBytes    uint64    `json:"MemoryUsageBytes"`
+	NetworkRxBytes      uint64    `json:"NetworkRxBytes"`
+	NetworkTxBytes      uint64    `json:"NetworkTxBytes"`
+	StorageReadBytes    uint64    `json:"StorageReadBytes"`
+	StorageWriteBytes   uint64    `json:"StorageWriteBytes"`
+}
+
+// ResourceUsage is the resource usage of a task accumulated over its lifetime, e.g. to charge back
+// the usage of a shared cluster. The totals are final once StoppedAt is set.
+type ResourceUsage struct {
+	TaskARN  string `json:"TaskARN"`
+	Family   string `json:"Family"`
+	Revision string `json:"Revision"`
+	// StartedAt is the time of the first sample of the task
+	StartedAt time.Time `json:"StartedAt"`
+	// StoppedAt is the time the task stopped, it is nil while the task runs
+	StoppedAt *time.Time `json:"StoppedAt,omitempty"`
+	// UpdatedAt is the time of the last sample accumulated
+	UpdatedAt time.Time `json:"UpdatedAt"`
+
+	CPUSeconds        float64 `json:"CPUSeconds"`
+	MemoryGBSeconds   float64 `json:"MemoryGBSeconds"`
+	NetworkRxBytes    uint64  `json:"NetworkRxBytes"`
+	NetworkTxBytes    uint64  `json:"NetworkTxBytes"`
+	StorageReadBytes  uint64  `json:"StorageReadBytes"`
+	StorageWriteBytes uint64  `json:"StorageWriteBytes"`
+
+	// LastSamples holds the last sample accumulated from every source of the task, e.g. the runtime
+	// id of a container, so that the counters are not accumulated twice after an agent restart.
+	// They are dropped once the task stops.
+	LastSamples map[string]ResourceUsageSample `json:"LastSamples,omitempty"`
+}
+
+// NewResourceUsage returns the empty resource usage of a task.
+func NewResourceUsage(taskARN, family, revision string) *ResourceUsage {
+	return &ResourceUsage{
+		TaskARN:     taskARN,
+		Family:      family,
+		Revision:    revision,
+		LastSamples: make(map[string]ResourceUsageSample),
+	}
+}
+
+// Accumulate adds the usage since the last sample of the source to the totals, and returns whether
+// the totals changed. The counters of the first sample of a source are the usage since the source
+// started, so they are added as is. Memory is accumulated from the second sample on, as the memory
+// used during the interval between two samples.
+func (usage *ResourceUsage) Accumulate(source string, sample ResourceUsageSample) bool {
+	if usage.StoppedAt != nil {
+		return false
+	}
+	if usage.LastSamples == nil {
+		usage.LastSamples = make(map[string]ResourceUsageSample)
+	}
+	last, ok := usage.LastSamples[source]
+	if ok && !sample.Timestamp.After(last.Timestamp) {
+		return false
+	}
+	usage.LastSamples[source] = sample
+
+	if usage.StartedAt.IsZero() || sample.Timestamp.Before(usage.StartedAt) {
+		usage.StartedAt = sample.Timestamp
+	}
+	if sample.Timestamp.After(usage.UpdatedAt) {
+		usage.UpdatedAt = sample.Timestamp
+	}
+
+	usage.CPUSeconds += float64(counterIncrease(sample.CPUUsageNanoseconds, last.CPUUsageNanoseconds)) / nanosecondsInSecond
+	usage.NetworkRxBytes += counterIncrease(sample.NetworkRxBytes, last.NetworkRxBytes)
+	usage.NetworkTxBytes += counterIncrease(sample.NetworkTxBytes, last.NetworkTxBytes)
+	usage.StorageReadBytes += counterIncrease(sample.StorageReadBytes, last.StorageReadBytes)
+	usage.StorageWriteBytes += counterIncrease(sample.StorageWriteBytes, last.StorageWriteBytes)
+	if ok {
+		interval := sample.Timestamp.Sub(last.Timestamp).Seconds()
+		usage.MemoryGBSeconds += float64(last.MemoryUsageBytes) / bytesInGB * interval
+	}
+	return true
+}
+
+// Stop makes the totals final.
+func (usage *ResourceUsage) Stop(stoppedAt time.Time) {
+	usage.StoppedAt = &stoppedAt
+	usage.LastSamples = nil
+}
+
+// Totals returns a copy of the usage without the last samples.
+func (usage *ResourceUsage) Totals() *ResourceUsage {
+	totals := *usage
+	totals.LastSamples = nil
+	if usage.StoppedAt != nil {
+		stoppedAt := *usage.StoppedAt
+		totals.StoppedAt = &stoppedAt
+	}
+	return &totals
+}
+
+// counterIncrease returns the increase of a cumulative counter since the last sample. A counter lower
+// than in the last sample was reset, e.g. because the container restarted, so it all counts.
+func counterIncrease(current, last uint64) uint64 {
+	if current < last {
+		return current
+	}
+	return current - last
+}
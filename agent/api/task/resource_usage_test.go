@@ -0,0 +1,96 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package task
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceUsageAccumulate(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	usage := NewResourceUsage("arn:task", "family", "1")
+
+	assert.True(t, usage.Accumulate("container", ResourceUsageSample{
+		Timestamp:           start,
+		CPUUsageNanoseconds: 2e9,
+		MemoryUsageBytes:    bytesInGB,
+		NetworkRxBytes:      100,
+		StorageWriteBytes:   10,
+	}))
+	assert.Equal(t, 2.0, usage.CPUSeconds, "the counters of the first sample count as is")
+	assert.Equal(t, 0.0, usage.MemoryGBSeconds)
+	assert.Equal(t, start, usage.StartedAt)
+
+	assert.True(t, usage.Accumulate("container", ResourceUsageSample{
+		Timestamp:           start.Add(10 * time.Second),
+		CPUUsageNanoseconds: 5e9,
+		MemoryUsageBytes:    2 * bytesInGB,
+		NetworkRxBytes:      150,
+		StorageWriteBytes:   30,
+	}))
+	assert.Equal(t, 5.0, usage.CPUSeconds)
+	assert.Equal(t, 10.0, usage.MemoryGBSeconds, "1 GB used for 10 seconds")
+	assert.Equal(t, uint64(150), usage.NetworkRxBytes)
+	assert.Equal(t, uint64(30), usage.StorageWriteBytes)
+
+	assert.False(t, usage.Accumulate("container", ResourceUsageSample{Timestamp: start.Add(10 * time.Second)}),
+		"a sample that is not newer than the last one is ignored")
+
+	// the container restarted, its counters start over
+	assert.True(t, usage.Accumulate("container", ResourceUsageSample{
+		Timestamp:           start.Add(20 * time.Second),
+		CPUUsageNanoseconds: 1e9,
+	}))
+	assert.Equal(t, 6.0, usage.CPUSeconds)
+	assert.Equal(t, 30.0, usage.MemoryGBSeconds)
+	assert.Equal(t, start.Add(20*time.Second), usage.UpdatedAt)
+}
+
+func TestResourceUsageStop(t *testing.T) {
+	now := time.Now()
+	usage := NewResourceUsage("arn:task", "family", "1")
+	usage.Accumulate("container", ResourceUsageSample{Timestamp: now, CPUUsageNanoseconds: 1e9})
+	usage.Stop(now)
+
+	require.NotNil(t, usage.StoppedAt)
+	assert.Nil(t, usage.LastSamples)
+	assert.False(t, usage.Accumulate("container", ResourceUsageSample{Timestamp: now.Add(time.Second)}),
+		"the totals of a stopped task are final")
+	assert.Equal(t, 1.0, usage.CPUSeconds)
+}
+
+func TestResourceUsagePersistsLastSamples(t *testing.T) {
+	now := time.Now().UTC()
+	usage := NewResourceUsage("arn:task", "family", "1")
+	usage.Accumulate("container", ResourceUsageSample{Timestamp: now, CPUUsageNanoseconds: 1e9})
+
+	data, err := json.Marshal(usage)
+	require.NoError(t, err)
+	var loaded ResourceUsage
+	require.NoError(t, json.Unmarshal(data, &loaded))
+	assert.True(t, loaded.Accumulate("container", ResourceUsageSample{Timestamp: now.Add(time.Second), CPUUsageNanoseconds: 3e9}))
+	assert.Equal(t, 3.0, loaded.CPUSeconds, "the counters are not accumulated twice")
+
+	totals := loaded.Totals()
+	assert.Nil(t, totals.LastSamples)
+	assert.NotNil(t, loaded.LastSamples)
+}
@@ -35,6 +35,9 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/credentialspec"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/envFiles"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/firelens"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource/localsecret"
+	resourceplugin "github.com/aws/amazon-ecs-agent/agent/taskresource/plugin"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource/secretfiles"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/ssmsecret"
 	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
 	resourcetype "github.com/aws/amazon-ecs-agent/agent/taskresource/types"
@@ -284,6 +287,13 @@ type Task struct {
 	// LaunchType is the launch type of this task.
 	LaunchType string `json:"LaunchType,omitempty"`
 
+	// queuePosition is the position of the task in the queue of the tasks waiting for host resources,
+	// starting at 1, or 0 when the task isn't waiting. It is not saved in the agent state, as the tasks
+	// are queued again when the agent restarts.
+	queuePosition int
+	// waitReason is why the task is waiting for host resources.
+	waitReason string
+
 	// lock is for protecting all fields in the task struct
 	lock sync.RWMutex
 
@@ -323,6 +333,7 @@ func TaskFromACS(acsTask *ecsacs.Task, envelope *ecsacs.PayloadMessage) (*Task,
 	task.ResourcesMapUnsafe = make(map[string][]taskresource.TaskResource)
 
 	task.initNetworkMode(acsTask.NetworkMode)
+	task.initRestartPolicies(acsTask.Containers)
 
 	// extract and validate attachments
 	if err := handleTaskAttachments(acsTask, task); err != nil {
@@ -414,7 +425,7 @@ func (task *Task) PostUnmarshalTask(cfg *config.Config,
 		return apierrors.NewResourceInitError(task.Arn, err)
 	}
 
-	task.initSecretResources(credentialsManager, resourceFields)
+	task.initSecretResources(cfg, credentialsManager, resourceFields)
 
 	task.initializeCredentialsEndpoint(credentialsManager)
 
@@ -468,6 +479,8 @@ func (task *Task) PostUnmarshalTask(cfg *config.Config,
 	}
 	task.populateTaskARN()
 
+	task.initializePluginResources(resourceFields)
+
 	// fsxWindowsFileserver is the product type -- it is technically "agnostic" ie it should apply to both Windows and Linux tasks
 	if task.requiresFSxWindowsFileServerResource() {
 		if err := task.initializeFSxWindowsFileServerResource(cfg, credentialsManager, resourceFields); err != nil {
@@ -515,6 +528,44 @@ func (task *Task) initializeCredentialSpecResource(config *config.Config, creden
 	return nil
 }
 
+// initRestartPolicies sets the restart policies of the non-essential containers of the task, with
+// the ignored exit codes and attempt period of their Docker labels. A container with an invalid
+// restart policy is not restarted.
+func (task *Task) initRestartPolicies(acsContainers []*ecsacs.Container) {
+	for _, acsContainer := range acsContainers {
+		if aws.BoolValue(acsContainer.Essential) {
+			continue
+		}
+		container, ok := task.ContainerByName(aws.StringValue(acsContainer.Name))
+		if !ok {
+			continue
+		}
+		var containerConfig struct {
+			Labels map[string]string
+		}
+		if container.DockerConfig.Config != nil {
+			if err := json.Unmarshal([]byte(aws.StringValue(container.DockerConfig.Config)), &containerConfig); err != nil {
+				// an invalid Docker config fails the container when it is created, the labels are ignored here
+				containerConfig.Labels = nil
+			}
+		}
+		restartPolicy, err := apicontainer.NewRestartPolicy(aws.StringValue(acsContainer.RestartPolicy),
+			int(aws.Int64Value(acsContainer.RestartMaxAttempts)), containerConfig.Labels)
+		if err != nil {
+			logger.Warn("Ignoring the restart policy of the container", logger.Fields{
+				field.TaskARN:   task.Arn,
+				field.Container: container.Name,
+				field.Error:     err,
+			})
+			continue
+		}
+		if restartPolicy != nil {
+			container.RestartPolicy = restartPolicy
+			container.RestartTracker = apicontainer.NewRestartTracker()
+		}
+	}
+}
+
 // initNetworkMode initializes/infers the network mode for the task and assigns the result to this task's NetworkMode field.
 // ACS is streaming down this value with task payload. In case of docker bridge mode task, this value might be left empty
 // as it's the default task network mode.
@@ -637,7 +688,7 @@ func (task *Task) populateTaskARN() {
 	}
 }
 
-func (task *Task) initSecretResources(credentialsManager credentials.Manager,
+func (task *Task) initSecretResources(cfg *config.Config, credentialsManager credentials.Manager,
 	resourceFields *taskresource.ResourceFields) {
 	if task.requiresASMDockerAuthData() {
 		task.initializeASMAuthResource(credentialsManager, resourceFields)
@@ -650,6 +701,51 @@ func (task *Task) initSecretResources(credentialsManager credentials.Manager,
 	if task.requiresASMSecret() {
 		task.initializeASMSecretResource(credentialsManager, resourceFields)
 	}
+
+	if task.requiresLocalSecret() {
+		task.initializeLocalSecretResource(resourceFields)
+	}
+
+	if cfg.SecretFilesEnabled.Enabled() {
+		task.initializeSecretFiles(cfg, resourceFields)
+	}
+}
+
+// initializeSecretFiles makes the secret resources write the secrets of the containers to files in a
+// tmpfs of the task, that's mounted in the containers.
+func (task *Task) initializeSecretFiles(cfg *config.Config, resourceFields *taskresource.ResourceFields) {
+	dir := secretfiles.TaskDir(cfg.DataDir, task.GetID())
+	if resources, ok := task.getSSMSecretsResource(); ok {
+		if secretFiles := secretfiles.NewConfig(dir, task.Containers, apicontainer.SecretProviderSSM,
+			cfg.SecretRotationInterval); secretFiles != nil {
+			resources[0].(*ssmsecret.SSMSecretResource).SetSecretFiles(resourceFields.Ctx, secretFiles)
+		}
+	}
+	if resources, ok := task.getASMSecretsResource(); ok {
+		if secretFiles := secretfiles.NewConfig(dir, task.Containers, apicontainer.SecretProviderASM,
+			cfg.SecretRotationInterval); secretFiles != nil {
+			resources[0].(*asmsecret.ASMSecretResource).SetSecretFiles(resourceFields.Ctx, secretFiles)
+		}
+	}
+}
+
+// initializePluginResources adds a resource of every registered resource plugin that containers of
+// the task require, the containers are created once the resource is created.
+func (task *Task) initializePluginResources(resourceFields *taskresource.ResourceFields) {
+	for _, plugin := range resourceplugin.Plugins() {
+		var resource *resourceplugin.Resource
+		for _, container := range task.Containers {
+			if !plugin.Requires(container) {
+				continue
+			}
+			if resource == nil {
+				resource = resourceplugin.NewResource(plugin, task.Arn, resourceFields)
+				task.AddResource(plugin.Name, resource)
+			}
+			container.BuildResourceDependency(resource.GetName(), resourcestatus.ResourceCreated,
+				apicontainerstatus.ContainerCreated)
+		}
+	}
 }
 
 func (task *Task) applyFirelensSetup(cfg *config.Config, resourceFields *taskresource.ResourceFields,
@@ -1102,14 +1198,14 @@ func (task *Task) initializeSSMSecretResource(credentialsManager credentials.Man
 	for _, container := range task.Containers {
 		if container.ShouldCreateWithSSMSecret() {
 			container.BuildResourceDependency(ssmSecretResource.GetName(),
-				resourcestatus.ResourceStatus(ssmsecret.SSMSecretCreated),
+				resourcestatus.ResourceCreated,
 				apicontainerstatus.ContainerCreated)
 		}
 
 		// Firelens container needs to depends on secret if other containers use secret log options.
 		if container.GetFirelensConfig() != nil && task.firelensDependsOnSecretResource(apicontainer.SecretProviderSSM) {
 			container.BuildResourceDependency(ssmSecretResource.GetName(),
-				resourcestatus.ResourceStatus(ssmsecret.SSMSecretCreated),
+				resourcestatus.ResourceCreated,
 				apicontainerstatus.ContainerCreated)
 		}
 	}
@@ -1119,7 +1215,7 @@ func (task *Task) initializeSSMSecretResource(credentialsManager credentials.Man
 // a certain provider type.
 func (task *Task) firelensDependsOnSecretResource(secretProvider string) bool {
 	isLogDriverSecretWithGivenProvider := func(s apicontainer.Secret) bool {
-		return s.Provider == secretProvider && s.Target == apicontainer.SecretTargetLogDriver
+		return s.GetProvider() == secretProvider && s.Target == apicontainer.SecretTargetLogDriver
 	}
 	for _, container := range task.Containers {
 		if container.GetLogDriver() == firelensDriverName && container.HasSecret(isLogDriverSecretWithGivenProvider) {
@@ -1136,7 +1232,7 @@ func (task *Task) getAllSSMSecretRequirements() map[string][]apicontainer.Secret
 
 	for _, container := range task.Containers {
 		for _, secret := range container.Secrets {
-			if secret.Provider == apicontainer.SecretProviderSSM {
+			if secret.GetProvider() == apicontainer.SecretProviderSSM {
 				if _, ok := reqs[secret.Region]; !ok {
 					reqs[secret.Region] = []apicontainer.Secret{}
 				}
@@ -1170,14 +1266,14 @@ func (task *Task) initializeASMSecretResource(credentialsManager credentials.Man
 	for _, container := range task.Containers {
 		if container.ShouldCreateWithASMSecret() {
 			container.BuildResourceDependency(asmSecretResource.GetName(),
-				resourcestatus.ResourceStatus(asmsecret.ASMSecretCreated),
+				resourcestatus.ResourceCreated,
 				apicontainerstatus.ContainerCreated)
 		}
 
 		// Firelens container needs to depends on secret if other containers use secret log options.
 		if container.GetFirelensConfig() != nil && task.firelensDependsOnSecretResource(apicontainer.SecretProviderASM) {
 			container.BuildResourceDependency(asmSecretResource.GetName(),
-				resourcestatus.ResourceStatus(asmsecret.ASMSecretCreated),
+				resourcestatus.ResourceCreated,
 				apicontainerstatus.ContainerCreated)
 		}
 	}
@@ -1189,7 +1285,58 @@ func (task *Task) getAllASMSecretRequirements() map[string]apicontainer.Secret {
 
 	for _, container := range task.Containers {
 		for _, secret := range container.Secrets {
-			if secret.Provider == apicontainer.SecretProviderASM {
+			if secret.GetProvider() == apicontainer.SecretProviderASM {
+				secretKey := secret.GetSecretResourceCacheKey()
+				if _, ok := reqs[secretKey]; !ok {
+					reqs[secretKey] = secret
+				}
+			}
+		}
+	}
+	return reqs
+}
+
+// requiresLocalSecret returns true if at least one container in the task
+// needs to retrieve secret from the local secrets of the instance
+func (task *Task) requiresLocalSecret() bool {
+	for _, container := range task.Containers {
+		if container.ShouldCreateWithLocalSecret() {
+			return true
+		}
+	}
+	return false
+}
+
+// initializeLocalSecretResource builds the resource dependency map for the localsecret resource
+func (task *Task) initializeLocalSecretResource(resourceFields *taskresource.ResourceFields) {
+	localSecretResource := localsecret.NewLocalSecretResource(task.Arn, task.getAllLocalSecretRequirements(),
+		resourceFields.LocalSecretClient)
+	task.AddResource(localsecret.ResourceName, localSecretResource)
+
+	// for every container that needs local secret vending as envvar, it needs to wait all secrets got retrieved
+	for _, container := range task.Containers {
+		if container.ShouldCreateWithLocalSecret() {
+			container.BuildResourceDependency(localSecretResource.GetName(),
+				resourcestatus.ResourceCreated,
+				apicontainerstatus.ContainerCreated)
+		}
+
+		// Firelens container needs to depends on secret if other containers use secret log options.
+		if container.GetFirelensConfig() != nil && task.firelensDependsOnSecretResource(apicontainer.SecretProviderLocal) {
+			container.BuildResourceDependency(localSecretResource.GetName(),
+				resourcestatus.ResourceCreated,
+				apicontainerstatus.ContainerCreated)
+		}
+	}
+}
+
+// getAllLocalSecretRequirements stores the local secrets in a task in a map
+func (task *Task) getAllLocalSecretRequirements() map[string]apicontainer.Secret {
+	reqs := make(map[string]apicontainer.Secret)
+
+	for _, container := range task.Containers {
+		for _, secret := range container.Secrets {
+			if secret.GetProvider() == apicontainer.SecretProviderLocal {
 				secretKey := secret.GetSecretResourceCacheKey()
 				if _, ok := reqs[secretKey]; !ok {
 					reqs[secretKey] = secret
@@ -1413,6 +1560,23 @@ func (task *Task) AddFirelensContainerBindMounts(firelensConfig *apicontainer.Fi
 	return nil
 }
 
+// AddSecretFilesBindMount adds the bind mount of the secret files of the container to its host config,
+// if any of its secrets are written to files.
+func (task *Task) AddSecretFilesBindMount(container *apicontainer.Container, hostConfig *dockercontainer.HostConfig,
+	config *config.Config) {
+	hasSecretFiles := false
+	if resources, ok := task.getSSMSecretsResource(); ok {
+		hasSecretFiles = resources[0].(*ssmsecret.SSMSecretResource).HasSecretFiles(container.Name)
+	}
+	if resources, ok := task.getASMSecretsResource(); ok && !hasSecretFiles {
+		hasSecretFiles = resources[0].(*asmsecret.ASMSecretResource).HasSecretFiles(container.Name)
+	}
+	if hasSecretFiles {
+		hostConfig.Binds = append(hostConfig.Binds,
+			secretfiles.ContainerBind(config.DataDirOnHost, task.GetID(), container.Name))
+	}
+}
+
 // IsNetworkModeAWSVPC checks if the task is configured to use the AWSVPC task networking feature.
 func (task *Task) IsNetworkModeAWSVPC() bool {
 	return task.NetworkMode == AWSVPCNetworkMode
@@ -2907,6 +3071,25 @@ func (task *Task) GetExecutionStoppedAt() time.Time {
 	return task.ExecutionStoppedAtUnsafe
 }
 
+// SetQueueStatus sets the position of the task in the queue of the tasks waiting for host resources, and
+// why it is waiting. A position of 0 means that the task is no longer waiting.
+func (task *Task) SetQueueStatus(position int, waitReason string) {
+	task.lock.Lock()
+	defer task.lock.Unlock()
+
+	task.queuePosition = position
+	task.waitReason = waitReason
+}
+
+// GetQueueStatus returns the position of the task in the queue of the tasks waiting for host resources,
+// and why it is waiting.
+func (task *Task) GetQueueStatus() (int, string) {
+	task.lock.RLock()
+	defer task.lock.RUnlock()
+
+	return task.queuePosition, task.waitReason
+}
+
 // String returns a human readable string representation of this object
 func (task *Task) String() string {
 	return task.stringUnsafe()
@@ -3102,6 +3285,7 @@ func (task *Task) getSSMSecretsResource() ([]taskresource.TaskResource, bool) {
 func (task *Task) PopulateSecrets(hostConfig *dockercontainer.HostConfig, container *apicontainer.Container) *apierrors.DockerClientConfigError {
 	var ssmRes *ssmsecret.SSMSecretResource
 	var asmRes *asmsecret.ASMSecretResource
+	var localRes *localsecret.LocalSecretResource
 
 	if container.ShouldCreateWithSSMSecret() {
 		resource, ok := task.getSSMSecretsResource()
@@ -3119,12 +3303,20 @@ func (task *Task) PopulateSecrets(hostConfig *dockercontainer.HostConfig, contai
 		asmRes = resource[0].(*asmsecret.ASMSecretResource)
 	}
 
-	populateContainerSecrets(hostConfig, container, ssmRes, asmRes)
+	if container.ShouldCreateWithLocalSecret() {
+		resource, ok := task.getLocalSecretsResource()
+		if !ok {
+			return &apierrors.DockerClientConfigError{Msg: "task secret data: unable to fetch local Secrets resource"}
+		}
+		localRes = resource[0].(*localsecret.LocalSecretResource)
+	}
+
+	populateContainerSecrets(hostConfig, container, ssmRes, asmRes, localRes)
 	return nil
 }
 
 func populateContainerSecrets(hostConfig *dockercontainer.HostConfig, container *apicontainer.Container,
-	ssmRes *ssmsecret.SSMSecretResource, asmRes *asmsecret.ASMSecretResource) {
+	ssmRes *ssmsecret.SSMSecretResource, asmRes *asmsecret.ASMSecretResource, localRes *localsecret.LocalSecretResource) {
 	envVars := make(map[string]string)
 
 	logDriverTokenName := ""
@@ -3133,20 +3325,27 @@ func populateContainerSecrets(hostConfig *dockercontainer.HostConfig, container
 	for _, secret := range container.Secrets {
 		secretVal := ""
 
-		if secret.Provider == apicontainer.SecretProviderSSM {
+		if secret.GetProvider() == apicontainer.SecretProviderSSM {
 			k := secret.GetSecretResourceCacheKey()
 			if secretValue, ok := ssmRes.GetCachedSecretValue(k); ok {
 				secretVal = secretValue
 			}
 		}
 
-		if secret.Provider == apicontainer.SecretProviderASM {
+		if secret.GetProvider() == apicontainer.SecretProviderASM {
 			k := secret.GetSecretResourceCacheKey()
 			if secretValue, ok := asmRes.GetCachedSecretValue(k); ok {
 				secretVal = secretValue
 			}
 		}
 
+		if secret.GetProvider() == apicontainer.SecretProviderLocal {
+			k := secret.GetSecretResourceCacheKey()
+			if secretValue, ok := localRes.GetCachedSecretValue(k); ok {
+				secretVal = secretValue
+			}
+		}
+
 		if secret.Type == apicontainer.SecretTypeEnv {
 			envVars[secret.Name] = secretVal
 			continue
@@ -3195,12 +3394,18 @@ func (task *Task) PopulateSecretLogOptionsToFirelensContainer(firelensContainer
 		asmRes = resource[0].(*asmsecret.ASMSecretResource)
 	}
 
+	var localRes *localsecret.LocalSecretResource
+	resource, ok = task.getLocalSecretsResource()
+	if ok {
+		localRes = resource[0].(*localsecret.LocalSecretResource)
+	}
+
 	for _, container := range task.Containers {
 		if container.GetLogDriver() != firelensDriverName {
 			continue
 		}
 
-		logDriverSecretData, err := collectLogDriverSecretData(container.Secrets, ssmRes, asmRes)
+		logDriverSecretData, err := collectLogDriverSecretData(container.Secrets, ssmRes, asmRes, localRes)
 		if err != nil {
 			return &apierrors.DockerClientConfigError{
 				Msg: fmt.Sprintf("unable to generate config to create firelens container: %v", err),
@@ -3225,7 +3430,7 @@ func (task *Task) PopulateSecretLogOptionsToFirelensContainer(firelensContainer
 
 // collectLogDriverSecretData collects all the secret values for log driver secrets.
 func collectLogDriverSecretData(secrets []apicontainer.Secret, ssmRes *ssmsecret.SSMSecretResource,
-	asmRes *asmsecret.ASMSecretResource) (map[string]string, error) {
+	asmRes *asmsecret.ASMSecretResource, localRes *localsecret.LocalSecretResource) (map[string]string, error) {
 	secretData := make(map[string]string)
 	for _, secret := range secrets {
 		if secret.Target != apicontainer.SecretTargetLogDriver {
@@ -3234,7 +3439,7 @@ func collectLogDriverSecretData(secrets []apicontainer.Secret, ssmRes *ssmsecret
 
 		secretVal := ""
 		cacheKey := secret.GetSecretResourceCacheKey()
-		if secret.Provider == apicontainer.SecretProviderSSM {
+		if secret.GetProvider() == apicontainer.SecretProviderSSM {
 			if ssmRes == nil {
 				return nil, errors.Errorf("missing secret value for secret %s", secret.Name)
 			}
@@ -3242,7 +3447,7 @@ func collectLogDriverSecretData(secrets []apicontainer.Secret, ssmRes *ssmsecret
 			if secretValue, ok := ssmRes.GetCachedSecretValue(cacheKey); ok {
 				secretVal = secretValue
 			}
-		} else if secret.Provider == apicontainer.SecretProviderASM {
+		} else if secret.GetProvider() == apicontainer.SecretProviderASM {
 			if asmRes == nil {
 				return nil, errors.Errorf("missing secret value for secret %s", secret.Name)
 			}
@@ -3250,6 +3455,14 @@ func collectLogDriverSecretData(secrets []apicontainer.Secret, ssmRes *ssmsecret
 			if secretValue, ok := asmRes.GetCachedSecretValue(cacheKey); ok {
 				secretVal = secretValue
 			}
+		} else if secret.GetProvider() == apicontainer.SecretProviderLocal {
+			if localRes == nil {
+				return nil, errors.Errorf("missing secret value for secret %s", secret.Name)
+			}
+
+			if secretValue, ok := localRes.GetCachedSecretValue(cacheKey); ok {
+				secretVal = secretValue
+			}
 		}
 
 		secretData[secret.Name] = secretVal
@@ -3267,6 +3480,15 @@ func (task *Task) getASMSecretsResource() ([]taskresource.TaskResource, bool) {
 	return res, ok
 }
 
+// getLocalSecretsResource retrieves localsecret resource from resource map
+func (task *Task) getLocalSecretsResource() ([]taskresource.TaskResource, bool) {
+	task.lock.RLock()
+	defer task.lock.RUnlock()
+
+	res, ok := task.ResourcesMapUnsafe[localsecret.ResourceName]
+	return res, ok
+}
+
 // InitializeResources initializes the required field in the task on agent restart
 // Some of the fields in task isn't saved in the agent state file, agent needs
 // to initialize these fields before processing the task, eg: docker client in resource
@@ -1309,7 +1309,7 @@ func TestCollectLogDriverSecretData(t *testing.T) {
 		},
 	}
 
-	secretData, err := collectLogDriverSecretData(secrets, ssmRes, asmRes)
+	secretData, err := collectLogDriverSecretData(secrets, ssmRes, asmRes, nil)
 	assert.NoError(t, err)
 	assert.Len(t, secretData, 2)
 	assert.Equal(t, "secret-val", secretData["secret-name"])
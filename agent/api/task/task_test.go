@@ -17,6 +17,7 @@
 package task
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"reflect"
@@ -40,10 +41,12 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
 	mock_dockerapi "github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi/mocks"
+	mock_localsecret "github.com/aws/amazon-ecs-agent/agent/localsecret/mocks"
 	mock_s3_factory "github.com/aws/amazon-ecs-agent/agent/s3/factory/mocks"
 	mock_ssm_factory "github.com/aws/amazon-ecs-agent/agent/ssm/factory/mocks"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/asmauth"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource/localsecret"
 	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
 	taskresourcevolume "github.com/aws/amazon-ecs-agent/agent/taskresource/volume"
 	"github.com/aws/amazon-ecs-agent/agent/utils"
@@ -59,6 +62,7 @@ import (
 
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/asmsecret"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/envFiles"
+	resourceplugin "github.com/aws/amazon-ecs-agent/agent/taskresource/plugin"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/ssmsecret"
 	"github.com/aws/aws-sdk-go/aws"
 	dockercontainer "github.com/docker/docker/api/types/container"
@@ -2888,7 +2892,7 @@ func TestPostUnmarshalTaskASMSecret(t *testing.T) {
 
 	resourceDep := apicontainer.ResourceDependency{
 		Name:           asmsecret.ResourceName,
-		RequiredStatus: resourcestatus.ResourceStatus(asmsecret.ASMSecretCreated),
+		RequiredStatus: resourcestatus.ResourceCreated,
 	}
 
 	err := task.PostUnmarshalTask(cfg, credentialsManager, resFields, nil, nil)
@@ -2986,7 +2990,7 @@ func TestInitializeAndGetSSMSecretResource(t *testing.T) {
 
 	resourceDep := apicontainer.ResourceDependency{
 		Name:           ssmsecret.ResourceName,
-		RequiredStatus: resourcestatus.ResourceStatus(ssmsecret.SSMSecretCreated),
+		RequiredStatus: resourcestatus.ResourceCreated,
 	}
 
 	assert.Equal(t, resourceDep, task.Containers[0].TransitionDependenciesMap[apicontainerstatus.ContainerCreated].ResourceDependencies[0])
@@ -2996,6 +3000,70 @@ func TestInitializeAndGetSSMSecretResource(t *testing.T) {
 	assert.True(t, ok)
 }
 
+func TestInitializeSecretFilesAndAddBindMount(t *testing.T) {
+	ssmSecret := apicontainer.Secret{Provider: "ssm", Name: "db-password", Region: "us-west-2", ValueFrom: "/db"}
+	asmSecret := apicontainer.Secret{Provider: "asm", Name: "api-key", Region: "us-west-2", ValueFrom: "api"}
+	task := &Task{
+		Arn:                "arn:aws:ecs:us-west-2:123456789012:task/cluster/task-id",
+		ResourcesMapUnsafe: make(map[string][]taskresource.TaskResource),
+		Containers: []*apicontainer.Container{
+			{
+				Name:                      "ssm",
+				Secrets:                   []apicontainer.Secret{ssmSecret},
+				TransitionDependenciesMap: make(map[apicontainerstatus.ContainerStatus]apicontainer.TransitionDependencySet),
+			},
+			{
+				Name:                      "asm",
+				Secrets:                   []apicontainer.Secret{asmSecret},
+				TransitionDependenciesMap: make(map[apicontainerstatus.ContainerStatus]apicontainer.TransitionDependencySet),
+			},
+			{
+				Name:                      "none",
+				TransitionDependenciesMap: make(map[apicontainerstatus.ContainerStatus]apicontainer.TransitionDependencySet),
+			},
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	credentialsManager := mock_credentials.NewMockManager(ctrl)
+	resFields := &taskresource.ResourceFields{
+		ResourceFieldsCommon: &taskresource.ResourceFieldsCommon{
+			SSMClientCreator:   mock_ssm_factory.NewMockSSMClientCreator(ctrl),
+			ASMClientCreator:   mock_asm_factory.NewMockClientCreator(ctrl),
+			CredentialsManager: credentialsManager,
+		},
+		Ctx: context.Background(),
+	}
+	cfg := &config.Config{
+		DataDir:                "/data",
+		DataDirOnHost:          "/var/lib/ecs",
+		SecretFilesEnabled:     config.BooleanDefaultFalse{Value: config.ExplicitlyEnabled},
+		SecretRotationInterval: time.Minute,
+	}
+	task.initSecretResources(cfg, credentialsManager, resFields)
+
+	for _, container := range task.Containers {
+		hostConfig := &dockercontainer.HostConfig{}
+		task.AddSecretFilesBindMount(container, hostConfig, cfg)
+		if container.Name == "none" {
+			assert.Empty(t, hostConfig.Binds)
+			continue
+		}
+		assert.Equal(t, []string{"/var/lib/ecs/data/secrets/task-id/" + container.Name + ":/run/secrets:ro"},
+			hostConfig.Binds)
+	}
+
+	// the secret files are disabled by default
+	task.ResourcesMapUnsafe = make(map[string][]taskresource.TaskResource)
+	cfg.SecretFilesEnabled = config.BooleanDefaultFalse{}
+	task.initSecretResources(cfg, credentialsManager, resFields)
+	hostConfig := &dockercontainer.HostConfig{}
+	task.AddSecretFilesBindMount(task.Containers[0], hostConfig, cfg)
+	assert.Empty(t, hostConfig.Binds)
+}
+
 func TestRequiresSSMSecret(t *testing.T) {
 	secret := apicontainer.Secret{
 		Provider:  "ssm",
@@ -3202,7 +3270,7 @@ func TestInitializeAndGetASMSecretResource(t *testing.T) {
 
 	resourceDep := apicontainer.ResourceDependency{
 		Name:           asmsecret.ResourceName,
-		RequiredStatus: resourcestatus.ResourceStatus(asmsecret.ASMSecretCreated),
+		RequiredStatus: resourcestatus.ResourceCreated,
 	}
 
 	assert.Equal(t, resourceDep, task.Containers[0].TransitionDependenciesMap[apicontainerstatus.ContainerCreated].ResourceDependencies[0])
@@ -3366,6 +3434,65 @@ func TestPopulateSecretsAsEnvOnlySSM(t *testing.T) {
 	assert.Equal(t, 1, len(container.Environment))
 }
 
+func TestInitializeAndPopulateLocalSecrets(t *testing.T) {
+	localSecret := apicontainer.Secret{
+		Provider:  "ssm",
+		Name:      "DB_PASSWORD",
+		Region:    "us-west-2",
+		Type:      "ENVIRONMENT_VARIABLE",
+		ValueFrom: "local-file://db/password",
+	}
+	ssmSecret := apicontainer.Secret{
+		Provider:  "ssm",
+		Name:      "API_KEY",
+		Region:    "us-west-2",
+		Type:      "ENVIRONMENT_VARIABLE",
+		ValueFrom: "/test/secretName",
+	}
+	container := &apicontainer.Container{
+		Name:                      "myName",
+		Image:                     "image:tag",
+		Secrets:                   []apicontainer.Secret{localSecret, ssmSecret},
+		TransitionDependenciesMap: make(map[apicontainerstatus.ContainerStatus]apicontainer.TransitionDependencySet),
+	}
+	task := &Task{
+		Arn:                "test",
+		ResourcesMapUnsafe: make(map[string][]taskresource.TaskResource),
+		Containers:         []*apicontainer.Container{container},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	credentialsManager := mock_credentials.NewMockManager(ctrl)
+	localSecretClient := mock_localsecret.NewMockClient(ctrl)
+	resFields := &taskresource.ResourceFields{
+		ResourceFieldsCommon: &taskresource.ResourceFieldsCommon{
+			SSMClientCreator:   mock_ssm_factory.NewMockSSMClientCreator(ctrl),
+			CredentialsManager: credentialsManager,
+			LocalSecretClient:  localSecretClient,
+		},
+	}
+	task.initSecretResources(&config.Config{}, credentialsManager, resFields)
+
+	assert.Equal(t, map[string][]apicontainer.Secret{"us-west-2": {ssmSecret}}, task.getAllSSMSecretRequirements(),
+		"local secrets aren't retrieved from SSM")
+	resources, ok := task.getLocalSecretsResource()
+	require.True(t, ok)
+	assert.Contains(t, container.TransitionDependenciesMap[apicontainerstatus.ContainerCreated].ResourceDependencies,
+		apicontainer.ResourceDependency{Name: localsecret.ResourceName, RequiredStatus: resourcestatus.ResourceCreated})
+
+	localSecretClient.EXPECT().GetSecret("local-file://db/password").Return("hunter2", nil)
+	require.NoError(t, resources[0].Create())
+	ssmRes, ok := task.getSSMSecretsResource()
+	require.True(t, ok)
+	ssmRes[0].(*ssmsecret.SSMSecretResource).SetCachedSecretValue(ssmSecret.GetSecretResourceCacheKey(), "api-key")
+
+	require.Nil(t, task.PopulateSecrets(&dockercontainer.HostConfig{}, container))
+	assert.Equal(t, "hunter2", container.Environment["DB_PASSWORD"])
+	assert.Equal(t, "api-key", container.Environment["API_KEY"])
+}
+
 func TestAddGPUResource(t *testing.T) {
 	container := &apicontainer.Container{
 		Name:  "myName",
@@ -3783,6 +3910,49 @@ func TestTaskFromACSPerContainerTimeouts(t *testing.T) {
 	assert.Equal(t, task.Containers[0].StopTimeout, expectedTimeout)
 }
 
+func TestTaskFromACSRestartPolicies(t *testing.T) {
+	taskFromACS := ecsacs.Task{
+		Containers: []*ecsacs.Container{
+			{
+				Name:          aws.String("app"),
+				Essential:     aws.Bool(true),
+				RestartPolicy: aws.String(apicontainer.RestartPolicyOnFailure),
+			},
+			{
+				Name:               aws.String("log-router"),
+				Essential:          aws.Bool(false),
+				RestartPolicy:      aws.String(apicontainer.RestartPolicyOnFailure),
+				RestartMaxAttempts: aws.Int64(3),
+				DockerConfig: &ecsacs.DockerConfig{
+					Config: aws.String(`{"Labels":{"com.amazonaws.ecs.restart-ignored-exit-codes":"143","com.amazonaws.ecs.restart-attempt-period":"30"}}`),
+				},
+			},
+			{
+				Name:          aws.String("invalid"),
+				Essential:     aws.Bool(false),
+				RestartPolicy: aws.String("ALWAYS"),
+			},
+		},
+	}
+	seqNum := int64(42)
+	task, err := TaskFromACS(&taskFromACS, &ecsacs.PayloadMessage{SeqNum: &seqNum})
+	require.NoError(t, err)
+
+	app, _ := task.ContainerByName("app")
+	assert.False(t, app.RestartPolicyEnabled(), "essential containers are not restarted in place")
+	invalid, _ := task.ContainerByName("invalid")
+	assert.False(t, invalid.RestartPolicyEnabled())
+
+	logRouter, _ := task.ContainerByName("log-router")
+	require.True(t, logRouter.RestartPolicyEnabled())
+	assert.Equal(t, &apicontainer.RestartPolicy{
+		IgnoredExitCodes:     []int{0, 143},
+		RestartAttemptPeriod: 30 * time.Second,
+		MaxAttempts:          3,
+	}, logRouter.RestartPolicy)
+	assert.Equal(t, 0, logRouter.RestartTracker.GetRestartCount())
+}
+
 // Tests that ACS Task to Task translation does not fail when ServiceName is missing.
 // Asserts that Task.ServiceName is empty in such a case.
 func TestTaskFromACSServiceNameMissing(t *testing.T) {
@@ -3925,6 +4095,48 @@ func TestInitializeAndGetEnvfilesResource(t *testing.T) {
 	assert.True(t, ok)
 }
 
+func TestInitializePluginResources(t *testing.T) {
+	require.NoError(t, resourceplugin.Register(resourceplugin.Plugin{
+		Name: "configBundle",
+		Requires: func(container *apicontainer.Container) bool {
+			return container.Name != "sidecar"
+		},
+		Create:  func(*resourceplugin.Resource) error { return nil },
+		Cleanup: func(*resourceplugin.Resource) error { return nil },
+	}))
+
+	newContainer := func(name string) *apicontainer.Container {
+		return &apicontainer.Container{
+			Name:                      name,
+			TransitionDependenciesMap: make(map[apicontainerstatus.ContainerStatus]apicontainer.TransitionDependencySet),
+		}
+	}
+	task := &Task{
+		Arn:                "testArn",
+		ResourcesMapUnsafe: make(map[string][]taskresource.TaskResource),
+		Containers:         []*apicontainer.Container{newContainer("app"), newContainer("sidecar")},
+	}
+	task.initializePluginResources(&taskresource.ResourceFields{})
+
+	require.Len(t, task.ResourcesMapUnsafe["configBundle"], 1)
+	resourceDep := apicontainer.ResourceDependency{
+		Name:           "configBundle",
+		RequiredStatus: resourcestatus.ResourceCreated,
+	}
+	assert.Equal(t, []apicontainer.ResourceDependency{resourceDep},
+		task.Containers[0].TransitionDependenciesMap[apicontainerstatus.ContainerCreated].ResourceDependencies)
+	assert.Empty(t, task.Containers[1].TransitionDependenciesMap[apicontainerstatus.ContainerCreated].ResourceDependencies)
+
+	// a task without containers requiring the resource doesn't get one
+	task = &Task{
+		Arn:                "testArn2",
+		ResourcesMapUnsafe: make(map[string][]taskresource.TaskResource),
+		Containers:         []*apicontainer.Container{newContainer("sidecar")},
+	}
+	task.initializePluginResources(&taskresource.ResourceFields{})
+	assert.Empty(t, task.ResourcesMapUnsafe)
+}
+
 func TestRequiresEnvfiles(t *testing.T) {
 	envfile := apicontainer.EnvironmentFile{
 		Value: "s3://bucket/envfile",
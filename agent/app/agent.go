@@ -29,6 +29,8 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/containermetadata"
 	"github.com/aws/amazon-ecs-agent/agent/credentials/instancecreds"
 	"github.com/aws/amazon-ecs-agent/agent/data"
+	// register the sqlite data store driver
+	_ "github.com/aws/amazon-ecs-agent/agent/data/sqlite"
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient/sdkclientfactory"
@@ -45,13 +47,16 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/eni/watcher"
 	"github.com/aws/amazon-ecs-agent/agent/eventhandler"
 	"github.com/aws/amazon-ecs-agent/agent/handlers"
+	"github.com/aws/amazon-ecs-agent/agent/localsecret"
 	"github.com/aws/amazon-ecs-agent/agent/metrics"
 	"github.com/aws/amazon-ecs-agent/agent/sighandlers"
 	"github.com/aws/amazon-ecs-agent/agent/sighandlers/exitcodes"
+	"github.com/aws/amazon-ecs-agent/agent/standalone"
 	"github.com/aws/amazon-ecs-agent/agent/statemanager"
 	"github.com/aws/amazon-ecs-agent/agent/stats"
 	"github.com/aws/amazon-ecs-agent/agent/stats/reporter"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
+	"github.com/aws/amazon-ecs-agent/agent/tracing"
 	"github.com/aws/amazon-ecs-agent/agent/utils"
 	"github.com/aws/amazon-ecs-agent/agent/utils/loader"
 	"github.com/aws/amazon-ecs-agent/agent/utils/mobypkgwrapper"
@@ -65,7 +70,6 @@ import (
 	"github.com/aws/amazon-ecs-agent/ecs-agent/eventstream"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
-	metricsfactory "github.com/aws/amazon-ecs-agent/ecs-agent/metrics"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/tcs/model/ecstcs"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/retry"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/wsclient"
@@ -80,6 +84,7 @@ import (
 const (
 	containerChangeEventStreamName             = "ContainerChange"
 	deregisterContainerInstanceEventStreamName = "DeregisterContainerInstance"
+	introspectionEventStreamName               = "IntrospectionEvents"
 	clusterMismatchErrorFormat                 = "Data mismatch; saved cluster '%v' does not match configured cluster '%v'. Perhaps you want to delete the configured checkpoint file?"
 	instanceIDMismatchErrorFormat              = "Data mismatch; saved InstanceID '%s' does not match current InstanceID '%s'. Overwriting old datafile"
 	instanceTypeMismatchErrorFormat            = "The current instance type does not match the registered instance type. Please revert the instance type change, or alternatively launch a new instance: %v"
@@ -126,6 +131,8 @@ type agent interface {
 	printECSAttributes() int
 	// startWindowsService starts the agent as a Windows Service
 	startWindowsService() int
+	// migrateDataStore copies the agent state from boltdb to the configured data store
+	migrateDataStore() int
 	// start starts the Agent execution
 	start() int
 	// setTerminationHandler sets the termination handler
@@ -152,6 +159,7 @@ type ecsAgent struct {
 	pauseLoader                 loader.Loader
 	serviceconnectManager       engineserviceconnect.Manager
 	daemonManagers              map[string]dm.DaemonManager
+	daemonSupervisor            *dm.Supervisor
 	eniWatcher                  *watcher.ENIWatcher
 	ebsWatcher                  *ebs.EBSWatcher
 	cniClient                   ecscni.CNIClient
@@ -212,9 +220,9 @@ func newAgent(blackholeEC2Metadata bool, acceptInsecureCert *bool) (agent, error
 
 	var dataClient data.Client
 	if cfg.Checkpoint.Enabled() {
-		dataClient, err = data.New(cfg.DataDir)
+		dataClient, err = data.NewWithDriver(cfg.DataStoreDriver, cfg.DataDir)
 		if err != nil {
-			logger.Critical("Error creating Docker client", logger.Fields{
+			logger.Critical("Error creating data client", logger.Fields{
 				field.Error: err,
 			})
 			cancel()
@@ -289,6 +297,10 @@ func (agent *ecsAgent) start() int {
 	state := dockerstate.NewTaskEngineState()
 	imageManager := engine.NewImageManager(agent.cfg, agent.dockerClient, state)
 	client := ecsclient.NewECSClient(agent.credentialProvider, agent.cfg, agent.ec2MetadataClient)
+	if agent.cfg.Standalone.Enabled() {
+		// Record the state changes locally instead of submitting them to ECS
+		client = standalone.NewClient(agent.cfg, client)
+	}
 
 	agent.initializeResourceFields(credentialsManager)
 	return agent.doStart(containerChangeEventStream, credentialsManager, state, imageManager, client, execcmd.NewManager())
@@ -365,6 +377,9 @@ func (agent *ecsAgent) doStart(containerChangeEventStream *eventstream.EventStre
 
 	agent.initMetricsEngine()
 
+	// Start exporting the task lifecycle traces when tracing is enabled
+	tracing.Init(agent.ctx, agent.cfg)
+
 	loadPauseErr := agent.loadPauseContainer()
 	if loadPauseErr != nil {
 		seelog.Errorf("Failed to load pause container: %v", loadPauseErr)
@@ -404,7 +419,7 @@ func (agent *ecsAgent) doStart(containerChangeEventStream *eventstream.EventStre
 			}
 			return exitcodes.ExitError
 		}
-	} else if !agent.cfg.External.Enabled() {
+	} else if !agent.cfg.External.Enabled() && !agent.cfg.Standalone.Enabled() {
 		// Set VPC and Subnet IDs for the EC2 instance
 		err, terminal := agent.setVPCSubnet()
 		switch err {
@@ -432,6 +447,7 @@ func (agent *ecsAgent) doStart(containerChangeEventStream *eventstream.EventStre
 		}
 		return exitcodes.ExitTerminal
 	}
+	metrics.MetricsEngineGlobal.SetEntryTags(agent.cfg.Cluster, agent.containerInstanceARN)
 
 	scManager := agent.serviceconnectManager
 	scManager.SetECSClient(client, agent.containerInstanceARN)
@@ -462,6 +478,8 @@ func (agent *ecsAgent) doStart(containerChangeEventStream *eventstream.EventStre
 		agent.saveMetadata(data.EC2InstanceIDKey, currentEC2InstanceID)
 	}
 
+	agent.daemonSupervisor = dm.NewSupervisor(taskEngine)
+
 	// now that we know the container instance ARN, we can create the doctor
 	// and pass it on to ACS and TACS
 	doctor, doctorCreateErr := agent.newDoctorWithHealthchecks(agent.cfg.Cluster, agent.containerInstanceARN)
@@ -473,8 +491,10 @@ func (agent *ecsAgent) doStart(containerChangeEventStream *eventstream.EventStre
 
 	// Begin listening to the docker daemon and saving changes
 	taskEngine.SetDataClient(agent.dataClient)
+	taskEngine.SetECSClient(client)
 	imageManager.SetDataClient(agent.dataClient)
 	taskEngine.MustInit(agent.ctx)
+	go agent.daemonSupervisor.Run(agent.ctx)
 
 	// Start back ground routines, including the telemetry session
 	deregisterInstanceEventStream := eventstream.NewEventStream(
@@ -486,6 +506,10 @@ func (agent *ecsAgent) doStart(containerChangeEventStream *eventstream.EventStre
 		taskEngine, deregisterInstanceEventStream, client, taskHandler, attachmentEventHandler, state, doctor)
 	// TODO add EBS watcher to async routines
 	agent.startEBSWatcher(state, taskEngine)
+	if agent.cfg.Standalone.Enabled() {
+		// Run the local tasks instead of the acs session, which should block doStart
+		return agent.startStandaloneSource(taskEngine)
+	}
 	// Start the acs session, which should block doStart
 	return agent.startACSSession(credentialsManager, taskEngine,
 		deregisterInstanceEventStream, client, state, taskHandler, doctor)
@@ -632,6 +656,10 @@ func (agent *ecsAgent) initMetricsEngine() {
 	// We init the global MetricsEngine before we publish metrics
 	metrics.MustInit(agent.cfg)
 	metrics.PublishMetrics()
+	if err := metrics.InitEntryFactory(agent.ctx, agent.cfg); err != nil {
+		seelog.Warnf("Unable to set up the %s metrics sink, the metrics will not be published to it: %v",
+			agent.cfg.MetricsSink, err)
+	}
 }
 
 // newDoctorWithHealthchecks creates a new doctor and also configures
@@ -644,6 +672,9 @@ func (agent *ecsAgent) newDoctorWithHealthchecks(cluster, containerInstanceARN s
 	healthcheckList := []doctor.Healthcheck{
 		runtimeHealthCheck,
 	}
+	if agent.daemonSupervisor != nil {
+		healthcheckList = append(healthcheckList, dockerdoctor.NewManagedDaemonHealthcheck(agent.daemonSupervisor))
+	}
 
 	// set up the doctor and return it
 	return doctor.NewDoctor(healthcheckList, cluster, containerInstanceARN)
@@ -678,6 +709,16 @@ func (agent *ecsAgent) setClusterInConfig(previousCluster string) error {
 	return nil
 }
 
+// newLocalSecretClient creates the client of the local secrets of the instance
+func (agent *ecsAgent) newLocalSecretClient() localsecret.Client {
+	return localsecret.NewClient(localsecret.Config{
+		File:      agent.cfg.LocalSecretsFile,
+		KeyFile:   agent.cfg.LocalSecretsKeyFile,
+		Endpoint:  agent.cfg.LocalSecretsEndpoint,
+		TokenFile: agent.cfg.LocalSecretsTokenFile,
+	})
+}
+
 // getEC2InstanceID gets the EC2 instance ID from the metadata service
 func (agent *ecsAgent) getEC2InstanceID() string {
 	var instanceID string
@@ -877,23 +918,35 @@ func (agent *ecsAgent) startAsyncRoutines(
 	doctor *doctor.Doctor,
 ) {
 
+	// Stream of the state changes and image cleanups served by the introspection api
+	introspectionEventStream := eventstream.NewEventStream(introspectionEventStreamName, agent.ctx)
+	introspectionEventStream.StartListening()
+	imageManager.SetEventStream(introspectionEventStream)
+
 	// Start of the periodic image cleanup process
 	if !agent.cfg.ImageCleanupDisabled.Enabled() {
 		go imageManager.StartImageCleanupProcess(agent.ctx)
 	}
 
+	// Start of the background image pre-warm process
+	if agent.cfg.ImagePrewarmEnabled.Enabled() {
+		go imageManager.StartImagePrewarmProcess(agent.ctx)
+	}
+
 	// Start automatic spot instance draining poller routine
 	if agent.cfg.SpotInstanceDrainingEnabled.Enabled() {
 		go agent.startSpotInstanceDrainingPoller(agent.ctx, client)
 	}
 
-	// Agent introspection api
-	go handlers.ServeIntrospectionHTTPEndpoint(agent.ctx, &agent.containerInstanceARN, taskEngine, agent.cfg)
-
 	telemetryMessages := make(chan ecstcs.TelemetryMessage, telemetryChannelDefaultBufferSize)
 	healthMessages := make(chan ecstcs.HealthMessage, telemetryChannelDefaultBufferSize)
 
 	statsEngine := stats.NewDockerStatsEngine(agent.cfg, agent.dockerClient, containerChangeEventStream, telemetryMessages, healthMessages)
+	statsEngine.SetDataClient(agent.dataClient)
+
+	// Agent introspection api
+	go handlers.ServeIntrospectionHTTPEndpoint(agent.ctx, &agent.containerInstanceARN, taskEngine, statsEngine, introspectionEventStream,
+		agent.daemonSupervisor, agent.cfg)
 
 	// Start serving the endpoint to fetch IAM Role credentials and other task metadata
 	if agent.cfg.TaskMetadataAZDisabled {
@@ -904,7 +957,7 @@ func (agent *ecsAgent) startAsyncRoutines(
 	}
 
 	// Start sending events to the backend
-	go eventhandler.HandleEngineEvents(agent.ctx, taskEngine, client, taskHandler, attachmentEventHandler)
+	go eventhandler.HandleEngineEvents(agent.ctx, taskEngine, client, taskHandler, attachmentEventHandler, introspectionEventStream)
 
 	err := statsEngine.MustInit(agent.ctx, taskEngine, agent.cfg.Cluster, agent.containerInstanceARN)
 	if err != nil {
@@ -913,6 +966,16 @@ func (agent *ecsAgent) startAsyncRoutines(
 	}
 	go statsEngine.StartMetricsPublish()
 
+	// Start serving the task and container stats to Prometheus scrapers
+	if agent.cfg.TaskMetricsExporterEnabled.Enabled() {
+		go handlers.ServeTaskMetricsHTTPEndpoint(agent.ctx, stats.NewTaskMetricsExporter(statsEngine))
+	}
+
+	// There is no telemetry service to send the metrics to in standalone mode
+	if agent.cfg.Standalone.Enabled() {
+		return
+	}
+
 	session, err := reporter.NewDockerTelemetrySession(agent.containerInstanceARN, agent.credentialProvider, agent.cfg, deregisterInstanceEventStream,
 		client, taskEngine, telemetryMessages, healthMessages, doctor)
 	if err != nil {
@@ -974,6 +1037,18 @@ func (agent *ecsAgent) spotInstanceDrainingPoller(client api.ECSClient) bool {
 	return false
 }
 
+// startStandaloneSource runs the tasks of the local task definitions. This is a blocking
+// call and only returns when the agent is stopped
+func (agent *ecsAgent) startStandaloneSource(taskEngine engine.TaskEngine) int {
+	if err := standalone.NewSource(agent.cfg, taskEngine).Run(agent.ctx); err != nil {
+		logger.Critical("Unable to run standalone tasks", logger.Fields{
+			field.Error: err,
+		})
+		return exitcodes.ExitTerminal
+	}
+	return exitcodes.ExitSuccess
+}
+
 // startACSSession starts a session with ECS's Agent Communication service. This
 // is a blocking call and only returns when the handler returns
 func (agent *ecsAgent) startACSSession(
@@ -1006,10 +1081,13 @@ func (agent *ecsAgent) startACSSession(
 	}
 
 	minAgentCfg := &wsclient.WSClientMinAgentConfig{
-		AcceptInsecureCert: agent.cfg.AcceptInsecureCert,
-		AWSRegion:          agent.cfg.AWSRegion,
-		DockerEndpoint:     agent.cfg.DockerEndpoint,
-		IsDocker:           true,
+		AcceptInsecureCert:    agent.cfg.AcceptInsecureCert,
+		AWSRegion:             agent.cfg.AWSRegion,
+		DockerEndpoint:        agent.cfg.DockerEndpoint,
+		IsDocker:              true,
+		DisableCompression:    agent.cfg.WebsocketCompressionDisabled.Enabled(),
+		MaxInboundMessageSize: agent.cfg.ACSMaxMessageSize,
+		MessageMetrics:        metrics.MetricsEngineGlobal.WebsocketMessageRecorder(metrics.WebsocketClientACS),
 	}
 
 	payloadMessageHandler := agentacs.NewPayloadMessageHandler(taskEngine, client, agent.dataClient, taskHandler,
@@ -1027,7 +1105,7 @@ func (agent *ecsAgent) startACSSession(
 		agent.credentialProvider,
 		inactiveInstanceCB,
 		acsclient.NewACSClientFactory(),
-		metricsfactory.NewNopEntryFactory(),
+		metrics.MetricsEngineGlobal.EntryFactory(),
 		version.Version,
 		version.GitHashString(),
 		dockerVersion,
@@ -507,33 +507,50 @@ func (agent *ecsAgent) appendServiceConnectCapabilities(capabilities []*ecs.Attr
 }
 
 func (agent *ecsAgent) appendEBSTaskAttachCapabilities(capabilities []*ecs.Attribute) []*ecs.Attribute {
-	// todo update to import multiple daemons and append capabilities
-	// for now load only EBS CSI Driver daemon
+	agent.loadManagedDaemons()
+	if _, ok := agent.daemonManagers[md.EbsCsiDriver]; !ok {
+		return capabilities
+	}
+	capabilities = appendNameOnlyAttribute(capabilities, attributePrefix+capabilityEBSTaskAttach)
+	return capabilities
+}
+
+// loadManagedDaemons imports the managed daemon definitions and loads the image of each daemon.
+// A daemon manager is set for every daemon whose image could be loaded; the task engine runs a
+// daemon task for each of them.
+func (agent *ecsAgent) loadManagedDaemons() {
 	daemonDefinitions, err := md.ImportAll()
 	if err != nil {
 		logger.Error(fmt.Sprintf("Daemon import failure: %s", err))
-		return capabilities
+		return
 	}
 	if len(daemonDefinitions) == 0 {
 		logger.Warn("daemonDefinitions is empty/nil after import")
-		return capabilities
+		return
 	}
 	for _, daemonDef := range daemonDefinitions {
-		if daemonDef.GetImageName() == md.EbsCsiDriver {
-			csiDaemonManager := dm.NewDaemonManager(daemonDef)
-			agent.setDaemonManager(md.EbsCsiDriver, csiDaemonManager)
-			if _, err := csiDaemonManager.LoadImage(agent.ctx, agent.dockerClient); err != nil {
+		daemonName := daemonDef.GetImageName()
+		if _, ok := agent.daemonManagers[daemonName]; ok {
+			continue
+		}
+		daemonManager := dm.NewDaemonManager(daemonDef)
+		if _, err := daemonManager.LoadImage(agent.ctx, agent.dockerClient); err != nil {
+			if daemonName == md.EbsCsiDriver {
 				logger.Error("Failed to load the EBS CSI Driver. This container instance will not be able to support EBS Task Attach",
 					logger.Fields{
 						field.Error: err,
 					},
 				)
-				return capabilities
+			} else {
+				logger.Error("Failed to load the managed daemon image", logger.Fields{
+					field.Image: daemonName,
+					field.Error: err,
+				})
 			}
+			continue
 		}
+		agent.setDaemonManager(daemonName, daemonManager)
 	}
-	capabilities = appendNameOnlyAttribute(capabilities, attributePrefix+capabilityEBSTaskAttach)
-	return capabilities
 }
 
 func defaultGetSubDirectories(path string) ([]string, error) {
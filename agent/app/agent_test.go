@@ -468,6 +468,7 @@ func testDoStartHappyPathWithConditions(t *testing.T, blackholed bool, warmPools
 	}
 
 	imageManager.EXPECT().AddImageToCleanUpExclusionList(gomock.Eq("service_connect_agent:v1")).Times(1)
+	imageManager.EXPECT().SetEventStream(gomock.Any()).MaxTimes(1)
 	imageManager.EXPECT().StartImageCleanupProcess(gomock.Any()).MaxTimes(1)
 	dockerClient.EXPECT().ListContainers(gomock.Any(), gomock.Any(), gomock.Any()).Return(
 		dockerapi.ListContainersResponse{}).AnyTimes()
@@ -170,6 +170,7 @@ func (agent *ecsAgent) initializeResourceFields(credentialsManager credentials.M
 			SSMClientCreator:   ssmfactory.NewSSMClientCreator(),
 			S3ClientCreator:    s3factory.NewS3ClientCreator(),
 			CredentialsManager: credentialsManager,
+			LocalSecretClient:  agent.newLocalSecretClient(),
 			EC2InstanceID:      agent.getEC2InstanceID(),
 		},
 		Ctx:              agent.ctx,
@@ -314,6 +314,7 @@ func (agent *ecsAgent) initializeResourceFields(credentialsManager credentials.M
 			FSxClientCreator:   fsxfactory.NewFSxClientCreator(),
 			S3ClientCreator:    s3factory.NewS3ClientCreator(),
 			CredentialsManager: credentialsManager,
+			LocalSecretClient:  agent.newLocalSecretClient(),
 		},
 		Ctx:          agent.ctx,
 		DockerClient: agent.dockerClient,
@@ -50,6 +50,7 @@ func (m *mockAgent) setTerminationHandler(handler sighandlers.TerminationHandler
 }
 func (m *mockAgent) printECSAttributes() int   { return 0 }
 func (m *mockAgent) startWindowsService() int  { return 0 }
+func (m *mockAgent) migrateDataStore() int     { return 0 }
 func (m *mockAgent) getConfig() *config.Config { return &config.Config{} }
 
 func TestHandler_RunAgent_StartExitImmediately(t *testing.T) {
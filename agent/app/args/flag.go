@@ -28,6 +28,7 @@ const (
 	blacholeEC2MetadataUsage = "Blackhole the EC2 Metadata requests. Setting this option can cause the ECS Agent to fail to work properly.  We do not recommend setting this option"
 	windowsServiceUsage      = "Run the ECS agent as a Windows Service"
 	healthcheckServiceUsage  = "Run the agent healthcheck"
+	migrateDataStoreUsage    = "Copy the agent state from the boltdb data store to the data store configured with ECS_DATA_STORE_DRIVER and exit"
 
 	versionFlagName              = "version"
 	logLevelFlagName             = "loglevel"
@@ -39,6 +40,7 @@ const (
 	blackholeEC2MetadataFlagName = "blackhole-ec2-metadata"
 	windowsServiceFlagName       = "windows-service"
 	healthCheckFlagName          = "healthcheck"
+	migrateDataStoreFlagName     = "migrate-data-store"
 )
 
 // Args wraps various ECS Agent arguments
@@ -65,6 +67,8 @@ type Args struct {
 	WindowsService *bool
 	// Healthcheck indicates that agent should run healthcheck
 	Healthcheck *bool
+	// MigrateDataStore indicates that the agent should migrate its state to the configured data store
+	MigrateDataStore *bool
 }
 
 // New creates a new Args object from the argument list
@@ -82,6 +86,7 @@ func New(arguments []string) (*Args, error) {
 		ECSAttributes:        flagset.Bool(ecsAttributesFlagName, false, ecsAttributesUsage),
 		WindowsService:       flagset.Bool(windowsServiceFlagName, false, windowsServiceUsage),
 		Healthcheck:          flagset.Bool(healthCheckFlagName, false, healthcheckServiceUsage),
+		MigrateDataStore:     flagset.Bool(migrateDataStoreFlagName, false, migrateDataStoreUsage),
 	}
 
 	err := flagset.Parse(arguments)
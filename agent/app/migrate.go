@@ -0,0 +1,61 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package app
+
+import (
+	"github.com/aws/amazon-ecs-agent/agent/data"
+	"github.com/aws/amazon-ecs-agent/agent/sighandlers/exitcodes"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+)
+
+// migrateDataStore copies the agent state persisted in the boltdb data store under the data directory
+// to the data store configured with ECS_DATA_STORE_DRIVER, which the agent has already opened.
+func (agent *ecsAgent) migrateDataStore() int {
+	fields := logger.Fields{
+		"dataDir": agent.cfg.DataDir,
+		"driver":  agent.cfg.DataStoreDriver,
+	}
+	if !agent.cfg.Checkpoint.Enabled() {
+		logger.Critical("Unable to migrate the data store, checkpointing is disabled", fields)
+		return exitcodes.ExitTerminal
+	}
+	if agent.cfg.DataStoreDriver == data.BoltDBDriver {
+		logger.Critical("Unable to migrate the data store, the configured data store driver is already boltdb", fields)
+		return exitcodes.ExitTerminal
+	}
+
+	src, err := data.Open(data.BoltDBDriver, agent.cfg.DataDir)
+	if err != nil {
+		logger.Critical("Error opening the boltdb data store", fields, logger.Fields{field.Error: err})
+		return exitcodes.ExitError
+	}
+	defer src.Close()
+	defer agent.dataClient.Close()
+
+	summary, err := data.Migrate(src, agent.dataClient)
+	if err != nil {
+		logger.Critical("Error migrating the data store", fields, logger.Fields{field.Error: err})
+		return exitcodes.ExitError
+	}
+	logger.Info("Migrated the data store", fields, logger.Fields{
+		"tasks":          summary.Tasks,
+		"containers":     summary.Containers,
+		"imageStates":    summary.ImageStates,
+		"eniAttachments": summary.ENIAttachments,
+		"resourceUsages": summary.ResourceUsages,
+		"metadata":       summary.Metadata,
+	})
+	return exitcodes.ExitSuccess
+}
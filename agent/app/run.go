@@ -81,6 +81,9 @@ func Run(arguments []string) int {
 	case *parsedArgs.ECSAttributes:
 		// Print agent's ecs attributes based on its environment and exit
 		return agent.printECSAttributes()
+	case *parsedArgs.MigrateDataStore:
+		// Copy the agent state from boltdb to the configured data store and exit
+		return agent.migrateDataStore()
 	case *parsedArgs.WindowsService:
 		// Enable Windows Service
 		return agent.startWindowsService()
@@ -0,0 +1,93 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// agent-state prints the state persisted by the ECS agent in its data directory. The boltdb file is
+// opened in read-only mode; when the agent is running, inspect a copy of the file instead.
+//
+//	agent-state [-format table|json] [-task ARN] [-status STATUS] <data dir or agent.db>
+//	agent-state -diff [-format table|json] [-task ARN] [-status STATUS] <old> <new>
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/aws/amazon-ecs-agent/agent/data/inspect"
+)
+
+const (
+	exitSuccess = 0
+	exitError   = 1
+	exitUsage   = 2
+)
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(arguments []string) int {
+	flagset := flag.NewFlagSet("agent-state", flag.ContinueOnError)
+	format := flagset.String("format", inspect.FormatTable, "Output format: [<table>|<json>]")
+	taskARN := flagset.String("task", "", "Only print the task with this ARN and the objects that belong to it")
+	status := flagset.String("status", "", "Only print the tasks, containers and ENI attachments with this known status")
+	diff := flagset.Bool("diff", false, "Print the differences between two snapshots of the data directory")
+	flagset.Usage = func() {
+		fmt.Fprintln(flagset.Output(), "Usage: agent-state [flags] <data dir or agent.db>")
+		fmt.Fprintln(flagset.Output(), "       agent-state -diff [flags] <old data dir or agent.db> <new data dir or agent.db>")
+		flagset.PrintDefaults()
+	}
+	if err := flagset.Parse(arguments); err != nil {
+		return exitUsage
+	}
+	if *format != inspect.FormatTable && *format != inspect.FormatJSON {
+		fmt.Fprintf(os.Stderr, "unsupported format %q\n", *format)
+		return exitUsage
+	}
+	expectedArgs := 1
+	if *diff {
+		expectedArgs = 2
+	}
+	if flagset.NArg() != expectedArgs {
+		flagset.Usage()
+		return exitUsage
+	}
+
+	filter := inspect.Filter{TaskARN: *taskARN, Status: *status}
+	var snapshots []*inspect.Snapshot
+	for _, path := range flagset.Args() {
+		snapshot, err := inspect.Load(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "unable to load %s: %v\n", path, err)
+			return exitError
+		}
+		snapshots = append(snapshots, snapshot.Filter(filter))
+	}
+
+	var err error
+	switch {
+	case *diff && *format == inspect.FormatJSON:
+		err = inspect.WriteJSON(os.Stdout, inspect.Diff(snapshots[0], snapshots[1]))
+	case *diff:
+		err = inspect.WriteDiffTable(os.Stdout, inspect.Diff(snapshots[0], snapshots[1]))
+	case *format == inspect.FormatJSON:
+		err = inspect.WriteJSON(os.Stdout, snapshots[0])
+	default:
+		err = inspect.WriteTable(os.Stdout, snapshots[0])
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to write output: %v\n", err)
+		return exitError
+	}
+	return exitSuccess
+}
@@ -18,7 +18,9 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"net/url"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"time"
@@ -48,9 +50,111 @@ const (
 	// AgentPrometheusExpositionPort is used to expose Prometheus metrics that can be scraped by a Prometheus server
 	AgentPrometheusExpositionPort = 51680
 
+	// AgentTaskMetricsExpositionPort is used to expose per-task and per-container stats in the OpenMetrics
+	// format, so that they can be scraped by a Prometheus server
+	AgentTaskMetricsExpositionPort = 51681
+
+	// AgentStandaloneAPIPort is used to serve the local API that runs and stops tasks in standalone mode.
+	// It is only bound to the loopback interface.
+	AgentStandaloneAPIPort = 51682
+
 	// defaultConfigFileName is the default (json-formatted) config file
 	defaultConfigFileName = "/etc/ecs_container_agent/config.json"
 
+	// DefaultDataStoreDriver is the name of the default storage engine used to checkpoint data.
+	DefaultDataStoreDriver = "boltdb"
+
+	// DefaultTracingEndpoint is the default OTLP/HTTP endpoint of the collector that receives the task lifecycle traces.
+	DefaultTracingEndpoint = "http://localhost:4318/v1/traces"
+
+	// standaloneTaskDirName is the name of the directory in the data directory that holds the task
+	// definitions run in standalone mode, when ECS_STANDALONE_TASK_DIR is not set.
+	standaloneTaskDirName = "tasks"
+
+	// DefaultTaskResourceUsageRetention is the default duration the resource usage totals of a stopped
+	// task are kept.
+	DefaultTaskResourceUsageRetention = 7 * 24 * time.Hour
+
+	// DefaultSecretRotationInterval is the default interval the secret files of the running tasks are
+	// refreshed at.
+	DefaultSecretRotationInterval = 15 * time.Minute
+
+	// minimumSecretRotationInterval is the minimum interval the secret files are refreshed at, to
+	// stay clear of the SSM and Secrets Manager API throttling.
+	minimumSecretRotationInterval = time.Minute
+
+	// DefaultTCSBufferMaxAge is the default duration the unpublished telemetry and health messages are
+	// kept on disk.
+	DefaultTCSBufferMaxAge = 30 * time.Minute
+
+	// DefaultTCSBufferMaxSize is the default maximum size of the unpublished telemetry and health
+	// messages kept on disk.
+	DefaultTCSBufferMaxSize = 10 * 1024 * 1024
+
+	// DefaultACSMaxMessageSize is the default maximum size of a message read from ACS.
+	DefaultACSMaxMessageSize = 64 * 1024 * 1024
+
+	// DefaultTCSMaxMessageSize is the default maximum size of a message read from TCS. TCS only
+	// sends acknowledgements and heartbeats.
+	DefaultTCSMaxMessageSize = 1024 * 1024
+
+	// MetricsSinkStatsD publishes the Agent internal metrics to a StatsD server over UDP.
+	MetricsSinkStatsD = "statsd"
+
+	// MetricsSinkOTLP publishes the Agent internal metrics to an OpenTelemetry collector over OTLP/HTTP.
+	MetricsSinkOTLP = "otlp"
+
+	// DefaultStatsDEndpoint is the default address of the StatsD server.
+	DefaultStatsDEndpoint = "127.0.0.1:8125"
+
+	// DefaultOTLPEndpoint is the default URL of the OTLP/HTTP metrics endpoint.
+	DefaultOTLPEndpoint = "http://127.0.0.1:4318/v1/metrics"
+
+	// TaskAdmissionPolicyFIFO admits the tasks waiting for host resources in the order they arrived in.
+	TaskAdmissionPolicyFIFO = "fifo"
+
+	// TaskAdmissionPolicyBestFit admits the largest waiting task that fits in the available host resources,
+	// so that smaller tasks are backfilled behind a task that doesn't fit.
+	TaskAdmissionPolicyBestFit = "bestfit"
+
+	// TaskAdmissionPolicyPriority admits the waiting tasks with the highest priority first, in the order
+	// they arrived in for the same priority.
+	TaskAdmissionPolicyPriority = "priority"
+
+	// DefaultTaskAdmissionPriorityTag is the default key of the task tag that sets the priority of a task.
+	DefaultTaskAdmissionPriorityTag = "ecs-task-priority"
+
+	// DefaultTaskAdmissionMaxWait is the default duration a task waits for host resources under
+	// TaskAdmissionPolicyBestFit before it is admitted ahead of the smaller tasks.
+	DefaultTaskAdmissionMaxWait = 10 * time.Minute
+
+	// ContainerStatsSourceDocker collects the container stats from one Docker stats stream per container.
+	ContainerStatsSourceDocker = "docker"
+
+	// ContainerStatsSourceCgroupfs collects the container stats from the cgroup filesystem and the
+	// network devices of the containers on a single ticker, falling back to Docker stats for the
+	// containers that can't be read.
+	ContainerStatsSourceCgroupfs = "cgroupfs"
+
+	// AuditLogFormatText writes the audit log entries as space separated fields.
+	AuditLogFormatText = "text"
+
+	// AuditLogFormatJSON writes the audit log entries as JSON objects with stable field names.
+	AuditLogFormatJSON = "json"
+
+	// AuditLogRolloverTypeSize rotates the audit log file when it reaches its maximum size.
+	AuditLogRolloverTypeSize = "size"
+
+	// AuditLogRolloverTypeHourly rotates the audit log file every hour.
+	AuditLogRolloverTypeHourly = "hourly"
+
+	// AuditLogSinkSyslog writes the audit log entries to the local syslog daemon in addition to
+	// the audit log file.
+	AuditLogSinkSyslog = "syslog"
+
+	// DefaultMetricsSinkFlushInterval is the default interval the metrics are sent to the sink at.
+	DefaultMetricsSinkFlushInterval = 10 * time.Second
+
 	// DefaultClusterName is the name of the default cluster.
 	DefaultClusterName = "default"
 
@@ -88,6 +192,14 @@ const (
 	//DefaultImagePullTimeout specifies the timeout for PullImage API.
 	DefaultImagePullTimeout = 2 * time.Hour
 
+	// DefaultImageCleanupDiskCheckInterval specifies the default time between two disk usage checks
+	// when watermark image cleanup is enabled.
+	DefaultImageCleanupDiskCheckInterval = 1 * time.Minute
+
+	// DefaultImagePrewarmConcurrency specifies the default number of images that are pre-warmed
+	// at the same time.
+	DefaultImagePrewarmConcurrency = 2
+
 	// minimumTaskCleanupWaitDuration specifies the minimum duration to wait before cleaning up
 	// a task's container. This is used to enforce sane values for the config.TaskCleanupWaitDuration field.
 	minimumTaskCleanupWaitDuration = time.Second
@@ -115,6 +227,14 @@ const (
 	// performing image cleanup.
 	minimumNumImagesToDeletePerCycle = 1
 
+	// minimumImageCleanupDiskCheckInterval specifies the minimum time between two disk usage checks
+	// when watermark image cleanup is enabled.
+	minimumImageCleanupDiskCheckInterval = 10 * time.Second
+
+	// minimumImagePrewarmConcurrency specifies the minimum number of images that are pre-warmed
+	// at the same time.
+	minimumImagePrewarmConcurrency = 1
+
 	// defaultCNIPluginsPath is the default path where cni binaries are located
 	defaultCNIPluginsPath = "/amazon-ecs-cni-plugins"
 
@@ -340,6 +460,102 @@ func (cfg *Config) validateAndOverrideBounds() error {
 		cfg.TaskCleanupWaitDuration = DefaultTaskCleanupWaitDuration
 	}
 
+	if cfg.TaskResourceUsageRetention <= 0 {
+		seelog.Warnf("Invalid value for ECS_TASK_RESOURCE_USAGE_RETENTION, will be overridden with the default value: %s. Parsed value: %v.", DefaultTaskResourceUsageRetention.String(), cfg.TaskResourceUsageRetention)
+		cfg.TaskResourceUsageRetention = DefaultTaskResourceUsageRetention
+	}
+
+	if cfg.TCSBufferMaxAge <= 0 {
+		seelog.Warnf("Invalid value for ECS_TCS_BUFFER_MAX_AGE, will be overridden with the default value: %s. Parsed value: %v.", DefaultTCSBufferMaxAge.String(), cfg.TCSBufferMaxAge)
+		cfg.TCSBufferMaxAge = DefaultTCSBufferMaxAge
+	}
+
+	if cfg.TCSBufferMaxSize <= 0 {
+		seelog.Warnf("Invalid value for ECS_TCS_BUFFER_MAX_SIZE, will be overridden with the default value: %d. Parsed value: %v.", DefaultTCSBufferMaxSize, cfg.TCSBufferMaxSize)
+		cfg.TCSBufferMaxSize = DefaultTCSBufferMaxSize
+	}
+
+	cfg.MetricsSink = strings.ToLower(cfg.MetricsSink)
+	switch cfg.MetricsSink {
+	case "":
+	case MetricsSinkStatsD:
+		if cfg.MetricsSinkEndpoint == "" {
+			cfg.MetricsSinkEndpoint = DefaultStatsDEndpoint
+		}
+	case MetricsSinkOTLP:
+		if cfg.MetricsSinkEndpoint == "" {
+			cfg.MetricsSinkEndpoint = DefaultOTLPEndpoint
+		}
+	default:
+		seelog.Warnf("Invalid value for ECS_METRICS_SINK, the metrics will not be published to a sink. Parsed value: %v, expected one of: %s, %s.", cfg.MetricsSink, MetricsSinkStatsD, MetricsSinkOTLP)
+		cfg.MetricsSink = ""
+	}
+
+	cfg.TaskAdmissionPolicy = strings.ToLower(cfg.TaskAdmissionPolicy)
+	switch cfg.TaskAdmissionPolicy {
+	case TaskAdmissionPolicyFIFO, TaskAdmissionPolicyBestFit, TaskAdmissionPolicyPriority:
+	default:
+		seelog.Warnf("Invalid value for ECS_TASK_ADMISSION_POLICY, will be overridden with the default value: %s. Parsed value: %v, expected one of: %s, %s, %s.", TaskAdmissionPolicyFIFO, cfg.TaskAdmissionPolicy, TaskAdmissionPolicyFIFO, TaskAdmissionPolicyBestFit, TaskAdmissionPolicyPriority)
+		cfg.TaskAdmissionPolicy = TaskAdmissionPolicyFIFO
+	}
+
+	if cfg.TaskAdmissionMaxWait <= 0 {
+		seelog.Warnf("Invalid value for ECS_TASK_ADMISSION_MAX_WAIT, will be overridden with the default value: %s. Parsed value: %v, expected a positive duration.", DefaultTaskAdmissionMaxWait.String(), cfg.TaskAdmissionMaxWait)
+		cfg.TaskAdmissionMaxWait = DefaultTaskAdmissionMaxWait
+	}
+
+	cfg.ContainerStatsSource = strings.ToLower(cfg.ContainerStatsSource)
+	if cfg.ContainerStatsSource != ContainerStatsSourceDocker && cfg.ContainerStatsSource != ContainerStatsSourceCgroupfs {
+		seelog.Warnf("Invalid value for ECS_CONTAINER_STATS_SOURCE, will be overridden with the default value: %s. Parsed value: %v, expected one of: %s, %s.", ContainerStatsSourceDocker, cfg.ContainerStatsSource, ContainerStatsSourceDocker, ContainerStatsSourceCgroupfs)
+		cfg.ContainerStatsSource = ContainerStatsSourceDocker
+	}
+
+	cfg.CredentialsAuditLogFormat = strings.ToLower(cfg.CredentialsAuditLogFormat)
+	if cfg.CredentialsAuditLogFormat != AuditLogFormatText && cfg.CredentialsAuditLogFormat != AuditLogFormatJSON {
+		seelog.Warnf("Invalid value for ECS_AUDIT_LOG_FORMAT, will be overridden with the default value: %s. Parsed value: %v, expected one of: %s, %s.", AuditLogFormatText, cfg.CredentialsAuditLogFormat, AuditLogFormatText, AuditLogFormatJSON)
+		cfg.CredentialsAuditLogFormat = AuditLogFormatText
+	}
+
+	cfg.CredentialsAuditLogRolloverType = strings.ToLower(cfg.CredentialsAuditLogRolloverType)
+	if cfg.CredentialsAuditLogRolloverType != "" && cfg.CredentialsAuditLogRolloverType != AuditLogRolloverTypeSize &&
+		cfg.CredentialsAuditLogRolloverType != AuditLogRolloverTypeHourly {
+		seelog.Warnf("Invalid value for ECS_AUDIT_LOG_ROLLOVER_TYPE, the agent log rollover type will be used instead. Parsed value: %v, expected one of: %s, %s.", cfg.CredentialsAuditLogRolloverType, AuditLogRolloverTypeSize, AuditLogRolloverTypeHourly)
+		cfg.CredentialsAuditLogRolloverType = ""
+	}
+
+	if cfg.CredentialsAuditLogSink != "" && !isValidAuditLogSink(cfg.CredentialsAuditLogSink) {
+		seelog.Warnf("Invalid value for ECS_AUDIT_LOG_SINK, the audit log entries will only be written to the audit log file. Parsed value: %v, expected %s or a unix socket address such as unix:///var/run/audit.sock.", cfg.CredentialsAuditLogSink, AuditLogSinkSyslog)
+		cfg.CredentialsAuditLogSink = ""
+	}
+
+	if cfg.MetricsSinkFlushInterval <= 0 {
+		seelog.Warnf("Invalid value for ECS_METRICS_SINK_FLUSH_INTERVAL, will be overridden with the default value: %s. Parsed value: %v.", DefaultMetricsSinkFlushInterval.String(), cfg.MetricsSinkFlushInterval)
+		cfg.MetricsSinkFlushInterval = DefaultMetricsSinkFlushInterval
+	}
+
+	if cfg.ACSMaxMessageSize <= 0 {
+		seelog.Warnf("Invalid value for ECS_ACS_MAX_MESSAGE_SIZE, will be overridden with the default value: %d. Parsed value: %v.", DefaultACSMaxMessageSize, cfg.ACSMaxMessageSize)
+		cfg.ACSMaxMessageSize = DefaultACSMaxMessageSize
+	}
+
+	if cfg.TCSMaxMessageSize <= 0 {
+		seelog.Warnf("Invalid value for ECS_TCS_MAX_MESSAGE_SIZE, will be overridden with the default value: %d. Parsed value: %v.", DefaultTCSMaxMessageSize, cfg.TCSMaxMessageSize)
+		cfg.TCSMaxMessageSize = DefaultTCSMaxMessageSize
+	}
+
+	if cfg.SecretRotationInterval < minimumSecretRotationInterval {
+		seelog.Warnf("Invalid value for ECS_SECRET_ROTATION_INTERVAL, will be overridden with the default value: %s. Parsed value: %v, minimum value: %v.", DefaultSecretRotationInterval.String(), cfg.SecretRotationInterval, minimumSecretRotationInterval)
+		cfg.SecretRotationInterval = DefaultSecretRotationInterval
+	}
+
+	if cfg.LocalSecretsEndpoint != "" {
+		if endpoint, err := url.Parse(cfg.LocalSecretsEndpoint); err != nil || endpoint.Host == "" ||
+			(endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+			seelog.Warnf("Invalid value for ECS_LOCAL_SECRETS_ENDPOINT, it must be an http or https URL, the local secrets endpoint is disabled. Parsed value: %s", cfg.LocalSecretsEndpoint)
+			cfg.LocalSecretsEndpoint = ""
+		}
+	}
+
 	if cfg.ImagePullInactivityTimeout < minimumImagePullInactivityTimeout {
 		seelog.Warnf("Invalid value for image pull inactivity timeout duration, will be overridden with the default value: %s. Parsed value: %v, minimum value: %v.", defaultImagePullInactivityTimeout.String(), cfg.ImagePullInactivityTimeout, minimumImagePullInactivityTimeout)
 		cfg.ImagePullInactivityTimeout = defaultImagePullInactivityTimeout
@@ -355,6 +571,13 @@ func (cfg *Config) validateAndOverrideBounds() error {
 		cfg.NumImagesToDeletePerCycle = DefaultNumImagesToDeletePerCycle
 	}
 
+	cfg.imageCleanupDiskWatermarkOverrides()
+
+	if cfg.ImagePrewarmConcurrency < minimumImagePrewarmConcurrency {
+		seelog.Warnf("Invalid value for ECS_IMAGE_PREWARM_CONCURRENCY, will be overridden with the default value: %d. Parsed value: %d, minimum value: %d.", DefaultImagePrewarmConcurrency, cfg.ImagePrewarmConcurrency, minimumImagePrewarmConcurrency)
+		cfg.ImagePrewarmConcurrency = DefaultImagePrewarmConcurrency
+	}
+
 	if cfg.TaskMetadataSteadyStateRate <= 0 || cfg.TaskMetadataBurstRate <= 0 {
 		seelog.Warnf("Invalid values for rate limits, will be overridden with default values: %d,%d.", DefaultTaskMetadataSteadyStateRate, DefaultTaskMetadataBurstRate)
 		cfg.TaskMetadataSteadyStateRate = DefaultTaskMetadataSteadyStateRate
@@ -364,11 +587,47 @@ func (cfg *Config) validateAndOverrideBounds() error {
 	// check the PollMetrics specific configurations
 	cfg.pollMetricsOverrides()
 
+	if cfg.TaskMetricsExporterEnabled.Enabled() {
+		cfg.ReservedPorts = append(cfg.ReservedPorts, AgentTaskMetricsExpositionPort)
+	}
+
+	if cfg.Standalone.Enabled() {
+		if cfg.StandaloneTaskDir == "" {
+			cfg.StandaloneTaskDir = filepath.Join(cfg.DataDir, standaloneTaskDirName)
+		}
+		cfg.ReservedPorts = append(cfg.ReservedPorts, AgentStandaloneAPIPort)
+	}
+
 	cfg.platformOverrides()
 
 	return nil
 }
 
+func (cfg *Config) imageCleanupDiskWatermarkOverrides() {
+	if cfg.ImageCleanupDiskHighWatermark == 0 && cfg.ImageCleanupDiskLowWatermark == 0 {
+		return
+	}
+	if cfg.ImageCleanupDiskHighWatermark <= 0 || cfg.ImageCleanupDiskHighWatermark > 100 ||
+		cfg.ImageCleanupDiskLowWatermark <= 0 || cfg.ImageCleanupDiskLowWatermark >= cfg.ImageCleanupDiskHighWatermark {
+		seelog.Warnf("Invalid values for ECS_IMAGE_CLEANUP_DISK_HIGH_WATERMARK and ECS_IMAGE_CLEANUP_DISK_LOW_WATERMARK, disabling watermark image cleanup. Parsed values: %d, %d. Both must be within (0, 100] and the low watermark must be less than the high watermark.",
+			cfg.ImageCleanupDiskHighWatermark, cfg.ImageCleanupDiskLowWatermark)
+		cfg.ImageCleanupDiskHighWatermark = 0
+		cfg.ImageCleanupDiskLowWatermark = 0
+		return
+	}
+	if cfg.ImageCleanupDiskCheckInterval < minimumImageCleanupDiskCheckInterval {
+		seelog.Warnf("Invalid value for ECS_IMAGE_CLEANUP_DISK_CHECK_INTERVAL, will be overridden with the default value: %s. Parsed value: %v, minimum value: %v.",
+			DefaultImageCleanupDiskCheckInterval.String(), cfg.ImageCleanupDiskCheckInterval, minimumImageCleanupDiskCheckInterval)
+		cfg.ImageCleanupDiskCheckInterval = DefaultImageCleanupDiskCheckInterval
+	}
+}
+
+// ImageCleanupDiskWatermarkEnabled returns true if images should be evicted when the disk usage
+// of the Docker data root goes above ImageCleanupDiskHighWatermark
+func (cfg *Config) ImageCleanupDiskWatermarkEnabled() bool {
+	return cfg.ImageCleanupDiskHighWatermark > 0 && cfg.ImageCleanupDiskLowWatermark > 0
+}
+
 func (cfg *Config) pollMetricsOverrides() {
 	if cfg.PollMetrics.Enabled() {
 		if cfg.PollingMetricsWaitDuration < minimumPollingMetricsWaitDuration {
@@ -532,12 +791,17 @@ func environmentConfig() (Config, error) {
 		ReservedPortsUDP:                    parseReservedPorts("ECS_RESERVED_PORTS_UDP"),
 		DataDir:                             dataDir,
 		Checkpoint:                          parseCheckpoint(dataDir),
+		DataStoreDriver:                     os.Getenv("ECS_DATA_STORE_DRIVER"),
 		EngineAuthType:                      os.Getenv("ECS_ENGINE_AUTH_TYPE"),
 		EngineAuthData:                      NewSensitiveRawMessage([]byte(os.Getenv("ECS_ENGINE_AUTH_DATA"))),
 		UpdatesEnabled:                      parseBooleanDefaultFalseConfig("ECS_UPDATES_ENABLED"),
 		UpdateDownloadDir:                   os.Getenv("ECS_UPDATE_DOWNLOAD_DIR"),
 		DisableMetrics:                      parseBooleanDefaultFalseConfig("ECS_DISABLE_METRICS"),
 		ReservedMemory:                      parseEnvVariableUint16("ECS_RESERVED_MEMORY"),
+		TaskAdmissionPolicy:                 os.Getenv("ECS_TASK_ADMISSION_POLICY"),
+		TaskAdmissionPriorityTag:            os.Getenv("ECS_TASK_ADMISSION_PRIORITY_TAG"),
+		TaskAdmissionMaxWait:                parseEnvVariableDuration("ECS_TASK_ADMISSION_MAX_WAIT"),
+		TaskAdmissionFamilyPriorities:       parseTaskAdmissionFamilyPriorities(),
 		AvailableLoggingDrivers:             parseAvailableLoggingDrivers(),
 		PrivilegedDisabled:                  parseBooleanDefaultFalseConfig("ECS_DISABLE_PRIVILEGED"),
 		SELinuxCapable:                      parseBooleanDefaultFalseConfig("ECS_SELINUX_CAPABLE"),
@@ -556,14 +820,25 @@ func environmentConfig() (Config, error) {
 		ImagePullTimeout:                    parseEnvVariableDuration("ECS_IMAGE_PULL_TIMEOUT"),
 		CredentialsAuditLogFile:             os.Getenv("ECS_AUDIT_LOGFILE"),
 		CredentialsAuditLogDisabled:         utils.ParseBool(os.Getenv("ECS_AUDIT_LOGFILE_DISABLED"), false),
+		CredentialsAuditLogFormat:           os.Getenv("ECS_AUDIT_LOG_FORMAT"),
+		CredentialsAuditLogRolloverType:     os.Getenv("ECS_AUDIT_LOG_ROLLOVER_TYPE"),
+		CredentialsAuditLogMaxFileSize:      parseEnvVariableSize("ECS_AUDIT_LOG_MAX_FILE_SIZE"),
+		CredentialsAuditLogMaxRollCount:     int(parseEnvVariableUint16("ECS_AUDIT_LOG_MAX_ROLL_COUNT")),
+		CredentialsAuditLogSink:             os.Getenv("ECS_AUDIT_LOG_SINK"),
 		TaskIAMRoleEnabledForNetworkHost:    utils.ParseBool(os.Getenv("ECS_ENABLE_TASK_IAM_ROLE_NETWORK_HOST"), false),
+		TaskCredentialsSourceEnforced:       utils.ParseBool(os.Getenv("ECS_ENFORCE_TASK_CREDENTIALS_SOURCE"), false),
 		ImageCleanupDisabled:                parseBooleanDefaultFalseConfig("ECS_DISABLE_IMAGE_CLEANUP"),
 		MinimumImageDeletionAge:             parseEnvVariableDuration("ECS_IMAGE_MINIMUM_CLEANUP_AGE"),
 		NonECSMinimumImageDeletionAge:       parseEnvVariableDuration("NON_ECS_IMAGE_MINIMUM_CLEANUP_AGE"),
 		ImageCleanupInterval:                parseEnvVariableDuration("ECS_IMAGE_CLEANUP_INTERVAL"),
 		NumImagesToDeletePerCycle:           parseNumImagesToDeletePerCycle(),
 		NumNonECSContainersToDeletePerCycle: parseNumNonECSContainersToDeletePerCycle(),
+		ImageCleanupDiskHighWatermark:       parseImageCleanupDiskWatermark("ECS_IMAGE_CLEANUP_DISK_HIGH_WATERMARK"),
+		ImageCleanupDiskLowWatermark:        parseImageCleanupDiskWatermark("ECS_IMAGE_CLEANUP_DISK_LOW_WATERMARK"),
+		ImageCleanupDiskCheckInterval:       parseEnvVariableDuration("ECS_IMAGE_CLEANUP_DISK_CHECK_INTERVAL"),
 		ImagePullBehavior:                   parseImagePullBehavior(),
+		ImagePrewarmEnabled:                 parseBooleanDefaultFalseConfig("ECS_ENABLE_IMAGE_PREWARM"),
+		ImagePrewarmConcurrency:             parseImagePrewarmConcurrency(),
 		ImageCleanupExclusionList:           parseImageCleanupExclusionList("ECS_EXCLUDE_UNTRACKED_IMAGE"),
 		InstanceAttributes:                  instanceAttributes,
 		CNIPluginsPath:                      os.Getenv("ECS_CNI_PLUGINS_PATH"),
@@ -579,7 +854,29 @@ func environmentConfig() (Config, error) {
 		ContainerInstanceTags:               containerInstanceTags,
 		ContainerInstancePropagateTagsFrom:  parseContainerInstancePropagateTagsFrom(),
 		PollMetrics:                         parseBooleanDefaultFalseConfig("ECS_POLL_METRICS"),
+		TaskMetricsExporterEnabled:          parseBooleanDefaultFalseConfig("ECS_ENABLE_TASK_METRICS_EXPORTER"),
+		TracingEnabled:                      parseBooleanDefaultFalseConfig("ECS_ENABLE_TRACING"),
+		TracingEndpoint:                     os.Getenv("ECS_TRACING_ENDPOINT"),
+		Standalone:                          parseBooleanDefaultFalseConfig("ECS_STANDALONE"),
+		StandaloneTaskDir:                   os.Getenv("ECS_STANDALONE_TASK_DIR"),
+		TaskResourceUsageRetention:          parseEnvVariableDuration("ECS_TASK_RESOURCE_USAGE_RETENTION"),
+		SecretFilesEnabled:                  parseBooleanDefaultFalseConfig("ECS_ENABLE_SECRET_FILES"),
+		SecretRotationInterval:              parseEnvVariableDuration("ECS_SECRET_ROTATION_INTERVAL"),
+		LocalSecretsFile:                    os.Getenv("ECS_LOCAL_SECRETS_FILE"),
+		LocalSecretsKeyFile:                 os.Getenv("ECS_LOCAL_SECRETS_KEY_FILE"),
+		LocalSecretsEndpoint:                os.Getenv("ECS_LOCAL_SECRETS_ENDPOINT"),
+		LocalSecretsTokenFile:               os.Getenv("ECS_LOCAL_SECRETS_TOKEN_FILE"),
+		TCSBufferDisabled:                   parseBooleanDefaultFalseConfig("ECS_DISABLE_TCS_BUFFER"),
+		TCSBufferMaxAge:                     parseEnvVariableDuration("ECS_TCS_BUFFER_MAX_AGE"),
+		TCSBufferMaxSize:                    parseEnvVariableSize("ECS_TCS_BUFFER_MAX_SIZE"),
+		WebsocketCompressionDisabled:        parseBooleanDefaultFalseConfig("ECS_DISABLE_WEBSOCKET_COMPRESSION"),
+		ACSMaxMessageSize:                   parseEnvVariableSize("ECS_ACS_MAX_MESSAGE_SIZE"),
+		TCSMaxMessageSize:                   parseEnvVariableSize("ECS_TCS_MAX_MESSAGE_SIZE"),
+		MetricsSink:                         os.Getenv("ECS_METRICS_SINK"),
+		MetricsSinkEndpoint:                 os.Getenv("ECS_METRICS_SINK_ENDPOINT"),
+		MetricsSinkFlushInterval:            parseEnvVariableDuration("ECS_METRICS_SINK_FLUSH_INTERVAL"),
 		PollingMetricsWaitDuration:          parseEnvVariableDuration("ECS_POLLING_METRICS_WAIT_DURATION"),
+		ContainerStatsSource:                os.Getenv("ECS_CONTAINER_STATS_SOURCE"),
 		DisableDockerHealthCheck:            parseBooleanDefaultFalseConfig("ECS_DISABLE_DOCKER_HEALTH_CHECK"),
 		GPUSupportEnabled:                   utils.ParseBool(os.Getenv("ECS_ENABLE_GPU_SUPPORT"), false),
 		InferentiaSupportEnabled:            utils.ParseBool(os.Getenv("ECS_ENABLE_INF_SUPPORT"), false),
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -140,6 +141,7 @@ func TestEnvironmentConfig(t *testing.T) {
 	defer setTestEnv("ECS_ENABLE_TASK_IAM_ROLE", "true")()
 	defer setTestEnv("ECS_ENABLE_UNTRACKED_IMAGE_CLEANUP", "true")()
 	defer setTestEnv("ECS_ENABLE_TASK_IAM_ROLE_NETWORK_HOST", "true")()
+	defer setTestEnv("ECS_ENFORCE_TASK_CREDENTIALS_SOURCE", "true")()
 	defer setTestEnv("ECS_DISABLE_IMAGE_CLEANUP", "true")()
 	defer setTestEnv("ECS_IMAGE_CLEANUP_INTERVAL", "2h")()
 	defer setTestEnv("ECS_IMAGE_MINIMUM_CLEANUP_AGE", "30m")()
@@ -189,6 +191,7 @@ func TestEnvironmentConfig(t *testing.T) {
 	assert.True(t, conf.TaskIAMRoleEnabled.Enabled(), "Wrong value for TaskIAMRoleEnabled")
 	assert.Equal(t, ExplicitlyEnabled, conf.DeleteNonECSImagesEnabled.Value, "Wrong value for DeleteNonECSImagesEnabled")
 	assert.True(t, conf.TaskIAMRoleEnabledForNetworkHost, "Wrong value for TaskIAMRoleEnabledForNetworkHost")
+	assert.True(t, conf.TaskCredentialsSourceEnforced, "Wrong value for TaskCredentialsSourceEnforced")
 	assert.True(t, conf.ImageCleanupDisabled.Enabled(), "Wrong value for ImageCleanupDisabled")
 	assert.True(t, conf.PollMetrics.Enabled(), "Wrong value for PollMetrics")
 	expectedDurationPollingMetricsWaitDuration, _ := time.ParseDuration("10s")
@@ -654,6 +657,299 @@ func TestImageCleanupMinimumNumImagesToDeletePerCycle(t *testing.T) {
 	assert.Equal(t, DefaultNumImagesToDeletePerCycle, cfg.NumImagesToDeletePerCycle, "Wrong value for NumImagesToDeletePerCycle")
 }
 
+func TestImageCleanupDiskWatermarks(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_IMAGE_CLEANUP_DISK_HIGH_WATERMARK", "85")()
+	defer setTestEnv("ECS_IMAGE_CLEANUP_DISK_LOW_WATERMARK", "70")()
+	defer setTestEnv("ECS_IMAGE_CLEANUP_DISK_CHECK_INTERVAL", "1s")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.True(t, cfg.ImageCleanupDiskWatermarkEnabled())
+	assert.Equal(t, 85, cfg.ImageCleanupDiskHighWatermark)
+	assert.Equal(t, 70, cfg.ImageCleanupDiskLowWatermark)
+	assert.Equal(t, DefaultImageCleanupDiskCheckInterval, cfg.ImageCleanupDiskCheckInterval, "Wrong value for ImageCleanupDiskCheckInterval")
+}
+
+func TestImageCleanupDiskWatermarksInvalid(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_IMAGE_CLEANUP_DISK_HIGH_WATERMARK", "70")()
+	defer setTestEnv("ECS_IMAGE_CLEANUP_DISK_LOW_WATERMARK", "85")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.False(t, cfg.ImageCleanupDiskWatermarkEnabled())
+}
+
+func TestImagePrewarmMinimumConcurrency(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_IMAGE_PREWARM_CONCURRENCY", "0")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultImagePrewarmConcurrency, cfg.ImagePrewarmConcurrency, "Wrong value for ImagePrewarmConcurrency")
+}
+
+func TestDataStoreDriver(t *testing.T) {
+	defer setTestRegion()()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultDataStoreDriver, cfg.DataStoreDriver, "Wrong default value for DataStoreDriver")
+
+	defer setTestEnv("ECS_DATA_STORE_DRIVER", "sqlite")()
+	cfg, err = NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, "sqlite", cfg.DataStoreDriver, "Wrong value for DataStoreDriver")
+}
+
+func TestTaskMetricsExporterEnabled(t *testing.T) {
+	defer setTestRegion()()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.False(t, cfg.TaskMetricsExporterEnabled.Enabled(), "Wrong default value for TaskMetricsExporterEnabled")
+	assert.NotContains(t, cfg.ReservedPorts, uint16(AgentTaskMetricsExpositionPort))
+
+	defer setTestEnv("ECS_ENABLE_TASK_METRICS_EXPORTER", "true")()
+	cfg, err = NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.True(t, cfg.TaskMetricsExporterEnabled.Enabled(), "Wrong value for TaskMetricsExporterEnabled")
+	assert.Contains(t, cfg.ReservedPorts, uint16(AgentTaskMetricsExpositionPort))
+}
+
+func TestTracingConfig(t *testing.T) {
+	defer setTestRegion()()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.False(t, cfg.TracingEnabled.Enabled(), "Wrong default value for TracingEnabled")
+	assert.Equal(t, DefaultTracingEndpoint, cfg.TracingEndpoint, "Wrong default value for TracingEndpoint")
+
+	defer setTestEnv("ECS_ENABLE_TRACING", "true")()
+	defer setTestEnv("ECS_TRACING_ENDPOINT", "http://collector:4318/v1/traces")()
+	cfg, err = NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.True(t, cfg.TracingEnabled.Enabled(), "Wrong value for TracingEnabled")
+	assert.Equal(t, "http://collector:4318/v1/traces", cfg.TracingEndpoint, "Wrong value for TracingEndpoint")
+}
+
+func TestStandaloneConfig(t *testing.T) {
+	defer setTestRegion()()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.False(t, cfg.Standalone.Enabled(), "Wrong default value for Standalone")
+	assert.NotContains(t, cfg.ReservedPorts, uint16(AgentStandaloneAPIPort))
+
+	defer setTestEnv("ECS_STANDALONE", "true")()
+	cfg, err = NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.True(t, cfg.Standalone.Enabled(), "Wrong value for Standalone")
+	assert.Equal(t, filepath.Join(cfg.DataDir, "tasks"), cfg.StandaloneTaskDir, "Wrong default value for StandaloneTaskDir")
+	assert.Contains(t, cfg.ReservedPorts, uint16(AgentStandaloneAPIPort))
+
+	defer setTestEnv("ECS_STANDALONE_TASK_DIR", "/etc/ecs/tasks")()
+	cfg, err = NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, "/etc/ecs/tasks", cfg.StandaloneTaskDir, "Wrong value for StandaloneTaskDir")
+}
+
+func TestTaskResourceUsageRetention(t *testing.T) {
+	defer setTestRegion()()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultTaskResourceUsageRetention, cfg.TaskResourceUsageRetention, "Wrong default value for TaskResourceUsageRetention")
+
+	defer setTestEnv("ECS_TASK_RESOURCE_USAGE_RETENTION", "48h")()
+	cfg, err = NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, 48*time.Hour, cfg.TaskResourceUsageRetention, "Wrong value for TaskResourceUsageRetention")
+}
+
+func TestInvalidTaskResourceUsageRetention(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_TASK_RESOURCE_USAGE_RETENTION", "-1h")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultTaskResourceUsageRetention, cfg.TaskResourceUsageRetention, "Wrong value for TaskResourceUsageRetention")
+}
+
+func TestSecretFiles(t *testing.T) {
+	defer setTestRegion()()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.False(t, cfg.SecretFilesEnabled.Enabled(), "Secret files should be disabled by default")
+	assert.Equal(t, DefaultSecretRotationInterval, cfg.SecretRotationInterval, "Wrong default value for SecretRotationInterval")
+
+	defer setTestEnv("ECS_SECRET_ROTATION_INTERVAL", "5m")()
+	cfg, err = NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, cfg.SecretRotationInterval, "Wrong value for SecretRotationInterval")
+}
+
+func TestInvalidSecretRotationInterval(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_SECRET_ROTATION_INTERVAL", "10s")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultSecretRotationInterval, cfg.SecretRotationInterval, "Wrong value for SecretRotationInterval")
+}
+
+func TestLocalSecrets(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_LOCAL_SECRETS_FILE", "/etc/ecs/secrets.enc")()
+	defer setTestEnv("ECS_LOCAL_SECRETS_KEY_FILE", "/etc/ecs/secrets.key")()
+	defer setTestEnv("ECS_LOCAL_SECRETS_ENDPOINT", "http://127.0.0.1:8200/v1")()
+	defer setTestEnv("ECS_LOCAL_SECRETS_TOKEN_FILE", "/etc/ecs/vault-token")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, "/etc/ecs/secrets.enc", cfg.LocalSecretsFile)
+	assert.Equal(t, "/etc/ecs/secrets.key", cfg.LocalSecretsKeyFile)
+	assert.Equal(t, "http://127.0.0.1:8200/v1", cfg.LocalSecretsEndpoint)
+	assert.Equal(t, "/etc/ecs/vault-token", cfg.LocalSecretsTokenFile)
+}
+
+func TestInvalidLocalSecretsEndpoint(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_LOCAL_SECRETS_ENDPOINT", "127.0.0.1:8200")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Empty(t, cfg.LocalSecretsEndpoint)
+}
+
+func TestTCSBuffer(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_DISABLE_TCS_BUFFER", "true")()
+	defer setTestEnv("ECS_TCS_BUFFER_MAX_AGE", "1h")()
+	defer setTestEnv("ECS_TCS_BUFFER_MAX_SIZE", "64m")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.True(t, cfg.TCSBufferDisabled.Enabled())
+	assert.Equal(t, time.Hour, cfg.TCSBufferMaxAge)
+	assert.Equal(t, int64(64*1024*1024), cfg.TCSBufferMaxSize)
+}
+
+func TestInvalidTCSBufferLimits(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_TCS_BUFFER_MAX_AGE", "-1m")()
+	defer setTestEnv("ECS_TCS_BUFFER_MAX_SIZE", "lots")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.False(t, cfg.TCSBufferDisabled.Enabled())
+	assert.Equal(t, DefaultTCSBufferMaxAge, cfg.TCSBufferMaxAge)
+	assert.Equal(t, int64(DefaultTCSBufferMaxSize), cfg.TCSBufferMaxSize)
+}
+
+func TestWebsocketLimits(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_DISABLE_WEBSOCKET_COMPRESSION", "true")()
+	defer setTestEnv("ECS_ACS_MAX_MESSAGE_SIZE", "128m")()
+	defer setTestEnv("ECS_TCS_MAX_MESSAGE_SIZE", "512k")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.True(t, cfg.WebsocketCompressionDisabled.Enabled())
+	assert.Equal(t, int64(128*1024*1024), cfg.ACSMaxMessageSize)
+	assert.Equal(t, int64(512*1024), cfg.TCSMaxMessageSize)
+}
+
+func TestInvalidWebsocketLimits(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_ACS_MAX_MESSAGE_SIZE", "-1")()
+	defer setTestEnv("ECS_TCS_MAX_MESSAGE_SIZE", "huge")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.False(t, cfg.WebsocketCompressionDisabled.Enabled())
+	assert.Equal(t, int64(DefaultACSMaxMessageSize), cfg.ACSMaxMessageSize)
+	assert.Equal(t, int64(DefaultTCSMaxMessageSize), cfg.TCSMaxMessageSize)
+}
+
+func TestMetricsSink(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_METRICS_SINK", "StatsD")()
+	defer setTestEnv("ECS_METRICS_SINK_FLUSH_INTERVAL", "1m")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, MetricsSinkStatsD, cfg.MetricsSink)
+	assert.Equal(t, DefaultStatsDEndpoint, cfg.MetricsSinkEndpoint)
+	assert.Equal(t, time.Minute, cfg.MetricsSinkFlushInterval)
+}
+
+func TestMetricsSinkEndpoint(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_METRICS_SINK", "otlp")()
+	defer setTestEnv("ECS_METRICS_SINK_ENDPOINT", "http://collector:4318/v1/metrics")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, MetricsSinkOTLP, cfg.MetricsSink)
+	assert.Equal(t, "http://collector:4318/v1/metrics", cfg.MetricsSinkEndpoint)
+	assert.Equal(t, DefaultMetricsSinkFlushInterval, cfg.MetricsSinkFlushInterval)
+}
+
+func TestInvalidMetricsSink(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_METRICS_SINK", "graphite")()
+	defer setTestEnv("ECS_METRICS_SINK_FLUSH_INTERVAL", "0s")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Empty(t, cfg.MetricsSink)
+	assert.Equal(t, DefaultMetricsSinkFlushInterval, cfg.MetricsSinkFlushInterval)
+}
+
+func TestInvalidContainerStatsSource(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_CONTAINER_STATS_SOURCE", "procfs")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, ContainerStatsSourceDocker, cfg.ContainerStatsSource)
+}
+
+func TestCredentialsAuditLogConfig(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_AUDIT_LOG_FORMAT", "JSON")()
+	defer setTestEnv("ECS_AUDIT_LOG_ROLLOVER_TYPE", "size")()
+	defer setTestEnv("ECS_AUDIT_LOG_MAX_FILE_SIZE", "50m")()
+	defer setTestEnv("ECS_AUDIT_LOG_MAX_ROLL_COUNT", "10")()
+	defer setTestEnv("ECS_AUDIT_LOG_SINK", "unix:///var/run/audit.sock")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, AuditLogFormatJSON, cfg.CredentialsAuditLogFormat)
+	assert.Equal(t, AuditLogRolloverTypeSize, cfg.CredentialsAuditLogRolloverType)
+	assert.Equal(t, int64(50*1024*1024), cfg.CredentialsAuditLogMaxFileSize)
+	assert.Equal(t, 10, cfg.CredentialsAuditLogMaxRollCount)
+	assert.Equal(t, "unix:///var/run/audit.sock", cfg.CredentialsAuditLogSink)
+}
+
+func TestInvalidCredentialsAuditLogConfig(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_AUDIT_LOG_FORMAT", "xml")()
+	defer setTestEnv("ECS_AUDIT_LOG_ROLLOVER_TYPE", "daily")()
+	defer setTestEnv("ECS_AUDIT_LOG_SINK", "tcp://127.0.0.1:514")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, AuditLogFormatText, cfg.CredentialsAuditLogFormat)
+	assert.Empty(t, cfg.CredentialsAuditLogRolloverType)
+	assert.Empty(t, cfg.CredentialsAuditLogSink)
+}
+
+func TestTaskAdmissionPolicy(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_TASK_ADMISSION_POLICY", "Priority")()
+	defer setTestEnv("ECS_TASK_ADMISSION_FAMILY_PRIORITIES", `{"critical":10,"batch":-1}`)()
+	defer setTestEnv("ECS_TASK_ADMISSION_MAX_WAIT", "30m")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, TaskAdmissionPolicyPriority, cfg.TaskAdmissionPolicy)
+	assert.Equal(t, DefaultTaskAdmissionPriorityTag, cfg.TaskAdmissionPriorityTag)
+	assert.Equal(t, 30*time.Minute, cfg.TaskAdmissionMaxWait)
+	assert.Equal(t, map[string]int{"critical": 10, "batch": -1}, cfg.TaskAdmissionFamilyPriorities)
+}
+
+func TestInvalidTaskAdmissionPolicy(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_TASK_ADMISSION_POLICY", "lifo")()
+	defer setTestEnv("ECS_TASK_ADMISSION_FAMILY_PRIORITIES", `{"critical":"high"}`)()
+	defer setTestEnv("ECS_TASK_ADMISSION_MAX_WAIT", "-1m")()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, TaskAdmissionPolicyFIFO, cfg.TaskAdmissionPolicy)
+	assert.Equal(t, DefaultTaskAdmissionMaxWait, cfg.TaskAdmissionMaxWait)
+	assert.Nil(t, cfg.TaskAdmissionFamilyPriorities)
+}
+
 func TestInvalidImagePullBehavior(t *testing.T) {
 	defer setTestRegion()()
 	defer setTestEnv("ECS_IMAGE_PULL_BEHAVIOR", "invalid")()
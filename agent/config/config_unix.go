@@ -66,8 +66,12 @@ func DefaultConfig() Config {
 		ReservedPortsUDP:                    []uint16{},
 		DataDir:                             "/data/",
 		DataDirOnHost:                       "/var/lib/ecs",
+		DataStoreDriver:                     DefaultDataStoreDriver,
 		DisableMetrics:                      BooleanDefaultFalse{Value: ExplicitlyDisabled},
 		ReservedMemory:                      0,
+		TaskAdmissionPolicy:                 TaskAdmissionPolicyFIFO,
+		TaskAdmissionPriorityTag:            DefaultTaskAdmissionPriorityTag,
+		TaskAdmissionMaxWait:                DefaultTaskAdmissionMaxWait,
 		AvailableLoggingDrivers:             []dockerclient.LoggingDriver{dockerclient.JSONFileDriver, dockerclient.NoneDriver},
 		TaskCleanupWaitDuration:             DefaultTaskCleanupWaitDuration,
 		DockerStopTimeout:                   defaultDockerStopTimeout,
@@ -76,6 +80,7 @@ func DefaultConfig() Config {
 		DependentContainersPullUpfront:      BooleanDefaultFalse{Value: ExplicitlyDisabled},
 		CredentialsAuditLogFile:             defaultCredentialsAuditLogFile,
 		CredentialsAuditLogDisabled:         false,
+		CredentialsAuditLogFormat:           AuditLogFormatText,
 		ImageCleanupDisabled:                BooleanDefaultFalse{Value: ExplicitlyDisabled},
 		MinimumImageDeletionAge:             DefaultImageDeletionAge,
 		NonECSMinimumImageDeletionAge:       DefaultNonECSImageDeletionAge,
@@ -84,6 +89,9 @@ func DefaultConfig() Config {
 		ImagePullTimeout:                    DefaultImagePullTimeout,
 		NumImagesToDeletePerCycle:           DefaultNumImagesToDeletePerCycle,
 		NumNonECSContainersToDeletePerCycle: DefaultNumNonECSContainersToDeletePerCycle,
+		ImageCleanupDiskCheckInterval:       DefaultImageCleanupDiskCheckInterval,
+		ImagePrewarmEnabled:                 BooleanDefaultFalse{Value: ExplicitlyDisabled},
+		ImagePrewarmConcurrency:             DefaultImagePrewarmConcurrency,
 		CNIPluginsPath:                      defaultCNIPluginsPath,
 		PauseContainerTarballPath:           pauseContainerTarballPath,
 		PauseContainerImageName:             DefaultPauseContainerImageName,
@@ -98,7 +106,22 @@ func DefaultConfig() Config {
 		ContainerInstancePropagateTagsFrom:  ContainerInstancePropagateTagsFromNoneType,
 		PrometheusMetricsEnabled:            false,
 		PollMetrics:                         BooleanDefaultFalse{Value: NotSet},
+		TaskMetricsExporterEnabled:          BooleanDefaultFalse{Value: ExplicitlyDisabled},
+		TracingEnabled:                      BooleanDefaultFalse{Value: ExplicitlyDisabled},
+		TracingEndpoint:                     DefaultTracingEndpoint,
+		Standalone:                          BooleanDefaultFalse{Value: ExplicitlyDisabled},
+		TaskResourceUsageRetention:          DefaultTaskResourceUsageRetention,
+		SecretFilesEnabled:                  BooleanDefaultFalse{Value: ExplicitlyDisabled},
+		SecretRotationInterval:              DefaultSecretRotationInterval,
+		TCSBufferDisabled:                   BooleanDefaultFalse{Value: ExplicitlyDisabled},
+		TCSBufferMaxAge:                     DefaultTCSBufferMaxAge,
+		TCSBufferMaxSize:                    DefaultTCSBufferMaxSize,
+		WebsocketCompressionDisabled:        BooleanDefaultFalse{Value: ExplicitlyDisabled},
+		ACSMaxMessageSize:                   DefaultACSMaxMessageSize,
+		TCSMaxMessageSize:                   DefaultTCSMaxMessageSize,
+		MetricsSinkFlushInterval:            DefaultMetricsSinkFlushInterval,
 		PollingMetricsWaitDuration:          DefaultPollingMetricsWaitDuration,
+		ContainerStatsSource:                ContainerStatsSourceDocker,
 		NvidiaRuntime:                       DefaultNvidiaRuntime,
 		CgroupCPUPeriod:                     defaultCgroupCPUPeriod,
 		GMSACapable:                         parseGMSACapability(),
@@ -53,6 +53,7 @@ func TestConfigDefault(t *testing.T) {
 	assert.False(t, cfg.TaskENIEnabled.Enabled(), "TaskENIEnabled set incorrectly")
 	assert.False(t, cfg.TaskIAMRoleEnabled.Enabled(), "TaskIAMRoleEnabled set incorrectly")
 	assert.False(t, cfg.TaskIAMRoleEnabledForNetworkHost, "TaskIAMRoleEnabledForNetworkHost set incorrectly")
+	assert.False(t, cfg.TaskCredentialsSourceEnforced, "TaskCredentialsSourceEnforced set incorrectly")
 	assert.Equal(t, NotSet, cfg.TaskCPUMemLimit.Value, "TaskCPUMemLimit should be NotSet")
 	assert.False(t, cfg.CredentialsAuditLogDisabled, "CredentialsAuditLogDisabled set incorrectly")
 	assert.Equal(t, defaultCredentialsAuditLogFile, cfg.CredentialsAuditLogFile, "CredentialsAuditLogFile is set incorrectly")
@@ -285,3 +286,15 @@ func TestCPUPeriodSettings(t *testing.T) {
 		})
 	}
 }
+
+func TestContainerStatsSource(t *testing.T) {
+	defer setTestRegion()()
+	cfg, err := NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, ContainerStatsSourceDocker, cfg.ContainerStatsSource)
+
+	defer setTestEnv("ECS_CONTAINER_STATS_SOURCE", "CGROUPFS")()
+	cfg, err = NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	assert.NoError(t, err)
+	assert.Equal(t, ContainerStatsSourceCgroupfs, cfg.ContainerStatsSource)
+}
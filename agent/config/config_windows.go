@@ -118,7 +118,11 @@ func DefaultConfig() Config {
 		// DataDirOnHost is identical to DataDir for Windows because we do not
 		// run as a container
 		DataDirOnHost:                       dataDir,
+		DataStoreDriver:                     DefaultDataStoreDriver,
 		ReservedMemory:                      0,
+		TaskAdmissionPolicy:                 TaskAdmissionPolicyFIFO,
+		TaskAdmissionPriorityTag:            DefaultTaskAdmissionPriorityTag,
+		TaskAdmissionMaxWait:                DefaultTaskAdmissionMaxWait,
 		AvailableLoggingDrivers:             []dockerclient.LoggingDriver{dockerclient.JSONFileDriver, dockerclient.NoneDriver, dockerclient.AWSLogsDriver},
 		TaskCleanupWaitDuration:             DefaultTaskCleanupWaitDuration,
 		DockerStopTimeout:                   defaultDockerStopTimeout,
@@ -129,12 +133,16 @@ func DefaultConfig() Config {
 		ImagePullTimeout:                    DefaultImagePullTimeout,
 		CredentialsAuditLogFile:             filepath.Join(ecsRoot, defaultCredentialsAuditLogFile),
 		CredentialsAuditLogDisabled:         false,
+		CredentialsAuditLogFormat:           AuditLogFormatText,
 		ImageCleanupDisabled:                BooleanDefaultFalse{Value: ExplicitlyDisabled},
 		MinimumImageDeletionAge:             DefaultImageDeletionAge,
 		NonECSMinimumImageDeletionAge:       DefaultNonECSImageDeletionAge,
 		ImageCleanupInterval:                DefaultImageCleanupTimeInterval,
 		NumImagesToDeletePerCycle:           DefaultNumImagesToDeletePerCycle,
 		NumNonECSContainersToDeletePerCycle: DefaultNumNonECSContainersToDeletePerCycle,
+		ImageCleanupDiskCheckInterval:       DefaultImageCleanupDiskCheckInterval,
+		ImagePrewarmEnabled:                 BooleanDefaultFalse{Value: ExplicitlyDisabled},
+		ImagePrewarmConcurrency:             DefaultImagePrewarmConcurrency,
 		ContainerMetadataEnabled:            BooleanDefaultFalse{Value: ExplicitlyDisabled},
 		TaskCPUMemLimit:                     BooleanDefaultTrue{Value: ExplicitlyDisabled},
 		PlatformVariables:                   platformVariables,
@@ -142,7 +150,22 @@ func DefaultConfig() Config {
 		TaskMetadataBurstRate:               DefaultTaskMetadataBurstRate,
 		SharedVolumeMatchFullConfig:         BooleanDefaultFalse{Value: ExplicitlyDisabled}, //only requiring shared volumes to match on name, which is default docker behavior
 		PollMetrics:                         BooleanDefaultFalse{Value: NotSet},
+		TaskMetricsExporterEnabled:          BooleanDefaultFalse{Value: ExplicitlyDisabled},
+		TracingEnabled:                      BooleanDefaultFalse{Value: ExplicitlyDisabled},
+		TracingEndpoint:                     DefaultTracingEndpoint,
+		Standalone:                          BooleanDefaultFalse{Value: ExplicitlyDisabled},
+		TaskResourceUsageRetention:          DefaultTaskResourceUsageRetention,
+		SecretFilesEnabled:                  BooleanDefaultFalse{Value: ExplicitlyDisabled},
+		SecretRotationInterval:              DefaultSecretRotationInterval,
+		TCSBufferDisabled:                   BooleanDefaultFalse{Value: ExplicitlyDisabled},
+		TCSBufferMaxAge:                     DefaultTCSBufferMaxAge,
+		TCSBufferMaxSize:                    DefaultTCSBufferMaxSize,
+		WebsocketCompressionDisabled:        BooleanDefaultFalse{Value: ExplicitlyDisabled},
+		ACSMaxMessageSize:                   DefaultACSMaxMessageSize,
+		TCSMaxMessageSize:                   DefaultTCSMaxMessageSize,
+		MetricsSinkFlushInterval:            DefaultMetricsSinkFlushInterval,
 		PollingMetricsWaitDuration:          DefaultPollingMetricsWaitDuration,
+		ContainerStatsSource:                ContainerStatsSourceDocker,
 		GMSACapable:                         BooleanDefaultFalse{Value: ExplicitlyDisabled},
 		GMSADomainlessCapable:               BooleanDefaultFalse{Value: ExplicitlyDisabled},
 		FSxWindowsFileServerCapable:         BooleanDefaultTrue{Value: NotSet},
@@ -168,6 +191,18 @@ func (cfg *Config) platformOverrides() {
 	// ensure TaskResourceLimit is disabled
 	cfg.TaskCPUMemLimit.Value = ExplicitlyDisabled
 
+	// secret files are written to a tmpfs, which Windows doesn't have
+	cfg.SecretFilesEnabled.Value = ExplicitlyDisabled
+
+	// container stats can only be read from cgroupfs on Linux
+	cfg.ContainerStatsSource = ContainerStatsSourceDocker
+
+	// there is no syslog daemon to write the audit log entries to on Windows
+	if cfg.CredentialsAuditLogSink == AuditLogSinkSyslog {
+		seelog.Warnf("ECS_AUDIT_LOG_SINK=%s is not supported on Windows, the audit log entries will only be written to the audit log file.", AuditLogSinkSyslog)
+		cfg.CredentialsAuditLogSink = ""
+	}
+
 	cpuUnbounded := parseBooleanDefaultFalseConfig("ECS_ENABLE_CPU_UNBOUNDED_WINDOWS_WORKAROUND")
 	memoryUnbounded := parseBooleanDefaultFalseConfig("ECS_ENABLE_MEMORY_UNBOUNDED_WINDOWS_WORKAROUND")
 
@@ -17,6 +17,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
@@ -28,6 +29,7 @@ import (
 	"github.com/cihub/seelog"
 	cniTypes "github.com/containernetworking/cni/pkg/types"
 	"github.com/docker/go-connections/nat"
+	"github.com/docker/go-units"
 )
 
 const (
@@ -182,6 +184,24 @@ func parseNumNonECSContainersToDeletePerCycle() int {
 	return numNonEcsContainersToDeletePerCycle
 }
 
+func parseImageCleanupDiskWatermark(envVar string) int {
+	watermarkEnvVal := os.Getenv(envVar)
+	watermark, err := strconv.Atoi(watermarkEnvVal)
+	if watermarkEnvVal != "" && err != nil {
+		seelog.Warnf("Invalid format for \"%s\", expected an integer percentage. err %v", envVar, err)
+	}
+	return watermark
+}
+
+func parseImagePrewarmConcurrency() int {
+	imagePrewarmConcurrencyEnvVal := os.Getenv("ECS_IMAGE_PREWARM_CONCURRENCY")
+	imagePrewarmConcurrency, err := strconv.Atoi(imagePrewarmConcurrencyEnvVal)
+	if imagePrewarmConcurrencyEnvVal != "" && err != nil {
+		seelog.Warnf("Invalid format for \"ECS_IMAGE_PREWARM_CONCURRENCY\", expected an integer. err %v", err)
+	}
+	return imagePrewarmConcurrency
+}
+
 func parseImagePullBehavior() ImagePullBehaviorType {
 	ImagePullBehaviorString := os.Getenv("ECS_IMAGE_PULL_BEHAVIOR")
 	switch ImagePullBehaviorString {
@@ -330,6 +350,21 @@ func parseContainerInstancePropagateTagsFrom() ContainerInstancePropagateTagsFro
 	}
 }
 
+// parseEnvVariableSize parses a number of bytes with an optional unit
+// suffix such as "512k" or "10m".
+func parseEnvVariableSize(envVar string) int64 {
+	envVal := os.Getenv(envVar)
+	if envVal == "" {
+		return 0
+	}
+	size, err := units.RAMInBytes(strings.TrimSpace(envVal))
+	if err != nil {
+		seelog.Warnf("Invalid format for \""+envVar+"\" environment variable; expected a size such as \"10m\". err %v", err)
+		return 0
+	}
+	return size
+}
+
 func parseEnvVariableUint16(envVar string) uint16 {
 	envVal := os.Getenv(envVar)
 	var var16 uint16
@@ -359,6 +394,32 @@ func parseEnvVariableDuration(envVar string) time.Duration {
 	return duration
 }
 
+func parseTaskAdmissionFamilyPriorities() map[string]int {
+	prioritiesEnv := os.Getenv("ECS_TASK_ADMISSION_FAMILY_PRIORITIES")
+	if prioritiesEnv == "" {
+		return nil
+	}
+	var priorities map[string]int
+	if err := json.Unmarshal([]byte(prioritiesEnv), &priorities); err != nil {
+		seelog.Warnf("Invalid format for ECS_TASK_ADMISSION_FAMILY_PRIORITIES, expected a json hash of task definition families to integer priorities: %v", err)
+		return nil
+	}
+	return priorities
+}
+
+// isValidAuditLogSink returns true if the sink is either the syslog sink or the address of a
+// stream or datagram unix socket.
+func isValidAuditLogSink(sink string) bool {
+	if sink == AuditLogSinkSyslog {
+		return true
+	}
+	sinkURL, err := url.Parse(sink)
+	if err != nil {
+		return false
+	}
+	return (sinkURL.Scheme == "unix" || sinkURL.Scheme == "unixgram") && sinkURL.Path != ""
+}
+
 func parseImageCleanupExclusionList(envVar string) []string {
 	imageEnv := os.Getenv(envVar)
 	var imageCleanupExclusionList []string
@@ -64,6 +64,36 @@ func TestParseNumImagesToDeletePerCycle(t *testing.T) {
 	assert.Zero(t, v)
 }
 
+func TestParseImageCleanupDiskWatermark(t *testing.T) {
+	// unset value
+	t.Setenv("ECS_IMAGE_CLEANUP_DISK_HIGH_WATERMARK", "")
+	v := parseImageCleanupDiskWatermark("ECS_IMAGE_CLEANUP_DISK_HIGH_WATERMARK")
+	assert.Zero(t, v)
+	// valid value
+	t.Setenv("ECS_IMAGE_CLEANUP_DISK_HIGH_WATERMARK", "85")
+	v = parseImageCleanupDiskWatermark("ECS_IMAGE_CLEANUP_DISK_HIGH_WATERMARK")
+	assert.Equal(t, 85, v)
+	// invalid value
+	t.Setenv("ECS_IMAGE_CLEANUP_DISK_HIGH_WATERMARK", "85%")
+	v = parseImageCleanupDiskWatermark("ECS_IMAGE_CLEANUP_DISK_HIGH_WATERMARK")
+	assert.Zero(t, v)
+}
+
+func TestParseImagePrewarmConcurrency(t *testing.T) {
+	// unset value
+	t.Setenv("ECS_IMAGE_PREWARM_CONCURRENCY", "")
+	v := parseImagePrewarmConcurrency()
+	assert.Zero(t, v)
+	// valid value
+	t.Setenv("ECS_IMAGE_PREWARM_CONCURRENCY", "4")
+	v = parseImagePrewarmConcurrency()
+	assert.Equal(t, 4, v)
+	// invalid value
+	t.Setenv("ECS_IMAGE_PREWARM_CONCURRENCY", "foobar")
+	v = parseImagePrewarmConcurrency()
+	assert.Zero(t, v)
+}
+
 func TestParseNumNonECSContainersToDeletePerCycle(t *testing.T) {
 	// unset value
 	t.Setenv("NONECS_NUM_CONTAINERS_DELETE_PER_CYCLE", "")
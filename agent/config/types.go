@@ -71,6 +71,10 @@ type Config struct {
 	// file, in DataDir, such that on instance or agent restarts it will resume
 	// as the same ContainerInstance. It defaults to false.
 	Checkpoint BooleanDefaultFalse
+	// DataStoreDriver is the name of the storage engine used to checkpoint data
+	// in DataDir. It defaults to "boltdb"; "sqlite" stores the data in an embedded
+	// SQLite file that can be queried offline.
+	DataStoreDriver string `trim:"true"`
 
 	// EngineAuthType configures what type of data is in EngineAuthData.
 	// Supported types, right now, can be found in the dockerauth package: https://godoc.org/github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerauth
@@ -99,6 +103,10 @@ type Config struct {
 	// again when PollMetrics is set to true
 	PollingMetricsWaitDuration time.Duration
 
+	// ContainerStatsSource is where the container stats are collected from. It is one of
+	// ContainerStatsSourceDocker and ContainerStatsSourceCgroupfs.
+	ContainerStatsSource string
+
 	// DisableDockerHealthCheck configures whether container health feature was enabled
 	// on the instance
 	DisableDockerHealthCheck BooleanDefaultFalse
@@ -108,6 +116,22 @@ type Config struct {
 	// This doesn't reserve memory usage on the instance
 	ReservedMemory uint16
 
+	// TaskAdmissionPolicy is the policy the tasks waiting for host resources are admitted with. It is
+	// one of TaskAdmissionPolicyFIFO, TaskAdmissionPolicyBestFit and TaskAdmissionPolicyPriority.
+	TaskAdmissionPolicy string
+
+	// TaskAdmissionPriorityTag is the key of the task tag that sets the priority of the task under
+	// TaskAdmissionPolicyPriority.
+	TaskAdmissionPriorityTag string
+
+	// TaskAdmissionMaxWait is how long a task waits for host resources under TaskAdmissionPolicyBestFit
+	// before it is admitted ahead of the smaller tasks, so that large tasks aren't starved.
+	TaskAdmissionMaxWait time.Duration
+
+	// TaskAdmissionFamilyPriorities maps task definition families to the priority of their tasks under
+	// TaskAdmissionPolicyPriority, for the tasks without the priority tag.
+	TaskAdmissionFamilyPriorities map[string]int
+
 	// DockerStopTimeout specifies the amount of time before a SIGKILL is issued to
 	// containers managed by ECS
 	DockerStopTimeout time.Duration
@@ -170,10 +194,37 @@ type Config struct {
 	// CredentialsAuditLogEnabled specifies whether audit logging is disabled.
 	CredentialsAuditLogDisabled bool
 
+	// CredentialsAuditLogFormat is the format the audit log entries are written in. It is one of
+	// AuditLogFormatText and AuditLogFormatJSON.
+	CredentialsAuditLogFormat string
+
+	// CredentialsAuditLogRolloverType specifies whether the audit log file is rotated by size or
+	// hourly. It is one of AuditLogRolloverTypeSize and AuditLogRolloverTypeHourly, or empty to
+	// rotate the audit log the same way as the agent log.
+	CredentialsAuditLogRolloverType string
+
+	// CredentialsAuditLogMaxFileSize is the size in bytes the audit log file is rotated at when it
+	// is rotated by size. The maximum size of the agent log file is used if it is zero.
+	CredentialsAuditLogMaxFileSize int64
+
+	// CredentialsAuditLogMaxRollCount is the number of rotated audit log files to keep. The
+	// maximum roll count of the agent log is used if it is zero.
+	CredentialsAuditLogMaxRollCount int
+
+	// CredentialsAuditLogSink is an additional destination the audit log entries are written to.
+	// It is either AuditLogSinkSyslog or the address of a unix socket, such as
+	// "unix:///var/run/audit.sock" or "unixgram:///var/run/audit.sock".
+	CredentialsAuditLogSink string
+
 	// TaskIAMRoleEnabledForNetworkHost specifies if the Agent is capable of launching
 	// tasks with IAM Roles when networkMode is set to 'host'
 	TaskIAMRoleEnabledForNetworkHost bool
 
+	// TaskCredentialsSourceEnforced specifies whether requests to the task credentials
+	// endpoint are rejected unless they originate from the task that the credentials
+	// belong to. Requests for execution role credentials are not checked
+	TaskCredentialsSourceEnforced bool
+
 	// TaskENIEnabled specifies if the Agent is capable of launching task within
 	// defined EC2 networks
 	TaskENIEnabled BooleanDefaultFalse
@@ -201,6 +252,19 @@ type Config struct {
 	// when Agent performs cleanup
 	NumImagesToDeletePerCycle int
 
+	// ImageCleanupDiskHighWatermark specifies the disk usage percentage of the Docker
+	// data root above which the Agent immediately starts evicting images. Watermark
+	// cleanup is disabled when it is not set
+	ImageCleanupDiskHighWatermark int
+
+	// ImageCleanupDiskLowWatermark specifies the disk usage percentage of the Docker
+	// data root that watermark cleanup evicts images down to
+	ImageCleanupDiskLowWatermark int
+
+	// ImageCleanupDiskCheckInterval specifies the time between two disk usage checks
+	// of the Docker data root when watermark cleanup is enabled
+	ImageCleanupDiskCheckInterval time.Duration
+
 	// NumNonECSContainersToDeletePerCycle specifies the num of NonECS containers to delete every time
 	// when Agent performs cleanup
 	NumNonECSContainersToDeletePerCycle int
@@ -209,6 +273,15 @@ type Config struct {
 	// local Docker image cache
 	ImagePullBehavior ImagePullBehaviorType
 
+	// ImagePrewarmEnabled specifies whether the Agent will pull the images listed in
+	// the image pre-warm manifest under DataDir in the background, and keep them
+	// from being removed by automated image cleanup while they remain listed
+	ImagePrewarmEnabled BooleanDefaultFalse
+
+	// ImagePrewarmConcurrency specifies the maximum number of image pre-warm pulls
+	// that the Agent will run at the same time
+	ImagePrewarmConcurrency int
+
 	// InstanceAttributes contains key/value pairs representing
 	// attributes to be associated with this instance within the
 	// ECS service and used to influence behavior such as launch
@@ -234,11 +307,98 @@ type Config struct {
 	// the image from the tarball; the referenced image must already be loaded.
 	PauseContainerTag string
 
+	// TaskMetricsExporterEnabled configures whether the per-task and per-container stats collected
+	// by the stats engine are exposed in the OpenMetrics format on AgentTaskMetricsExpositionPort.
+	// This is disabled by default.
+	TaskMetricsExporterEnabled BooleanDefaultFalse
+
+	// TracingEnabled configures whether the transitions of the task lifecycle are recorded as
+	// spans and exported to TracingEndpoint. This is disabled by default.
+	TracingEnabled BooleanDefaultFalse
+
+	// TracingEndpoint is the OTLP/HTTP traces endpoint of the collector the spans are exported to
+	TracingEndpoint string `trim:"true"`
+
+	// Standalone configures whether the agent runs without ECS. In standalone mode the agent does
+	// not connect to ACS and TCS, runs the task definitions found in StandaloneTaskDir or posted to
+	// the local API on AgentStandaloneAPIPort, and records the task state changes on disk instead
+	// of submitting them to ECS. This is disabled by default.
+	Standalone BooleanDefaultFalse
+
+	// StandaloneTaskDir is the directory of the task definition files run in standalone mode. It
+	// defaults to the "tasks" directory in DataDir.
+	StandaloneTaskDir string `trim:"true"`
+
+	// TaskResourceUsageRetention is the duration the final resource usage totals of a stopped task
+	// are kept in the data store, and exposed by the task metadata and introspection endpoints.
+	TaskResourceUsageRetention time.Duration
+
+	// SecretFilesEnabled configures whether the SSM and Secrets Manager secrets of the containers are
+	// also written as files to a tmpfs of the task, mounted read-only in the containers at
+	// /run/secrets/<secret name>, and refreshed every SecretRotationInterval. This is disabled by
+	// default, and not supported on Windows.
+	SecretFilesEnabled BooleanDefaultFalse
+
+	// SecretRotationInterval is how often the secret files of the running tasks are fetched again
+	// with the task execution role, and swapped when their value changed.
+	SecretRotationInterval time.Duration
+
+	// LocalSecretsFile is the path of the encrypted file of the local secrets, that the secrets with a
+	// local-file:// valueFrom are retrieved from instead of SSM Parameter Store or Secrets Manager.
+	LocalSecretsFile string
+
+	// LocalSecretsKeyFile is the path of the file with the base64 encoded AES-256 key LocalSecretsFile
+	// is encrypted with.
+	LocalSecretsKeyFile string
+
+	// LocalSecretsEndpoint is the base URL of the Vault style secrets endpoint that the secrets with a
+	// local-http:// valueFrom are retrieved from.
+	LocalSecretsEndpoint string
+
+	// LocalSecretsTokenFile is the path of the file with the token of LocalSecretsEndpoint.
+	LocalSecretsTokenFile string
+
+	// TCSBufferDisabled configures whether the telemetry and health messages that cannot be published
+	// to TCS while its connection is down are dropped, instead of being queued on disk in DataDir and
+	// replayed in order once the connection is back.
+	TCSBufferDisabled BooleanDefaultFalse
+
+	// TCSBufferMaxAge is how long the unpublished telemetry and health messages are kept before
+	// they are dropped.
+	TCSBufferMaxAge time.Duration
+
+	// TCSBufferMaxSize is the maximum size in bytes of the unpublished telemetry and health messages
+	// kept on disk. The oldest messages are dropped to make room for new ones.
+	TCSBufferMaxSize int64
+
+	// WebsocketCompressionDisabled configures whether the permessage-deflate compression of the
+	// ACS and TCS websocket connections is not negotiated.
+	WebsocketCompressionDisabled BooleanDefaultFalse
+
+	// ACSMaxMessageSize is the maximum size in bytes of a message read from ACS. The connection
+	// is closed and established again when it is exceeded.
+	ACSMaxMessageSize int64
+
+	// TCSMaxMessageSize is the maximum size in bytes of a message read from TCS. The connection
+	// is closed and established again when it is exceeded.
+	TCSMaxMessageSize int64
+
 	// PrometheusMetricsEnabled configures whether Agent metrics should be
 	// collected and published to the specified endpoint. This is disabled by
 	// default.
 	PrometheusMetricsEnabled bool
 
+	// MetricsSink is the sink the Agent internal metrics are published to, in addition to
+	// Prometheus. It is one of MetricsSinkStatsD and MetricsSinkOTLP, or empty to publish no metrics.
+	MetricsSink string
+
+	// MetricsSinkEndpoint is the address of the metrics sink: the host:port of the StatsD server, or
+	// the URL of the OTLP/HTTP metrics endpoint.
+	MetricsSinkEndpoint string
+
+	// MetricsSinkFlushInterval is the interval the metrics are sent to the sink at.
+	MetricsSinkFlushInterval time.Duration
+
 	// AWSVPCBlockInstanceMetdata specifies if InstanceMetadata endpoint should be blocked
 	// for tasks that are launched with network mode "awsvpc" when ECS_AWSVPC_BLOCK_IMDS=true
 	AWSVPCBlockInstanceMetdata BooleanDefaultFalse
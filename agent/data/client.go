@@ -14,8 +14,11 @@
 package data
 
 import (
+	"fmt"
 	"path/filepath"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/aws/amazon-ecs-agent/agent/api/container"
 	"github.com/aws/amazon-ecs-agent/agent/api/task"
@@ -24,31 +27,54 @@ import (
 	generaldata "github.com/aws/amazon-ecs-agent/ecs-agent/data"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/modeltransformer"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
+
+	"github.com/pkg/errors"
 	bolt "go.etcd.io/bbolt"
 )
 
 const (
+	// BoltDBDriver is the name of the default driver, which stores the data in a boltdb file.
+	BoltDBDriver = "boltdb"
+
 	dbName = "agent.db"
 	dbMode = 0600
 
-	containersBucketName     = "containers"
-	tasksBucketName          = "tasks"
-	imagesBucketName         = "images"
-	eniAttachmentsBucketName = "eniattachments"
-	metadataBucketName       = "metadata"
-	emptyAgentVersionMsg     = "No version info available in boltDB. Either this is a fresh instance, or we were using state file to persist data. Transformer not applicable."
+	// dbOpenReadOnlyTimeout is the time to wait for the file lock when opening the boltdb file in read-only mode
+	dbOpenReadOnlyTimeout = time.Second
+
+	containersBucketName        = "containers"
+	tasksBucketName             = "tasks"
+	imagesBucketName            = "images"
+	eniAttachmentsBucketName    = "eniattachments"
+	metadataBucketName          = "metadata"
+	taskResourceUsageBucketName = "taskresourceusage"
+	emptyAgentVersionMsg        = "No version info available in boltDB. Either this is a fresh instance, or we were using state file to persist data. Transformer not applicable."
 )
 
 var (
 	dbClient Client
 	once     sync.Once
 
+	driversLock sync.RWMutex
+	drivers     = map[string]OpenFunc{
+		BoltDBDriver: func(dataDir string) (Client, error) {
+			return setup(dataDir)
+		},
+	}
+
 	buckets = []string{
 		imagesBucketName,
 		containersBucketName,
 		tasksBucketName,
 		eniAttachmentsBucketName,
 		metadataBucketName,
+		taskResourceUsageBucketName,
+	}
+
+	// optionalBuckets are the buckets that a data file written by an older agent may not have. They
+	// are not required to open the file in read-only mode.
+	optionalBuckets = map[string]bool{
+		taskResourceUsageBucketName: true,
 	}
 )
 
@@ -92,10 +118,57 @@ type Client interface {
 	// GetMetadata gets the value of a certain kind of metadata.
 	GetMetadata(string) (string, error)
 
+	// SaveTaskResourceUsage saves the resource usage accumulated by a task.
+	SaveTaskResourceUsage(*task.ResourceUsage) error
+	// DeleteTaskResourceUsage deletes the resource usage of a task.
+	DeleteTaskResourceUsage(string) error
+	// GetTaskResourceUsages gets the resource usage of all the tasks.
+	GetTaskResourceUsages() ([]*task.ResourceUsage, error)
+
 	// Close closes the connection to database.
 	Close() error
 }
 
+// OpenFunc opens a Client whose data is stored under dataDir.
+type OpenFunc func(dataDir string) (Client, error)
+
+// RegisterDriver makes a storage engine available by the provided name. It panics if a driver
+// is already registered with the same name.
+func RegisterDriver(name string, open OpenFunc) {
+	driversLock.Lock()
+	defer driversLock.Unlock()
+	if _, ok := drivers[name]; ok {
+		panic("data: RegisterDriver called twice for driver " + name)
+	}
+	drivers[name] = open
+}
+
+// Drivers returns the sorted names of the registered drivers.
+func Drivers() []string {
+	driversLock.RLock()
+	defer driversLock.RUnlock()
+	var names []string
+	for name := range drivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Open returns a new data client of the named driver. It always runs the driver setup.
+func Open(driverName, dataDir string) (Client, error) {
+	if driverName == "" {
+		driverName = BoltDBDriver
+	}
+	driversLock.RLock()
+	open, ok := drivers[driverName]
+	driversLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown data store driver %q, registered drivers: %v", driverName, Drivers())
+	}
+	return open(dataDir)
+}
+
 // client implements the Client interface using boltdb as the backing data store.
 type client struct {
 	generaldata.Client
@@ -103,9 +176,14 @@ type client struct {
 
 // New returns a data client that implements the Client interface with boltdb.
 func New(dataDir string) (Client, error) {
+	return NewWithDriver(BoltDBDriver, dataDir)
+}
+
+// NewWithDriver returns a data client that implements the Client interface with the named driver.
+func NewWithDriver(driverName, dataDir string) (Client, error) {
 	var err error
 	once.Do(func() {
-		dbClient, err = setup(dataDir)
+		dbClient, err = Open(driverName, dataDir)
 	})
 	if err != nil {
 		return nil, err
@@ -113,6 +191,46 @@ func New(dataDir string) (Client, error) {
 	return dbClient, nil
 }
 
+// BoltDBPath returns the path of the boltdb file under dataDir.
+func BoltDBPath(dataDir string) string {
+	return filepath.Join(dataDir, dbName)
+}
+
+// NewReadOnly returns a data client backed by the boltdb file at dbPath, opened in read-only mode so that
+// the state of the agent can be inspected without modifying it. Save and delete operations fail on it.
+// Opening fails after dbOpenReadOnlyTimeout if the file is held open by a running agent.
+func NewReadOnly(dbPath string) (Client, error) {
+	db, err := bolt.Open(dbPath, dbMode, &bolt.Options{
+		ReadOnly: true,
+		Timeout:  dbOpenReadOnlyTimeout,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open %s in read-only mode", dbPath)
+	}
+	err = db.View(func(tx *bolt.Tx) error {
+		for _, b := range buckets {
+			if tx.Bucket([]byte(b)) == nil && !optionalBuckets[b] {
+				return errors.Errorf("bucket %s not found", b)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	transformer := modeltransformer.NewTransformer()
+	transformationfunctions.RegisterTaskTransformationFunctions(transformer)
+	return &client{
+		generaldata.Client{
+			Accessor:    generaldata.DBAccessor{},
+			DB:          db,
+			Transformer: transformer,
+		},
+	}, nil
+}
+
 // NewWithSetup returns a data client that implements the Client interface with boltdb.
 // It always runs the db setup. Used for testing.
 func NewWithSetup(dataDir string) (Client, error) {
@@ -122,7 +240,7 @@ func NewWithSetup(dataDir string) (Client, error) {
 // setup initiates the boltdb client and makes sure the buckets we use and transformer are created, and
 // registers transformation functions to transformer.
 func setup(dataDir string) (*client, error) {
-	db, err := bolt.Open(filepath.Join(dataDir, dbName), dbMode, nil)
+	db, err := bolt.Open(BoltDBPath(dataDir), dbMode, nil)
 	err = db.Update(func(tx *bolt.Tx) error {
 		for _, b := range buckets {
 			_, err = tx.CreateBucketIfNotExists([]byte(b))
@@ -56,3 +56,56 @@ func newTestClient(t *testing.T) Client {
 	})
 	return testClient
 }
+
+func TestOpenDriver(t *testing.T) {
+	c, err := Open("", t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, c.Close())
+
+	c, err = Open(BoltDBDriver, t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, c.Close())
+
+	_, err = Open("unknown", t.TempDir())
+	require.Error(t, err)
+}
+
+func TestRegisterDriver(t *testing.T) {
+	const testDriverName = "test-driver"
+	RegisterDriver(testDriverName, func(dataDir string) (Client, error) {
+		return NewNoopClient(), nil
+	})
+	t.Cleanup(func() {
+		driversLock.Lock()
+		defer driversLock.Unlock()
+		delete(drivers, testDriverName)
+	})
+
+	require.Contains(t, Drivers(), testDriverName)
+	c, err := Open(testDriverName, t.TempDir())
+	require.NoError(t, err)
+	require.Equal(t, NewNoopClient(), c)
+
+	require.Panics(t, func() {
+		RegisterDriver(testDriverName, nil)
+	})
+}
+
+func TestNewReadOnly(t *testing.T) {
+	testDir := t.TempDir()
+	c, err := NewWithSetup(testDir)
+	require.NoError(t, err)
+	require.NoError(t, c.SaveMetadata(ClusterNameKey, "test-cluster"))
+	require.NoError(t, c.Close())
+
+	readOnlyClient, err := NewReadOnly(BoltDBPath(testDir))
+	require.NoError(t, err)
+	defer readOnlyClient.Close()
+	val, err := readOnlyClient.GetMetadata(ClusterNameKey)
+	require.NoError(t, err)
+	require.Equal(t, "test-cluster", val)
+	require.Error(t, readOnlyClient.SaveMetadata(ClusterNameKey, "other-cluster"))
+
+	_, err = NewReadOnly(filepath.Join(t.TempDir(), dbName))
+	require.Error(t, err)
+}
@@ -0,0 +1,168 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package inspect
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+const (
+	// ChangeAdded is an object that is only in the new snapshot
+	ChangeAdded = "added"
+	// ChangeRemoved is an object that is only in the old snapshot
+	ChangeRemoved = "removed"
+	// ChangeModified is an object that is in both snapshots with different contents
+	ChangeModified = "modified"
+
+	kindTask          = "task"
+	kindContainer     = "container"
+	kindImage         = "image"
+	kindENIAttachment = "eniAttachment"
+	kindMetadata      = "metadata"
+)
+
+// Change is a difference between two snapshots for a single object.
+type Change struct {
+	// Kind is the type of the object: task, container, image, eniAttachment or metadata
+	Kind string `json:"kind"`
+	// ID identifies the object, e.g. the task ARN or the metadata key
+	ID string `json:"id"`
+	// Type is one of added, removed or modified
+	Type string `json:"type"`
+	// Details lists the summarized fields that changed, as "name: old -> new"
+	Details []string `json:"details,omitempty"`
+}
+
+// summaryField is a field of an object that is reported in the details of a change.
+type summaryField struct {
+	name  string
+	value string
+}
+
+// diffEntry is an object of a snapshot as compared by Diff.
+type diffEntry struct {
+	fields []summaryField
+	raw    []byte
+}
+
+// Diff returns the changes from the old snapshot to the new one, ordered by kind and id.
+func Diff(old, new *Snapshot) []Change {
+	var changes []Change
+	changes = append(changes, diffEntries(kindTask, old.taskEntries(), new.taskEntries())...)
+	changes = append(changes, diffEntries(kindContainer, old.containerEntries(), new.containerEntries())...)
+	changes = append(changes, diffEntries(kindImage, old.imageEntries(), new.imageEntries())...)
+	changes = append(changes, diffEntries(kindENIAttachment, old.eniAttachmentEntries(), new.eniAttachmentEntries())...)
+	changes = append(changes, diffEntries(kindMetadata, old.metadataEntries(), new.metadataEntries())...)
+	return changes
+}
+
+func diffEntries(kind string, old, new map[string]diffEntry) []Change {
+	var changes []Change
+	for id, newEntry := range new {
+		oldEntry, ok := old[id]
+		if !ok {
+			changes = append(changes, Change{Kind: kind, ID: id, Type: ChangeAdded})
+			continue
+		}
+		if bytes.Equal(oldEntry.raw, newEntry.raw) {
+			continue
+		}
+		change := Change{Kind: kind, ID: id, Type: ChangeModified}
+		for i, newField := range newEntry.fields {
+			if oldField := oldEntry.fields[i]; oldField.value != newField.value {
+				change.Details = append(change.Details,
+					fmt.Sprintf("%s: %s -> %s", newField.name, oldField.value, newField.value))
+			}
+		}
+		if len(change.Details) == 0 {
+			change.Details = []string{"other fields changed"}
+		}
+		changes = append(changes, change)
+	}
+	for id := range old {
+		if _, ok := new[id]; !ok {
+			changes = append(changes, Change{Kind: kind, ID: id, Type: ChangeRemoved})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].ID < changes[j].ID
+	})
+	return changes
+}
+
+func newDiffEntry(obj interface{}, fields ...summaryField) diffEntry {
+	// marshaling only fails for unsupported types, which the persisted objects are not
+	raw, _ := json.Marshal(obj)
+	return diffEntry{fields: fields, raw: raw}
+}
+
+func (s *Snapshot) taskEntries() map[string]diffEntry {
+	entries := make(map[string]diffEntry)
+	for _, task := range s.Tasks {
+		entries[task.Arn] = newDiffEntry(task,
+			summaryField{"knownStatus", task.GetKnownStatus().String()},
+			summaryField{"desiredStatus", task.GetDesiredStatus().String()},
+		)
+	}
+	return entries
+}
+
+func (s *Snapshot) containerEntries() map[string]diffEntry {
+	entries := make(map[string]diffEntry)
+	for _, container := range s.Containers {
+		if container.Container == nil {
+			continue
+		}
+		entries[containerKey(container)] = newDiffEntry(container,
+			summaryField{"dockerId", container.DockerID},
+			summaryField{"knownStatus", container.Container.GetKnownStatus().String()},
+			summaryField{"desiredStatus", container.Container.GetDesiredStatus().String()},
+		)
+	}
+	return entries
+}
+
+func (s *Snapshot) imageEntries() map[string]diffEntry {
+	entries := make(map[string]diffEntry)
+	for _, imageState := range s.ImageStates {
+		entries[imageState.GetImageID()] = newDiffEntry(imageState,
+			summaryField{"names", imageNames(imageState)},
+			summaryField{"lastUsedAt", formatTime(imageState.LastUsedAt)},
+		)
+	}
+	return entries
+}
+
+func (s *Snapshot) eniAttachmentEntries() map[string]diffEntry {
+	entries := make(map[string]diffEntry)
+	for _, eniAttachment := range s.ENIAttachments {
+		entries[eniAttachment.AttachmentARN] = newDiffEntry(eniAttachment,
+			summaryField{"status", eniAttachment.Status.String()},
+			summaryField{"attachStatusSent", strconv.FormatBool(eniAttachment.AttachStatusSent)},
+		)
+	}
+	return entries
+}
+
+func (s *Snapshot) metadataEntries() map[string]diffEntry {
+	entries := make(map[string]diffEntry)
+	for key, val := range s.Metadata {
+		entries[key] = newDiffEntry(val, summaryField{"value", val})
+	}
+	return entries
+}
@@ -0,0 +1,129 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package inspect
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/engine/image"
+)
+
+const (
+	// FormatTable prints the objects as aligned columns
+	FormatTable = "table"
+	// FormatJSON prints the objects as indented JSON
+	FormatJSON = "json"
+
+	// emptyValue is printed in table cells without a value
+	emptyValue = "-"
+)
+
+// WriteJSON writes v as indented JSON.
+func WriteJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// WriteTable writes one table per type of object in the snapshot.
+func WriteTable(w io.Writer, snapshot *Snapshot) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "TASKS")
+	fmt.Fprintln(tw, "ARN\tFAMILY\tVERSION\tKNOWN STATUS\tDESIRED STATUS")
+	for _, task := range snapshot.Tasks {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", task.Arn, cell(task.Family), cell(task.Version),
+			task.GetKnownStatus().String(), task.GetDesiredStatus().String())
+	}
+
+	fmt.Fprintln(tw, "\nCONTAINERS")
+	fmt.Fprintln(tw, "TASK ARN\tNAME\tDOCKER ID\tIMAGE\tKNOWN STATUS\tDESIRED STATUS")
+	for _, container := range snapshot.Containers {
+		if container.Container == nil {
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", container.Container.GetTaskARN(), container.Container.Name,
+			cell(container.DockerID), cell(container.Container.Image),
+			container.Container.GetKnownStatus().String(), container.Container.GetDesiredStatus().String())
+	}
+
+	fmt.Fprintln(tw, "\nIMAGES")
+	fmt.Fprintln(tw, "IMAGE ID\tNAMES\tSIZE\tPULLED AT\tLAST USED AT")
+	for _, imageState := range snapshot.ImageStates {
+		var size int64
+		if imageState.Image != nil {
+			size = imageState.Image.Size
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\n", cell(imageState.GetImageID()), imageNames(imageState), size,
+			formatTime(imageState.PulledAt), formatTime(imageState.LastUsedAt))
+	}
+
+	fmt.Fprintln(tw, "\nENI ATTACHMENTS")
+	fmt.Fprintln(tw, "ATTACHMENT ARN\tTASK ARN\tMAC ADDRESS\tSTATUS\tSTATUS SENT")
+	for _, eniAttachment := range snapshot.ENIAttachments {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%t\n", eniAttachment.AttachmentARN, cell(eniAttachment.TaskARN),
+			cell(eniAttachment.MACAddress), eniAttachment.Status.String(), eniAttachment.AttachStatusSent)
+	}
+
+	fmt.Fprintln(tw, "\nMETADATA")
+	fmt.Fprintln(tw, "KEY\tVALUE")
+	var keys []string
+	for key := range snapshot.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(tw, "%s\t%s\n", key, cell(snapshot.Metadata[key]))
+	}
+
+	return tw.Flush()
+}
+
+// WriteDiffTable writes the changes between two snapshots as a table.
+func WriteDiffTable(w io.Writer, changes []Change) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "KIND\tID\tCHANGE\tDETAILS")
+	for _, change := range changes {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", change.Kind, change.ID, change.Type,
+			cell(strings.Join(change.Details, "; ")))
+	}
+	return tw.Flush()
+}
+
+func cell(value string) string {
+	if value == "" {
+		return emptyValue
+	}
+	return value
+}
+
+func imageNames(imageState *image.ImageState) string {
+	if imageState.Image == nil {
+		return emptyValue
+	}
+	return cell(strings.Join(imageState.Image.Names, ","))
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return emptyValue
+	}
+	return t.UTC().Format(time.RFC3339)
+}
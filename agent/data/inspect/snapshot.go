@@ -0,0 +1,171 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package inspect reads the state persisted by the agent in its data directory, so that it can be
+// examined offline, e.g. after a host was drained or the agent crashed.
+package inspect
+
+import (
+	"os"
+	"sort"
+	"strings"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/data"
+	"github.com/aws/amazon-ecs-agent/agent/engine/image"
+	ni "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
+
+	"github.com/pkg/errors"
+)
+
+// Snapshot is the state persisted by the agent at a point in time.
+type Snapshot struct {
+	Tasks          []*apitask.Task                 `json:"tasks"`
+	Containers     []*apicontainer.DockerContainer `json:"containers"`
+	ImageStates    []*image.ImageState             `json:"imageStates"`
+	ENIAttachments []*ni.ENIAttachment             `json:"eniAttachments"`
+	Metadata       map[string]string               `json:"metadata"`
+}
+
+// Filter selects the objects of a snapshot. Empty fields match everything.
+type Filter struct {
+	// TaskARN selects the task with this ARN, its containers, the images referenced by its
+	// containers and its ENI attachments
+	TaskARN string
+	// Status selects the tasks and containers with this known status and the ENI attachments
+	// with this attachment status, compared case-insensitively
+	Status string
+}
+
+// Load reads a snapshot from the boltdb file at path, or from the boltdb file under path if it
+// is a directory. The file is opened in read-only mode.
+func Load(path string) (*Snapshot, error) {
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if fileInfo.IsDir() {
+		path = data.BoltDBPath(path)
+	}
+	client, err := data.NewReadOnly(path)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+	return load(client)
+}
+
+func load(client data.Client) (*Snapshot, error) {
+	snapshot := &Snapshot{Metadata: make(map[string]string)}
+	var err error
+	if snapshot.Tasks, err = client.GetTasks(); err != nil {
+		return nil, errors.Wrap(err, "failed to load tasks")
+	}
+	if snapshot.Containers, err = client.GetContainers(); err != nil {
+		return nil, errors.Wrap(err, "failed to load containers")
+	}
+	if snapshot.ImageStates, err = client.GetImageStates(); err != nil {
+		return nil, errors.Wrap(err, "failed to load image states")
+	}
+	if snapshot.ENIAttachments, err = client.GetENIAttachments(); err != nil {
+		return nil, errors.Wrap(err, "failed to load eni attachments")
+	}
+	for _, key := range data.MetadataKeys {
+		if val, err := client.GetMetadata(key); err == nil {
+			snapshot.Metadata[key] = val
+		}
+	}
+	snapshot.sort()
+	return snapshot, nil
+}
+
+// sort orders the objects of the snapshot so that the output is stable.
+func (s *Snapshot) sort() {
+	sort.Slice(s.Tasks, func(i, j int) bool {
+		return s.Tasks[i].Arn < s.Tasks[j].Arn
+	})
+	sort.Slice(s.Containers, func(i, j int) bool {
+		return containerKey(s.Containers[i]) < containerKey(s.Containers[j])
+	})
+	sort.Slice(s.ImageStates, func(i, j int) bool {
+		return s.ImageStates[i].GetImageID() < s.ImageStates[j].GetImageID()
+	})
+	sort.Slice(s.ENIAttachments, func(i, j int) bool {
+		return s.ENIAttachments[i].AttachmentARN < s.ENIAttachments[j].AttachmentARN
+	})
+}
+
+// Filter returns a new snapshot with the objects selected by the filter. Metadata is always kept.
+func (s *Snapshot) Filter(filter Filter) *Snapshot {
+	filtered := &Snapshot{Metadata: s.Metadata}
+	for _, task := range s.Tasks {
+		if matches(filter.TaskARN, task.Arn) && matchesStatus(filter.Status, task.GetKnownStatus().String()) {
+			filtered.Tasks = append(filtered.Tasks, task)
+		}
+	}
+	for _, container := range s.Containers {
+		if container.Container == nil {
+			continue
+		}
+		if matches(filter.TaskARN, container.Container.GetTaskARN()) &&
+			matchesStatus(filter.Status, container.Container.GetKnownStatus().String()) {
+			filtered.Containers = append(filtered.Containers, container)
+		}
+	}
+	taskImageNames := make(map[string]struct{})
+	for _, container := range filtered.Containers {
+		taskImageNames[container.Container.Image] = struct{}{}
+	}
+	for _, imageState := range s.ImageStates {
+		if filter.TaskARN == "" || imageReferenced(imageState, taskImageNames) {
+			filtered.ImageStates = append(filtered.ImageStates, imageState)
+		}
+	}
+	for _, eniAttachment := range s.ENIAttachments {
+		if matches(filter.TaskARN, eniAttachment.TaskARN) && matchesStatus(filter.Status, eniAttachment.Status.String()) {
+			filtered.ENIAttachments = append(filtered.ENIAttachments, eniAttachment)
+		}
+	}
+	return filtered
+}
+
+func matches(want, got string) bool {
+	return want == "" || want == got
+}
+
+func matchesStatus(want, got string) bool {
+	return want == "" || strings.EqualFold(want, got)
+}
+
+// imageReferenced returns true if one of the names of the image is in imageNames. The containers
+// using an image are not persisted with its state, so images are matched by name.
+func imageReferenced(imageState *image.ImageState, imageNames map[string]struct{}) bool {
+	if imageState.Image == nil {
+		return false
+	}
+	for _, name := range imageState.Image.Names {
+		if _, ok := imageNames[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// containerKey identifies a container across snapshots.
+func containerKey(container *apicontainer.DockerContainer) string {
+	if container.Container == nil {
+		return container.DockerID
+	}
+	return container.Container.GetTaskARN() + "/" + container.Container.Name
+}
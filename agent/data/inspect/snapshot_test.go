@@ -0,0 +1,181 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package inspect
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/data"
+	"github.com/aws/amazon-ecs-agent/agent/engine/image"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/api/attachmentinfo"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/api/status"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
+	ni "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testTaskARN1      = "arn:aws:ecs:us-west-2:1234567890:task/test-cluster/task1"
+	testTaskARN2      = "arn:aws:ecs:us-west-2:1234567890:task/test-cluster/task2"
+	testAttachmentARN = "arn:aws:ecs:us-west-2:1234567890:attachment/eni1"
+)
+
+// newTestDataDir saves the given tasks, with one container each, to a new data directory
+func newTestDataDir(t *testing.T, tasks ...*apitask.Task) string {
+	dataDir := t.TempDir()
+	client, err := data.NewWithSetup(dataDir)
+	require.NoError(t, err)
+	defer client.Close()
+
+	for _, task := range tasks {
+		require.NoError(t, client.SaveTask(task))
+		container := &apicontainer.Container{
+			Name:              "app",
+			Image:             task.Family + ":latest",
+			TaskARNUnsafe:     task.Arn,
+			KnownStatusUnsafe: apicontainerstatus.ContainerRunning,
+		}
+		if task.KnownStatusUnsafe == apitaskstatus.TaskStopped {
+			container.KnownStatusUnsafe = apicontainerstatus.ContainerStopped
+		}
+		require.NoError(t, client.SaveDockerContainer(&apicontainer.DockerContainer{
+			DockerID:  task.Family + "-docker-id",
+			Container: container,
+		}))
+		require.NoError(t, client.SaveImageState(&image.ImageState{
+			Image: &image.Image{ImageID: "sha256:" + task.Family, Names: []string{container.Image}},
+		}))
+	}
+	require.NoError(t, client.SaveENIAttachment(&ni.ENIAttachment{
+		AttachmentInfo: attachmentinfo.AttachmentInfo{
+			TaskARN:       testTaskARN1,
+			AttachmentARN: testAttachmentARN,
+			Status:        status.AttachmentAttached,
+		},
+	}))
+	require.NoError(t, client.SaveMetadata(data.ClusterNameKey, "test-cluster"))
+	return dataDir
+}
+
+func newTestTask(arn, family string, knownStatus apitaskstatus.TaskStatus) *apitask.Task {
+	return &apitask.Task{
+		Arn:                 arn,
+		Family:              family,
+		Version:             "1",
+		KnownStatusUnsafe:   knownStatus,
+		DesiredStatusUnsafe: apitaskstatus.TaskRunning,
+	}
+}
+
+func TestLoadAndFilter(t *testing.T) {
+	dataDir := newTestDataDir(t,
+		newTestTask(testTaskARN1, "web", apitaskstatus.TaskRunning),
+		newTestTask(testTaskARN2, "worker", apitaskstatus.TaskStopped),
+	)
+
+	snapshot, err := Load(dataDir)
+	require.NoError(t, err)
+	assert.Len(t, snapshot.Tasks, 2)
+	assert.Len(t, snapshot.Containers, 2)
+	assert.Len(t, snapshot.ImageStates, 2)
+	assert.Len(t, snapshot.ENIAttachments, 1)
+	assert.Equal(t, "test-cluster", snapshot.Metadata[data.ClusterNameKey])
+
+	byTask := snapshot.Filter(Filter{TaskARN: testTaskARN1})
+	require.Len(t, byTask.Tasks, 1)
+	assert.Equal(t, testTaskARN1, byTask.Tasks[0].Arn)
+	require.Len(t, byTask.Containers, 1)
+	assert.Equal(t, "web-docker-id", byTask.Containers[0].DockerID)
+	require.Len(t, byTask.ImageStates, 1)
+	assert.Equal(t, "sha256:web", byTask.ImageStates[0].GetImageID())
+	assert.Len(t, byTask.ENIAttachments, 1)
+
+	byStatus := snapshot.Filter(Filter{Status: "stopped"})
+	require.Len(t, byStatus.Tasks, 1)
+	assert.Equal(t, testTaskARN2, byStatus.Tasks[0].Arn)
+	assert.Len(t, byStatus.Containers, 1)
+	assert.Len(t, byStatus.ImageStates, 2, "images are not filtered by status")
+	assert.Empty(t, byStatus.ENIAttachments)
+}
+
+func TestLoadFile(t *testing.T) {
+	dataDir := newTestDataDir(t, newTestTask(testTaskARN1, "web", apitaskstatus.TaskRunning))
+
+	snapshot, err := Load(data.BoltDBPath(dataDir))
+	require.NoError(t, err)
+	assert.Len(t, snapshot.Tasks, 1)
+
+	_, err = Load(t.TempDir() + "/missing")
+	assert.Error(t, err)
+}
+
+func TestDiff(t *testing.T) {
+	old, err := Load(newTestDataDir(t,
+		newTestTask(testTaskARN1, "web", apitaskstatus.TaskRunning),
+	))
+	require.NoError(t, err)
+	new, err := Load(newTestDataDir(t,
+		newTestTask(testTaskARN1, "web", apitaskstatus.TaskStopped),
+		newTestTask(testTaskARN2, "worker", apitaskstatus.TaskRunning),
+	))
+	require.NoError(t, err)
+
+	assert.Empty(t, Diff(old, old))
+
+	changes := Diff(old, new)
+	assert.Contains(t, changes, Change{
+		Kind:    kindTask,
+		ID:      testTaskARN1,
+		Type:    ChangeModified,
+		Details: []string{"knownStatus: RUNNING -> STOPPED"},
+	})
+	assert.Contains(t, changes, Change{Kind: kindTask, ID: testTaskARN2, Type: ChangeAdded})
+	assert.Contains(t, changes, Change{Kind: kindContainer, ID: testTaskARN2 + "/app", Type: ChangeAdded})
+	assert.Contains(t, changes, Change{Kind: kindImage, ID: "sha256:worker", Type: ChangeAdded})
+
+	reverse := Diff(new, old)
+	assert.Contains(t, reverse, Change{Kind: kindTask, ID: testTaskARN2, Type: ChangeRemoved})
+}
+
+func TestWriteOutput(t *testing.T) {
+	snapshot, err := Load(newTestDataDir(t, newTestTask(testTaskARN1, "web", apitaskstatus.TaskRunning)))
+	require.NoError(t, err)
+
+	var table bytes.Buffer
+	require.NoError(t, WriteTable(&table, snapshot))
+	assert.Contains(t, table.String(), testTaskARN1)
+	assert.Contains(t, table.String(), "web-docker-id")
+	assert.Contains(t, table.String(), testAttachmentARN)
+	assert.Contains(t, table.String(), "test-cluster")
+
+	var jsonOutput bytes.Buffer
+	require.NoError(t, WriteJSON(&jsonOutput, snapshot))
+	decoded := make(map[string]interface{})
+	require.NoError(t, json.Unmarshal(jsonOutput.Bytes(), &decoded))
+	assert.Len(t, decoded["tasks"], 1)
+
+	var diffTable bytes.Buffer
+	require.NoError(t, WriteDiffTable(&diffTable, []Change{{Kind: kindTask, ID: testTaskARN1, Type: ChangeAdded}}))
+	assert.Contains(t, diffTable.String(), testTaskARN1)
+}
@@ -26,6 +26,16 @@ const (
 	TaskManifestSeqNumKey   = "task-manifest-seq-num"
 )
 
+// MetadataKeys are all the metadata keys saved by the agent.
+var MetadataKeys = []string{
+	AgentVersionKey,
+	AvailabilityZoneKey,
+	ClusterNameKey,
+	ContainerInstanceARNKey,
+	EC2InstanceIDKey,
+	TaskManifestSeqNumKey,
+}
+
 func (c *client) SaveMetadata(key, val string) error {
 	return c.DB.Batch(func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte(metadataBucketName))
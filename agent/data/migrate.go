@@ -0,0 +1,112 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package data
+
+import (
+	"github.com/aws/amazon-ecs-agent/agent/version"
+
+	"github.com/pkg/errors"
+)
+
+// MigrationSummary holds the number of objects copied by Migrate.
+type MigrationSummary struct {
+	Tasks          int
+	Containers     int
+	ImageStates    int
+	ENIAttachments int
+	ResourceUsages int
+	Metadata       int
+}
+
+// Migrate copies all the data from the src client to the dst client. Objects that already exist in
+// dst are overwritten. The agent version recorded in dst is set to the running agent version.
+func Migrate(src, dst Client) (MigrationSummary, error) {
+	var summary MigrationSummary
+
+	tasks, err := src.GetTasks()
+	if err != nil {
+		return summary, errors.Wrap(err, "failed to load tasks")
+	}
+	for _, task := range tasks {
+		if err := dst.SaveTask(task); err != nil {
+			return summary, errors.Wrapf(err, "failed to save task %s", task.Arn)
+		}
+		summary.Tasks++
+	}
+
+	containers, err := src.GetContainers()
+	if err != nil {
+		return summary, errors.Wrap(err, "failed to load containers")
+	}
+	for _, container := range containers {
+		if err := dst.SaveDockerContainer(container); err != nil {
+			return summary, errors.Wrapf(err, "failed to save container %s", container.Container.Name)
+		}
+		summary.Containers++
+	}
+
+	imageStates, err := src.GetImageStates()
+	if err != nil {
+		return summary, errors.Wrap(err, "failed to load image states")
+	}
+	for _, imageState := range imageStates {
+		if err := dst.SaveImageState(imageState); err != nil {
+			return summary, errors.Wrapf(err, "failed to save image state %s", imageState.GetImageID())
+		}
+		summary.ImageStates++
+	}
+
+	eniAttachments, err := src.GetENIAttachments()
+	if err != nil {
+		return summary, errors.Wrap(err, "failed to load eni attachments")
+	}
+	for _, eniAttachment := range eniAttachments {
+		if err := dst.SaveENIAttachment(eniAttachment); err != nil {
+			return summary, errors.Wrapf(err, "failed to save eni attachment %s", eniAttachment.AttachmentARN)
+		}
+		summary.ENIAttachments++
+	}
+
+	usages, err := src.GetTaskResourceUsages()
+	if err != nil {
+		return summary, errors.Wrap(err, "failed to load task resource usages")
+	}
+	for _, usage := range usages {
+		if err := dst.SaveTaskResourceUsage(usage); err != nil {
+			return summary, errors.Wrapf(err, "failed to save task resource usage %s", usage.TaskARN)
+		}
+		summary.ResourceUsages++
+	}
+
+	for _, key := range MetadataKeys {
+		if key == AgentVersionKey {
+			// tasks are already transformed to the current model when they are read from src
+			continue
+		}
+		val, err := src.GetMetadata(key)
+		if err != nil {
+			// metadata keys are optional, e.g. the task manifest sequence number
+			continue
+		}
+		if err := dst.SaveMetadata(key, val); err != nil {
+			return summary, errors.Wrapf(err, "failed to save metadata %s", key)
+		}
+		summary.Metadata++
+	}
+	if err := dst.SaveMetadata(AgentVersionKey, version.Version); err != nil {
+		return summary, errors.Wrapf(err, "failed to save metadata %s", AgentVersionKey)
+	}
+
+	return summary, nil
+}
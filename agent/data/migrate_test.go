@@ -0,0 +1,92 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package data
+
+import (
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/engine/image"
+	"github.com/aws/amazon-ecs-agent/agent/version"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/api/attachmentinfo"
+	ni "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrate(t *testing.T) {
+	src := newTestClient(t)
+	dst := newTestClient(t)
+
+	require.NoError(t, src.SaveTask(&apitask.Task{Arn: testTaskArn}))
+	require.NoError(t, src.SaveDockerContainer(&apicontainer.DockerContainer{
+		DockerID: testDockerID,
+		Container: &apicontainer.Container{
+			Name:          testContainerName,
+			TaskARNUnsafe: testTaskArn,
+		},
+	}))
+	require.NoError(t, src.SaveImageState(&image.ImageState{
+		Image: &image.Image{ImageID: "sha256:a"},
+	}))
+	require.NoError(t, src.SaveENIAttachment(&ni.ENIAttachment{
+		AttachmentInfo: attachmentinfo.AttachmentInfo{AttachmentARN: testAttachmentArn},
+	}))
+	require.NoError(t, src.SaveTaskResourceUsage(apitask.NewResourceUsage(testTaskArn, "family", "1")))
+	require.NoError(t, src.SaveMetadata(ClusterNameKey, "test-cluster"))
+	require.NoError(t, src.SaveMetadata(AgentVersionKey, "1.0.0"))
+
+	summary, err := Migrate(src, dst)
+	require.NoError(t, err)
+	assert.Equal(t, MigrationSummary{
+		Tasks:          1,
+		Containers:     1,
+		ImageStates:    1,
+		ENIAttachments: 1,
+		ResourceUsages: 1,
+		Metadata:       1,
+	}, summary)
+
+	tasks, err := dst.GetTasks()
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, testTaskArn, tasks[0].Arn)
+
+	containers, err := dst.GetContainers()
+	require.NoError(t, err)
+	require.Len(t, containers, 1)
+	assert.Equal(t, testDockerID, containers[0].DockerID)
+
+	imageStates, err := dst.GetImageStates()
+	require.NoError(t, err)
+	require.Len(t, imageStates, 1)
+	assert.Equal(t, "sha256:a", imageStates[0].GetImageID())
+
+	eniAttachments, err := dst.GetENIAttachments()
+	require.NoError(t, err)
+	require.Len(t, eniAttachments, 1)
+	assert.Equal(t, testAttachmentArn, eniAttachments[0].AttachmentARN)
+
+	clusterName, err := dst.GetMetadata(ClusterNameKey)
+	require.NoError(t, err)
+	assert.Equal(t, "test-cluster", clusterName)
+	agentVersion, err := dst.GetMetadata(AgentVersionKey)
+	require.NoError(t, err)
+	assert.Equal(t, version.Version, agentVersion)
+}
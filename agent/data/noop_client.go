@@ -88,6 +88,18 @@ func (c *noopClient) GetMetadata(string) (string, error) {
 	return "", nil
 }
 
+func (c *noopClient) SaveTaskResourceUsage(*task.ResourceUsage) error {
+	return nil
+}
+
+func (c *noopClient) DeleteTaskResourceUsage(string) error {
+	return nil
+}
+
+func (c *noopClient) GetTaskResourceUsages() ([]*task.ResourceUsage, error) {
+	return nil, nil
+}
+
 func (c *noopClient) Close() error {
 	return nil
 }
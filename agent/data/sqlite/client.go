@@ -0,0 +1,346 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package sqlite implements the data.Client interface on top of an embedded SQLite database.
+//
+// The SQLite engine is provided by modernc.org/sqlite, which does not require cgo, so the
+// agent binary can still be built statically.
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/data"
+	"github.com/aws/amazon-ecs-agent/agent/data/transformationfunctions"
+	"github.com/aws/amazon-ecs-agent/agent/engine/image"
+	"github.com/aws/amazon-ecs-agent/agent/utils"
+	"github.com/aws/amazon-ecs-agent/agent/version"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/modeltransformer"
+	ni "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
+
+	"github.com/pkg/errors"
+	_ "modernc.org/sqlite"
+)
+
+const (
+	// DriverName is the name under which the SQLite backend is registered as a data store driver.
+	DriverName = "sqlite"
+
+	// sqlDriverName is the name under which modernc.org/sqlite registers its database/sql driver.
+	sqlDriverName = "sqlite"
+
+	dbName = "agent.sqlite"
+
+	// dbParams are the connection parameters of the database. The database is read by other
+	// processes while the agent runs, so it uses a write-ahead log, with which the readers don't
+	// block the agent writes, and waits for the locks instead of failing with SQLITE_BUSY.
+	dbParams = "_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+
+	tasksTableName             = "tasks"
+	containersTableName        = "containers"
+	imagesTableName            = "images"
+	eniAttachmentsTableName    = "eni_attachments"
+	metadataTableName          = "metadata"
+	taskResourceUsageTableName = "task_resource_usage"
+)
+
+var (
+	tables = []string{
+		tasksTableName,
+		containersTableName,
+		imagesTableName,
+		eniAttachmentsTableName,
+		metadataTableName,
+		taskResourceUsageTableName,
+	}
+)
+
+func init() {
+	data.RegisterDriver(DriverName, Open)
+}
+
+// client implements the data.Client interface using SQLite as the backing data store.
+// Every table has the same layout as a boltdb bucket: a text primary key and the JSON
+// encoded object, so that the data can be moved between the two backends as is.
+type client struct {
+	db          *sql.DB
+	transformer *modeltransformer.Transformer
+}
+
+// Open returns a data client that stores the data in a SQLite file under dataDir, and makes sure
+// the tables we use are created.
+func Open(dataDir string) (data.Client, error) {
+	db, err := sql.Open(sqlDriverName, filepath.Join(dataDir, dbName)+"?"+dbParams)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open sqlite database")
+	}
+	// SQLite only allows a single writer at a time, serialize access instead of
+	// failing with SQLITE_BUSY.
+	db.SetMaxOpenConns(1)
+
+	for _, table := range tables {
+		if _, err = db.Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (id TEXT PRIMARY KEY, data BLOB NOT NULL)",
+			table)); err != nil {
+			db.Close()
+			return nil, errors.Wrapf(err, "failed to create table %s", table)
+		}
+	}
+
+	transformer := modeltransformer.NewTransformer()
+	transformationfunctions.RegisterTaskTransformationFunctions(transformer)
+
+	return &client{
+		db:          db,
+		transformer: transformer,
+	}, nil
+}
+
+// Close closes the database connection.
+func (c *client) Close() error {
+	return c.db.Close()
+}
+
+func (c *client) put(table, id string, obj interface{}) error {
+	objData, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	_, err = c.db.Exec(fmt.Sprintf("INSERT OR REPLACE INTO %s (id, data) VALUES (?, ?)", table), id, objData)
+	return err
+}
+
+func (c *client) get(table, id string, obj interface{}) error {
+	var objData []byte
+	err := c.db.QueryRow(fmt.Sprintf("SELECT data FROM %s WHERE id = ?", table), id).Scan(&objData)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.Errorf("key %s does not exist in table %s", id, table)
+		}
+		return err
+	}
+	return json.Unmarshal(objData, obj)
+}
+
+func (c *client) delete(table, id string) error {
+	_, err := c.db.Exec(fmt.Sprintf("DELETE FROM %s WHERE id = ?", table), id)
+	return err
+}
+
+// walk calls walkFunc with the id and data of every row of the table.
+func (c *client) walk(table string, walkFunc func(id string, objData []byte) error) error {
+	rows, err := c.db.Query(fmt.Sprintf("SELECT id, data FROM %s", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		var objData []byte
+		if err := rows.Scan(&id, &objData); err != nil {
+			return err
+		}
+		if err := walkFunc(id, objData); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// SaveDockerContainer saves a docker container to the containers table.
+func (c *client) SaveDockerContainer(container *apicontainer.DockerContainer) error {
+	id, err := data.GetContainerID(container.Container)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate database id")
+	}
+	return c.put(containersTableName, id, container)
+}
+
+// SaveContainer saves an apicontainer.Container to the containers table. If a corresponding
+// apicontainer.DockerContainer exists, this updates the Container part of it; otherwise, a new
+// apicontainer.DockerContainer is created and saved.
+func (c *client) SaveContainer(container *apicontainer.Container) error {
+	id, err := data.GetContainerID(container)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate database id")
+	}
+
+	dockerContainer := &apicontainer.DockerContainer{}
+	if err := c.get(containersTableName, id, dockerContainer); err != nil {
+		dockerContainer = &apicontainer.DockerContainer{}
+	}
+	dockerContainer.Container = container
+	return c.put(containersTableName, id, dockerContainer)
+}
+
+// DeleteContainer deletes a container from the containers table.
+func (c *client) DeleteContainer(id string) error {
+	return c.delete(containersTableName, id)
+}
+
+// GetContainers returns all the containers in the containers table.
+func (c *client) GetContainers() ([]*apicontainer.DockerContainer, error) {
+	var containers []*apicontainer.DockerContainer
+	err := c.walk(containersTableName, func(id string, objData []byte) error {
+		container := apicontainer.DockerContainer{}
+		if err := json.Unmarshal(objData, &container); err != nil {
+			return err
+		}
+		containers = append(containers, &container)
+		return nil
+	})
+	return containers, err
+}
+
+// SaveTask saves a task to the tasks table.
+func (c *client) SaveTask(task *apitask.Task) error {
+	id, err := utils.GetTaskID(task.Arn)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate database id")
+	}
+	return c.put(tasksTableName, id, task)
+}
+
+// DeleteTask deletes a task from the tasks table.
+func (c *client) DeleteTask(id string) error {
+	return c.delete(tasksTableName, id)
+}
+
+// GetTasks returns all the tasks in the tasks table.
+func (c *client) GetTasks() ([]*apitask.Task, error) {
+	// transform the model before loading it to agent state, the same way the boltdb client does.
+	agentVersionInDB, err := c.GetMetadata(data.AgentVersionKey)
+	transform := true
+	if err != nil {
+		logger.Info("No version info available in the sqlite database. Transformer not applicable.")
+		transform = false
+	}
+
+	var tasks []*apitask.Task
+	err = c.walk(tasksTableName, func(id string, taskData []byte) error {
+		var err error
+		if transform && c.transformer.IsUpgrade(version.Version, agentVersionInDB) {
+			if taskData, err = c.transformer.TransformTask(agentVersionInDB, taskData); err != nil {
+				return err
+			}
+		}
+		task := apitask.Task{}
+		if err = json.Unmarshal(taskData, &task); err != nil {
+			return err
+		}
+		tasks = append(tasks, &task)
+		return nil
+	})
+	return tasks, err
+}
+
+// SaveImageState saves an image state to the images table.
+func (c *client) SaveImageState(img *image.ImageState) error {
+	id := img.GetImageID()
+	if id == "" {
+		return errors.New("failed to generate database image id")
+	}
+	return c.put(imagesTableName, id, img)
+}
+
+// DeleteImageState deletes an image state from the images table.
+func (c *client) DeleteImageState(id string) error {
+	return c.delete(imagesTableName, id)
+}
+
+// GetImageStates returns all the image states in the images table.
+func (c *client) GetImageStates() ([]*image.ImageState, error) {
+	var imageStates []*image.ImageState
+	err := c.walk(imagesTableName, func(id string, objData []byte) error {
+		imageState := image.ImageState{}
+		if err := json.Unmarshal(objData, &imageState); err != nil {
+			return err
+		}
+		imageStates = append(imageStates, &imageState)
+		return nil
+	})
+	return imageStates, err
+}
+
+// SaveENIAttachment saves an ENI attachment to the eni_attachments table.
+func (c *client) SaveENIAttachment(eni *ni.ENIAttachment) error {
+	id, err := utils.GetENIAttachmentId(eni.AttachmentARN)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate database id")
+	}
+	return c.put(eniAttachmentsTableName, id, eni)
+}
+
+// DeleteENIAttachment deletes an ENI attachment from the eni_attachments table.
+func (c *client) DeleteENIAttachment(id string) error {
+	return c.delete(eniAttachmentsTableName, id)
+}
+
+// GetENIAttachments returns all the ENI attachments in the eni_attachments table.
+func (c *client) GetENIAttachments() ([]*ni.ENIAttachment, error) {
+	var eniAttachments []*ni.ENIAttachment
+	err := c.walk(eniAttachmentsTableName, func(id string, objData []byte) error {
+		eniAttachment := ni.ENIAttachment{}
+		if err := json.Unmarshal(objData, &eniAttachment); err != nil {
+			return err
+		}
+		eniAttachments = append(eniAttachments, &eniAttachment)
+		return nil
+	})
+	return eniAttachments, err
+}
+
+// SaveMetadata saves a metadata value to the metadata table.
+func (c *client) SaveMetadata(key, val string) error {
+	return c.put(metadataTableName, key, val)
+}
+
+// GetMetadata returns a metadata value from the metadata table.
+func (c *client) GetMetadata(key string) (string, error) {
+	var val string
+	err := c.get(metadataTableName, key, &val)
+	return val, err
+}
+
+// SaveTaskResourceUsage saves the resource usage of a task to the task_resource_usage table.
+func (c *client) SaveTaskResourceUsage(usage *apitask.ResourceUsage) error {
+	id, err := utils.GetTaskID(usage.TaskARN)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate database id")
+	}
+	return c.put(taskResourceUsageTableName, id, usage)
+}
+
+// DeleteTaskResourceUsage deletes the resource usage of a task from the task_resource_usage table.
+func (c *client) DeleteTaskResourceUsage(id string) error {
+	return c.delete(taskResourceUsageTableName, id)
+}
+
+// GetTaskResourceUsages returns the resource usage of all the tasks in the task_resource_usage table.
+func (c *client) GetTaskResourceUsages() ([]*apitask.ResourceUsage, error) {
+	var usages []*apitask.ResourceUsage
+	err := c.walk(taskResourceUsageTableName, func(id string, objData []byte) error {
+		usage := apitask.ResourceUsage{}
+		if err := json.Unmarshal(objData, &usage); err != nil {
+			return err
+		}
+		usages = append(usages, &usage)
+		return nil
+	})
+	return usages, err
+}
@@ -0,0 +1,187 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sqlite
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/data"
+	"github.com/aws/amazon-ecs-agent/agent/engine/image"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/api/attachmentinfo"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
+	ni "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testTaskArn       = "arn:aws:ecs:us-west-2:1234567890:task/test-cluster/abc"
+	testAttachmentArn = "arn:aws:ecs:us-west-2:167933679560:attachment/test-arn"
+)
+
+func newTestClient(t *testing.T) data.Client {
+	testClient, err := Open(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, testClient.Close())
+	})
+	return testClient
+}
+
+func TestDriverRegistered(t *testing.T) {
+	assert.Contains(t, data.Drivers(), DriverName)
+}
+
+// TestWriteWhileRead verifies that the agent can write to the database while another process
+// holds a read transaction on it.
+func TestWriteWhileRead(t *testing.T) {
+	dataDir := t.TempDir()
+	testClient, err := Open(dataDir)
+	require.NoError(t, err)
+	defer testClient.Close()
+	require.NoError(t, testClient.SaveTask(&apitask.Task{Arn: testTaskArn}))
+
+	reader, err := sql.Open(sqlDriverName, filepath.Join(dataDir, dbName))
+	require.NoError(t, err)
+	defer reader.Close()
+	tx, err := reader.Begin()
+	require.NoError(t, err)
+	var count int
+	require.NoError(t, tx.QueryRow("SELECT COUNT(*) FROM "+tasksTableName).Scan(&count))
+	assert.Equal(t, 1, count)
+
+	require.NoError(t, testClient.SaveTask(&apitask.Task{Arn: testTaskArn + "2"}))
+	require.NoError(t, tx.Commit())
+
+	tasks, err := testClient.GetTasks()
+	require.NoError(t, err)
+	assert.Len(t, tasks, 2)
+}
+
+func TestManageTasks(t *testing.T) {
+	testClient := newTestClient(t)
+
+	require.NoError(t, testClient.SaveTask(&apitask.Task{Arn: testTaskArn}))
+	res, err := testClient.GetTasks()
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.Equal(t, testTaskArn, res[0].Arn)
+
+	require.NoError(t, testClient.DeleteTask("abc"))
+	res, err = testClient.GetTasks()
+	require.NoError(t, err)
+	assert.Len(t, res, 0)
+
+	assert.Error(t, testClient.SaveTask(&apitask.Task{Arn: "invalid-arn"}))
+}
+
+func TestManageContainers(t *testing.T) {
+	testClient := newTestClient(t)
+
+	testDockerContainer := &apicontainer.DockerContainer{
+		DockerID: "test-docker-id",
+		Container: &apicontainer.Container{
+			Name:          "test-name",
+			TaskARNUnsafe: testTaskArn,
+		},
+	}
+	require.NoError(t, testClient.SaveDockerContainer(testDockerContainer))
+	testDockerContainer.Container.SetKnownStatus(apicontainerstatus.ContainerRunning)
+	require.NoError(t, testClient.SaveContainer(testDockerContainer.Container))
+	require.NoError(t, testClient.SaveContainer(&apicontainer.Container{
+		Name:          "test-name-2",
+		TaskARNUnsafe: testTaskArn,
+	}))
+
+	res, err := testClient.GetContainers()
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	assert.Equal(t, apicontainerstatus.ContainerRunning, res[0].Container.GetKnownStatus())
+	assert.Equal(t, "test-docker-id", res[0].DockerID)
+
+	require.NoError(t, testClient.DeleteContainer("abc-test-name"))
+	require.NoError(t, testClient.DeleteContainer("abc-test-name-2"))
+	res, err = testClient.GetContainers()
+	require.NoError(t, err)
+	assert.Len(t, res, 0)
+}
+
+func TestManageImageStates(t *testing.T) {
+	testClient := newTestClient(t)
+
+	require.NoError(t, testClient.SaveImageState(&image.ImageState{
+		Image: &image.Image{ImageID: "sha256:a"},
+	}))
+	res, err := testClient.GetImageStates()
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.Equal(t, "sha256:a", res[0].GetImageID())
+
+	require.NoError(t, testClient.DeleteImageState("sha256:a"))
+	res, err = testClient.GetImageStates()
+	require.NoError(t, err)
+	assert.Len(t, res, 0)
+}
+
+func TestManageENIAttachments(t *testing.T) {
+	testClient := newTestClient(t)
+
+	require.NoError(t, testClient.SaveENIAttachment(&ni.ENIAttachment{
+		AttachmentInfo: attachmentinfo.AttachmentInfo{AttachmentARN: testAttachmentArn},
+	}))
+	res, err := testClient.GetENIAttachments()
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.Equal(t, testAttachmentArn, res[0].AttachmentARN)
+
+	require.NoError(t, testClient.DeleteENIAttachment("test-arn"))
+	res, err = testClient.GetENIAttachments()
+	require.NoError(t, err)
+	assert.Len(t, res, 0)
+}
+
+func TestManageTaskResourceUsage(t *testing.T) {
+	testClient := newTestClient(t)
+
+	require.NoError(t, testClient.SaveTaskResourceUsage(apitask.NewResourceUsage(testTaskArn, "family", "1")))
+	res, err := testClient.GetTaskResourceUsages()
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.Equal(t, testTaskArn, res[0].TaskARN)
+
+	require.NoError(t, testClient.DeleteTaskResourceUsage("abc"))
+	res, err = testClient.GetTaskResourceUsages()
+	require.NoError(t, err)
+	assert.Len(t, res, 0)
+}
+
+func TestManageMetadata(t *testing.T) {
+	testClient := newTestClient(t)
+
+	_, err := testClient.GetMetadata(data.ClusterNameKey)
+	assert.Error(t, err)
+
+	require.NoError(t, testClient.SaveMetadata(data.ClusterNameKey, "test-cluster"))
+	val, err := testClient.GetMetadata(data.ClusterNameKey)
+	require.NoError(t, err)
+	assert.Equal(t, "test-cluster", val)
+}
@@ -0,0 +1,66 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package data
+
+import (
+	"encoding/json"
+
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/utils"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+// SaveTaskResourceUsage saves the resource usage of a task to the task resource usage bucket. The
+// usage is kept under the id of the task, but outlives the task in the task bucket.
+func (c *client) SaveTaskResourceUsage(usage *apitask.ResourceUsage) error {
+	id, err := utils.GetTaskID(usage.TaskARN)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate database id")
+	}
+	return c.DB.Batch(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(taskResourceUsageBucketName))
+		return c.Accessor.PutObject(b, id, usage)
+	})
+}
+
+// DeleteTaskResourceUsage deletes the resource usage of a task from the task resource usage bucket.
+func (c *client) DeleteTaskResourceUsage(id string) error {
+	return c.DB.Batch(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(taskResourceUsageBucketName))
+		return b.Delete([]byte(id))
+	})
+}
+
+// GetTaskResourceUsages returns the resource usage of all the tasks in the task resource usage bucket.
+func (c *client) GetTaskResourceUsages() ([]*apitask.ResourceUsage, error) {
+	var usages []*apitask.ResourceUsage
+	err := c.DB.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(taskResourceUsageBucketName))
+		if bucket == nil {
+			// the data file was written by an agent that did not account the resource usage
+			return nil
+		}
+		return c.Accessor.Walk(bucket, func(id string, data []byte) error {
+			usage := apitask.ResourceUsage{}
+			if err := json.Unmarshal(data, &usage); err != nil {
+				return err
+			}
+			usages = append(usages, &usage)
+			return nil
+		})
+	})
+	return usages, err
+}
@@ -0,0 +1,53 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package data
+
+import (
+	"testing"
+	"time"
+
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManageTaskResourceUsage(t *testing.T) {
+	testClient := newTestClient(t)
+
+	usage := apitask.NewResourceUsage(testTaskArn, "family", "1")
+	usage.Accumulate("container", apitask.ResourceUsageSample{Timestamp: time.Now(), CPUUsageNanoseconds: 1e9})
+	require.NoError(t, testClient.SaveTaskResourceUsage(usage))
+
+	// the usage outlives the task
+	require.NoError(t, testClient.SaveTask(&apitask.Task{Arn: testTaskArn}))
+	require.NoError(t, testClient.DeleteTask("abc"))
+
+	res, err := testClient.GetTaskResourceUsages()
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.Equal(t, testTaskArn, res[0].TaskARN)
+	assert.Equal(t, 1.0, res[0].CPUSeconds)
+	assert.Contains(t, res[0].LastSamples, "container")
+
+	require.NoError(t, testClient.DeleteTaskResourceUsage("abc"))
+	res, err = testClient.GetTaskResourceUsages()
+	require.NoError(t, err)
+	assert.Len(t, res, 0)
+
+	assert.Error(t, testClient.SaveTaskResourceUsage(apitask.NewResourceUsage("invalid-arn", "family", "1")))
+}
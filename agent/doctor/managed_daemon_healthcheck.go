@@ -0,0 +1,55 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+package doctor
+
+import (
+	"github.com/aws/amazon-ecs-agent/agent/doctor/statustracker"
+	dm "github.com/aws/amazon-ecs-agent/agent/engine/daemonmanager"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/doctor"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+)
+
+// Health check for the managed daemons restarted by the agent.
+type managedDaemonHealthcheck struct {
+	reporter dm.RestartStatusReporter
+	*statustracker.HealthCheckStatusTracker
+}
+
+// Constructor for Managed Daemon Health Check
+func NewManagedDaemonHealthcheck(reporter dm.RestartStatusReporter) doctor.Healthcheck {
+	return &managedDaemonHealthcheck{
+		reporter:                 reporter,
+		HealthCheckStatusTracker: statustracker.NewHealthCheckStatusTracker(),
+	}
+}
+
+// Performs a health check of the managed daemons, which are impaired when any of them is crash
+// looping.
+func (m *managedDaemonHealthcheck) RunCheck() doctor.HealthcheckStatus {
+	for _, status := range m.reporter.GetRestartStatuses() {
+		if status.CrashLooping {
+			logger.Error("Managed daemon is crash looping", logger.Fields{
+				"daemonName":   status.DaemonName,
+				"restartCount": status.RestartCount,
+			})
+			m.SetHealthcheckStatus(doctor.HealthcheckStatusImpaired)
+			return m.GetHealthcheckStatus()
+		}
+	}
+	m.SetHealthcheckStatus(doctor.HealthcheckStatusOk)
+	return m.GetHealthcheckStatus()
+}
+
+func (m *managedDaemonHealthcheck) GetHealthcheckType() string {
+	return doctor.HealthcheckTypeManagedDaemon
+}
@@ -0,0 +1,55 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+package doctor
+
+import (
+	"testing"
+
+	dm "github.com/aws/amazon-ecs-agent/agent/engine/daemonmanager"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/doctor"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeRestartStatusReporter []dm.RestartStatus
+
+func (r fakeRestartStatusReporter) GetRestartStatuses() []dm.RestartStatus { return r }
+
+func TestManagedDaemonGetHealthcheckType(t *testing.T) {
+	hc := NewManagedDaemonHealthcheck(fakeRestartStatusReporter{})
+	assert.Equal(t, doctor.HealthcheckTypeManagedDaemon, hc.GetHealthcheckType())
+}
+
+func TestManagedDaemonRunCheck(t *testing.T) {
+	tcs := []struct {
+		name     string
+		statuses []dm.RestartStatus
+		expected doctor.HealthcheckStatus
+	}{
+		{"no daemons", nil, doctor.HealthcheckStatusOk},
+		{"restarted daemon", []dm.RestartStatus{{DaemonName: "a", RestartCount: 2}}, doctor.HealthcheckStatusOk},
+		{"crash looping daemon", []dm.RestartStatus{
+			{DaemonName: "a"},
+			{DaemonName: "b", RestartCount: 5, CrashLooping: true},
+		}, doctor.HealthcheckStatusImpaired},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			hc := NewManagedDaemonHealthcheck(fakeRestartStatusReporter(tc.statuses))
+			assert.Equal(t, tc.expected, hc.RunCheck())
+			assert.Equal(t, tc.expected, hc.GetHealthcheckStatus())
+		})
+	}
+}
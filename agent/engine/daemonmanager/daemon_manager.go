@@ -15,6 +15,7 @@ package daemonmanager
 
 import (
 	"context"
+	"fmt"
 
 	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
@@ -41,3 +42,9 @@ func NewDaemonManager(manageddaemon *md.ManagedDaemon) DaemonManager {
 func (dm *daemonManager) GetManagedDaemon() *md.ManagedDaemon {
 	return dm.managedDaemon
 }
+
+// daemonContainerName returns the name of the container of a daemon task. The name must be unique
+// among running containers, there is a single managed daemon of each type per instance.
+func daemonContainerName(daemonName string) string {
+	return fmt.Sprintf("ecs-managed-%s", daemonName)
+}
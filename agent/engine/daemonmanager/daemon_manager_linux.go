@@ -68,22 +68,22 @@ func (dm *daemonManager) CreateDaemonTask() (*apitask.Task, error) {
 	loadedImageRef := dm.managedDaemon.GetLoadedDaemonImageRef()
 	containerRunning := apicontainerstatus.ContainerRunning
 	stringCaps := []string{}
-	if dm.managedDaemon.GetLinuxParameters() != nil {
-		caps := dm.managedDaemon.GetLinuxParameters().Capabilities.Add
-		for _, cap := range caps {
-			stringCaps = append(stringCaps, *cap)
-		}
+	dropCaps := []string{}
+	if linuxParameters := dm.managedDaemon.GetLinuxParameters(); linuxParameters != nil && linuxParameters.Capabilities != nil {
+		stringCaps = append(stringCaps, aws.StringValueSlice(linuxParameters.Capabilities.Add)...)
+		dropCaps = append(dropCaps, aws.StringValueSlice(linuxParameters.Capabilities.Drop)...)
 	}
 	dockerHostConfig := dockercontainer.HostConfig{
 		Mounts:      []dockermount.Mount{},
 		NetworkMode: apitask.HostNetworkMode,
-		// the default value of 0 for MaximumRetryCount means retry indefinitely
+		// the daemon task is restarted by the Supervisor with crash loop backoff, docker doesn't
+		// restart the daemon container
 		RestartPolicy: dockercontainer.RestartPolicy{
-			Name:              "on-failure",
-			MaximumRetryCount: 0,
+			Name: "no",
 		},
 		Privileged: dm.managedDaemon.GetPrivileged(),
 		CapAdd:     stringCaps,
+		CapDrop:    dropCaps,
 	}
 	if !dm.managedDaemon.IsValidManagedDaemon() {
 		return nil, fmt.Errorf("%s is an invalid managed daemon", imageName)
@@ -107,6 +107,7 @@ func (dm *daemonManager) CreateDaemonTask() (*apitask.Task, error) {
 			Type:        dockermount.TypeBind,
 			Source:      mp.SourceVolumeHostPath,
 			Target:      mp.ContainerPath,
+			ReadOnly:    mp.ReadOnly,
 			BindOptions: &bindOptions,
 		}
 		dockerHostConfig.Mounts = append(dockerHostConfig.Mounts, mountPoint)
@@ -131,9 +132,7 @@ func (dm *daemonManager) CreateDaemonTask() (*apitask.Task, error) {
 		Arn:                 fmt.Sprintf("arn:::::/%s-%s", dm.managedDaemon.GetImageName(), uuid.NewUUID()),
 		DesiredStatusUnsafe: apitaskstatus.TaskRunning,
 		Containers: []*apicontainer.Container{{
-			// name must be unique among running containers
-			// We should only have a single managed daemon of each type per instance
-			Name:                      fmt.Sprintf("ecs-managed-%s", dm.managedDaemon.GetImageName()),
+			Name:                      daemonContainerName(dm.managedDaemon.GetImageName()),
 			Image:                     loadedImageRef,
 			ContainerArn:              fmt.Sprintf("arn:::::/instance-%s", imageName),
 			Type:                      apicontainer.ContainerManagedDaemon,
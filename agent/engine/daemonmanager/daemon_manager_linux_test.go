@@ -25,10 +25,13 @@ import (
 	"time"
 
 	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
 	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
 	md "github.com/aws/amazon-ecs-agent/ecs-agent/manageddaemon"
 	"github.com/aws/aws-sdk-go/aws"
+	dockercontainer "github.com/docker/docker/api/types/container"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -279,3 +282,31 @@ func containsString(arr []interface{}, target string) bool {
 	}
 	return false
 }
+
+func TestCreateDaemonTaskCapabilitiesAndReadOnlyMounts(t *testing.T) {
+	origMkdir := mkdirAllAndChown
+	defer func() { mkdirAllAndChown = origMkdir }()
+	mkdirAllAndChown = func(path string, perm fs.FileMode, uid, gid int) error {
+		return nil
+	}
+	tmd := md.NewManagedDaemon(TestDaemonName, TestImageTag)
+	tmd.SetMountPoints([]*md.MountPoint{
+		{SourceVolumeID: "agentCommunicationMount", ContainerPath: "/container/run/"},
+		{SourceVolumeID: "applicationLogMount", ContainerPath: "/container/log/"},
+		{SourceVolumeID: TestOtherVolumeID, ContainerPath: "/host", SourceVolumeHostPath: "/", ReadOnly: true},
+	})
+	tmd.SetLinuxParameters(&ecsacs.LinuxParameters{Capabilities: &ecsacs.KernelCapabilities{
+		Add:  aws.StringSlice([]string{"SYS_PTRACE"}),
+		Drop: aws.StringSlice([]string{"NET_RAW"}),
+	}})
+
+	task, err := NewDaemonManager(tmd).CreateDaemonTask()
+	require.NoError(t, err)
+	var hostConfig dockercontainer.HostConfig
+	require.NoError(t, json.Unmarshal([]byte(aws.StringValue(task.Containers[0].DockerConfig.HostConfig)), &hostConfig))
+	assert.Equal(t, []string{"SYS_PTRACE"}, []string(hostConfig.CapAdd))
+	assert.Equal(t, []string{"NET_RAW"}, []string(hostConfig.CapDrop))
+	for _, mount := range hostConfig.Mounts {
+		assert.Equal(t, mount.Target == "/host", mount.ReadOnly)
+	}
+}
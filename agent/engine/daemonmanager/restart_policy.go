@@ -0,0 +1,153 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package daemonmanager
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/retry"
+)
+
+const (
+	restartBackoffMin      = 5 * time.Second
+	restartBackoffMax      = 5 * time.Minute
+	restartBackoffJitter   = 0.2
+	restartBackoffMultiple = 2
+	// a daemon that stays up this long is considered recovered, its backoff starts over
+	restartBackoffResetAfter = 10 * time.Minute
+	// at most maxRestartsInWindow restarts are done in restartWindow, a daemon that reaches the cap
+	// is crash looping
+	maxRestartsInWindow = 5
+	restartWindow       = 10 * time.Minute
+)
+
+// RestartStatus is the restart history of a managed daemon.
+type RestartStatus struct {
+	DaemonName   string
+	RestartCount int
+	// LastExitCode is the exit code of the daemon container the last time the daemon stopped.
+	LastExitCode  *int       `json:",omitempty"`
+	LastExitedAt  *time.Time `json:",omitempty"`
+	NextRestartAt *time.Time `json:",omitempty"`
+	// CrashLooping is true when the daemon reached the restart rate cap, until it stays up for
+	// restartBackoffResetAfter.
+	CrashLooping bool
+}
+
+// RestartStatusReporter reports the restart status of the managed daemons.
+type RestartStatusReporter interface {
+	GetRestartStatuses() []RestartStatus
+}
+
+// restartPolicy decides when a stopped daemon is restarted: with an exponential backoff between
+// consecutive failures, and no more than maxRestartsInWindow times in restartWindow.
+type restartPolicy struct {
+	lock          sync.Mutex
+	backoff       retry.Backoff
+	status        RestartStatus
+	startedAt     time.Time
+	nextRestartAt time.Time
+	// restarts are the times of the restarts in the last restartWindow
+	restarts []time.Time
+}
+
+func newRestartPolicy(daemonName string) *restartPolicy {
+	return &restartPolicy{
+		backoff: retry.NewExponentialBackoff(restartBackoffMin, restartBackoffMax,
+			restartBackoffJitter, restartBackoffMultiple),
+		status: RestartStatus{DaemonName: daemonName},
+	}
+}
+
+// started records that a daemon task was started.
+func (p *restartPolicy) started(now time.Time) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.startedAt = now
+	p.nextRestartAt = time.Time{}
+	p.status.NextRestartAt = nil
+}
+
+// running records that the daemon task is still running, which resets the backoff once the daemon
+// has been up long enough.
+func (p *restartPolicy) running(now time.Time) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if !p.startedAt.IsZero() && now.Sub(p.startedAt) >= restartBackoffResetAfter {
+		p.backoff.Reset()
+		p.status.CrashLooping = false
+	}
+}
+
+// stopped records that the daemon task stopped and schedules its restart. It returns true the first
+// time it's called for the daemon task.
+func (p *restartPolicy) stopped(exitCode *int, now time.Time) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if !p.nextRestartAt.IsZero() {
+		return false
+	}
+	if exitCode != nil {
+		code := *exitCode
+		p.status.LastExitCode = &code
+	}
+	p.status.LastExitedAt = &now
+
+	if !p.startedAt.IsZero() && now.Sub(p.startedAt) >= restartBackoffResetAfter {
+		p.backoff.Reset()
+		p.status.CrashLooping = false
+	}
+	p.nextRestartAt = now.Add(p.backoff.Duration())
+
+	// cap the restart rate
+	var recent []time.Time
+	for _, restart := range p.restarts {
+		if now.Sub(restart) < restartWindow {
+			recent = append(recent, restart)
+		}
+	}
+	p.restarts = recent
+	if len(p.restarts) >= maxRestartsInWindow {
+		p.status.CrashLooping = true
+		if windowEnd := p.restarts[len(p.restarts)-maxRestartsInWindow].Add(restartWindow); windowEnd.After(p.nextRestartAt) {
+			p.nextRestartAt = windowEnd
+		}
+	}
+	nextRestartAt := p.nextRestartAt
+	p.status.NextRestartAt = &nextRestartAt
+	return true
+}
+
+// shouldRestart returns true if the restart of the stopped daemon is due.
+func (p *restartPolicy) shouldRestart(now time.Time) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return !p.nextRestartAt.IsZero() && !now.Before(p.nextRestartAt)
+}
+
+// restarted records that the stopped daemon was started again.
+func (p *restartPolicy) restarted(now time.Time) {
+	p.lock.Lock()
+	p.status.RestartCount++
+	p.restarts = append(p.restarts, now)
+	p.lock.Unlock()
+	p.started(now)
+}
+
+func (p *restartPolicy) getStatus() RestartStatus {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.status
+}
@@ -0,0 +1,92 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package daemonmanager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// crash stops the daemon and restarts it as soon as the policy allows, it returns the restart time.
+func crash(t *testing.T, policy *restartPolicy, now time.Time) time.Time {
+	require.True(t, policy.stopped(nil, now))
+	assert.False(t, policy.stopped(nil, now), "the stop of a daemon task is only recorded once")
+	restartAt := *policy.getStatus().NextRestartAt
+	assert.False(t, policy.shouldRestart(restartAt.Add(-time.Millisecond)))
+	require.True(t, policy.shouldRestart(restartAt))
+	policy.restarted(restartAt)
+	return restartAt
+}
+
+func TestRestartPolicyBackoffGrows(t *testing.T) {
+	policy := newRestartPolicy("daemon")
+	now := time.Now()
+	policy.started(now)
+
+	var previousDelay time.Duration
+	for i := 0; i < 3; i++ {
+		restartAt := crash(t, policy, now)
+		delay := restartAt.Sub(now)
+		assert.Greater(t, delay, previousDelay)
+		previousDelay, now = delay, restartAt
+	}
+	assert.Equal(t, 3, policy.getStatus().RestartCount)
+	assert.False(t, policy.getStatus().CrashLooping)
+}
+
+func TestRestartPolicyCrashLoop(t *testing.T) {
+	policy := newRestartPolicy("daemon")
+	now := time.Now()
+	policy.started(now)
+	for i := 0; i < maxRestartsInWindow; i++ {
+		now = crash(t, policy, now)
+	}
+	assert.False(t, policy.getStatus().CrashLooping)
+
+	exitCode := 137
+	require.True(t, policy.stopped(&exitCode, now))
+	status := policy.getStatus()
+	assert.True(t, status.CrashLooping)
+	assert.Equal(t, 137, *status.LastExitCode)
+	assert.Equal(t, now, *status.LastExitedAt)
+	assert.False(t, status.NextRestartAt.Before(policy.restarts[0].Add(restartWindow)),
+		"the restart is delayed until the restart rate drops below the cap")
+}
+
+func TestRestartPolicyResetsAfterLongRun(t *testing.T) {
+	policy := newRestartPolicy("daemon")
+	now := time.Now()
+	policy.started(now)
+	for i := 0; i < maxRestartsInWindow; i++ {
+		now = crash(t, policy, now)
+	}
+	require.True(t, policy.stopped(nil, now))
+	require.True(t, policy.getStatus().CrashLooping)
+	now = *policy.getStatus().NextRestartAt
+	policy.restarted(now)
+
+	policy.running(now.Add(restartBackoffResetAfter))
+	assert.False(t, policy.getStatus().CrashLooping)
+
+	now = now.Add(restartBackoffResetAfter)
+	require.True(t, policy.stopped(nil, now))
+	assert.LessOrEqual(t, policy.getStatus().NextRestartAt.Sub(now),
+		time.Duration(float64(restartBackoffMin)*(1+restartBackoffJitter)))
+}
@@ -0,0 +1,210 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package daemonmanager
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+	md "github.com/aws/amazon-ecs-agent/ecs-agent/manageddaemon"
+)
+
+// supervisionInterval is how often the daemon tasks are checked and restarted if they stopped.
+var supervisionInterval = 5 * time.Second
+
+// TaskEngine is the subset of the task engine the daemon tasks are run with.
+type TaskEngine interface {
+	AddTask(*apitask.Task)
+	ListTasks() ([]*apitask.Task, error)
+	GetDaemonManagers() map[string]DaemonManager
+	GetDaemonTask(string) *apitask.Task
+	SetDaemonTask(string, *apitask.Task)
+}
+
+// Supervisor runs a daemon task for each daemon manager of the task engine, and restarts the daemon
+// tasks that stop according to a restart policy with crash loop backoff. The EBS CSI driver is only
+// started on the first EBS volume attachment, it's supervised once started.
+type Supervisor struct {
+	taskEngine TaskEngine
+	lock       sync.Mutex
+	policies   map[string]*restartPolicy
+	now        func() time.Time
+}
+
+// NewSupervisor creates a Supervisor of the daemon tasks of the task engine.
+func NewSupervisor(taskEngine TaskEngine) *Supervisor {
+	return &Supervisor{
+		taskEngine: taskEngine,
+		policies:   make(map[string]*restartPolicy),
+		now:        time.Now,
+	}
+}
+
+// Run supervises the daemon tasks until ctx is done.
+func (s *Supervisor) Run(ctx context.Context) {
+	s.supervise()
+	ticker := time.NewTicker(supervisionInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.supervise()
+		}
+	}
+}
+
+// GetRestartStatuses returns the restart status of every managed daemon, sorted by daemon name.
+func (s *Supervisor) GetRestartStatuses() []RestartStatus {
+	names := s.daemonNames()
+	statuses := make([]RestartStatus, 0, len(names))
+	for _, name := range names {
+		statuses = append(statuses, s.policy(name).getStatus())
+	}
+	return statuses
+}
+
+func (s *Supervisor) daemonNames() []string {
+	managers := s.taskEngine.GetDaemonManagers()
+	names := make([]string, 0, len(managers))
+	for name := range managers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *Supervisor) policy(name string) *restartPolicy {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	policy, ok := s.policies[name]
+	if !ok {
+		policy = newRestartPolicy(name)
+		s.policies[name] = policy
+	}
+	return policy
+}
+
+func (s *Supervisor) supervise() {
+	managers := s.taskEngine.GetDaemonManagers()
+	for _, name := range s.daemonNames() {
+		policy := s.policy(name)
+		now := s.now()
+		task := s.taskEngine.GetDaemonTask(name)
+		if task == nil {
+			// adopt the daemon task that was running before the agent restarted
+			if task = restoredDaemonTask(s.taskEngine, name); task != nil {
+				logger.Info("Found running managed daemon task", logger.Fields{
+					field.Image:   name,
+					field.TaskARN: task.Arn,
+				})
+				s.taskEngine.SetDaemonTask(name, task)
+				policy.started(now)
+				continue
+			}
+			if name == md.EbsCsiDriver {
+				continue
+			}
+			if s.startDaemonTask(name, managers[name]) {
+				policy.started(now)
+			}
+			continue
+		}
+
+		if !task.GetKnownStatus().Terminal() {
+			policy.running(now)
+			continue
+		}
+		if policy.stopped(daemonExitCode(task), now) {
+			status := policy.getStatus()
+			logFields := logger.Fields{
+				field.Image:             name,
+				field.TaskARN:           task.Arn,
+				field.ContainerExitCode: status.LastExitCode,
+				"restartCount":          status.RestartCount,
+				"nextRestartAt":         status.NextRestartAt,
+			}
+			if status.CrashLooping {
+				logger.Error("Managed daemon is crash looping, restarting it with backoff", logFields)
+			} else {
+				logger.Warn("Managed daemon stopped, restarting it with backoff", logFields)
+			}
+		}
+		if policy.shouldRestart(now) && s.startDaemonTask(name, managers[name]) {
+			policy.restarted(now)
+		}
+	}
+}
+
+func (s *Supervisor) startDaemonTask(name string, manager DaemonManager) bool {
+	task, err := manager.CreateDaemonTask()
+	if err != nil {
+		logger.Error("Unable to create managed daemon task", logger.Fields{
+			field.Image: name,
+			field.Error: err,
+		})
+		return false
+	}
+	s.taskEngine.SetDaemonTask(name, task)
+	s.taskEngine.AddTask(task)
+	logger.Info("Started managed daemon task", logger.Fields{
+		field.Image:   name,
+		field.TaskARN: task.Arn,
+	})
+	return true
+}
+
+// restoredDaemonTask returns the daemon task of the named daemon that the task engine manages and
+// that is not stopped, if any.
+func restoredDaemonTask(taskEngine TaskEngine, name string) *apitask.Task {
+	tasks, err := taskEngine.ListTasks()
+	if err != nil {
+		return nil
+	}
+	for _, task := range tasks {
+		if !task.IsInternal || task.GetKnownStatus().Terminal() || task.GetDesiredStatus().Terminal() {
+			continue
+		}
+		if daemonContainer(task, name) != nil {
+			return task
+		}
+	}
+	return nil
+}
+
+func daemonContainer(task *apitask.Task, name string) *apicontainer.Container {
+	for _, container := range task.Containers {
+		if container.Type == apicontainer.ContainerManagedDaemon && container.Name == daemonContainerName(name) {
+			return container
+		}
+	}
+	return nil
+}
+
+// daemonExitCode returns the exit code of the container of a daemon task.
+func daemonExitCode(task *apitask.Task) *int {
+	for _, container := range task.Containers {
+		if container.Type == apicontainer.ContainerManagedDaemon {
+			return container.GetKnownExitCode()
+		}
+	}
+	return nil
+}
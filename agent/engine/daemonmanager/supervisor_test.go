@@ -0,0 +1,167 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package daemonmanager
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	mock_daemonmanager "github.com/aws/amazon-ecs-agent/agent/engine/daemonmanager/mock"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
+	md "github.com/aws/amazon-ecs-agent/ecs-agent/manageddaemon"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTaskEngine struct {
+	managers    map[string]DaemonManager
+	daemonTasks map[string]*apitask.Task
+	tasks       []*apitask.Task
+}
+
+func newFakeTaskEngine(managers map[string]DaemonManager) *fakeTaskEngine {
+	return &fakeTaskEngine{managers: managers, daemonTasks: make(map[string]*apitask.Task)}
+}
+
+func (e *fakeTaskEngine) AddTask(task *apitask.Task) { e.tasks = append(e.tasks, task) }
+
+func (e *fakeTaskEngine) ListTasks() ([]*apitask.Task, error) { return e.tasks, nil }
+
+func (e *fakeTaskEngine) GetDaemonManagers() map[string]DaemonManager { return e.managers }
+
+func (e *fakeTaskEngine) GetDaemonTask(name string) *apitask.Task { return e.daemonTasks[name] }
+
+func (e *fakeTaskEngine) SetDaemonTask(name string, task *apitask.Task) { e.daemonTasks[name] = task }
+
+func newTestDaemonTask(name string) *apitask.Task {
+	return &apitask.Task{
+		Arn:                 "arn:::::/" + name,
+		IsInternal:          true,
+		DesiredStatusUnsafe: apitaskstatus.TaskRunning,
+		Containers: []*apicontainer.Container{{
+			Name: daemonContainerName(name),
+			Type: apicontainer.ContainerManagedDaemon,
+		}},
+	}
+}
+
+func newTestSupervisor(taskEngine TaskEngine, now *time.Time) *Supervisor {
+	supervisor := NewSupervisor(taskEngine)
+	supervisor.now = func() time.Time { return *now }
+	return supervisor
+}
+
+func stopTestDaemonTask(task *apitask.Task, exitCode int) {
+	task.Containers[0].SetKnownExitCode(&exitCode)
+	task.SetKnownStatus(apitaskstatus.TaskStopped)
+}
+
+func TestSupervisorStartsDaemons(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	exporter := mock_daemonmanager.NewMockDaemonManager(ctrl)
+	ebs := mock_daemonmanager.NewMockDaemonManager(ctrl)
+	taskEngine := newFakeTaskEngine(map[string]DaemonManager{"node-exporter": exporter, md.EbsCsiDriver: ebs})
+	now := time.Now()
+	supervisor := newTestSupervisor(taskEngine, &now)
+
+	exporterTask := newTestDaemonTask("node-exporter")
+	exporter.EXPECT().CreateDaemonTask().Return(exporterTask, nil)
+	supervisor.supervise()
+
+	assert.Equal(t, exporterTask, taskEngine.GetDaemonTask("node-exporter"))
+	assert.Nil(t, taskEngine.GetDaemonTask(md.EbsCsiDriver), "the EBS CSI driver is started on demand")
+	assert.Len(t, taskEngine.tasks, 1)
+
+	// a running daemon task is left alone
+	supervisor.supervise()
+	assert.Len(t, taskEngine.tasks, 1)
+
+	statuses := supervisor.GetRestartStatuses()
+	require.Len(t, statuses, 2)
+	assert.Equal(t, md.EbsCsiDriver, statuses[0].DaemonName)
+	assert.Equal(t, "node-exporter", statuses[1].DaemonName)
+	assert.Zero(t, statuses[1].RestartCount)
+}
+
+func TestSupervisorRestartsStoppedDaemonsWithBackoff(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ebs := mock_daemonmanager.NewMockDaemonManager(ctrl)
+	taskEngine := newFakeTaskEngine(map[string]DaemonManager{md.EbsCsiDriver: ebs})
+	now := time.Now()
+	supervisor := newTestSupervisor(taskEngine, &now)
+	stoppedTask := newTestDaemonTask(md.EbsCsiDriver)
+	taskEngine.SetDaemonTask(md.EbsCsiDriver, stoppedTask)
+	supervisor.supervise()
+
+	stopTestDaemonTask(stoppedTask, 2)
+	supervisor.supervise()
+	assert.Equal(t, stoppedTask, taskEngine.GetDaemonTask(md.EbsCsiDriver), "the restart is delayed by the backoff")
+
+	status := supervisor.GetRestartStatuses()[0]
+	require.NotNil(t, status.LastExitCode)
+	assert.Equal(t, 2, *status.LastExitCode)
+	require.NotNil(t, status.NextRestartAt)
+
+	newTask := newTestDaemonTask(md.EbsCsiDriver)
+	ebs.EXPECT().CreateDaemonTask().Return(newTask, nil)
+	now = *status.NextRestartAt
+	supervisor.supervise()
+
+	assert.Equal(t, newTask, taskEngine.GetDaemonTask(md.EbsCsiDriver), "a started daemon is supervised")
+	status = supervisor.GetRestartStatuses()[0]
+	assert.Equal(t, 1, status.RestartCount)
+	assert.Nil(t, status.NextRestartAt)
+	assert.False(t, status.CrashLooping)
+}
+
+func TestSupervisorAdoptsRestoredTask(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	exporter := mock_daemonmanager.NewMockDaemonManager(ctrl)
+	taskEngine := newFakeTaskEngine(map[string]DaemonManager{"node-exporter": exporter})
+	restoredTask := newTestDaemonTask("node-exporter")
+	taskEngine.tasks = []*apitask.Task{restoredTask}
+
+	now := time.Now()
+	newTestSupervisor(taskEngine, &now).supervise()
+	assert.Equal(t, restoredTask, taskEngine.GetDaemonTask("node-exporter"))
+	assert.Len(t, taskEngine.tasks, 1)
+}
+
+func TestSupervisorCreateError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	exporter := mock_daemonmanager.NewMockDaemonManager(ctrl)
+	taskEngine := newFakeTaskEngine(map[string]DaemonManager{"node-exporter": exporter})
+	exporter.EXPECT().CreateDaemonTask().Return(nil, errors.New("error"))
+
+	now := time.Now()
+	newTestSupervisor(taskEngine, &now).supervise()
+	assert.Nil(t, taskEngine.GetDaemonTask("node-exporter"))
+	assert.Empty(t, taskEngine.tasks)
+}
@@ -21,6 +21,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/aws/amazon-ecs-agent/ecs-agent/eventstream"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
 
@@ -33,6 +34,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
 	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
 	"github.com/aws/amazon-ecs-agent/agent/engine/image"
+	"github.com/aws/amazon-ecs-agent/agent/metrics"
 )
 
 const (
@@ -47,7 +49,9 @@ type ImageManager interface {
 	AddAllImageStates(imageStates []*image.ImageState)
 	GetImageStateFromImageName(containerImageName string) (*image.ImageState, bool)
 	StartImageCleanupProcess(ctx context.Context)
+	StartImagePrewarmProcess(ctx context.Context)
 	SetDataClient(dataClient data.Client)
+	SetEventStream(eventStream *eventstream.EventStream)
 	AddImageToCleanUpExclusionList(image string)
 }
 
@@ -57,6 +61,7 @@ type dockerImageManager struct {
 	imageStates                        []*image.ImageState
 	client                             dockerapi.DockerClient
 	dataClient                         data.Client
+	eventStream                        *eventstream.EventStream
 	updateLock                         sync.RWMutex
 	imageCleanupTicker                 *time.Ticker
 	state                              dockerstate.TaskEngineState
@@ -70,6 +75,17 @@ type dockerImageManager struct {
 	nonECSContainerCleanupWaitDuration time.Duration
 	numNonECSContainersToDelete        int
 	nonECSMinimumAgeBeforeDeletion     time.Duration
+	imagePullTimeout                   time.Duration
+	imagePrewarmEnabled                config.BooleanDefaultFalse
+	imagePrewarmManifestPath           string
+	imagePrewarmConcurrency            int
+	prewarmImageNames                  map[string]struct{}
+	prewarmLock                        sync.RWMutex
+	diskHighWatermark                  int
+	diskLowWatermark                   int
+	diskCheckInterval                  time.Duration
+	diskPressureCleanupInProgress      int32
+	dockerRootDir                      string
 }
 
 // ImageStatesForDeletion is used for implementing the sort interface
@@ -89,6 +105,13 @@ func NewImageManager(cfg *config.Config, client dockerapi.DockerClient, state do
 		nonECSContainerCleanupWaitDuration: cfg.TaskCleanupWaitDuration,
 		numNonECSContainersToDelete:        cfg.NumNonECSContainersToDeletePerCycle,
 		nonECSMinimumAgeBeforeDeletion:     cfg.NonECSMinimumImageDeletionAge,
+		imagePullTimeout:                   cfg.ImagePullTimeout,
+		imagePrewarmEnabled:                cfg.ImagePrewarmEnabled,
+		imagePrewarmManifestPath:           imagePrewarmManifestPath(cfg.DataDir),
+		imagePrewarmConcurrency:            cfg.ImagePrewarmConcurrency,
+		diskHighWatermark:                  cfg.ImageCleanupDiskHighWatermark,
+		diskLowWatermark:                   cfg.ImageCleanupDiskLowWatermark,
+		diskCheckInterval:                  cfg.ImageCleanupDiskCheckInterval,
 	}
 }
 
@@ -97,6 +120,11 @@ func (imageManager *dockerImageManager) SetDataClient(dataClient data.Client) {
 	imageManager.dataClient = dataClient
 }
 
+// SetEventStream sets the event stream that image cleanup events are published to
+func (imageManager *dockerImageManager) SetEventStream(eventStream *eventstream.EventStream) {
+	imageManager.eventStream = eventStream
+}
+
 func buildImageCleanupExclusionList(cfg *config.Config) []string {
 	// append known cached internal images to imageCleanupExclusionList
 	excludedImages := append(cfg.ImageCleanupExclusionList,
@@ -356,10 +384,19 @@ func (imageManager *dockerImageManager) StartImageCleanupProcess(ctx context.Con
 
 func (imageManager *dockerImageManager) performPeriodicImageCleanup(ctx context.Context, imageCleanupInterval time.Duration) {
 	imageManager.imageCleanupTicker = time.NewTicker(imageCleanupInterval)
+	// diskCheckTickerC stays nil, and never fires, when watermark cleanup is disabled
+	var diskCheckTickerC <-chan time.Time
+	if imageManager.diskWatermarkCleanupEnabled() {
+		diskCheckTicker := time.NewTicker(imageManager.diskCheckInterval)
+		defer diskCheckTicker.Stop()
+		diskCheckTickerC = diskCheckTicker.C
+	}
 	for {
 		select {
 		case <-imageManager.imageCleanupTicker.C:
 			go imageManager.removeUnusedImages(ctx)
+		case <-diskCheckTickerC:
+			go imageManager.removeImagesUnderDiskPressure(ctx)
 		case <-ctx.Done():
 			imageManager.imageCleanupTicker.Stop()
 			return
@@ -487,36 +524,46 @@ func (imageManager *dockerImageManager) removeNonECSImages(ctx context.Context,
 		if !imageManager.nonECSImageOldEnough(image) {
 			continue
 		}
-		fields := logger.Fields{
-			field.ImageID:        image.ImageID,
-			field.ImageSizeBytes: image.Size,
-			"repoTags":           image.RepoTags,
-		}
-		if len(image.RepoTags) > 1 {
-			logger.Debug("Non-ECS image has more than one tag", fields)
-			for _, tag := range image.RepoTags {
-				err := imageManager.client.RemoveImage(ctx, tag, dockerclient.RemoveImageTimeout)
-				if err != nil {
-					logger.Error("Error removing non-ECS RepoTag", fields, logger.Fields{
-						field.Error: err,
-						"imageTag":  tag,
-					})
-				} else {
-					logger.Info("Non-ECS image tag removed", fields, logger.Fields{"imageTag": tag})
-					numImagesAlreadyDeleted++
-				}
-			}
-		} else {
-			logger.Debug("Removing non-ECS image", fields)
-			err := imageManager.client.RemoveImage(ctx, image.ImageID, dockerclient.RemoveImageTimeout)
+		numImagesAlreadyDeleted += imageManager.removeNonECSImage(ctx, image, metrics.ImageCleanupTriggerPeriodic)
+	}
+}
+
+// removeNonECSImage removes all tags of a non-ECS image and returns the number of tags or images removed
+func (imageManager *dockerImageManager) removeNonECSImage(ctx context.Context, image ImageWithSizeID, trigger string) int {
+	var numImagesDeleted = 0
+	fields := logger.Fields{
+		field.ImageID:        image.ImageID,
+		field.ImageSizeBytes: image.Size,
+		"repoTags":           image.RepoTags,
+	}
+	if len(image.RepoTags) > 1 {
+		logger.Debug("Non-ECS image has more than one tag", fields)
+		for _, tag := range image.RepoTags {
+			err := imageManager.client.RemoveImage(ctx, tag, dockerclient.RemoveImageTimeout)
 			if err != nil {
-				logger.Error("Error removing non-ECS image", fields, logger.Fields{field.Error: err})
+				logger.Error("Error removing non-ECS RepoTag", fields, logger.Fields{
+					field.Error: err,
+					"imageTag":  tag,
+				})
 			} else {
-				logger.Info("Non-ECS image removed", fields)
-				numImagesAlreadyDeleted++
+				logger.Info("Non-ECS image tag removed", fields, logger.Fields{"imageTag": tag})
+				numImagesDeleted++
 			}
 		}
+	} else {
+		logger.Debug("Removing non-ECS image", fields)
+		err := imageManager.client.RemoveImage(ctx, image.ImageID, dockerclient.RemoveImageTimeout)
+		if err != nil {
+			logger.Error("Error removing non-ECS image", fields, logger.Fields{field.Error: err})
+		} else {
+			logger.Info("Non-ECS image removed", fields)
+			numImagesDeleted++
+		}
+	}
+	if numImagesDeleted > 0 {
+		imageManager.recordImageEviction(trigger, metrics.ImageTypeNonECS, image.ImageID, image.RepoTags, image.Size)
 	}
+	return numImagesDeleted
 }
 
 // getNonECSImages returns type ImageWithSizeID with all fields populated.
@@ -624,6 +671,10 @@ func (imageManager *dockerImageManager) isExcludedFromCleanup(imageState *image.
 				return true
 			}
 		}
+		// images listed in the pre-warm manifest are kept until they fall out of the list
+		if imageManager.isPrewarmPinned(ecsName) {
+			return true
+		}
 	}
 	return false
 }
@@ -634,7 +685,11 @@ func (imageManager *dockerImageManager) removeLeastRecentlyUsedImage(ctx context
 		return fmt.Errorf("No more eligible images for deletion")
 	}
 	logger.Info("Image ready for deletion", leastRecentlyUsedImage.Fields())
-	imageManager.removeImage(ctx, leastRecentlyUsedImage)
+	imageNames := append([]string(nil), leastRecentlyUsedImage.Image.Names...)
+	if imageManager.removeImage(ctx, leastRecentlyUsedImage) {
+		imageManager.recordImageEviction(metrics.ImageCleanupTriggerPeriodic, metrics.ImageTypeECS,
+			leastRecentlyUsedImage.Image.ImageID, imageNames, leastRecentlyUsedImage.Image.Size)
+	}
 	return nil
 }
 
@@ -648,29 +703,57 @@ func (imageManager *dockerImageManager) getUnusedImageForDeletion() *image.Image
 	return imageManager.getLeastRecentlyUsedImage(candidateImageStatesForDeletion)
 }
 
-func (imageManager *dockerImageManager) removeImage(ctx context.Context, leastRecentlyUsedImage *image.ImageState) {
+// removeImage removes all names of the image and returns true if all of them were removed
+func (imageManager *dockerImageManager) removeImage(ctx context.Context, leastRecentlyUsedImage *image.ImageState) bool {
 	// Handling deleting while traversing a slice
 	imageNames := make([]string, len(leastRecentlyUsedImage.Image.Names))
 	copy(imageNames, leastRecentlyUsedImage.Image.Names)
 	if len(imageNames) == 0 {
 		// potentially untagged image of format <none>:<none>; remove by ID
-		imageManager.deleteImage(ctx, leastRecentlyUsedImage.Image.ImageID, leastRecentlyUsedImage)
-	} else {
-		// Image has multiple tags/repos. Untag each name and delete the final reference to image
-		for _, imageName := range imageNames {
-			imageManager.deleteImage(ctx, imageName, leastRecentlyUsedImage)
+		return imageManager.deleteImage(ctx, leastRecentlyUsedImage.Image.ImageID, leastRecentlyUsedImage)
+	}
+	// Image has multiple tags/repos. Untag each name and delete the final reference to image
+	removed := true
+	for _, imageName := range imageNames {
+		if !imageManager.deleteImage(ctx, imageName, leastRecentlyUsedImage) {
+			removed = false
 		}
 	}
+	return removed
 }
 
-func (imageManager *dockerImageManager) deleteImage(ctx context.Context, imageID string, imageState *image.ImageState) {
+// recordImageEviction records the eviction metric of a removed image and publishes an image cleanup event
+func (imageManager *dockerImageManager) recordImageEviction(trigger, imageType, imageID string, imageNames []string,
+	sizeBytes int64) {
+	metrics.MetricsEngineGlobal.RecordImageEvictionMetric(trigger, imageType, sizeBytes)
+	if imageManager.eventStream == nil {
+		return
+	}
+	event := image.CleanupEvent{
+		ImageID:    imageID,
+		ImageNames: imageNames,
+		SizeBytes:  sizeBytes,
+		Trigger:    trigger,
+		ImageType:  imageType,
+		RemovedAt:  time.Now(),
+	}
+	if err := imageManager.eventStream.WriteToEventStream(event); err != nil {
+		logger.Debug("Unable to publish image cleanup event", logger.Fields{
+			field.ImageID: imageID,
+			field.Error:   err,
+		})
+	}
+}
+
+// deleteImage removes an image name or ID and returns true if it is no longer on the instance
+func (imageManager *dockerImageManager) deleteImage(ctx context.Context, imageID string, imageState *image.ImageState) bool {
 	if imageID == "" {
 		var fields logger.Fields
 		if imageState != nil {
 			fields = imageState.Fields()
 		}
 		logger.Error("Image ID to be deleted is null", fields)
-		return
+		return false
 	}
 	logger.Debug(fmt.Sprintf("Removing Image: %s", imageID), imageState.Fields())
 	err := imageManager.client.RemoveImage(ctx, imageID, dockerclient.RemoveImageTimeout)
@@ -680,7 +763,7 @@ func (imageManager *dockerImageManager) deleteImage(ctx context.Context, imageID
 		} else {
 			logger.Error(fmt.Sprintf("Error removing Image %v", imageID), imageState.Fields(), logger.Fields{field.Error: err})
 			delete(imageManager.imageStatesConsideredForDeletion, imageState.Image.ImageID)
-			return
+			return false
 		}
 	}
 	logger.Info(fmt.Sprintf("Image removed: %v", imageID), imageState.Fields())
@@ -691,6 +774,7 @@ func (imageManager *dockerImageManager) deleteImage(ctx context.Context, imageID
 		imageManager.removeImageState(imageState)
 		imageManager.state.RemoveImageState(imageState)
 	}
+	return true
 }
 
 func (imageManager *dockerImageManager) GetImageStateFromImageName(containerImageName string) (*image.ImageState, bool) {
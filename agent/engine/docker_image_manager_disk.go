@@ -0,0 +1,147 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync/atomic"
+
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
+	"github.com/aws/amazon-ecs-agent/agent/metrics"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+)
+
+// getDiskUsagePercent returns the used percentage of the file system that contains a path.
+// It is a variable so that it can be overridden in tests.
+var getDiskUsagePercent = diskUsagePercent
+
+func (imageManager *dockerImageManager) diskWatermarkCleanupEnabled() bool {
+	return imageManager.diskHighWatermark > 0 && imageManager.diskLowWatermark > 0
+}
+
+// dockerDataRootDiskUsage returns the used percentage of the file system that holds the Docker data root
+func (imageManager *dockerImageManager) dockerDataRootDiskUsage(ctx context.Context) (float64, error) {
+	if imageManager.dockerRootDir == "" {
+		info, err := imageManager.client.Info(ctx, dockerclient.InfoTimeout)
+		if err != nil {
+			return 0, err
+		}
+		if info.DockerRootDir == "" {
+			return 0, errors.New("docker did not report its data root directory")
+		}
+		imageManager.dockerRootDir = info.DockerRootDir
+	}
+	usedPercent, err := getDiskUsagePercent(imageManager.dockerRootDir)
+	if err != nil {
+		return 0, err
+	}
+	metrics.MetricsEngineGlobal.RecordDiskUsageMetric(imageManager.dockerRootDir, usedPercent)
+	return usedPercent, nil
+}
+
+// removeImagesUnderDiskPressure evicts images when the disk usage of the Docker data root is above the high
+// watermark. Least recently used ECS images are evicted first, then non-ECS images if their cleanup is
+// enabled, until the disk usage is below the low watermark or no more images are eligible for deletion.
+func (imageManager *dockerImageManager) removeImagesUnderDiskPressure(ctx context.Context) {
+	// the check runs on its own ticker; skip it while a previous one is still evicting images
+	if !atomic.CompareAndSwapInt32(&imageManager.diskPressureCleanupInProgress, 0, 1) {
+		logger.Debug("Disk pressure image cleanup already in progress")
+		return
+	}
+	defer atomic.StoreInt32(&imageManager.diskPressureCleanupInProgress, 0)
+
+	usedPercent, err := imageManager.dockerDataRootDiskUsage(ctx)
+	if err != nil {
+		logger.Error("Error getting disk usage of the Docker data root", logger.Fields{field.Error: err})
+		return
+	}
+	if usedPercent < float64(imageManager.diskHighWatermark) {
+		return
+	}
+	fields := logger.Fields{
+		"dockerRootDir": imageManager.dockerRootDir,
+		"highWatermark": imageManager.diskHighWatermark,
+		"lowWatermark":  imageManager.diskLowWatermark,
+	}
+	logger.Warn("Disk usage is above the high watermark, starting image cleanup", fields,
+		logger.Fields{"diskUsagePercent": usedPercent})
+
+	logger.Debug("Attempting to obtain ImagePullDeleteLock for removing images under disk pressure")
+	ImagePullDeleteLock.Lock()
+	logger.Debug("Obtained ImagePullDeleteLock for removing images under disk pressure")
+	defer logger.Debug("Released ImagePullDeleteLock after removing images under disk pressure")
+	defer ImagePullDeleteLock.Unlock()
+
+	imageManager.updateLock.Lock()
+	defer imageManager.updateLock.Unlock()
+
+	lowWatermark := float64(imageManager.diskLowWatermark)
+	var numECSImagesDeleted, numNonECSImagesDeleted int
+	imageManager.imageStatesConsideredForDeletion = imageManager.imagesConsiderForDeletion(imageManager.getAllImageStates())
+	for usedPercent >= lowWatermark {
+		leastRecentlyUsedImage := imageManager.getUnusedImageForDeletion()
+		if leastRecentlyUsedImage == nil {
+			break
+		}
+		logger.Info("Image ready for deletion under disk pressure", leastRecentlyUsedImage.Fields())
+		imageNames := append([]string(nil), leastRecentlyUsedImage.Image.Names...)
+		if !imageManager.removeImage(ctx, leastRecentlyUsedImage) {
+			continue
+		}
+		imageManager.recordImageEviction(metrics.ImageCleanupTriggerDiskPressure, metrics.ImageTypeECS,
+			leastRecentlyUsedImage.Image.ImageID, imageNames, leastRecentlyUsedImage.Image.Size)
+		numECSImagesDeleted++
+		if usedPercent, err = imageManager.dockerDataRootDiskUsage(ctx); err != nil {
+			logger.Error("Error getting disk usage of the Docker data root", logger.Fields{field.Error: err})
+			return
+		}
+	}
+
+	if usedPercent >= lowWatermark && imageManager.deleteNonECSImagesEnabled.Enabled() {
+		imageManager.removeNonECSContainers(ctx)
+		nonECSImages := imageManager.getNonECSImages(ctx)
+		// under disk pressure the largest images are removed first to reach the low watermark sooner
+		sort.Slice(nonECSImages, func(i, j int) bool {
+			return nonECSImages[i].Size > nonECSImages[j].Size
+		})
+		for _, nonECSImage := range nonECSImages {
+			if usedPercent < lowWatermark {
+				break
+			}
+			if !imageManager.nonECSImageOldEnough(nonECSImage) {
+				continue
+			}
+			if imageManager.removeNonECSImage(ctx, nonECSImage, metrics.ImageCleanupTriggerDiskPressure) == 0 {
+				continue
+			}
+			numNonECSImagesDeleted++
+			if usedPercent, err = imageManager.dockerDataRootDiskUsage(ctx); err != nil {
+				logger.Error("Error getting disk usage of the Docker data root", logger.Fields{field.Error: err})
+				return
+			}
+		}
+	}
+
+	fields["diskUsagePercent"] = usedPercent
+	fields["ecsImagesRemoved"] = numECSImagesDeleted
+	fields["nonECSImagesRemoved"] = numNonECSImagesDeleted
+	if usedPercent >= lowWatermark {
+		logger.Warn("No more eligible images for deletion, disk usage is still above the low watermark", fields)
+		return
+	}
+	logger.Info("Disk usage is below the low watermark, finished image cleanup", fields)
+}
@@ -0,0 +1,226 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/data"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
+	mock_dockerapi "github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi/mocks"
+	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
+	"github.com/aws/amazon-ecs-agent/agent/engine/image"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/eventstream"
+
+	"github.com/docker/docker/api/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+const testDockerRootDir = "/var/lib/docker"
+
+// setTestDiskUsage makes getDiskUsagePercent return the given values in order, repeating the last one
+func setTestDiskUsage(t *testing.T, usages ...float64) {
+	original := getDiskUsagePercent
+	t.Cleanup(func() { getDiskUsagePercent = original })
+	getDiskUsagePercent = func(path string) (float64, error) {
+		assert.Equal(t, testDockerRootDir, path)
+		usage := usages[0]
+		if len(usages) > 1 {
+			usages = usages[1:]
+		}
+		return usage, nil
+	}
+}
+
+func newTestDiskPressureImageManager(client dockerapi.DockerClient) *dockerImageManager {
+	imageManager := &dockerImageManager{
+		client:                   client,
+		state:                    dockerstate.NewTaskEngineState(),
+		minimumAgeBeforeDeletion: config.DefaultImageDeletionAge,
+		numImagesToDelete:        config.DefaultNumImagesToDeletePerCycle,
+		imageCleanupTimeInterval: config.DefaultImageCleanupTimeInterval,
+		diskHighWatermark:        85,
+		diskLowWatermark:         70,
+		diskCheckInterval:        config.DefaultImageCleanupDiskCheckInterval,
+	}
+	imageManager.SetDataClient(data.NewNoopClient())
+	return imageManager
+}
+
+func addTestUnusedImageState(imageManager *dockerImageManager, imageID, imageName string, lastUsedAt time.Time) *image.ImageState {
+	imageState := &image.ImageState{
+		Image: &image.Image{
+			ImageID: imageID,
+			Names:   []string{imageName},
+		},
+		PulledAt:   time.Now().AddDate(0, -2, 0),
+		LastUsedAt: lastUsedAt,
+	}
+	imageManager.AddAllImageStates([]*image.ImageState{imageState})
+	return imageState
+}
+
+func TestRemoveImagesUnderDiskPressureBelowHighWatermark(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+	imageManager := newTestDiskPressureImageManager(client)
+	addTestUnusedImageState(imageManager, "sha256:a", "a", time.Now().AddDate(0, -1, 0))
+	setTestDiskUsage(t, 80)
+
+	client.EXPECT().Info(gomock.Any(), dockerclient.InfoTimeout).Return(types.Info{DockerRootDir: testDockerRootDir}, nil)
+
+	imageManager.removeImagesUnderDiskPressure(context.TODO())
+	assert.Equal(t, 1, imageManager.GetImageStatesCount())
+}
+
+func TestRemoveImagesUnderDiskPressureEvictsUntilLowWatermark(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+	imageManager := newTestDiskPressureImageManager(client)
+	addTestUnusedImageState(imageManager, "sha256:a", "a", time.Now().AddDate(0, -1, 0))
+	addTestUnusedImageState(imageManager, "sha256:b", "b", time.Now().AddDate(0, 0, -2))
+	addTestUnusedImageState(imageManager, "sha256:c", "c", time.Now().AddDate(0, 0, -1))
+	// usage drops below the low watermark after the two least recently used images are removed
+	setTestDiskUsage(t, 90, 75, 65)
+
+	client.EXPECT().Info(gomock.Any(), dockerclient.InfoTimeout).Return(types.Info{DockerRootDir: testDockerRootDir}, nil)
+	gomock.InOrder(
+		client.EXPECT().RemoveImage(gomock.Any(), "a", dockerclient.RemoveImageTimeout).Return(nil),
+		client.EXPECT().RemoveImage(gomock.Any(), "b", dockerclient.RemoveImageTimeout).Return(nil),
+	)
+
+	imageManager.removeImagesUnderDiskPressure(context.TODO())
+	assert.Equal(t, 1, imageManager.GetImageStatesCount())
+	_, ok := imageManager.getImageState("sha256:c")
+	assert.True(t, ok, "most recently used image should be kept")
+}
+
+// captureImageEvictions subscribes to the image cleanup events published by the image manager
+func captureImageEvictions(t *testing.T, imageManager *dockerImageManager) <-chan image.CleanupEvent {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	events := make(chan image.CleanupEvent, 10)
+	eventStream := eventstream.NewEventStream(t.Name(), ctx)
+	eventStream.StartListening()
+	assert.NoError(t, eventStream.Subscribe(t.Name(), func(event ...interface{}) error {
+		if cleanupEvent, ok := event[0].(image.CleanupEvent); ok {
+			events <- cleanupEvent
+		}
+		return nil
+	}))
+	imageManager.SetEventStream(eventStream)
+	return events
+}
+
+func TestRemoveImagesUnderDiskPressureRemovalFails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+	imageManager := newTestDiskPressureImageManager(client)
+	evictions := captureImageEvictions(t, imageManager)
+	addTestUnusedImageState(imageManager, "sha256:a", "a", time.Now().AddDate(0, -1, 0))
+	addTestUnusedImageState(imageManager, "sha256:b", "b", time.Now().AddDate(0, 0, -2))
+	// usage only drops after the second image is removed
+	setTestDiskUsage(t, 90, 65)
+
+	client.EXPECT().Info(gomock.Any(), dockerclient.InfoTimeout).Return(types.Info{DockerRootDir: testDockerRootDir}, nil)
+	gomock.InOrder(
+		client.EXPECT().RemoveImage(gomock.Any(), "a", dockerclient.RemoveImageTimeout).Return(errors.New("error")),
+		client.EXPECT().RemoveImage(gomock.Any(), "b", dockerclient.RemoveImageTimeout).Return(nil),
+	)
+
+	imageManager.removeImagesUnderDiskPressure(context.TODO())
+	_, ok := imageManager.getImageState("sha256:a")
+	assert.True(t, ok, "image that failed to be removed should be kept")
+
+	// only the removed image is recorded as evicted
+	select {
+	case event := <-evictions:
+		assert.Equal(t, "sha256:b", event.ImageID)
+	case <-time.After(time.Second):
+		t.Fatal("expected an eviction event for the removed image")
+	}
+	select {
+	case event := <-evictions:
+		t.Fatalf("unexpected eviction event for %s", event.ImageID)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestRemoveLeastRecentlyUsedImageRemovalFails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+	imageManager := newTestDiskPressureImageManager(client)
+	evictions := captureImageEvictions(t, imageManager)
+	addTestUnusedImageState(imageManager, "sha256:a", "a", time.Now().AddDate(0, -1, 0))
+	imageManager.imageStatesConsideredForDeletion = imageManager.imagesConsiderForDeletion(imageManager.getAllImageStates())
+
+	client.EXPECT().RemoveImage(gomock.Any(), "a", dockerclient.RemoveImageTimeout).Return(errors.New("error"))
+
+	assert.NoError(t, imageManager.removeLeastRecentlyUsedImage(context.TODO()))
+	select {
+	case event := <-evictions:
+		t.Fatalf("unexpected eviction event for %s", event.ImageID)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestRemoveImagesUnderDiskPressureFallsBackToNonECSImages(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+	imageManager := newTestDiskPressureImageManager(client)
+	imageManager.deleteNonECSImagesEnabled = config.BooleanDefaultFalse{Value: config.ExplicitlyEnabled}
+	addTestUnusedImageState(imageManager, "sha256:a", "a", time.Now().AddDate(0, -1, 0))
+	setTestDiskUsage(t, 90, 80, 60)
+
+	oldEnough := time.Now().AddDate(0, -2, 0).Format(time.RFC3339)
+	client.EXPECT().Info(gomock.Any(), dockerclient.InfoTimeout).Return(types.Info{DockerRootDir: testDockerRootDir}, nil)
+	client.EXPECT().RemoveImage(gomock.Any(), "a", dockerclient.RemoveImageTimeout).Return(nil)
+	client.EXPECT().ListContainers(gomock.Any(), true, dockerclient.ListContainersTimeout).Return(dockerapi.ListContainersResponse{})
+	client.EXPECT().ListImages(gomock.Any(), dockerclient.ListImagesTimeout).Return(dockerapi.ListImagesResponse{
+		ImageIDs: []string{"sha256:small", "sha256:large"},
+	})
+	client.EXPECT().InspectImage("sha256:small").Return(&types.ImageInspect{ID: "sha256:small", Size: 1, Created: oldEnough}, nil)
+	client.EXPECT().InspectImage("sha256:large").Return(&types.ImageInspect{ID: "sha256:large", Size: 100, Created: oldEnough}, nil)
+	client.EXPECT().RemoveImage(gomock.Any(), "sha256:large", dockerclient.RemoveImageTimeout).Return(nil)
+
+	imageManager.removeImagesUnderDiskPressure(context.TODO())
+	assert.Zero(t, imageManager.GetImageStatesCount())
+}
+
+func TestRemoveImagesUnderDiskPressureInfoError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+	imageManager := newTestDiskPressureImageManager(client)
+	addTestUnusedImageState(imageManager, "sha256:a", "a", time.Now().AddDate(0, -1, 0))
+
+	client.EXPECT().Info(gomock.Any(), dockerclient.InfoTimeout).Return(types.Info{}, errors.New("error"))
+
+	imageManager.removeImagesUnderDiskPressure(context.TODO())
+	assert.Equal(t, 1, imageManager.GetImageStatesCount())
+}
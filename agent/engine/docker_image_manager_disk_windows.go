@@ -0,0 +1,39 @@
+//go:build windows
+// +build windows
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/windows"
+)
+
+// diskUsagePercent returns the used percentage of the volume that contains path
+func diskUsagePercent(path string) (float64, error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+	var freeBytesAvailable, totalBytes, totalFreeBytes uint64
+	if err := windows.GetDiskFreeSpaceEx(pathPtr, &freeBytesAvailable, &totalBytes, &totalFreeBytes); err != nil {
+		return 0, fmt.Errorf("unable to get free disk space of %s: %w", path, err)
+	}
+	if totalBytes == 0 {
+		return 0, fmt.Errorf("volume of %s reports zero size", path)
+	}
+	return float64(totalBytes-totalFreeBytes) * 100 / float64(totalBytes), nil
+}
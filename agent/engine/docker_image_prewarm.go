@@ -0,0 +1,217 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/engine/image"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+)
+
+const (
+	// imagePrewarmManifestFileName is the name of the image pre-warm manifest under the agent's data directory
+	imagePrewarmManifestFileName = "image_prewarm.json"
+
+	// imagePrewarmRefreshInterval is the time between two reads of the image pre-warm manifest
+	imagePrewarmRefreshInterval = 5 * time.Minute
+)
+
+// imagePrewarmManifest is the format of the image pre-warm manifest, e.g.
+//
+//	{"images": ["amazonlinux:2", "public.ecr.aws/nginx/nginx:latest"]}
+type imagePrewarmManifest struct {
+	Images []string `json:"images"`
+}
+
+// loadImagePrewarmManifest reads the list of images to pre-warm from the manifest file.
+// A missing manifest is treated as an empty list.
+func loadImagePrewarmManifest(manifestPath string) ([]string, error) {
+	manifestBytes, err := os.ReadFile(manifestPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to read image pre-warm manifest %s: %w", manifestPath, err)
+	}
+	var manifest imagePrewarmManifest
+	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+		return nil, fmt.Errorf("unable to parse image pre-warm manifest %s: %w", manifestPath, err)
+	}
+	var images []string
+	seen := make(map[string]struct{})
+	for _, imageName := range manifest.Images {
+		imageName = strings.TrimSpace(imageName)
+		if imageName == "" {
+			continue
+		}
+		if _, ok := seen[imageName]; ok {
+			continue
+		}
+		seen[imageName] = struct{}{}
+		images = append(images, imageName)
+	}
+	return images, nil
+}
+
+func imagePrewarmManifestPath(dataDir string) string {
+	return filepath.Join(dataDir, imagePrewarmManifestFileName)
+}
+
+// StartImagePrewarmProcess pulls the images listed in the image pre-warm manifest and keeps
+// refreshing the list until the context is canceled.
+func (imageManager *dockerImageManager) StartImagePrewarmProcess(ctx context.Context) {
+	if !imageManager.imagePrewarmEnabled.Enabled() {
+		logger.Debug("Image pre-warm is disabled")
+		return
+	}
+	// passing the refresh interval as argument which would help during testing
+	imageManager.performPeriodicImagePrewarm(ctx, imagePrewarmRefreshInterval)
+}
+
+func (imageManager *dockerImageManager) performPeriodicImagePrewarm(ctx context.Context, refreshInterval time.Duration) {
+	imageManager.prewarmImages(ctx)
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			imageManager.prewarmImages(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// prewarmImages reloads the manifest, updates the set of images pinned against cleanup and
+// pulls the images that have not been pulled yet, with at most imagePrewarmConcurrency pulls in flight.
+func (imageManager *dockerImageManager) prewarmImages(ctx context.Context) {
+	images, err := loadImagePrewarmManifest(imageManager.imagePrewarmManifestPath)
+	if err != nil {
+		// Keep the previously pinned images so that a bad edit of the manifest
+		// does not make them eligible for cleanup
+		logger.Error("Error loading image pre-warm manifest", logger.Fields{field.Error: err})
+		return
+	}
+	imageManager.setPrewarmImages(images)
+
+	sem := make(chan struct{}, imageManager.imagePrewarmConcurrency)
+	var wg sync.WaitGroup
+	for _, imageName := range images {
+		if imageState, ok := imageManager.GetImageStateFromImageName(imageName); ok && imageState.GetPullSucceeded() {
+			logger.Debug("Image already pre-warmed", logger.Fields{field.Image: imageName})
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		}
+		wg.Add(1)
+		go func(imageName string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			imageManager.prewarmImage(ctx, imageName)
+		}(imageName)
+	}
+	wg.Wait()
+}
+
+func (imageManager *dockerImageManager) prewarmImage(ctx context.Context, imageName string) {
+	fields := logger.Fields{field.Image: imageName}
+	logger.Debug("Attempting to obtain ImagePullDeleteLock to pre-warm image", fields)
+	ImagePullDeleteLock.RLock()
+	logger.Debug("Acquired ImagePullDeleteLock, start pre-warming image", fields)
+	defer logger.Debug("Released ImagePullDeleteLock after pre-warming image", fields)
+	defer ImagePullDeleteLock.RUnlock()
+
+	startTime := time.Now()
+	metadata := imageManager.client.PullImage(ctx, imageName, nil, imageManager.imagePullTimeout)
+	if metadata.Error != nil {
+		logger.Error("Error pre-warming image", fields, logger.Fields{field.Error: metadata.Error})
+		return
+	}
+	if err := imageManager.recordPrewarmedImage(imageName); err != nil {
+		logger.Error("Error recording pre-warmed image", fields, logger.Fields{field.Error: err})
+		return
+	}
+	logger.Info("Image pre-warmed", fields, logger.Fields{field.Elapsed: time.Since(startTime).String()})
+}
+
+// recordPrewarmedImage adds the pulled image to the image states tracked by the image manager, so
+// that the cleanup LRU logic accounts for it once it is no longer pinned.
+func (imageManager *dockerImageManager) recordPrewarmedImage(imageName string) error {
+	imageInspected, err := imageManager.client.InspectImage(imageName)
+	if err != nil {
+		return err
+	}
+
+	imageManager.updateLock.Lock()
+	defer imageManager.updateLock.Unlock()
+	imageManager.removeExistingImageNameOfDifferentID(imageName, imageInspected.ID)
+	imageState, ok := imageManager.getImageState(imageInspected.ID)
+	if !ok {
+		imageState = &image.ImageState{
+			Image: &image.Image{
+				ImageID: imageInspected.ID,
+				Size:    imageInspected.Size,
+			},
+			PulledAt:   time.Now(),
+			LastUsedAt: time.Now(),
+		}
+		imageState.AddImageName(imageName)
+		imageState.SetPullSucceeded(true)
+		imageManager.addImageState(imageState)
+	} else {
+		imageState.AddImageName(imageName)
+		imageState.SetPullSucceeded(true)
+		imageManager.saveImageStateData(imageState)
+	}
+	imageManager.state.AddImageState(imageState)
+	return nil
+}
+
+// setPrewarmImages replaces the set of images pinned against cleanup
+func (imageManager *dockerImageManager) setPrewarmImages(images []string) {
+	prewarmImages := make(map[string]struct{}, len(images))
+	for _, imageName := range images {
+		prewarmImages[imageName] = struct{}{}
+	}
+
+	imageManager.prewarmLock.Lock()
+	defer imageManager.prewarmLock.Unlock()
+	for imageName := range imageManager.prewarmImageNames {
+		if _, ok := prewarmImages[imageName]; !ok {
+			logger.Info("Image no longer pinned by pre-warm manifest", logger.Fields{field.Image: imageName})
+		}
+	}
+	imageManager.prewarmImageNames = prewarmImages
+}
+
+// isPrewarmPinned returns true if the image name is listed in the image pre-warm manifest
+func (imageManager *dockerImageManager) isPrewarmPinned(imageName string) bool {
+	imageManager.prewarmLock.RLock()
+	defer imageManager.prewarmLock.RUnlock()
+	_, ok := imageManager.prewarmImageNames[imageName]
+	return ok
+}
@@ -0,0 +1,137 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/data"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
+	mock_dockerapi "github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi/mocks"
+	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
+	"github.com/aws/amazon-ecs-agent/agent/engine/image"
+
+	"github.com/docker/docker/api/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPrewarmImageManager(t *testing.T, client dockerapi.DockerClient, manifest string) *dockerImageManager {
+	dataDir := t.TempDir()
+	if manifest != "" {
+		require.NoError(t, os.WriteFile(filepath.Join(dataDir, imagePrewarmManifestFileName), []byte(manifest), 0644))
+	}
+	cfg := defaultTestConfig()
+	cfg.DataDir = dataDir
+	cfg.ImagePrewarmEnabled = config.BooleanDefaultFalse{Value: config.ExplicitlyEnabled}
+	imageManager := NewImageManager(cfg, client, dockerstate.NewTaskEngineState()).(*dockerImageManager)
+	imageManager.SetDataClient(data.NewNoopClient())
+	return imageManager
+}
+
+func TestLoadImagePrewarmManifest(t *testing.T) {
+	dataDir := t.TempDir()
+	manifestPath := imagePrewarmManifestPath(dataDir)
+
+	images, err := loadImagePrewarmManifest(manifestPath)
+	assert.NoError(t, err, "missing manifest should not be an error")
+	assert.Empty(t, images)
+
+	require.NoError(t, os.WriteFile(manifestPath, []byte(`{"images": ["a:1", " b:2 ", "", "a:1"]}`), 0644))
+	images, err = loadImagePrewarmManifest(manifestPath)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a:1", "b:2"}, images)
+
+	require.NoError(t, os.WriteFile(manifestPath, []byte(`{"images": `), 0644))
+	_, err = loadImagePrewarmManifest(manifestPath)
+	assert.Error(t, err)
+}
+
+func TestPrewarmImagesPullsAndRecordsImageState(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+	imageManager := newTestPrewarmImageManager(t, client, `{"images": ["a:1", "b:2"]}`)
+
+	client.EXPECT().PullImage(gomock.Any(), "a:1", nil, gomock.Any()).Return(dockerapi.DockerContainerMetadata{})
+	client.EXPECT().PullImage(gomock.Any(), "b:2", nil, gomock.Any()).Return(dockerapi.DockerContainerMetadata{})
+	client.EXPECT().InspectImage("a:1").Return(&types.ImageInspect{ID: "sha256:a", Size: 1}, nil)
+	client.EXPECT().InspectImage("b:2").Return(&types.ImageInspect{ID: "sha256:b", Size: 2}, nil)
+
+	imageManager.prewarmImages(context.TODO())
+
+	assert.Equal(t, 2, imageManager.GetImageStatesCount())
+	for _, imageName := range []string{"a:1", "b:2"} {
+		imageState, ok := imageManager.GetImageStateFromImageName(imageName)
+		require.True(t, ok, "image state should be recorded for %s", imageName)
+		assert.True(t, imageState.GetPullSucceeded())
+		assert.True(t, imageManager.isPrewarmPinned(imageName))
+	}
+	assert.Len(t, imageManager.state.AllImageStates(), 2)
+
+	// images that are already pre-warmed are not pulled again
+	imageManager.prewarmImages(context.TODO())
+}
+
+func TestPrewarmImagesPullFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+	imageManager := newTestPrewarmImageManager(t, client, `{"images": ["a:1"]}`)
+
+	client.EXPECT().PullImage(gomock.Any(), "a:1", nil, gomock.Any()).Return(dockerapi.DockerContainerMetadata{
+		Error: dockerapi.CannotPullContainerError{FromError: errors.New("error")},
+	})
+
+	imageManager.prewarmImages(context.TODO())
+
+	assert.Zero(t, imageManager.GetImageStatesCount())
+	assert.True(t, imageManager.isPrewarmPinned("a:1"))
+}
+
+func TestPrewarmImagesInvalidManifestKeepsPins(t *testing.T) {
+	imageManager := newTestPrewarmImageManager(t, nil, `{"images": `)
+	imageManager.setPrewarmImages([]string{"a:1"})
+
+	imageManager.prewarmImages(context.TODO())
+
+	assert.True(t, imageManager.isPrewarmPinned("a:1"))
+}
+
+func TestPrewarmedImageExcludedFromCleanupUntilUnlisted(t *testing.T) {
+	imageManager := newTestPrewarmImageManager(t, nil, "")
+	imageState := &image.ImageState{
+		Image: &image.Image{
+			ImageID: "sha256:a",
+			Names:   []string{"a:1"},
+		},
+		PulledAt: time.Now().AddDate(0, -2, 0),
+	}
+
+	imageManager.setPrewarmImages([]string{"a:1"})
+	assert.Empty(t, imageManager.imagesConsiderForDeletion([]*image.ImageState{imageState}))
+
+	imageManager.setPrewarmImages(nil)
+	assert.Len(t, imageManager.imagesConsiderForDeletion([]*image.ImageState{imageState}), 1)
+}
@@ -44,12 +44,14 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/credentialspec"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/firelens"
+	"github.com/aws/amazon-ecs-agent/agent/tracing"
 	"github.com/aws/amazon-ecs-agent/agent/utils"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/api/appnet"
 	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
 	apierrors "github.com/aws/amazon-ecs-agent/ecs-agent/api/errors"
 	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/ecs_client/model/ecs"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/eventstream"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
@@ -137,6 +139,8 @@ type DockerTaskEngine struct {
 
 	// waitingTasksQueue is a FIFO queue of tasks waiting to acquire host resources
 	waitingTaskQueue []*managedTask
+	// admissionPolicy decides the order the waiting tasks are admitted in
+	admissionPolicy taskAdmissionPolicy
 
 	events                 <-chan dockerapi.DockerContainerChangeEvent
 	monitorQueuedTaskEvent chan struct{}
@@ -144,6 +148,7 @@ type DockerTaskEngine struct {
 
 	client       dockerapi.DockerClient
 	dataClient   data.Client
+	ecsClient    api.ECSClient
 	cniClient    ecscni.CNIClient
 	appnetClient appnet.AppNetClient
 
@@ -251,6 +256,8 @@ func NewDockerTaskEngine(cfg *config.Config,
 		daemonTasks:                       make(map[string]*apitask.Task),
 	}
 
+	dockerTaskEngine.admissionPolicy = newTaskAdmissionPolicy(cfg, dockerTaskEngine.taskTags,
+		dockerTaskEngine.wakeUpTaskQueueMonitor)
 	dockerTaskEngine.initializeContainerStatusToTransitionFunction()
 
 	return dockerTaskEngine
@@ -368,26 +375,45 @@ func (engine *DockerTaskEngine) topTask() (*managedTask, error) {
 
 func (engine *DockerTaskEngine) enqueueTask(task *managedTask) {
 	engine.waitingTasksLock.Lock()
+	task.queuedAt = time.Now()
 	engine.waitingTaskQueue = append(engine.waitingTaskQueue, task)
+	task.SetQueueStatus(len(engine.waitingTaskQueue), waitReasonQueued)
 	engine.waitingTasksLock.Unlock()
 	logger.Debug("Enqueued task in Waiting Task Queue", logger.Fields{field.TaskARN: task.Arn})
+	engine.taskAdmissionPolicy().queued(task)
 	engine.wakeUpTaskQueueMonitor()
 }
 
-func (engine *DockerTaskEngine) dequeueTask() (*managedTask, error) {
+// waitingTasks returns the tasks in waitingTaskQueue, in the order they were queued in.
+func (engine *DockerTaskEngine) waitingTasks() []*managedTask {
 	engine.waitingTasksLock.Lock()
 	defer engine.waitingTasksLock.Unlock()
-	if len(engine.waitingTaskQueue) > 0 {
-		task := engine.waitingTaskQueue[0]
-		engine.waitingTaskQueue = engine.waitingTaskQueue[1:]
-		logger.Debug("Dequeued task from Waiting Task Queue", logger.Fields{field.TaskARN: task.Arn})
-		return task, nil
+	return append([]*managedTask(nil), engine.waitingTaskQueue...)
+}
+
+func (engine *DockerTaskEngine) dequeueTask(task *managedTask) {
+	engine.waitingTasksLock.Lock()
+	defer engine.waitingTasksLock.Unlock()
+	for i, waitingTask := range engine.waitingTaskQueue {
+		if waitingTask == task {
+			engine.waitingTaskQueue = append(engine.waitingTaskQueue[:i:i], engine.waitingTaskQueue[i+1:]...)
+			task.SetQueueStatus(0, "")
+			engine.taskAdmissionPolicy().dequeued(task)
+			logger.Debug("Dequeued task from Waiting Task Queue", logger.Fields{field.TaskARN: task.Arn})
+			return
+		}
 	}
+}
 
-	return nil, fmt.Errorf("no tasks in waiting queue")
+// taskAdmissionPolicy returns the policy the waiting tasks are admitted with, FIFO when it isn't set.
+func (engine *DockerTaskEngine) taskAdmissionPolicy() taskAdmissionPolicy {
+	if engine.admissionPolicy == nil {
+		return &fifoAdmissionPolicy{}
+	}
+	return engine.admissionPolicy
 }
 
-// monitorQueuedTasks starts as many tasks as possible based on FIFO order of waitingTaskQueue
+// monitorQueuedTasks starts as many tasks as possible based on the admission policy order of waitingTaskQueue
 // and availability of host resources. When no more tasks can be started, it will wait on
 // monitorQueuedTaskEvent channel. This channel receives (best effort) messages when
 // - a task stops
@@ -402,21 +428,39 @@ func (engine *DockerTaskEngine) monitorQueuedTasks(ctx context.Context) {
 			return
 		case <-engine.monitorQueuedTaskEvent:
 			// Dequeue as many tasks as possible and start wake up their goroutines
-			for {
-				task, err := engine.topTask()
-				if err != nil {
-					break
-				}
-				dequeuedTask := engine.tryDequeueWaitingTasks(task)
-				if !dequeuedTask {
-					break
-				}
+			for engine.admitWaitingTask() {
 			}
 			logger.Debug("No more tasks could be started at this moment, waiting")
 		}
 	}
 }
 
+// admitWaitingTask admits the first waiting task that can be admitted in the order of the admission
+// policy. When none can, it records the queue position of the waiting tasks and why they are waiting,
+// and returns false.
+func (engine *DockerTaskEngine) admitWaitingTask() bool {
+	policy := engine.taskAdmissionPolicy()
+	ordered := policy.order(engine.waitingTasks())
+	waitReasons := make(map[*managedTask]string, len(ordered))
+	for i, task := range ordered {
+		if engine.tryDequeueWaitingTasks(task) {
+			return true
+		}
+		unavailable := engine.hostResourceManager.unavailableResources(task.ToHostResources())
+		waitReasons[task] = fmt.Sprintf(waitReasonInsufficientResources, strings.Join(unavailable, ", "))
+		if !policy.backfills(task) {
+			for _, behind := range ordered[i+1:] {
+				waitReasons[behind] = fmt.Sprintf(waitReasonQueuedBehind, task.Arn)
+			}
+			break
+		}
+	}
+	for i, task := range ordered {
+		task.SetQueueStatus(i+1, waitReasons[task])
+	}
+	return false
+}
+
 func (engine *DockerTaskEngine) tryDequeueWaitingTasks(task *managedTask) bool {
 	// Isolate monitorQueuedTasks processing from changes of desired status updates to prevent
 	// unexpected updates to host resource manager when tasks are being processed by monitorQueuedTasks
@@ -427,17 +471,17 @@ func (engine *DockerTaskEngine) tryDequeueWaitingTasks(task *managedTask) bool {
 	taskDesiredStatus := task.GetDesiredStatus()
 	if taskDesiredStatus.Terminal() {
 		logger.Info("Task desired status changed to STOPPED while waiting for host resources, progressing without consuming resources", logger.Fields{field.TaskARN: task.Arn})
-		engine.returnWaitingTask()
+		engine.returnWaitingTask(task)
 		return true
 	}
 	taskHostResources := task.ToHostResources()
 	consumed, err := task.engine.hostResourceManager.consume(task.Arn, taskHostResources)
 	if err != nil {
-		engine.failWaitingTask(err)
+		engine.failWaitingTask(task, err)
 		return true
 	}
 	if consumed {
-		engine.startWaitingTask()
+		engine.startWaitingTask(task)
 		return true
 	}
 	return false
@@ -445,20 +489,20 @@ func (engine *DockerTaskEngine) tryDequeueWaitingTasks(task *managedTask) bool {
 }
 
 // To be called when resources are not to be consumed by host resource manager, just dequeues and returns
-func (engine *DockerTaskEngine) returnWaitingTask() {
-	task, _ := engine.dequeueTask()
+func (engine *DockerTaskEngine) returnWaitingTask(task *managedTask) {
+	engine.dequeueTask(task)
 	task.consumedHostResourceEvent <- struct{}{}
 }
 
-func (engine *DockerTaskEngine) failWaitingTask(err error) {
-	task, _ := engine.dequeueTask()
+func (engine *DockerTaskEngine) failWaitingTask(task *managedTask, err error) {
+	engine.dequeueTask(task)
 	logger.Error(fmt.Sprintf("Error consuming resources due to invalid task config : %s", err.Error()), logger.Fields{field.TaskARN: task.Arn})
 	task.SetDesiredStatus(apitaskstatus.TaskStopped)
 	task.consumedHostResourceEvent <- struct{}{}
 }
 
-func (engine *DockerTaskEngine) startWaitingTask() {
-	task, _ := engine.dequeueTask()
+func (engine *DockerTaskEngine) startWaitingTask(task *managedTask) {
+	engine.dequeueTask(task)
 	logger.Info("Host resources consumed, progressing task", logger.Fields{field.TaskARN: task.Arn})
 	task.consumedHostResourceEvent <- struct{}{}
 }
@@ -564,6 +608,19 @@ func (engine *DockerTaskEngine) SetDataClient(client data.Client) {
 	engine.dataClient = client
 }
 
+// SetECSClient sets the ECS client that is used by the DockerTaskEngine to get the tags of the tasks.
+func (engine *DockerTaskEngine) SetECSClient(client api.ECSClient) {
+	engine.ecsClient = client
+}
+
+// taskTags returns the tags of the task with the given ARN.
+func (engine *DockerTaskEngine) taskTags(taskARN string) ([]*ecs.Tag, error) {
+	if engine.ecsClient == nil {
+		return nil, errors.New("no ECS client to get the task tags with")
+	}
+	return engine.ecsClient.GetResourceTags(taskARN)
+}
+
 func (engine *DockerTaskEngine) Context() context.Context {
 	return engine.ctx
 }
@@ -1421,7 +1478,11 @@ func (engine *DockerTaskEngine) pullAndUpdateContainerReference(task *apitask.Ta
 		defer container.SetASMDockerAuthConfig(types.AuthConfig{})
 	}
 
+	span := tracing.StartSpan(task.Arn, tracing.SpanPullImage,
+		tracing.String(tracing.AttributeContainerName, container.Name),
+		tracing.String(tracing.AttributeContainerImage, container.Image))
 	metadata := engine.client.PullImage(engine.ctx, container.Image, container.RegistryAuthentication, engine.cfg.ImagePullTimeout)
+	span.End(metadata.Error)
 
 	// Don't add internal images(created by ecs-agent) into imagemanger state
 	if container.IsInternal() {
@@ -1556,6 +1617,8 @@ func (engine *DockerTaskEngine) createContainer(task *apitask.Task, container *a
 		}
 	}
 
+	task.AddSecretFilesBindMount(container, hostConfig, engine.cfg)
+
 	firelensConfig := container.GetFirelensConfig()
 	if firelensConfig != nil {
 		err := task.AddFirelensContainerBindMounts(firelensConfig, hostConfig, engine.cfg)
@@ -1760,8 +1823,11 @@ func (engine *DockerTaskEngine) createContainer(task *apitask.Task, container *a
 	}
 
 	createContainerBegin := time.Now()
+	span := tracing.StartSpan(task.Arn, tracing.SpanCreateContainer,
+		tracing.String(tracing.AttributeContainerName, container.Name))
 	metadata := client.CreateContainer(engine.ctx, config, hostConfig,
 		dockerContainerName, engine.cfg.ContainerCreateTimeout)
+	span.End(metadata.Error)
 	if metadata.DockerID != "" {
 		dockerContainer := &apicontainer.DockerContainer{DockerID: metadata.DockerID,
 			DockerName: dockerContainerName,
@@ -1825,7 +1891,10 @@ func (engine *DockerTaskEngine) startContainer(task *apitask.Task, container *ap
 	}
 
 	startContainerBegin := time.Now()
+	span := tracing.StartSpan(task.Arn, tracing.SpanStartContainer,
+		tracing.String(tracing.AttributeContainerName, container.Name))
 	dockerContainerMD := client.StartContainer(engine.ctx, dockerID, engine.cfg.ContainerStartTimeout)
+	span.End(dockerContainerMD.Error)
 	if dockerContainerMD.Error != nil {
 		return dockerContainerMD
 	}
@@ -2030,7 +2099,11 @@ func (engine *DockerTaskEngine) provisionContainerResourcesAwsvpc(task *apitask.
 	})
 
 	// Invoke the libcni to config the network namespace for the container
+	span := tracing.StartSpan(task.Arn, tracing.SpanSetupNetwork,
+		tracing.String(tracing.AttributeContainerName, container.Name),
+		tracing.String(tracing.AttributeNetworkMode, task.NetworkMode))
 	result, err := engine.cniClient.SetupNS(engine.ctx, cniConfig, cniSetupTimeout)
+	span.End(err)
 	if err != nil {
 		logger.Error("Unable to configure pause container namespace", logger.Fields{
 			field.TaskID: task.GetID(),
@@ -2109,7 +2182,11 @@ func (engine *DockerTaskEngine) provisionContainerResourcesBridgeMode(task *apit
 	}
 
 	// Invoke the libcni to config the network namespace for the container
+	span := tracing.StartSpan(task.Arn, tracing.SpanSetupNetwork,
+		tracing.String(tracing.AttributeContainerName, container.Name),
+		tracing.String(tracing.AttributeNetworkMode, task.NetworkMode))
 	_, err = engine.cniClient.SetupNS(engine.ctx, cniConfig, cniSetupTimeout)
+	span.End(err)
 
 	if err != nil {
 		logger.Error("Unable to configure pause container namespace", logger.Fields{
@@ -17,6 +17,7 @@ package engine
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 
 	"github.com/aws/amazon-ecs-agent/agent/utils"
@@ -277,6 +278,31 @@ func (h *HostResourceManager) consumable(resources map[string]*ecs.Resource) (bo
 	return true, nil
 }
 
+// unavailableResources returns the names of the resources that the host doesn't have enough of for
+// the task to consume them, sorted by name.
+func (h *HostResourceManager) unavailableResources(resources map[string]*ecs.Resource) []string {
+	h.hostResourceManagerRWLock.Lock()
+	defer h.hostResourceManagerRWLock.Unlock()
+
+	if err := h.checkResourcesHealth(resources); err != nil {
+		return nil
+	}
+	var unavailable []string
+	for resourceKey := range resources {
+		consumable := true
+		if *resources[resourceKey].Type == "INTEGER" {
+			consumable = h.checkConsumableIntType(resourceKey, resources)
+		} else if *resources[resourceKey].Type == "STRINGSET" {
+			consumable = h.checkConsumableStringSetType(resourceKey, resources)
+		}
+		if !consumable {
+			unavailable = append(unavailable, resourceKey)
+		}
+	}
+	sort.Strings(unavailable)
+	return unavailable
+}
+
 // Utility function to manage release of ports
 // s2 is contiguous sub slice of s1, each is unique (ports)
 // returns a slice after removing s2 from s1, if found
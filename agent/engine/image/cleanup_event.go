@@ -0,0 +1,34 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package image
+
+import (
+	"time"
+)
+
+// CleanupEvent is published by the image manager when it removes an image from the instance.
+type CleanupEvent struct {
+	// ImageID is the ID of the removed image
+	ImageID string
+	// ImageNames are the names the image was referenced by
+	ImageNames []string
+	// SizeBytes is the size of the removed image
+	SizeBytes int64
+	// Trigger is the reason of the removal, periodic cleanup or disk pressure
+	Trigger string
+	// ImageType tells whether the image was tracked by the image manager (ecs) or not (non_ecs)
+	ImageType string
+	// RemovedAt is the time the image was removed
+	RemovedAt time.Time
+}
@@ -18,6 +18,7 @@ import (
 
 	"context"
 
+	"github.com/aws/amazon-ecs-agent/agent/api"
 	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
 	"github.com/aws/amazon-ecs-agent/agent/data"
 	dm "github.com/aws/amazon-ecs-agent/agent/engine/daemonmanager"
@@ -39,6 +40,8 @@ type TaskEngine interface {
 	StateChangeEvents() chan statechange.Event
 	// SetDataClient sets the data client that is used by the task engine.
 	SetDataClient(data.Client)
+	// SetECSClient sets the ECS client that is used by the task engine to get the tags of the tasks.
+	SetECSClient(api.ECSClient)
 
 	// AddTask adds a new task to the task engine and manages its container's
 	// lifecycle. If it returns an error, the task was not added.
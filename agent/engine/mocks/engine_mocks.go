@@ -22,12 +22,14 @@ import (
 	context "context"
 	reflect "reflect"
 
+	api "github.com/aws/amazon-ecs-agent/agent/api"
 	container "github.com/aws/amazon-ecs-agent/agent/api/container"
 	task "github.com/aws/amazon-ecs-agent/agent/api/task"
 	data "github.com/aws/amazon-ecs-agent/agent/data"
 	daemonmanager "github.com/aws/amazon-ecs-agent/agent/engine/daemonmanager"
 	image "github.com/aws/amazon-ecs-agent/agent/engine/image"
 	statechange "github.com/aws/amazon-ecs-agent/agent/statechange"
+	eventstream "github.com/aws/amazon-ecs-agent/ecs-agent/eventstream"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -229,6 +231,18 @@ func (mr *MockTaskEngineMockRecorder) SetDataClient(arg0 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDataClient", reflect.TypeOf((*MockTaskEngine)(nil).SetDataClient), arg0)
 }
 
+// SetECSClient mocks base method.
+func (m *MockTaskEngine) SetECSClient(arg0 api.ECSClient) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetECSClient", arg0)
+}
+
+// SetECSClient indicates an expected call of SetECSClient.
+func (mr *MockTaskEngineMockRecorder) SetECSClient(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetECSClient", reflect.TypeOf((*MockTaskEngine)(nil).SetECSClient), arg0)
+}
+
 // StateChangeEvents mocks base method.
 func (m *MockTaskEngine) StateChangeEvents() chan statechange.Event {
 	m.ctrl.T.Helper()
@@ -374,6 +388,18 @@ func (mr *MockImageManagerMockRecorder) SetDataClient(arg0 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDataClient", reflect.TypeOf((*MockImageManager)(nil).SetDataClient), arg0)
 }
 
+// SetEventStream mocks base method.
+func (m *MockImageManager) SetEventStream(arg0 *eventstream.EventStream) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetEventStream", arg0)
+}
+
+// SetEventStream indicates an expected call of SetEventStream.
+func (mr *MockImageManagerMockRecorder) SetEventStream(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEventStream", reflect.TypeOf((*MockImageManager)(nil).SetEventStream), arg0)
+}
+
 // StartImageCleanupProcess mocks base method.
 func (m *MockImageManager) StartImageCleanupProcess(arg0 context.Context) {
 	m.ctrl.T.Helper()
@@ -385,3 +411,15 @@ func (mr *MockImageManagerMockRecorder) StartImageCleanupProcess(arg0 interface{
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartImageCleanupProcess", reflect.TypeOf((*MockImageManager)(nil).StartImageCleanupProcess), arg0)
 }
+
+// StartImagePrewarmProcess mocks base method.
+func (m *MockImageManager) StartImagePrewarmProcess(arg0 context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "StartImagePrewarmProcess", arg0)
+}
+
+// StartImagePrewarmProcess indicates an expected call of StartImagePrewarmProcess.
+func (mr *MockImageManagerMockRecorder) StartImagePrewarmProcess(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartImagePrewarmProcess", reflect.TypeOf((*MockImageManager)(nil).StartImagePrewarmProcess), arg0)
+}
@@ -0,0 +1,281 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/ecs_client/model/ecs"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/retry"
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+const (
+	// waitReasonQueued is why a task waits until the queue of the tasks waiting for host resources
+	// is processed.
+	waitReasonQueued = "waiting to be admitted"
+	// waitReasonInsufficientResources is why a task waits when the host doesn't have enough of some
+	// of its resources.
+	waitReasonInsufficientResources = "insufficient host resources: %s"
+	// waitReasonQueuedBehind is why a task waits behind a task that is admitted before it.
+	waitReasonQueuedBehind = "queued behind task %s"
+
+	// taskTagsBackoffMin and taskTagsBackoffMax bound the backoff between the attempts to get the
+	// tags of a waiting task for its priority.
+	taskTagsBackoffMin = 5 * time.Second
+	taskTagsBackoffMax = 5 * time.Minute
+)
+
+// taskAdmissionPolicy decides the order the tasks waiting for host resources are admitted in.
+type taskAdmissionPolicy interface {
+	// order returns the waiting tasks, given in the order they were queued in, in the order they
+	// are tried to be admitted in.
+	order(waiting []*managedTask) []*managedTask
+	// backfills returns whether the tasks after blocking, a task that doesn't fit in the host
+	// resources, can be admitted before it.
+	backfills(blocking *managedTask) bool
+	// queued is called when a task is added to the waiting tasks.
+	queued(task *managedTask)
+	// dequeued is called when a task is removed from the waiting tasks.
+	dequeued(task *managedTask)
+}
+
+// taskTagsGetter returns the tags of the task with the given ARN.
+type taskTagsGetter func(taskARN string) ([]*ecs.Tag, error)
+
+// newTaskAdmissionPolicy returns the configured admission policy. taskTags gets the tags of the tasks
+// for the priority policy, which calls onResolved when the priority of a waiting task changes.
+func newTaskAdmissionPolicy(cfg *config.Config, taskTags taskTagsGetter, onResolved func()) taskAdmissionPolicy {
+	switch cfg.TaskAdmissionPolicy {
+	case config.TaskAdmissionPolicyBestFit:
+		return &bestFitAdmissionPolicy{
+			maxWait: cfg.TaskAdmissionMaxWait,
+			now:     time.Now,
+		}
+	case config.TaskAdmissionPolicyPriority:
+		return &priorityAdmissionPolicy{
+			tag:              cfg.TaskAdmissionPriorityTag,
+			familyPriorities: cfg.TaskAdmissionFamilyPriorities,
+			taskTags:         taskTags,
+			onResolved:       onResolved,
+			newBackoff: func() retry.Backoff {
+				return retry.NewExponentialBackoff(taskTagsBackoffMin, taskTagsBackoffMax, 0.2, 2)
+			},
+			waiting:    make(map[string]bool),
+			priorities: make(map[string]int),
+		}
+	default:
+		return &fifoAdmissionPolicy{}
+	}
+}
+
+// fifoAdmissionPolicy admits the tasks strictly in the order they were queued in.
+type fifoAdmissionPolicy struct{}
+
+func (policy *fifoAdmissionPolicy) order(waiting []*managedTask) []*managedTask {
+	return waiting
+}
+
+func (policy *fifoAdmissionPolicy) backfills(blocking *managedTask) bool {
+	return false
+}
+
+func (policy *fifoAdmissionPolicy) queued(task *managedTask) {}
+
+func (policy *fifoAdmissionPolicy) dequeued(task *managedTask) {}
+
+// bestFitAdmissionPolicy admits the largest task that fits in the available host resources, by
+// memory then CPU, so that the tasks that fit are backfilled behind a large task that doesn't.
+// A task that has waited for longer than maxWait is admitted first, in the order it was queued
+// in, and isn't backfilled behind, so that large tasks aren't starved by a stream of small ones.
+type bestFitAdmissionPolicy struct {
+	maxWait time.Duration
+	now     func() time.Time
+}
+
+func (policy *bestFitAdmissionPolicy) order(waiting []*managedTask) []*managedTask {
+	type taskSize struct {
+		task   *managedTask
+		aged   bool
+		memory int64
+		cpu    int64
+	}
+	sizes := make([]taskSize, len(waiting))
+	for i, task := range waiting {
+		resources := task.ToHostResources()
+		sizes[i] = taskSize{
+			task:   task,
+			aged:   policy.aged(task),
+			memory: integerResource(resources[MEMORY]),
+			cpu:    integerResource(resources[CPU]),
+		}
+	}
+	sort.SliceStable(sizes, func(i, j int) bool {
+		if sizes[i].aged || sizes[j].aged {
+			return sizes[i].aged && !sizes[j].aged
+		}
+		if sizes[i].memory != sizes[j].memory {
+			return sizes[i].memory > sizes[j].memory
+		}
+		return sizes[i].cpu > sizes[j].cpu
+	})
+	ordered := make([]*managedTask, len(sizes))
+	for i, size := range sizes {
+		ordered[i] = size.task
+	}
+	return ordered
+}
+
+func (policy *bestFitAdmissionPolicy) backfills(blocking *managedTask) bool {
+	return !policy.aged(blocking)
+}
+
+func (policy *bestFitAdmissionPolicy) queued(task *managedTask) {}
+
+func (policy *bestFitAdmissionPolicy) dequeued(task *managedTask) {}
+
+// aged returns whether the task has waited for host resources for longer than maxWait.
+func (policy *bestFitAdmissionPolicy) aged(task *managedTask) bool {
+	return policy.maxWait > 0 && !task.queuedAt.IsZero() && policy.now().Sub(task.queuedAt) > policy.maxWait
+}
+
+// priorityAdmissionPolicy admits the tasks with the highest priority first, and the tasks with the
+// same priority in the order they were queued in. The priority of a task is set by a task tag, or
+// else by its task definition family. The tags of a task are got in the background once it is
+// queued, retried with a backoff while it waits, so that a slow or throttled ECS API doesn't stall
+// the admission. The family priority is used until the tags are got.
+type priorityAdmissionPolicy struct {
+	tag              string
+	familyPriorities map[string]int
+	taskTags         taskTagsGetter
+	// onResolved is called when the priority of a waiting task is resolved from its tags, so
+	// that the waiting tasks are ordered again
+	onResolved func()
+	newBackoff func() retry.Backoff
+
+	lock sync.Mutex
+	// waiting are the ARNs of the waiting tasks
+	waiting map[string]bool
+	// priorities are the priorities of the waiting tasks that were resolved from their tags
+	priorities map[string]int
+}
+
+func (policy *priorityAdmissionPolicy) order(waiting []*managedTask) []*managedTask {
+	priorities := make(map[*managedTask]int, len(waiting))
+	for _, task := range waiting {
+		priorities[task] = policy.priority(task)
+	}
+	ordered := append([]*managedTask(nil), waiting...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return priorities[ordered[i]] > priorities[ordered[j]]
+	})
+	return ordered
+}
+
+func (policy *priorityAdmissionPolicy) backfills(blocking *managedTask) bool {
+	return false
+}
+
+func (policy *priorityAdmissionPolicy) queued(task *managedTask) {
+	if policy.tag == "" || policy.taskTags == nil {
+		return
+	}
+	policy.lock.Lock()
+	policy.waiting[task.Arn] = true
+	policy.lock.Unlock()
+	go policy.resolvePriority(task.Arn)
+}
+
+func (policy *priorityAdmissionPolicy) dequeued(task *managedTask) {
+	policy.lock.Lock()
+	defer policy.lock.Unlock()
+	delete(policy.waiting, task.Arn)
+	delete(policy.priorities, task.Arn)
+}
+
+// priority returns the priority of a task resolved from its tags, or else the priority of its family.
+func (policy *priorityAdmissionPolicy) priority(task *managedTask) int {
+	policy.lock.Lock()
+	defer policy.lock.Unlock()
+	if priority, ok := policy.priorities[task.Arn]; ok {
+		return priority
+	}
+	return policy.familyPriorities[task.Family]
+}
+
+// resolvePriority gets the tags of a task until it succeeds or the task is no longer waiting, and
+// records the priority set by the priority tag, if any.
+func (policy *priorityAdmissionPolicy) resolvePriority(taskARN string) {
+	backoff := policy.newBackoff()
+	for {
+		tags, err := policy.taskTags(taskARN)
+		if err == nil {
+			policy.setPriority(taskARN, tags)
+			return
+		}
+		retryIn := backoff.Duration()
+		logger.Warn("Unable to get the task tags for its admission priority, using the priority of its family", logger.Fields{
+			field.TaskARN: taskARN,
+			field.Error:   err,
+			"retryIn":     retryIn.String(),
+		})
+		time.Sleep(retryIn)
+		policy.lock.Lock()
+		waiting := policy.waiting[taskARN]
+		policy.lock.Unlock()
+		if !waiting {
+			return
+		}
+	}
+}
+
+// setPriority records the priority set by the priority tag of a waiting task.
+func (policy *priorityAdmissionPolicy) setPriority(taskARN string, tags []*ecs.Tag) {
+	for _, tag := range tags {
+		if aws.StringValue(tag.Key) != policy.tag {
+			continue
+		}
+		priority, err := strconv.Atoi(aws.StringValue(tag.Value))
+		if err != nil {
+			logger.Warn("Invalid task admission priority tag value, using the priority of its family", logger.Fields{
+				field.TaskARN: taskARN,
+				field.Error:   err,
+			})
+			return
+		}
+		policy.lock.Lock()
+		waiting := policy.waiting[taskARN]
+		if waiting {
+			policy.priorities[taskARN] = priority
+		}
+		policy.lock.Unlock()
+		if waiting && policy.onResolved != nil {
+			policy.onResolved()
+		}
+		return
+	}
+}
+
+func integerResource(resource *ecs.Resource) int64 {
+	if resource == nil {
+		return 0
+	}
+	return aws.Int64Value(resource.IntegerValue)
+}
@@ -0,0 +1,255 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/engine/testdata"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/ecs_client/model/ecs"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/retry"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestWaitingTask(engine *DockerTaskEngine, arn string, memory int64) *managedTask {
+	task := testdata.LoadTask("sleep5")
+	task.Arn = arn
+	task.Family = "family-" + arn
+	task.CPU = 0.25
+	task.Memory = memory
+	return &managedTask{
+		Task:                      task,
+		engine:                    engine,
+		consumedHostResourceEvent: make(chan struct{}, 1),
+	}
+}
+
+func taskArns(tasks []*managedTask) []string {
+	arns := make([]string, len(tasks))
+	for i, task := range tasks {
+		arns[i] = task.Arn
+	}
+	return arns
+}
+
+func TestNewTaskAdmissionPolicy(t *testing.T) {
+	assert.IsType(t, &fifoAdmissionPolicy{}, newTaskAdmissionPolicy(&config.Config{}, nil, nil))
+	assert.IsType(t, &fifoAdmissionPolicy{}, newTaskAdmissionPolicy(&config.Config{
+		TaskAdmissionPolicy: config.TaskAdmissionPolicyFIFO,
+	}, nil, nil))
+	assert.IsType(t, &bestFitAdmissionPolicy{}, newTaskAdmissionPolicy(&config.Config{
+		TaskAdmissionPolicy: config.TaskAdmissionPolicyBestFit,
+	}, nil, nil))
+	assert.IsType(t, &priorityAdmissionPolicy{}, newTaskAdmissionPolicy(&config.Config{
+		TaskAdmissionPolicy: config.TaskAdmissionPolicyPriority,
+	}, nil, nil))
+}
+
+func TestTaskAdmissionPolicyOrder(t *testing.T) {
+	small := newTestWaitingTask(nil, "small", 256)
+	large := newTestWaitingTask(nil, "large", 2048)
+	medium := newTestWaitingTask(nil, "medium", 512)
+	tagged := newTestWaitingTask(nil, "tagged", 256)
+	waiting := []*managedTask{small, large, medium, tagged}
+
+	assert.Equal(t, []string{"small", "large", "medium", "tagged"},
+		taskArns((&fifoAdmissionPolicy{}).order(waiting)))
+	assert.Equal(t, []string{"large", "medium", "small", "tagged"},
+		taskArns((&bestFitAdmissionPolicy{now: time.Now}).order(waiting)))
+
+	priorityPolicy := &priorityAdmissionPolicy{
+		familyPriorities: map[string]int{"family-medium": 1, "family-large": 2},
+	}
+	assert.Equal(t, []string{"large", "medium", "small", "tagged"}, taskArns(priorityPolicy.order(waiting)))
+	assert.Equal(t, []string{"small", "large", "medium", "tagged"}, taskArns(waiting), "the queue is not reordered")
+}
+
+func TestPriorityAdmissionPolicyTaskTags(t *testing.T) {
+	small := newTestWaitingTask(nil, "small", 256)
+	large := newTestWaitingTask(nil, "large", 2048)
+	medium := newTestWaitingTask(nil, "medium", 512)
+	tagged := newTestWaitingTask(nil, "tagged", 256)
+	waiting := []*managedTask{small, large, medium, tagged}
+
+	var lock sync.Mutex
+	tagCalls := make(map[string]int)
+	taskTags := func(taskARN string) ([]*ecs.Tag, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		tagCalls[taskARN]++
+		switch {
+		case taskARN == "tagged" && tagCalls[taskARN] > 2:
+			return []*ecs.Tag{
+				{Key: aws.String("team"), Value: aws.String("payments")},
+				{Key: aws.String("priority"), Value: aws.String("5")},
+			}, nil
+		case taskARN == "tagged" || taskARN == "medium":
+			return nil, errors.New("throttled")
+		}
+		return nil, nil
+	}
+	getTagCalls := func(taskARN string) int {
+		lock.Lock()
+		defer lock.Unlock()
+		return tagCalls[taskARN]
+	}
+	resolved := make(chan struct{}, 1)
+	policy := newTaskAdmissionPolicy(&config.Config{
+		TaskAdmissionPolicy:           config.TaskAdmissionPolicyPriority,
+		TaskAdmissionPriorityTag:      "priority",
+		TaskAdmissionFamilyPriorities: map[string]int{"family-medium": 1, "family-large": 2},
+	}, taskTags, func() { resolved <- struct{}{} }).(*priorityAdmissionPolicy)
+	policy.newBackoff = func() retry.Backoff {
+		return retry.NewExponentialBackoff(time.Millisecond, time.Millisecond, 0, 1)
+	}
+
+	for _, task := range waiting {
+		policy.queued(task)
+	}
+	// the family priorities are used until the tags are got
+	select {
+	case <-resolved:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "the priority of the tagged task was not resolved")
+	}
+	assert.Equal(t, []string{"tagged", "large", "medium", "small"}, taskArns(policy.order(waiting)))
+	assert.Equal(t, 3, getTagCalls("tagged"), "the tags are got again after a failure")
+	assert.Equal(t, 1, getTagCalls("small"), "the tags are only got once")
+
+	// the tags of a task are no longer got once it stops waiting
+	policy.dequeued(medium)
+	policy.dequeued(tagged)
+	time.Sleep(10 * time.Millisecond)
+	calls := getTagCalls("medium")
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, calls, getTagCalls("medium"))
+	assert.Equal(t, []string{"large", "small"}, taskArns(policy.order([]*managedTask{small, large})))
+	assert.Empty(t, policy.priorities, "the priorities of the tasks that stopped waiting are dropped")
+}
+
+func TestBestFitAdmissionPolicyMaxWait(t *testing.T) {
+	now := time.Now()
+	small := newTestWaitingTask(nil, "small", 256)
+	small.queuedAt = now.Add(-time.Minute)
+	large := newTestWaitingTask(nil, "large", 2048)
+	large.queuedAt = now.Add(-time.Hour)
+	medium := newTestWaitingTask(nil, "medium", 512)
+	medium.queuedAt = now.Add(-2 * time.Hour)
+	waiting := []*managedTask{medium, large, small}
+
+	policy := &bestFitAdmissionPolicy{
+		maxWait: 3 * time.Hour,
+		now:     func() time.Time { return now },
+	}
+	assert.Equal(t, []string{"large", "medium", "small"}, taskArns(policy.order(waiting)))
+	assert.True(t, policy.backfills(large))
+
+	policy.maxWait = 30 * time.Minute
+	assert.Equal(t, []string{"medium", "large", "small"}, taskArns(policy.order(waiting)),
+		"the tasks that waited for longer than the max wait go first, in the order they were queued in")
+	assert.False(t, policy.backfills(large))
+	assert.True(t, policy.backfills(small))
+}
+
+func TestAdmitWaitingTask(t *testing.T) {
+	testCases := []struct {
+		policy            taskAdmissionPolicy
+		expectedAdmitted  []string
+		expectedPositions map[string]int
+		expectedReasons   map[string]string
+	}{
+		{
+			policy:            &fifoAdmissionPolicy{},
+			expectedAdmitted:  []string{"small0"},
+			expectedPositions: map[string]int{"large": 1, "small1": 2},
+			expectedReasons: map[string]string{
+				"large":  fmt.Sprintf(waitReasonInsufficientResources, MEMORY),
+				"small1": fmt.Sprintf(waitReasonQueuedBehind, "large"),
+			},
+		},
+		{
+			policy:            &bestFitAdmissionPolicy{maxWait: time.Hour, now: time.Now},
+			expectedAdmitted:  []string{"small0", "small1"},
+			expectedPositions: map[string]int{"large": 1},
+			expectedReasons: map[string]string{
+				"large": fmt.Sprintf(waitReasonInsufficientResources, MEMORY),
+			},
+		},
+		{
+			// the large task has waited for longer than the max wait, so nothing is backfilled behind it
+			policy: &bestFitAdmissionPolicy{
+				maxWait: time.Hour,
+				now:     func() time.Time { return time.Now().Add(2 * time.Hour) },
+			},
+			expectedAdmitted:  []string{"small0"},
+			expectedPositions: map[string]int{"large": 1, "small1": 2},
+			expectedReasons: map[string]string{
+				"large":  fmt.Sprintf(waitReasonInsufficientResources, MEMORY),
+				"small1": fmt.Sprintf(waitReasonQueuedBehind, "large"),
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%T", tc.policy), func(t *testing.T) {
+			// 1024 MiB of memory available on host
+			hostResourceManager := NewHostResourceManager(getTestHostResources())
+			taskEngine := &DockerTaskEngine{
+				managedTasks:           make(map[string]*managedTask),
+				monitorQueuedTaskEvent: make(chan struct{}, 1),
+				hostResourceManager:    &hostResourceManager,
+				admissionPolicy:        tc.policy,
+			}
+			tasks := []*managedTask{
+				newTestWaitingTask(taskEngine, "small0", 256),
+				newTestWaitingTask(taskEngine, "large", 2048),
+				newTestWaitingTask(taskEngine, "small1", 256),
+			}
+			for _, task := range tasks {
+				taskEngine.enqueueTask(task)
+			}
+			position, reason := tasks[2].GetQueueStatus()
+			assert.Equal(t, 3, position)
+			assert.Equal(t, waitReasonQueued, reason)
+
+			for taskEngine.admitWaitingTask() {
+			}
+
+			var admitted []string
+			for _, task := range tasks {
+				position, reason := task.GetQueueStatus()
+				select {
+				case <-task.consumedHostResourceEvent:
+					admitted = append(admitted, task.Arn)
+					assert.Zero(t, position)
+					assert.Empty(t, reason)
+				default:
+					assert.Equal(t, tc.expectedPositions[task.Arn], position, task.Arn)
+					assert.Equal(t, tc.expectedReasons[task.Arn], reason, task.Arn)
+				}
+			}
+			assert.Equal(t, tc.expectedAdmitted, admitted)
+			assert.Len(t, taskEngine.waitingTasks(), len(tasks)-len(tc.expectedAdmitted))
+		})
+	}
+}
@@ -15,6 +15,7 @@ package engine
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"path/filepath"
@@ -36,6 +37,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
 	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
 	taskresourcevolume "github.com/aws/amazon-ecs-agent/agent/taskresource/volume"
+	"github.com/aws/amazon-ecs-agent/agent/tracing"
 	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
 	apierrors "github.com/aws/amazon-ecs-agent/ecs-agent/api/errors"
 	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
@@ -71,6 +73,9 @@ var (
 type dockerContainerChange struct {
 	container *apicontainer.Container
 	event     dockerapi.DockerContainerChangeEvent
+	// restartResult is set on the change reporting the result of an in place restart of the
+	// container: a RUNNING event when it was restarted, else the STOPPED event it exited with.
+	restartResult bool
 }
 
 // resourceStateChange represents the required status change after resource transition
@@ -146,6 +151,10 @@ type managedTask struct {
 	consumedHostResourceEvent  chan struct{}
 	containerChangeEventStream *eventstream.EventStream
 
+	// queuedAt is when the task was queued to wait for host resources. It is set when the task
+	// is added to the waiting task queue, and read by the admission policy.
+	queuedAt time.Time
+
 	// unexpectedStart is a once that controls stopping a container that
 	// unexpectedly started one time.
 	// This exists because a 'start' after a container is meant to be stopped is
@@ -155,6 +164,11 @@ type managedTask struct {
 	// thing managing the container.
 	unexpectedStart sync.Once
 
+	// restartingContainers are the containers being restarted in place, keyed by name. The value
+	// is the STOPPED change received while the restart was in flight, if any. It is only
+	// accessed from the managed task goroutine.
+	restartingContainers map[string]*dockerContainerChange
+
 	_time     ttime.Time
 	_timeOnce sync.Once
 
@@ -204,6 +218,14 @@ func (mtask *managedTask) overseeTask() {
 	// not present on the backend
 	mtask.UpdateStatus()
 
+	// Trace the task until it is running or stopped
+	if mtask.GetKnownStatus() < apitaskstatus.TaskRunning {
+		tracing.StartTaskSpan(mtask.Arn,
+			tracing.String(tracing.AttributeTaskFamily, mtask.Family),
+			tracing.String(tracing.AttributeTaskRevision, mtask.Version),
+			tracing.String(tracing.AttributeNetworkMode, mtask.NetworkMode))
+	}
+
 	// Wait here until enough resources are available on host for the task to progress
 	// - Waits until host resource manager succesfully 'consume's task resources and returns
 	// - For tasks which have crossed this stage before (on agent restarts), resources are pre-consumed - returns immediately
@@ -297,6 +319,8 @@ func (mtask *managedTask) waitForHostResources() {
 		// to consume resources in host resource manager
 		return
 	}
+	span := tracing.StartSpan(mtask.Arn, tracing.SpanWaitForHostResources)
+	defer span.End(nil)
 
 	if !mtask.IsLaunchTypeFargate() && !mtask.IsInternal && !mtask.engine.hostResourceManager.checkTaskConsumed(mtask.Arn) {
 		// Internal tasks are started right away as their resources are not accounted for
@@ -427,10 +451,99 @@ func (mtask *managedTask) handleDesiredStatusChange(desiredStatus apitaskstatus.
 	mtask.engine.saveTaskData(mtask.Task)
 }
 
+// restartContainer starts restarting a stopped non-essential container in place when its
+// restart policy allows it. It returns true if the stopped event is handled by the restart, in
+// which case the container stays known RUNNING until the result of the restart is reported.
+func (mtask *managedTask) restartContainer(containerChange dockerContainerChange) bool {
+	container := containerChange.container
+	if _, ok := mtask.restartingContainers[container.Name]; ok {
+		// the container exited again while it was being restarted, the event is handled once the
+		// result of the restart is reported
+		mtask.restartingContainers[container.Name] = &containerChange
+		return true
+	}
+	if !container.RestartPolicyEnabled() || mtask.GetDesiredStatus().Terminal() {
+		return false
+	}
+	exitCode := containerChange.event.DockerContainerMetadata.ExitCode
+	now := mtask.time().Now()
+	fields := logger.Fields{
+		field.TaskID:    mtask.GetID(),
+		field.Container: container.Name,
+		field.RuntimeID: container.GetRuntimeID(),
+	}
+	if exitCode != nil {
+		fields[field.ContainerExitCode] = *exitCode
+	}
+	restart, reason := container.RestartTracker.ShouldRestart(container.RestartPolicy, exitCode,
+		container.GetStartedAt(), container.GetDesiredStatus(), now)
+	if !restart {
+		logger.Info("Not restarting stopped container", fields, logger.Fields{
+			field.Reason: reason,
+		})
+		return false
+	}
+
+	logger.Info("Restarting stopped container in place", fields, logger.Fields{
+		"restartCount": container.RestartTracker.GetRestartCount(),
+	})
+	// The attempt is recorded before the restart, so that a container that can't be restarted
+	// is not restarted again before the restart attempt period.
+	container.RestartTracker.RecordRestart(exitCode, now)
+	mtask.engine.saveContainerData(container)
+	if mtask.restartingContainers == nil {
+		mtask.restartingContainers = make(map[string]*dockerContainerChange)
+	}
+	mtask.restartingContainers[container.Name] = nil
+	go mtask.startRestartedContainer(containerChange, fields)
+	return true
+}
+
+// startRestartedContainer starts the stopped container, and reports the result to the managed
+// task as a container change.
+func (mtask *managedTask) startRestartedContainer(stopped dockerContainerChange, fields logger.Fields) {
+	result := stopped
+	result.restartResult = true
+	metadata := mtask.engine.startContainer(mtask.Task, stopped.container)
+	if metadata.Error != nil {
+		logger.Error("Unable to restart stopped container", fields, logger.Fields{
+			field.Error: metadata.Error,
+		})
+	} else {
+		result.event = dockerapi.DockerContainerChangeEvent{
+			Status:                  apicontainerstatus.ContainerRunning,
+			DockerContainerMetadata: metadata,
+		}
+	}
+	mtask.emitDockerContainerChange(result)
+}
+
+// handleContainerRestartResult handles the result of an in place restart of a container. The
+// container is stopped when it could not be restarted or it exited again during the restart.
+func (mtask *managedTask) handleContainerRestartResult(result dockerContainerChange) {
+	container := result.container
+	stopped := mtask.restartingContainers[container.Name]
+	delete(mtask.restartingContainers, container.Name)
+	if result.event.Status == apicontainerstatus.ContainerRunning {
+		updateContainerMetadata(&result.event.DockerContainerMetadata, container, mtask.Task)
+		mtask.engine.saveContainerData(container)
+		if stopped == nil {
+			return
+		}
+		result = *stopped
+	}
+	result.restartResult = false
+	mtask.handleContainerChange(result)
+}
+
 // handleContainerChange updates a container's known status. If the message
 // contains any interesting information (like exit codes or ports), they are
 // propagated.
 func (mtask *managedTask) handleContainerChange(containerChange dockerContainerChange) {
+	if containerChange.restartResult {
+		mtask.handleContainerRestartResult(containerChange)
+		return
+	}
 	// locate the container
 	container := containerChange.container
 	runtimeID := container.GetRuntimeID()
@@ -471,6 +584,10 @@ func (mtask *managedTask) handleContainerChange(containerChange dockerContainerC
 		return
 	}
 
+	if event.Status == apicontainerstatus.ContainerStopped && mtask.restartContainer(containerChange) {
+		return
+	}
+
 	// Container has progressed its status if we reach here. Make sure to save it to database.
 	defer mtask.engine.saveContainerData(container)
 
@@ -602,6 +719,13 @@ func getContainerEventLogFields(c api.ContainerStateChange) logger.Fields {
 
 func (mtask *managedTask) emitTaskEvent(task *apitask.Task, reason string) {
 	taskKnownStatus := task.GetKnownStatus()
+	if taskKnownStatus >= apitaskstatus.TaskRunning {
+		var err error
+		if taskKnownStatus.Terminal() && reason != "" {
+			err = errors.New(reason)
+		}
+		tracing.EndTaskSpan(task.Arn, taskKnownStatus.String(), err)
+	}
 	// Always do (idempotent) release host resources whenever state change with
 	// known status == STOPPED is done to ensure sync between tasks and host resource manager
 	if taskKnownStatus.Terminal() {
@@ -1234,7 +1358,11 @@ func (mtask *managedTask) applyResourceState(resource taskresource.TaskResource,
 	nextState resourcestatus.ResourceStatus) error {
 	resName := resource.GetName()
 	resStatus := resource.StatusString(nextState)
+	span := tracing.StartSpan(mtask.Arn, tracing.SpanResourceTransition,
+		tracing.String(tracing.AttributeResourceName, resName),
+		tracing.String(tracing.AttributeResourceStatus, resStatus))
 	err := resource.ApplyTransition(nextState)
+	span.End(err)
 	if err != nil {
 		logger.Info("Error transitioning resource", logger.Fields{
 			field.TaskID:       mtask.GetID(),
@@ -1729,6 +1729,120 @@ func TestHandleContainerChangeUpdateContainerHealth(t *testing.T) {
 	assert.Equal(t, "health check succeed", containerHealth.Output)
 }
 
+func TestHandleContainerChangeRestartPolicy(t *testing.T) {
+	testCases := []struct {
+		name            string
+		exitCode        int
+		startFails      bool
+		exitsAgain      bool
+		expectedStatus  apicontainerstatus.ContainerStatus
+		expectedRestart int
+	}{
+		{
+			name:            "restarted on failure",
+			exitCode:        1,
+			expectedStatus:  apicontainerstatus.ContainerRunning,
+			expectedRestart: 1,
+		},
+		{
+			name:           "not restarted on ignored exit code",
+			exitCode:       0,
+			expectedStatus: apicontainerstatus.ContainerStopped,
+		},
+		{
+			name:            "stopped when restart fails",
+			exitCode:        1,
+			startFails:      true,
+			expectedStatus:  apicontainerstatus.ContainerStopped,
+			expectedRestart: 1,
+		},
+		{
+			name:            "stopped when exiting again during restart",
+			exitCode:        1,
+			exitsAgain:      true,
+			expectedStatus:  apicontainerstatus.ContainerStopped,
+			expectedRestart: 1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			containerChangeEventStream := eventstream.NewEventStream(t.Name(), ctx)
+			containerChangeEventStream.StartListening()
+			mockClient := mock_dockerapi.NewMockDockerClient(ctrl)
+			hostResourceManager := NewHostResourceManager(getTestHostResources())
+
+			mTask := &managedTask{
+				Task:                       testdata.LoadTask("sleep5TaskCgroup"),
+				containerChangeEventStream: containerChangeEventStream,
+				stateChangeEvents:          make(chan statechange.Event),
+				dockerMessages:             make(chan dockerContainerChange, 1),
+				ctx:                        ctx,
+				engine: &DockerTaskEngine{
+					ctx:                 ctx,
+					cfg:                 &config.Config{},
+					client:              mockClient,
+					dataClient:          data.NewNoopClient(),
+					hostResourceManager: &hostResourceManager,
+				},
+			}
+			defer discardEvents(mTask.stateChangeEvents)()
+
+			mTask.SetKnownStatus(apitaskstatus.TaskRunning)
+			mTask.SetSentStatus(apitaskstatus.TaskRunning)
+			container := mTask.Containers[0]
+			container.SetRuntimeID("dockerID")
+			container.SetKnownStatus(apicontainerstatus.ContainerRunning)
+			container.RestartPolicy = &apicontainer.RestartPolicy{
+				IgnoredExitCodes:     []int{0},
+				RestartAttemptPeriod: time.Minute,
+			}
+			container.RestartTracker = apicontainer.NewRestartTracker()
+			container.SetStartedAt(time.Now().Add(-2 * time.Minute))
+
+			if tc.exitCode != 0 {
+				startMetadata := dockerapi.DockerContainerMetadata{DockerID: "dockerID"}
+				if tc.startFails {
+					startMetadata.Error = dockerapi.CannotStartContainerError{FromError: errors.New("start failed")}
+				}
+				mockClient.EXPECT().StartContainer(gomock.Any(), "dockerID", gomock.Any()).Return(startMetadata)
+			}
+
+			exitCode := tc.exitCode
+			stopped := dockerContainerChange{
+				container: container,
+				event: dockerapi.DockerContainerChangeEvent{
+					Status: apicontainerstatus.ContainerStopped,
+					DockerContainerMetadata: dockerapi.DockerContainerMetadata{
+						DockerID: "dockerID",
+						ExitCode: &exitCode,
+					},
+				},
+			}
+			mTask.handleContainerChange(stopped)
+			if tc.exitCode != 0 {
+				// the restart runs in the background and reports its result to the managed task
+				assert.Equal(t, apicontainerstatus.ContainerRunning, container.GetKnownStatus())
+				assert.Contains(t, mTask.restartingContainers, container.Name)
+				if tc.exitsAgain {
+					mTask.handleContainerChange(stopped)
+					assert.Equal(t, apicontainerstatus.ContainerRunning, container.GetKnownStatus())
+				}
+				result := <-mTask.dockerMessages
+				assert.True(t, result.restartResult)
+				mTask.handleContainerChange(result)
+				assert.NotContains(t, mTask.restartingContainers, container.Name)
+			}
+
+			assert.Equal(t, tc.expectedStatus, container.GetKnownStatus())
+			assert.Equal(t, tc.expectedRestart, container.RestartTracker.GetRestartCount())
+		})
+	}
+}
+
 func TestHandleContainerChangeUpdateMetadataRedundant(t *testing.T) {
 	eventStreamName := "TestHandleContainerChangeUpdateContainerHealth"
 	ctx, cancel := context.WithCancel(context.Background())
@@ -20,13 +20,14 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/api"
 	"github.com/aws/amazon-ecs-agent/agent/engine"
 	"github.com/aws/amazon-ecs-agent/agent/statechange"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/eventstream"
 	"github.com/cihub/seelog"
 )
 
 // HandleEngineEvents handles state change events from the state change event channel by sending it to
-// responsible event handler
+// responsible event handler. Every event is also written to eventStream, if not nil, for local consumers.
 func HandleEngineEvents(ctx context.Context, taskEngine engine.TaskEngine, client api.ECSClient,
-	taskHandler *TaskHandler, attachmentEventHandler *AttachmentEventHandler) {
+	taskHandler *TaskHandler, attachmentEventHandler *AttachmentEventHandler, eventStream *eventstream.EventStream) {
 
 	for {
 		stateChangeEvents := taskEngine.StateChangeEvents()
@@ -46,6 +47,11 @@ func HandleEngineEvents(ctx context.Context, taskEngine engine.TaskEngine, clien
 				if err != nil {
 					seelog.Errorf("Handler unable to add state change event %v: %v", event, err)
 				}
+				if eventStream != nil {
+					if err := eventStream.WriteToEventStream(event); err != nil {
+						seelog.Debugf("Unable to write state change event to event stream: %v", err)
+					}
+				}
 			}
 		}
 	}
@@ -0,0 +1,129 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package handlers
+
+import (
+	"net"
+	"net/http"
+
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
+	"github.com/pkg/errors"
+)
+
+// credentialsSourceVerifier implements tmdsv1.RequestSourceVerifier. It attributes the
+// source address of a credentials request to a task by matching it against the task's
+// awsvpc ENI and local addresses and the IP addresses of its containers.
+type credentialsSourceVerifier struct {
+	state dockerstate.TaskEngineState
+}
+
+func newCredentialsSourceVerifier(state dockerstate.TaskEngineState) *credentialsSourceVerifier {
+	return &credentialsSourceVerifier{
+		state: state,
+	}
+}
+
+// VerifyRequestSource returns an error unless the request originates from the task
+// with the given ARN.
+func (v *credentialsSourceVerifier) VerifyRequestSource(r *http.Request, taskARN string) error {
+	sourceIP, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return errors.Wrapf(err, "unable to parse request's ip address")
+	}
+
+	task, ok := v.state.TaskByArn(taskARN)
+	if !ok {
+		return errors.Errorf("unable to find task %s", taskARN)
+	}
+	if v.taskOwnsAddress(task, sourceIP) {
+		return nil
+	}
+
+	// Containers of a task in host network mode send requests from one of the host's
+	// addresses, which cannot be attributed to the task. Only reject such requests if
+	// the source address belongs to another task.
+	if task.IsNetworkModeHost() {
+		if ownerARN, ok := v.taskARNByAddress(sourceIP); ok {
+			return errors.Errorf("request source %s belongs to task %s", sourceIP, ownerARN)
+		}
+		return nil
+	}
+
+	return errors.Errorf("request source %s does not belong to task %s", sourceIP, taskARN)
+}
+
+// taskARNByAddress returns the ARN of the task that the address belongs to.
+func (v *credentialsSourceVerifier) taskARNByAddress(addr string) (string, bool) {
+	if taskARN, ok := v.state.GetTaskByIPAddress(addr); ok {
+		return taskARN, true
+	}
+	for _, task := range v.state.AllTasks() {
+		if v.taskOwnsAddress(task, addr) {
+			return task.Arn, true
+		}
+	}
+	return "", false
+}
+
+// taskOwnsAddress returns true if the address is assigned to the task or one of its
+// containers.
+func (v *credentialsSourceVerifier) taskOwnsAddress(task *apitask.Task, addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, taskAddr := range v.taskAddresses(task) {
+		if ip.Equal(net.ParseIP(taskAddr)) {
+			return true
+		}
+	}
+	return false
+}
+
+// taskAddresses returns the IP addresses known for the task: the local address
+// assigned to an awsvpc task, the addresses of its ENIs and the addresses Docker
+// reported for its containers.
+func (v *credentialsSourceVerifier) taskAddresses(task *apitask.Task) []string {
+	var addrs []string
+	if localIP := task.GetLocalIPAddress(); localIP != "" {
+		addrs = append(addrs, localIP)
+	}
+	if localIP, ok := v.state.GetIPAddressByTaskARN(task.Arn); ok {
+		addrs = append(addrs, localIP)
+	}
+	for _, eni := range task.GetTaskENIs() {
+		addrs = append(addrs, eni.GetIPV4Addresses()...)
+		addrs = append(addrs, eni.GetIPV6Addresses()...)
+	}
+
+	containers, ok := v.state.ContainerMapByArn(task.Arn)
+	if !ok {
+		return addrs
+	}
+	for _, dockerContainer := range containers {
+		settings := dockerContainer.Container.GetNetworkSettings()
+		if settings == nil {
+			continue
+		}
+		addrs = append(addrs, settings.IPAddress, settings.GlobalIPv6Address)
+		for _, network := range settings.Networks {
+			if network == nil {
+				continue
+			}
+			addrs = append(addrs, network.IPAddress, network.GlobalIPv6Address)
+		}
+	}
+	return addrs
+}
@@ -0,0 +1,156 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
+	ni "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/network"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	awsvpcTaskARN = "arn:aws:ecs:us-west-2:123456789012:task/cluster/awsvpc"
+	bridgeTaskARN = "arn:aws:ecs:us-west-2:123456789012:task/cluster/bridge"
+	hostTaskARN   = "arn:aws:ecs:us-west-2:123456789012:task/cluster/host"
+)
+
+func newCredentialsSourceVerifierTestState() dockerstate.TaskEngineState {
+	state := dockerstate.NewTaskEngineState()
+
+	awsvpcTask := &apitask.Task{Arn: awsvpcTaskARN, NetworkMode: apitask.AWSVPCNetworkMode}
+	awsvpcTask.AddTaskENI(&ni.NetworkInterface{
+		IPV4Addresses: []*ni.IPV4Address{{Address: "10.0.0.10", Primary: true}},
+		IPV6Addresses: []*ni.IPV6Address{{Address: "2001:db8::10"}},
+	})
+	state.AddTask(awsvpcTask)
+	state.AddTaskIPAddress("169.254.172.2", awsvpcTaskARN)
+
+	bridgeTask := &apitask.Task{Arn: bridgeTaskARN, NetworkMode: apitask.BridgeNetworkMode}
+	bridgeContainer := &apicontainer.Container{
+		Name: "app",
+		NetworkSettingsUnsafe: &types.NetworkSettings{
+			DefaultNetworkSettings: types.DefaultNetworkSettings{IPAddress: "172.17.0.2"},
+			Networks: map[string]*network.EndpointSettings{
+				"custom": {IPAddress: "172.18.0.2"},
+			},
+		},
+	}
+	bridgeTask.Containers = []*apicontainer.Container{bridgeContainer}
+	state.AddTask(bridgeTask)
+	state.AddContainer(&apicontainer.DockerContainer{
+		DockerID:   "bridge-docker-id",
+		DockerName: "bridge-docker-name",
+		Container:  bridgeContainer,
+	}, bridgeTask)
+
+	state.AddTask(&apitask.Task{Arn: hostTaskARN, NetworkMode: apitask.HostNetworkMode})
+	return state
+}
+
+func TestCredentialsSourceVerifier(t *testing.T) {
+	testCases := []struct {
+		name       string
+		remoteAddr string
+		taskARN    string
+		expectErr  bool
+	}{
+		{
+			name:       "awsvpc task from ENI address",
+			remoteAddr: "10.0.0.10:51000",
+			taskARN:    awsvpcTaskARN,
+		},
+		{
+			name:       "awsvpc task from ENI IPv6 address",
+			remoteAddr: "[2001:db8:0::10]:51000",
+			taskARN:    awsvpcTaskARN,
+		},
+		{
+			name:       "awsvpc task from local address",
+			remoteAddr: "169.254.172.2:51000",
+			taskARN:    awsvpcTaskARN,
+		},
+		{
+			name:       "bridge task from container address",
+			remoteAddr: "172.17.0.2:51000",
+			taskARN:    bridgeTaskARN,
+		},
+		{
+			name:       "bridge task from container address on user defined network",
+			remoteAddr: "172.18.0.2:51000",
+			taskARN:    bridgeTaskARN,
+		},
+		{
+			name:       "awsvpc task from address of another task",
+			remoteAddr: "172.17.0.2:51000",
+			taskARN:    awsvpcTaskARN,
+			expectErr:  true,
+		},
+		{
+			name:       "bridge task from unknown address",
+			remoteAddr: "172.17.0.99:51000",
+			taskARN:    bridgeTaskARN,
+			expectErr:  true,
+		},
+		{
+			name:       "host task from host address",
+			remoteAddr: "10.0.0.1:51000",
+			taskARN:    hostTaskARN,
+		},
+		{
+			name:       "host task from address of another task",
+			remoteAddr: "169.254.172.2:51000",
+			taskARN:    hostTaskARN,
+			expectErr:  true,
+		},
+		{
+			name:       "unknown task",
+			remoteAddr: "172.17.0.2:51000",
+			taskARN:    "arn:aws:ecs:us-west-2:123456789012:task/cluster/unknown",
+			expectErr:  true,
+		},
+		{
+			name:       "invalid remote address",
+			remoteAddr: "172.17.0.2",
+			taskARN:    bridgeTaskARN,
+			expectErr:  true,
+		},
+	}
+
+	verifier := newCredentialsSourceVerifier(newCredentialsSourceVerifierTestState())
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/v2/credentials/id", nil)
+			require.NoError(t, err)
+			req.RemoteAddr = tc.remoteAddr
+
+			err = verifier.VerifyRequestSource(req, tc.taskARN)
+			if tc.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
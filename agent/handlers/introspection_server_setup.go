@@ -24,8 +24,11 @@ import (
 
 	"github.com/aws/amazon-ecs-agent/agent/config"
 	"github.com/aws/amazon-ecs-agent/agent/engine"
+	dm "github.com/aws/amazon-ecs-agent/agent/engine/daemonmanager"
 	handlersutils "github.com/aws/amazon-ecs-agent/agent/handlers/utils"
 	v1 "github.com/aws/amazon-ecs-agent/agent/handlers/v1"
+	"github.com/aws/amazon-ecs-agent/agent/stats"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/eventstream"
 	logginghandler "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/logging"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/retry"
 	"github.com/cihub/seelog"
@@ -56,8 +59,11 @@ var (
 	pprofTraceHandler   = pprof.Trace
 )
 
-func introspectionServerSetup(containerInstanceArn *string, taskEngine handlersutils.DockerStateResolver, cfg *config.Config) *http.Server {
-	paths := []string{v1.AgentMetadataPath, v1.TaskContainerMetadataPath, v1.LicensePath}
+func introspectionServerSetup(containerInstanceArn *string, taskEngine handlersutils.DockerStateResolver,
+	statsEngine stats.Engine, eventStream *eventstream.EventStream, daemonStatusReporter dm.RestartStatusReporter,
+	cfg *config.Config) *http.Server {
+	paths := []string{v1.AgentMetadataPath, v1.TaskContainerMetadataPath, v1.TaskResourceUsagePath, v1.LicensePath,
+		v1.EventsPath, v1.ManagedDaemonsPath}
 
 	if cfg.EnableRuntimeStats.Enabled() {
 		paths = append(paths, pprofBasePath, pprofCMDLinePath, pprofProfilePath, pprofSymbolPath, pprofTracePath)
@@ -77,7 +83,7 @@ func introspectionServerSetup(containerInstanceArn *string, taskEngine handlersu
 	serverMux := http.NewServeMux()
 	serverMux.HandleFunc("/", defaultHandler)
 
-	v1HandlersSetup(serverMux, containerInstanceArn, taskEngine, cfg)
+	v1HandlersSetup(serverMux, containerInstanceArn, taskEngine, statsEngine, eventStream, daemonStatusReporter, cfg)
 	pprofHandlerSetup(serverMux, cfg)
 
 	// Log all requests and then pass through to serverMux
@@ -102,10 +108,16 @@ func introspectionServerSetup(containerInstanceArn *string, taskEngine handlersu
 func v1HandlersSetup(serverMux *http.ServeMux,
 	containerInstanceArn *string,
 	taskEngine handlersutils.DockerStateResolver,
+	statsEngine stats.Engine,
+	eventStream *eventstream.EventStream,
+	daemonStatusReporter dm.RestartStatusReporter,
 	cfg *config.Config) {
 	serverMux.HandleFunc(v1.AgentMetadataPath, v1.AgentMetadataHandler(containerInstanceArn, cfg))
 	serverMux.HandleFunc(v1.TaskContainerMetadataPath, v1.TaskContainerMetadataHandler(taskEngine))
+	serverMux.HandleFunc(v1.TaskResourceUsagePath, v1.TaskResourceUsageHandler(statsEngine))
 	serverMux.HandleFunc(v1.LicensePath, v1.LicenseHandler)
+	serverMux.HandleFunc(v1.EventsPath, v1.EventsHandler(eventStream, taskEngine))
+	serverMux.HandleFunc(v1.ManagedDaemonsPath, v1.ManagedDaemonsHandler(daemonStatusReporter))
 }
 
 func pprofHandlerSetup(serverMux *http.ServeMux, cfg *config.Config) {
@@ -120,14 +132,16 @@ func pprofHandlerSetup(serverMux *http.ServeMux, cfg *config.Config) {
 }
 
 // ServeIntrospectionHTTPEndpoint serves information about this agent/containerInstance and tasks
-// running on it. "V1" here indicates the hostname version of this server instead
-// of the handler versions, i.e. "V1" server can include "V1" and "V2" handlers.
-func ServeIntrospectionHTTPEndpoint(ctx context.Context, containerInstanceArn *string, taskEngine engine.TaskEngine, cfg *config.Config) {
+// running on it, and streams the events written to eventStream. "V1" here indicates the hostname version
+// of this server instead of the handler versions, i.e. "V1" server can include "V1" and "V2" handlers.
+func ServeIntrospectionHTTPEndpoint(ctx context.Context, containerInstanceArn *string, taskEngine engine.TaskEngine,
+	statsEngine stats.Engine, eventStream *eventstream.EventStream, daemonStatusReporter dm.RestartStatusReporter,
+	cfg *config.Config) {
 	// Is this the right level to type assert, assuming we'd abstract multiple taskengines here?
 	// Revisit if we ever add another type..
 	dockerTaskEngine := taskEngine.(*engine.DockerTaskEngine)
 
-	server := introspectionServerSetup(containerInstanceArn, dockerTaskEngine, cfg)
+	server := introspectionServerSetup(containerInstanceArn, dockerTaskEngine, statsEngine, eventStream, daemonStatusReporter, cfg)
 
 	go func() {
 		<-ctx.Done()
@@ -27,9 +27,11 @@ import (
 	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
 	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
 	"github.com/aws/amazon-ecs-agent/agent/config"
+	dm "github.com/aws/amazon-ecs-agent/agent/engine/daemonmanager"
 	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
 	mock_utils "github.com/aws/amazon-ecs-agent/agent/handlers/mocks"
 	v1 "github.com/aws/amazon-ecs-agent/agent/handlers/v1"
+	mock_stats "github.com/aws/amazon-ecs-agent/agent/stats/mock"
 	"github.com/aws/amazon-ecs-agent/agent/utils"
 	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
 	ni "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
@@ -199,6 +201,82 @@ func TestGetTaskByTaskArnAndDockerIDBadRequest(t *testing.T) {
 	}
 }
 
+func TestGetTaskResourceUsages(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	statsEngine := mock_stats.NewMockEngine(ctrl)
+	statsEngine.EXPECT().GetTaskResourceUsages().Return([]*apitask.ResourceUsage{
+		apitask.NewResourceUsage("taskB", "family", "1"),
+		apitask.NewResourceUsage("taskA", "family", "1"),
+	})
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", v1.TaskResourceUsagePath, nil)
+	v1.TaskResourceUsageHandler(statsEngine)(recorder, req)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var resp v1.TaskResourceUsagesResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+	require.Len(t, resp.Usages, 2)
+	assert.Equal(t, "taskA", resp.Usages[0].TaskARN)
+	assert.Equal(t, "taskB", resp.Usages[1].TaskARN)
+}
+
+func TestGetTaskResourceUsageByTaskArn(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	usage := apitask.NewResourceUsage("taskA", "family", "1")
+	usage.CPUSeconds = 42
+	statsEngine := mock_stats.NewMockEngine(ctrl)
+	statsEngine.EXPECT().GetTaskResourceUsage("taskA").Return(usage, true)
+	statsEngine.EXPECT().GetTaskResourceUsage("taskB").Return(nil, false)
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", v1.TaskResourceUsagePath+"?taskarn=taskA", nil)
+	v1.TaskResourceUsageHandler(statsEngine)(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var resp apitask.ResourceUsage
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+	assert.Equal(t, 42.0, resp.CPUSeconds)
+
+	recorder = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", v1.TaskResourceUsagePath+"?taskarn=taskB", nil)
+	v1.TaskResourceUsageHandler(statsEngine)(recorder, req)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+type fakeRestartStatusReporter []dm.RestartStatus
+
+func (r fakeRestartStatusReporter) GetRestartStatuses() []dm.RestartStatus { return r }
+
+func TestGetManagedDaemons(t *testing.T) {
+	exitCode := 1
+	reporter := fakeRestartStatusReporter{
+		{DaemonName: "ebs-csi-driver"},
+		{DaemonName: "node-exporter", RestartCount: 5, LastExitCode: &exitCode, CrashLooping: true},
+	}
+
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", v1.ManagedDaemonsPath, nil)
+	v1.ManagedDaemonsHandler(reporter)(recorder, req)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var resp v1.ManagedDaemonsResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+	require.Len(t, resp.Daemons, 2)
+	assert.Equal(t, "ebs-csi-driver", resp.Daemons[0].DaemonName)
+	assert.Nil(t, resp.Daemons[0].LastExitCode)
+	assert.Equal(t, 5, resp.Daemons[1].RestartCount)
+	assert.Equal(t, 1, *resp.Daemons[1].LastExitCode)
+	assert.True(t, resp.Daemons[1].CrashLooping)
+
+	// no managed daemons
+	recorder = httptest.NewRecorder()
+	v1.ManagedDaemonsHandler(nil)(recorder, req)
+	assert.Equal(t, `{"Daemons":[]}`, recorder.Body.String())
+}
+
 func TestBackendMismatchMapping(t *testing.T) {
 	// Test that a KnownStatus past a DesiredStatus suppresses the DesiredStatus output
 	ctrl := gomock.NewController(t)
@@ -299,7 +377,7 @@ func TestPProfHandlerSetup(t *testing.T) {
 					assert.Equal(t, p, recorder.Body.String())
 				} else {
 					assert.Equal(t, http.StatusOK, recorder.Code)
-					assert.Equal(t, `{"AvailableCommands":["/v1/metadata","/v1/tasks","/license"]}`, recorder.Body.String())
+					assert.Equal(t, `{"AvailableCommands":["/v1/metadata","/v1/tasks","/v1/tasks/usage","/license","/v1/events","/v1/daemons"]}`, recorder.Body.String())
 
 				}
 			})
@@ -481,7 +559,7 @@ func performMockRequest(t *testing.T, path string) *httptest.ResponseRecorder {
 		mockStateResolver.EXPECT().State().Return(state)
 	}
 
-	requestHandler := introspectionServerSetup(utils.Strptr(testContainerInstanceArn), mockStateResolver, &config.Config{
+	requestHandler := introspectionServerSetup(utils.Strptr(testContainerInstanceArn), mockStateResolver, nil, nil, nil, &config.Config{
 		Cluster:            testClusterArn,
 		EnableRuntimeStats: runtimeStatsConfigForTest,
 	})
@@ -0,0 +1,85 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/retry"
+	"github.com/cihub/seelog"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// TaskMetricsPath is the path of the endpoint that serves the task and container stats
+const TaskMetricsPath = "/metrics"
+
+func taskMetricsServerSetup(exporter prometheus.Collector) (*http.Server, error) {
+	registry := prometheus.NewRegistry()
+	if err := registry.Register(exporter); err != nil {
+		return nil, err
+	}
+
+	serverMux := http.NewServeMux()
+	serverMux.Handle(TaskMetricsPath, promhttp.HandlerFor(registry, promhttp.HandlerOpts{
+		ErrorLog:          seelogErrorLogger{},
+		EnableOpenMetrics: true,
+	}))
+
+	server := &http.Server{
+		Addr:         ":" + strconv.Itoa(config.AgentTaskMetricsExpositionPort),
+		Handler:      serverMux,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+	}
+	return server, nil
+}
+
+// ServeTaskMetricsHTTPEndpoint serves the metrics collected by exporter in the OpenMetrics format, or in the
+// Prometheus text format for scrapers that do not negotiate OpenMetrics.
+func ServeTaskMetricsHTTPEndpoint(ctx context.Context, exporter prometheus.Collector) {
+	server, err := taskMetricsServerSetup(exporter)
+	if err != nil {
+		seelog.Errorf("Unable to set up the task metrics endpoint: %v", err)
+		return
+	}
+
+	go func() {
+		<-ctx.Done()
+		if err := server.Shutdown(context.Background()); err != nil {
+			// Error from closing listeners, or context timeout:
+			seelog.Infof("HTTP server Shutdown: %v", err)
+		}
+	}()
+
+	retry.RetryWithBackoff(retry.NewExponentialBackoff(time.Second, time.Minute, 0.2, 2), func() error {
+		if err := server.ListenAndServe(); err != http.ErrServerClosed {
+			seelog.Errorf("Error running task metrics endpoint: %v", err)
+			return err
+		}
+		// server was cleanly closed via context
+		return nil
+	})
+}
+
+// seelogErrorLogger logs the errors of the promhttp handler with seelog
+type seelogErrorLogger struct{}
+
+func (seelogErrorLogger) Println(v ...interface{}) {
+	seelog.Error(v...)
+}
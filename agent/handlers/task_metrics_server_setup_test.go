@@ -0,0 +1,70 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskMetricsServerSetup(t *testing.T) {
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "ecs_task_containers", Help: "test gauge"})
+	gauge.Set(2)
+	server, err := taskMetricsServerSetup(gauge)
+	require.NoError(t, err)
+	assert.Equal(t, ":"+strconv.Itoa(config.AgentTaskMetricsExpositionPort), server.Addr)
+
+	testCases := []struct {
+		name                string
+		accept              string
+		expectedContentType string
+	}{
+		{
+			name:                "openmetrics",
+			accept:              "application/openmetrics-text; version=0.0.1",
+			expectedContentType: "application/openmetrics-text",
+		},
+		{
+			name:                "prometheus text",
+			accept:              "",
+			expectedContentType: "text/plain",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", TaskMetricsPath, nil)
+			require.NoError(t, err)
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			recorder := httptest.NewRecorder()
+			server.Handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.True(t, strings.HasPrefix(recorder.Header().Get("Content-Type"), tc.expectedContentType))
+			assert.Contains(t, recorder.Body.String(), "ecs_task_containers 2")
+		})
+	}
+}
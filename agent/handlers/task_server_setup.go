@@ -26,6 +26,7 @@ import (
 	v3 "github.com/aws/amazon-ecs-agent/agent/handlers/v3"
 	v4 "github.com/aws/amazon-ecs-agent/agent/handlers/v4"
 	"github.com/aws/amazon-ecs-agent/agent/logger/audit"
+	agentmetrics "github.com/aws/amazon-ecs-agent/agent/metrics"
 	"github.com/aws/amazon-ecs-agent/agent/stats"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
 	auditinterface "github.com/aws/amazon-ecs-agent/ecs-agent/logger/audit"
@@ -56,6 +57,7 @@ const (
 func taskServerSetup(
 	credentialsManager credentials.Manager,
 	auditLogger auditinterface.AuditLogger,
+	credentialsSourceVerifier tmdsv1.RequestSourceVerifier,
 	state dockerstate.TaskEngineState,
 	ecsClient api.ECSClient,
 	cluster string,
@@ -75,19 +77,20 @@ func taskServerSetup(
 	muxRouter.SkipClean(false)
 
 	muxRouter.HandleFunc(tmdsv1.CredentialsPath,
-		tmdsv1.CredentialsHandler(credentialsManager, auditLogger))
+		tmdsv1.CredentialsHandler(credentialsManager, auditLogger, credentialsSourceVerifier))
 
 	tmdsAgentState := v4.NewTMDSAgentState(state, statsEngine, ecsClient, cluster, availabilityZone, vpcID, containerInstanceArn)
-	metricsFactory := metrics.NewNopEntryFactory()
+	metricsFactory := agentmetrics.MetricsEngineGlobal.EntryFactory()
 
-	v2HandlersSetup(muxRouter, state, ecsClient, statsEngine, cluster, credentialsManager, auditLogger, availabilityZone, containerInstanceArn)
+	v2HandlersSetup(muxRouter, state, ecsClient, statsEngine, cluster, credentialsManager, auditLogger,
+		credentialsSourceVerifier, availabilityZone, containerInstanceArn)
 
 	v3HandlersSetup(muxRouter, state, ecsClient, statsEngine, cluster, availabilityZone, containerInstanceArn)
 
 	v4HandlersSetup(muxRouter, state, ecsClient, statsEngine, cluster, availabilityZone, vpcID, containerInstanceArn,
 		tmdsAgentState, metricsFactory)
 
-	agentAPIV1HandlersSetup(muxRouter, state, credentialsManager, cluster, tmdsAgentState,
+	agentAPIV1HandlersSetup(muxRouter, state, credentialsManager, auditLogger, cluster, tmdsAgentState,
 		taskProtectionClientFactory, metricsFactory)
 
 	return tmds.NewServer(auditLogger,
@@ -107,9 +110,11 @@ func v2HandlersSetup(muxRouter *mux.Router,
 	cluster string,
 	credentialsManager credentials.Manager,
 	auditLogger auditinterface.AuditLogger,
+	credentialsSourceVerifier tmdsv1.RequestSourceVerifier,
 	availabilityZone string,
 	containerInstanceArn string) {
-	muxRouter.HandleFunc(tmdsv2.CredentialsPath, tmdsv2.CredentialsHandler(credentialsManager, auditLogger))
+	muxRouter.HandleFunc(tmdsv2.CredentialsPath,
+		tmdsv2.CredentialsHandler(credentialsManager, auditLogger, credentialsSourceVerifier))
 	muxRouter.HandleFunc(v2.ContainerMetadataPath, v2.TaskContainerMetadataHandler(state, ecsClient, cluster, availabilityZone, containerInstanceArn, false))
 	muxRouter.HandleFunc(v2.TaskMetadataPath, v2.TaskContainerMetadataHandler(state, ecsClient, cluster, availabilityZone, containerInstanceArn, false))
 	muxRouter.HandleFunc(v2.TaskWithTagsMetadataPath, v2.TaskContainerMetadataHandler(state, ecsClient, cluster, availabilityZone, containerInstanceArn, true))
@@ -155,6 +160,7 @@ func v4HandlersSetup(muxRouter *mux.Router,
 	muxRouter.HandleFunc(tmdsv4.TaskMetadataWithTagsPath(), tmdsv4.TaskMetadataWithTagsHandler(tmdsAgentState, metricsFactory))
 	muxRouter.HandleFunc(tmdsv4.ContainerStatsPath(), tmdsv4.ContainerStatsHandler(tmdsAgentState, metricsFactory))
 	muxRouter.HandleFunc(tmdsv4.TaskStatsPath(), tmdsv4.TaskStatsHandler(tmdsAgentState, metricsFactory))
+	muxRouter.HandleFunc(v4.TaskResourceUsagePath, v4.TaskResourceUsageHandler(state, statsEngine))
 	muxRouter.HandleFunc(v4.ContainerAssociationsPath, v4.ContainerAssociationsHandler(state))
 	muxRouter.HandleFunc(v4.ContainerAssociationPathWithSlash, v4.ContainerAssociationHandler(state))
 	muxRouter.HandleFunc(v4.ContainerAssociationPath, v4.ContainerAssociationHandler(state))
@@ -165,6 +171,7 @@ func agentAPIV1HandlersSetup(
 	muxRouter *mux.Router,
 	state dockerstate.TaskEngineState,
 	credentialsManager credentials.Manager,
+	auditLogger auditinterface.AuditLogger,
 	cluster string,
 	agentState *v4.TMDSAgentState,
 	factory tp.TaskProtectionClientFactoryInterface,
@@ -174,13 +181,13 @@ func agentAPIV1HandlersSetup(
 		HandleFunc(
 			tp.TaskProtectionPath(),
 			tp.UpdateTaskProtectionHandler(agentState, credentialsManager,
-				factory, cluster, metricsFactory, ecsCallTimeout)).
+				factory, cluster, metricsFactory, ecsCallTimeout, auditLogger)).
 		Methods("PUT")
 	muxRouter.
 		HandleFunc(
 			tp.TaskProtectionPath(),
 			tp.GetTaskProtectionHandler(agentState, credentialsManager,
-				factory, cluster, metricsFactory, ecsCallTimeout)).
+				factory, cluster, metricsFactory, ecsCallTimeout, auditLogger)).
 		Methods("GET")
 }
 
@@ -206,10 +213,15 @@ func ServeTaskHTTPEndpoint(
 
 	auditLogger := audit.NewAuditLog(containerInstanceArn, cfg, logger)
 
+	var credentialsSourceVerifier tmdsv1.RequestSourceVerifier
+	if cfg.TaskCredentialsSourceEnforced {
+		credentialsSourceVerifier = newCredentialsSourceVerifier(state)
+	}
+
 	taskProtectionClientFactory := tpfactory.TaskProtectionClientFactory{
 		Region: cfg.AWSRegion, Endpoint: cfg.APIEndpoint, AcceptInsecureCert: cfg.AcceptInsecureCert,
 	}
-	server, err := taskServerSetup(credentialsManager, auditLogger, state, ecsClient, cfg.Cluster,
+	server, err := taskServerSetup(credentialsManager, auditLogger, credentialsSourceVerifier, state, ecsClient, cfg.Cluster,
 		statsEngine, cfg.TaskMetadataSteadyStateRate, cfg.TaskMetadataBurstRate,
 		availabilityZone, vpcID, containerInstanceArn, taskProtectionClientFactory)
 	if err != nil {
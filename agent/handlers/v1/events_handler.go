@@ -0,0 +1,265 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/api"
+	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
+	"github.com/aws/amazon-ecs-agent/agent/engine/image"
+	"github.com/aws/amazon-ecs-agent/agent/handlers/utils"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/eventstream"
+	commonutils "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/utils"
+	"github.com/cihub/seelog"
+	"github.com/pkg/errors"
+)
+
+const (
+	// EventsPath is the path of the server-sent events stream for v1 handler.
+	EventsPath       = "/v1/events"
+	familyQueryField = "family"
+
+	// EventTypeTaskStateChange is the type of the events emitted when a task changes state
+	EventTypeTaskStateChange = "TaskStateChange"
+	// EventTypeContainerStateChange is the type of the events emitted when a container changes state
+	EventTypeContainerStateChange = "ContainerStateChange"
+	// EventTypeManagedAgentStateChange is the type of the events emitted when a managed agent changes state
+	EventTypeManagedAgentStateChange = "ManagedAgentStateChange"
+	// EventTypeAttachmentStateChange is the type of the events emitted when an ENI attachment changes state
+	EventTypeAttachmentStateChange = "AttachmentStateChange"
+	// EventTypeImageCleanup is the type of the events emitted when an image is removed from the instance
+	EventTypeImageCleanup = "ImageCleanup"
+
+	eventsSubscriberPrefix = "IntrospectionEventsSubscriber"
+	// eventsSubscriberBufferSize is the number of events buffered for a client before new events are dropped
+	eventsSubscriberBufferSize = 256
+	// eventsKeepAliveInterval is the time between two comments written to keep idle connections open
+	eventsKeepAliveInterval = 30 * time.Second
+)
+
+// eventsSubscriberCount is used to generate a unique event stream subscriber name per request
+var eventsSubscriberCount uint64
+
+// EventResponse is the schema for the events of the 'v1/events' stream
+type EventResponse struct {
+	Type          string                `json:"Type"`
+	Timestamp     time.Time             `json:"Timestamp"`
+	TaskARN       string                `json:"TaskARN,omitempty"`
+	Family        string                `json:"Family,omitempty"`
+	ContainerName string                `json:"ContainerName,omitempty"`
+	Name          string                `json:"Name,omitempty"`
+	AttachmentARN string                `json:"AttachmentARN,omitempty"`
+	Status        string                `json:"Status,omitempty"`
+	Reason        string                `json:"Reason,omitempty"`
+	ExitCode      *int                  `json:"ExitCode,omitempty"`
+	Image         *ImageCleanupResponse `json:"Image,omitempty"`
+}
+
+// ImageCleanupResponse is the schema for the image of an ImageCleanup event
+type ImageCleanupResponse struct {
+	ImageID   string   `json:"ImageID"`
+	Names     []string `json:"Names,omitempty"`
+	SizeBytes int64    `json:"SizeBytes"`
+	Trigger   string   `json:"Trigger"`
+	ImageType string   `json:"ImageType"`
+}
+
+// NewEventResponse creates an EventResponse for an event written to the introspection event stream.
+// It returns false for events that are not streamed.
+func NewEventResponse(event interface{}, state dockerstate.TaskEngineState) (*EventResponse, bool) {
+	resp := &EventResponse{Timestamp: time.Now().UTC()}
+	switch e := event.(type) {
+	case api.TaskStateChange:
+		resp.Type = EventTypeTaskStateChange
+		resp.TaskARN = e.TaskARN
+		resp.Status = e.Status.String()
+		resp.Reason = e.Reason
+		if e.Task != nil {
+			resp.Family = e.Task.Family
+		}
+	case api.ContainerStateChange:
+		resp.Type = EventTypeContainerStateChange
+		resp.TaskARN = e.TaskArn
+		resp.ContainerName = e.ContainerName
+		resp.Status = e.Status.String()
+		resp.Reason = e.Reason
+		resp.ExitCode = e.ExitCode
+	case api.ManagedAgentStateChange:
+		resp.Type = EventTypeManagedAgentStateChange
+		resp.TaskARN = e.TaskArn
+		resp.Name = e.Name
+		if e.Container != nil {
+			resp.ContainerName = e.Container.Name
+		}
+		resp.Status = e.Status.String()
+		resp.Reason = e.Reason
+	case api.AttachmentStateChange:
+		if e.Attachment == nil {
+			return nil, false
+		}
+		resp.Type = EventTypeAttachmentStateChange
+		resp.TaskARN = e.Attachment.TaskARN
+		resp.AttachmentARN = e.Attachment.AttachmentARN
+		resp.Status = e.Attachment.Status.String()
+	case image.CleanupEvent:
+		resp.Type = EventTypeImageCleanup
+		resp.Timestamp = e.RemovedAt.UTC()
+		resp.Image = &ImageCleanupResponse{
+			ImageID:   e.ImageID,
+			Names:     e.ImageNames,
+			SizeBytes: e.SizeBytes,
+			Trigger:   e.Trigger,
+			ImageType: e.ImageType,
+		}
+	default:
+		return nil, false
+	}
+	if resp.Family == "" && resp.TaskARN != "" {
+		if task, ok := state.TaskByArn(resp.TaskARN); ok {
+			resp.Family = task.Family
+		}
+	}
+	return resp, true
+}
+
+// matches returns true if the event belongs to the task and family, when they are not empty
+func (resp *EventResponse) matches(taskARN, family string) bool {
+	return (taskARN == "" || resp.TaskARN == taskARN) && (family == "" || resp.Family == family)
+}
+
+// EventsHandler creates the server-sent events stream for the 'v1/events' API. Every task, container,
+// managed agent and ENI attachment state change and every image cleanup written to the event stream is
+// pushed to the client. Events can be filtered with the 'taskarn' and 'family' query fields.
+func EventsHandler(eventStream *eventstream.EventStream, taskEngine utils.DockerStateResolver) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		taskARN, _ := commonutils.ValueFromRequest(r, taskARNQueryField)
+		family, _ := commonutils.ValueFromRequest(r, familyQueryField)
+		state := taskEngine.State()
+
+		stream, err := newEventsStream(w)
+		if err != nil {
+			seelog.Errorf("Unable to start events stream: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		defer stream.close()
+
+		// the ordered subscription guarantees that the events are written to the client in the order
+		// they were written to the event stream, a handler would be called in a goroutine per event
+		subscriberName := fmt.Sprintf("%s-%d", eventsSubscriberPrefix, atomic.AddUint64(&eventsSubscriberCount, 1))
+		events, err := eventStream.SubscribeOrdered(subscriberName, eventsSubscriberBufferSize)
+		if err != nil {
+			seelog.Errorf("Unable to subscribe to the event stream: %v", err)
+			return
+		}
+		defer eventStream.Unsubscribe(subscriberName)
+		seelog.Infof("Events stream subscriber %s connected from %s", subscriberName, r.RemoteAddr)
+
+		// let the client know that it will receive the events from now on
+		err = stream.writeComment("connected")
+		ticker := time.NewTicker(eventsKeepAliveInterval)
+		defer ticker.Stop()
+		var id uint64
+		for err == nil {
+			select {
+			case arg := <-events:
+				event, ok := NewEventResponse(arg, state)
+				if !ok || !event.matches(taskARN, family) {
+					continue
+				}
+				id++
+				err = stream.writeEvent(id, event)
+			case <-ticker.C:
+				err = stream.writeComment("keepalive")
+			case <-stream.done:
+				err = io.EOF
+			case <-eventStream.Context().Done():
+				err = eventStream.Context().Err()
+			}
+		}
+		seelog.Infof("Events stream subscriber %s disconnected: %v", subscriberName, err)
+	}
+}
+
+// eventsStream writes server-sent events on a hijacked connection, so that the stream is not
+// closed by the write timeout of the introspection server.
+type eventsStream struct {
+	conn net.Conn
+	rw   *bufio.ReadWriter
+	// done is closed when the client closes the connection
+	done chan struct{}
+}
+
+func newEventsStream(w http.ResponseWriter) (*eventsStream, error) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, errors.New("connection does not support streaming")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	// clear the deadlines set by the server
+	if err = conn.SetDeadline(time.Time{}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	stream := &eventsStream{
+		conn: conn,
+		rw:   rw,
+		done: make(chan struct{}),
+	}
+	go func() {
+		// the client does not send anything after the request, reading only returns once the
+		// connection is closed
+		io.Copy(io.Discard, rw)
+		close(stream.done)
+	}()
+
+	rw.WriteString("HTTP/1.1 200 OK\r\n")
+	rw.WriteString("Content-Type: text/event-stream\r\n")
+	rw.WriteString("Cache-Control: no-cache\r\n")
+	rw.WriteString("Connection: close\r\n\r\n")
+	if err = rw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return stream, nil
+}
+
+func (stream *eventsStream) writeEvent(id uint64, event *EventResponse) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stream.rw, "id: %d\nevent: %s\ndata: %s\n\n", id, event.Type, data)
+	return stream.rw.Flush()
+}
+
+func (stream *eventsStream) writeComment(comment string) error {
+	fmt.Fprintf(stream.rw, ": %s\n\n", comment)
+	return stream.rw.Flush()
+}
+
+func (stream *eventsStream) close() {
+	stream.conn.Close()
+}
@@ -0,0 +1,194 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/api"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
+	"github.com/aws/amazon-ecs-agent/agent/engine/image"
+	mock_utils "github.com/aws/amazon-ecs-agent/agent/handlers/mocks"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/eventstream"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	eventsTestTaskARN1 = "arn:aws:ecs:us-west-2:1234567890:task/test-cluster/task1"
+	eventsTestTaskARN2 = "arn:aws:ecs:us-west-2:1234567890:task/test-cluster/task2"
+)
+
+// sseEvent is a server-sent event read from the events stream
+type sseEvent struct {
+	eventType string
+	data      EventResponse
+}
+
+// startEventsServer serves the events handler and returns a reader of the stream connected with the given
+// query. The handler subscribes to the event stream before it writes the "connected" comment.
+func startEventsServer(t *testing.T, eventStream *eventstream.EventStream, query string) *bufio.Reader {
+	ctrl := gomock.NewController(t)
+	state := dockerstate.NewTaskEngineState()
+	state.AddTask(&apitask.Task{Arn: eventsTestTaskARN1, Family: "web"})
+	state.AddTask(&apitask.Task{Arn: eventsTestTaskARN2, Family: "worker"})
+	stateResolver := mock_utils.NewMockDockerStateResolver(ctrl)
+	stateResolver.EXPECT().State().Return(state).AnyTimes()
+
+	server := httptest.NewServer(http.HandlerFunc(EventsHandler(eventStream, stateResolver)))
+	t.Cleanup(server.Close)
+
+	resp, err := http.Get(server.URL + EventsPath + query)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, ": connected\n", line)
+	_, err = reader.ReadString('\n')
+	require.NoError(t, err)
+	return reader
+}
+
+func readEvent(t *testing.T, reader *bufio.Reader) sseEvent {
+	var event sseEvent
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			return event
+		case strings.HasPrefix(line, "event: "):
+			event.eventType = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event.data))
+		}
+	}
+}
+
+func TestEventsHandler(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	eventStream := eventstream.NewEventStream("TestEventsHandler", ctx)
+	eventStream.StartListening()
+
+	reader := startEventsServer(t, eventStream, "")
+
+	require.NoError(t, eventStream.WriteToEventStream(api.TaskStateChange{
+		TaskARN: eventsTestTaskARN1,
+		Status:  apitaskstatus.TaskRunning,
+	}))
+	event := readEvent(t, reader)
+	assert.Equal(t, EventTypeTaskStateChange, event.eventType)
+	assert.Equal(t, eventsTestTaskARN1, event.data.TaskARN)
+	assert.Equal(t, "web", event.data.Family)
+	assert.Equal(t, "RUNNING", event.data.Status)
+
+	require.NoError(t, eventStream.WriteToEventStream(image.CleanupEvent{
+		ImageID:    "sha256:image",
+		ImageNames: []string{"image:latest"},
+		SizeBytes:  1024,
+		Trigger:    "periodic",
+		ImageType:  "ecs",
+		RemovedAt:  time.Now(),
+	}))
+	event = readEvent(t, reader)
+	assert.Equal(t, EventTypeImageCleanup, event.eventType)
+	require.NotNil(t, event.data.Image)
+	assert.Equal(t, "sha256:image", event.data.Image.ImageID)
+	assert.Equal(t, int64(1024), event.data.Image.SizeBytes)
+}
+
+func TestEventsHandlerPreservesOrder(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	eventStream := eventstream.NewEventStream("TestEventsHandlerPreservesOrder", ctx)
+	eventStream.StartListening()
+
+	reader := startEventsServer(t, eventStream, "")
+
+	const eventCount = 50
+	for i := 0; i < eventCount; i++ {
+		require.NoError(t, eventStream.WriteToEventStream(api.TaskStateChange{
+			TaskARN: eventsTestTaskARN1,
+			Status:  apitaskstatus.TaskRunning,
+			Reason:  strconv.Itoa(i),
+		}))
+	}
+	for i := 0; i < eventCount; i++ {
+		event := readEvent(t, reader)
+		assert.Equal(t, strconv.Itoa(i), event.data.Reason)
+	}
+}
+
+func TestEventsHandlerFilters(t *testing.T) {
+	testCases := []struct {
+		name  string
+		query string
+	}{
+		{name: "task arn", query: "?taskarn=" + eventsTestTaskARN2},
+		{name: "family", query: "?family=worker"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			eventStream := eventstream.NewEventStream("TestEventsHandlerFilters", ctx)
+			eventStream.StartListening()
+
+			reader := startEventsServer(t, eventStream, tc.query)
+
+			require.NoError(t, eventStream.WriteToEventStream(api.TaskStateChange{
+				TaskARN: eventsTestTaskARN1,
+				Status:  apitaskstatus.TaskRunning,
+			}))
+			require.NoError(t, eventStream.WriteToEventStream(image.CleanupEvent{ImageID: "sha256:image"}))
+			require.NoError(t, eventStream.WriteToEventStream(api.TaskStateChange{
+				TaskARN: eventsTestTaskARN2,
+				Status:  apitaskstatus.TaskStopped,
+			}))
+
+			event := readEvent(t, reader)
+			assert.Equal(t, EventTypeTaskStateChange, event.eventType)
+			assert.Equal(t, eventsTestTaskARN2, event.data.TaskARN)
+			assert.Equal(t, "worker", event.data.Family)
+		})
+	}
+}
+
+func TestNewEventResponseUnsupportedEvent(t *testing.T) {
+	_, ok := NewEventResponse(struct{}{}, dockerstate.NewTaskEngineState())
+	assert.False(t, ok)
+
+	_, ok = NewEventResponse(api.AttachmentStateChange{}, dockerstate.NewTaskEngineState())
+	assert.False(t, ok)
+}
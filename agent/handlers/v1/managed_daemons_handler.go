@@ -0,0 +1,49 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+
+	dm "github.com/aws/amazon-ecs-agent/agent/engine/daemonmanager"
+)
+
+// ManagedDaemonsPath is the managed daemons path for v1 handler.
+const ManagedDaemonsPath = "/v1/daemons"
+
+// ManagedDaemonsResponse is the schema for the managed daemons response.
+type ManagedDaemonsResponse struct {
+	Daemons []dm.RestartStatus
+}
+
+// ManagedDaemonsHandler creates response for the 'v1/daemons' API. Lists the restart status of the
+// managed daemons: how many times they were restarted, their last exit and whether they are crash looping.
+func ManagedDaemonsHandler(reporter dm.RestartStatusReporter) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response := &ManagedDaemonsResponse{Daemons: []dm.RestartStatus{}}
+		if reporter != nil {
+			response.Daemons = append(response.Daemons, reporter.GetRestartStatuses()...)
+		}
+
+		responseJSON, err := json.Marshal(response)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("{}"))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write(responseJSON)
+	}
+}
@@ -39,6 +39,9 @@ type TaskResponse struct {
 	Family        string              `json:"Family"`
 	Version       string              `json:"Version"`
 	Containers    []ContainerResponse `json:"Containers"`
+	// QueuePosition and WaitReason are only set for the tasks waiting for host resources
+	QueuePosition int    `json:"QueuePosition,omitempty"`
+	WaitReason    string `json:"WaitReason,omitempty"`
 }
 
 // TasksResponse is the schema for the tasks response JSON object
@@ -80,6 +83,7 @@ func NewTaskResponse(task *apitask.Task, containerMap map[string]*apicontainer.D
 		desiredStatus = ""
 	}
 
+	queuePosition, waitReason := task.GetQueueStatus()
 	return &TaskResponse{
 		Arn:           task.Arn,
 		DesiredStatus: desiredStatus,
@@ -87,6 +91,8 @@ func NewTaskResponse(task *apitask.Task, containerMap map[string]*apicontainer.D
 		Family:        task.Family,
 		Version:       task.Version,
 		Containers:    containers,
+		QueuePosition: queuePosition,
+		WaitReason:    waitReason,
 	}
 }
 
@@ -152,6 +152,27 @@ func TestTaskResponse(t *testing.T) {
 	assert.Equal(t, expectedTaskResponse, *taskResponse)
 }
 
+func TestTaskResponseQueueStatus(t *testing.T) {
+	task := &apitask.Task{
+		Arn:                 taskARN,
+		Family:              family,
+		Version:             version,
+		DesiredStatusUnsafe: apitaskstatus.TaskRunning,
+		KnownStatusUnsafe:   apitaskstatus.TaskStatusNone,
+	}
+	task.SetQueueStatus(2, "queued behind task t0")
+
+	taskResponse := NewTaskResponse(task, nil)
+	assert.Equal(t, 2, taskResponse.QueuePosition)
+	assert.Equal(t, "queued behind task t0", taskResponse.WaitReason)
+
+	task.SetQueueStatus(0, "")
+	taskJSON, err := json.Marshal(NewTaskResponse(task, nil))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(taskJSON), "QueuePosition")
+	assert.NotContains(t, string(taskJSON), "WaitReason")
+}
+
 func TestContainerResponse(t *testing.T) {
 	container := &apicontainer.Container{
 		Name:    containerName,
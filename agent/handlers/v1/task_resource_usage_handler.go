@@ -0,0 +1,67 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/stats"
+	commonutils "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/utils"
+	"github.com/cihub/seelog"
+)
+
+// TaskResourceUsagePath is the task resource usage path for v1 handler.
+const TaskResourceUsagePath = "/v1/tasks/usage"
+
+// TaskResourceUsagesResponse is the schema for the task resource usage list response.
+type TaskResourceUsagesResponse struct {
+	Usages []*apitask.ResourceUsage
+}
+
+// TaskResourceUsageHandler creates response for the 'v1/tasks/usage' API. Lists the resource usage of
+// all the tasks, including the stopped tasks that are still retained, if the request doesn't contain
+// any fields. Returns the resource usage of a task if 'taskarn' is specified in the request.
+func TaskResourceUsageHandler(statsEngine stats.Engine) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response interface{}
+		status := http.StatusOK
+		if taskArn, ok := commonutils.ValueFromRequest(r, taskARNQueryField); ok {
+			usage, found := statsEngine.GetTaskResourceUsage(taskArn)
+			if !found {
+				seelog.Warn("Could not find the resource usage of task: " + taskArn)
+				usage = &apitask.ResourceUsage{}
+				status = http.StatusNotFound
+			}
+			response = usage
+		} else {
+			usages := statsEngine.GetTaskResourceUsages()
+			sort.Slice(usages, func(i, j int) bool {
+				return usages[i].TaskARN < usages[j].TaskARN
+			})
+			response = &TaskResourceUsagesResponse{Usages: usages}
+		}
+
+		responseJSON, err := json.Marshal(response)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("{}"))
+			return
+		}
+		w.WriteHeader(status)
+		w.Write(responseJSON)
+	}
+}
@@ -186,6 +186,13 @@ func NewContainerResponse(
 		resp.LogDriver = container.GetLogDriver()
 		resp.LogOptions = container.GetLogOptions()
 		resp.ContainerARN = container.ContainerArn
+		if container.RestartPolicyEnabled() {
+			restartCount := container.RestartTracker.GetRestartCount()
+			resp.RestartCount = &restartCount
+			if lastRestartAt := container.RestartTracker.GetLastRestartAt(); !lastRestartAt.IsZero() {
+				resp.LastRestartedAt = &lastRestartAt
+			}
+		}
 	}
 
 	// Write the container health status inside the container
@@ -333,6 +333,36 @@ func TestContainerResponse(t *testing.T) {
 	}
 }
 
+func TestContainerResponseRestartPolicy(t *testing.T) {
+	container := &apicontainer.Container{
+		Name:                containerName,
+		DesiredStatusUnsafe: apicontainerstatus.ContainerRunning,
+		KnownStatusUnsafe:   apicontainerstatus.ContainerRunning,
+		RestartPolicy:       &apicontainer.RestartPolicy{RestartAttemptPeriod: time.Minute},
+		RestartTracker:      apicontainer.NewRestartTracker(),
+	}
+	dockerContainer := &apicontainer.DockerContainer{
+		DockerID:   containerID,
+		DockerName: containerName,
+		Container:  container,
+	}
+
+	resp := NewContainerResponse(dockerContainer, nil, true)
+	require.NotNil(t, resp.RestartCount)
+	assert.Equal(t, 0, *resp.RestartCount)
+	assert.Nil(t, resp.LastRestartedAt)
+
+	restartedAt := time.Now()
+	container.RestartTracker.RecordRestart(aws.Int(1), restartedAt)
+	resp = NewContainerResponse(dockerContainer, nil, true)
+	assert.Equal(t, 1, *resp.RestartCount)
+	assert.Equal(t, restartedAt, *resp.LastRestartedAt)
+
+	resp = NewContainerResponse(dockerContainer, nil, false)
+	assert.Nil(t, resp.RestartCount, "restart counts are only reported in v4 metadata")
+	assert.Nil(t, resp.LastRestartedAt)
+}
+
 func TestTaskResponseMarshal(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -0,0 +1,65 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v4
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
+	v3 "github.com/aws/amazon-ecs-agent/agent/handlers/v3"
+	"github.com/aws/amazon-ecs-agent/agent/stats"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/utils"
+)
+
+// requestTypeTaskResourceUsage specifies the request type of TaskResourceUsageHandler.
+const requestTypeTaskResourceUsage = "task resource usage"
+
+// Task resource usage endpoint: /v4/<v3 endpoint id>/task/usage
+var TaskResourceUsagePath = fmt.Sprintf("/v4/%s/task/usage",
+	utils.ConstructMuxVar(v3.V3EndpointIDMuxName, utils.AnythingButSlashRegEx))
+
+// TaskResourceUsageHandler returns the handler method for handling task resource usage requests.
+func TaskResourceUsageHandler(state dockerstate.TaskEngineState, statsEngine stats.Engine) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		taskARN, err := v3.GetTaskARNByRequest(r, state)
+		if err != nil {
+			responseJSON, err := json.Marshal(
+				fmt.Sprintf("V4 task resource usage handler: unable to get task arn from request: %s", err.Error()))
+			if e := utils.WriteResponseIfMarshalError(w, err); e != nil {
+				return
+			}
+			utils.WriteJSONToResponse(w, http.StatusNotFound, responseJSON, requestTypeTaskResourceUsage)
+			return
+		}
+
+		usage, ok := statsEngine.GetTaskResourceUsage(taskARN)
+		if !ok {
+			responseJSON, err := json.Marshal(
+				fmt.Sprintf("V4 task resource usage handler: no resource usage accounted yet for task %s", taskARN))
+			if e := utils.WriteResponseIfMarshalError(w, err); e != nil {
+				return
+			}
+			utils.WriteJSONToResponse(w, http.StatusNotFound, responseJSON, requestTypeTaskResourceUsage)
+			return
+		}
+
+		responseJSON, err := json.Marshal(usage)
+		if e := utils.WriteResponseIfMarshalError(w, err); e != nil {
+			return
+		}
+		utils.WriteJSONToResponse(w, http.StatusOK, responseJSON, requestTypeTaskResourceUsage)
+	}
+}
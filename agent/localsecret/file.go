@@ -0,0 +1,107 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package localsecret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const secretsKeySize = 32
+
+// secretsFile is a file of secrets encrypted with AES-256-GCM: a 12 bytes nonce followed by the
+// sealed JSON object of the secret values keyed by their name. The file is read for every secret so
+// that the secrets are up to date with the file.
+type secretsFile struct {
+	path    string
+	keyPath string
+}
+
+func (f *secretsFile) getSecret(name string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("local secret: missing secret name")
+	}
+	secrets, err := f.read()
+	if err != nil {
+		return "", err
+	}
+	value, ok := secrets[name]
+	if !ok {
+		return "", fmt.Errorf("local secret %s not found in %s", name, f.path)
+	}
+	return value, nil
+}
+
+func (f *secretsFile) read() (map[string]string, error) {
+	key, err := readSecretsKey(f.keyPath)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the local secrets file: %w", err)
+	}
+	plaintext, err := decrypt(key, data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt the local secrets file %s: %w", f.path, err)
+	}
+	secrets := make(map[string]string)
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, fmt.Errorf("unable to parse the local secrets file %s: %w", f.path, err)
+	}
+	return secrets, nil
+}
+
+func readSecretsKey(path string) ([]byte, error) {
+	if path == "" {
+		return nil, fmt.Errorf("no key file of the local secrets file is configured")
+	}
+	encoded, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the key of the local secrets file: %w", err)
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode the key of the local secrets file: %w", err)
+	}
+	if len(key) != secretsKeySize {
+		return nil, fmt.Errorf("the key of the local secrets file is %d bytes long, it must be %d bytes long",
+			len(key), secretsKeySize)
+	}
+	return key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func decrypt(key, data []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("file is too short")
+	}
+	return gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+}
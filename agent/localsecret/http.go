@@ -0,0 +1,105 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package localsecret
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultSecretKey is the key of the secret value in the secret data when valueFrom has no key
+	defaultSecretKey = "value"
+	tokenHeader      = "X-Vault-Token"
+	maxResponseSize  = 1 << 20
+)
+
+// secretsEndpoint is a Vault style secrets endpoint: GET <endpoint>/<path> returns the secret data
+// at path, {"data": {...}}. The data of key/value version 2 engines, {"data": {"data": {...}}}, is
+// unwrapped.
+type secretsEndpoint struct {
+	endpoint  string
+	tokenPath string
+	client    *http.Client
+}
+
+func newSecretsEndpoint(endpoint, tokenPath string, timeout time.Duration) *secretsEndpoint {
+	return &secretsEndpoint{
+		endpoint:  strings.TrimSuffix(endpoint, "/"),
+		tokenPath: tokenPath,
+		client:    &http.Client{Timeout: timeout},
+	}
+}
+
+type secretResponse struct {
+	Data map[string]interface{} `json:"data"`
+}
+
+// getSecret returns the secret of a reference of the <path>[#<key>] form.
+func (e *secretsEndpoint) getSecret(ref string) (string, error) {
+	path, key := ref, defaultSecretKey
+	if i := strings.LastIndex(ref, "#"); i >= 0 {
+		path, key = ref[:i], ref[i+1:]
+	}
+	path = strings.TrimPrefix(path, "/")
+	if path == "" || key == "" {
+		return "", fmt.Errorf("local secret %s: the reference requires a path and a key", ref)
+	}
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return "", fmt.Errorf("local secret %s: invalid path", ref)
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodGet, e.endpoint+"/"+(&url.URL{Path: path}).EscapedPath(), nil)
+	if err != nil {
+		return "", fmt.Errorf("local secret %s: %w", ref, err)
+	}
+	if e.tokenPath != "" {
+		token, err := os.ReadFile(e.tokenPath)
+		if err != nil {
+			return "", fmt.Errorf("unable to read the token of the local secrets endpoint: %w", err)
+		}
+		req.Header.Set(tokenHeader, strings.TrimSpace(string(token)))
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("local secret %s: %w", ref, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("local secret %s: unexpected response status %s", ref, resp.Status)
+	}
+
+	var body secretResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&body); err != nil {
+		return "", fmt.Errorf("local secret %s: unable to parse the response: %w", ref, err)
+	}
+	data := body.Data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		data = nested
+	}
+	value, ok := data[key].(string)
+	if !ok {
+		return "", fmt.Errorf("local secret %s: key %s not found", ref, key)
+	}
+	return value, nil
+}
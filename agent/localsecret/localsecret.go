@@ -0,0 +1,81 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package localsecret retrieves the secrets of tasks from the instance rather than from AWS, out of
+// an encrypted secrets file on the host or a local Vault style secrets endpoint. It's meant for
+// instances that can't reach SSM Parameter Store and Secrets Manager, such as on-premises instances.
+package localsecret
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+)
+
+const requestTimeout = 10 * time.Second
+
+// Config is the configuration of the local secrets of the instance.
+type Config struct {
+	// File is the path of the encrypted secrets file.
+	File string
+	// KeyFile is the path of the file with the base64 encoded AES-256 key of the secrets file.
+	KeyFile string
+	// Endpoint is the base URL of the secrets endpoint.
+	Endpoint string
+	// TokenFile is the path of the file with the token of the secrets endpoint, if it requires one.
+	TokenFile string
+}
+
+// Client retrieves local secrets.
+type Client interface {
+	// GetSecret returns the value of the secret referenced by valueFrom, which has either the
+	// local-file://<name> or the local-http://<path>[#<key>] form.
+	GetSecret(valueFrom string) (string, error)
+}
+
+type client struct {
+	file     *secretsFile
+	endpoint *secretsEndpoint
+}
+
+// NewClient creates a Client of the local secrets configured by cfg. The secrets of a source that
+// isn't configured can't be retrieved.
+func NewClient(cfg Config) Client {
+	c := &client{}
+	if cfg.File != "" {
+		c.file = &secretsFile{path: cfg.File, keyPath: cfg.KeyFile}
+	}
+	if cfg.Endpoint != "" {
+		c.endpoint = newSecretsEndpoint(cfg.Endpoint, cfg.TokenFile, requestTimeout)
+	}
+	return c
+}
+
+func (c *client) GetSecret(valueFrom string) (string, error) {
+	switch {
+	case strings.HasPrefix(valueFrom, apicontainer.LocalSecretFileScheme):
+		if c.file == nil {
+			return "", fmt.Errorf("local secret %s: no local secrets file is configured", valueFrom)
+		}
+		return c.file.getSecret(strings.TrimPrefix(valueFrom, apicontainer.LocalSecretFileScheme))
+	case strings.HasPrefix(valueFrom, apicontainer.LocalSecretHTTPScheme):
+		if c.endpoint == nil {
+			return "", fmt.Errorf("local secret %s: no local secrets endpoint is configured", valueFrom)
+		}
+		return c.endpoint.getSecret(strings.TrimPrefix(valueFrom, apicontainer.LocalSecretHTTPScheme))
+	default:
+		return "", fmt.Errorf("local secret %s: unsupported scheme", valueFrom)
+	}
+}
@@ -0,0 +1,141 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package localsecret
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestSecretsFile(t *testing.T, secrets string) Config {
+	dir := t.TempDir()
+	key := make([]byte, secretsKeySize)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	gcm, err := newGCM(key)
+	require.NoError(t, err)
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = rand.Read(nonce)
+	require.NoError(t, err)
+
+	cfg := Config{
+		File:    filepath.Join(dir, "secrets.enc"),
+		KeyFile: filepath.Join(dir, "secrets.key"),
+	}
+	require.NoError(t, os.WriteFile(cfg.File, gcm.Seal(nonce, nonce, []byte(secrets), nil), 0600))
+	require.NoError(t, os.WriteFile(cfg.KeyFile, []byte(base64.StdEncoding.EncodeToString(key)+"\n"), 0600))
+	return cfg
+}
+
+func TestGetSecretFromFile(t *testing.T) {
+	client := NewClient(writeTestSecretsFile(t, `{"db/password": "hunter2"}`))
+
+	value, err := client.GetSecret("local-file://db/password")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", value)
+
+	_, err = client.GetSecret("local-file://db/username")
+	assert.Error(t, err)
+	_, err = client.GetSecret("local-http://db#password")
+	assert.Error(t, err, "the secrets endpoint isn't configured")
+}
+
+func TestGetSecretFromFileErrors(t *testing.T) {
+	cfg := writeTestSecretsFile(t, `{"db/password": "hunter2"}`)
+
+	wrongKey := cfg
+	wrongKey.KeyFile = filepath.Join(t.TempDir(), "wrong.key")
+	require.NoError(t, os.WriteFile(wrongKey.KeyFile,
+		[]byte(base64.StdEncoding.EncodeToString(make([]byte, secretsKeySize))), 0600))
+	_, err := NewClient(wrongKey).GetSecret("local-file://db/password")
+	assert.Error(t, err)
+
+	shortKey := cfg
+	shortKey.KeyFile = filepath.Join(t.TempDir(), "short.key")
+	require.NoError(t, os.WriteFile(shortKey.KeyFile, []byte(base64.StdEncoding.EncodeToString([]byte("short"))), 0600))
+	_, err = NewClient(shortKey).GetSecret("local-file://db/password")
+	assert.Error(t, err)
+
+	noKey := cfg
+	noKey.KeyFile = ""
+	_, err = NewClient(noKey).GetSecret("local-file://db/password")
+	assert.Error(t, err)
+
+	_, err = NewClient(writeTestSecretsFile(t, `not json`)).GetSecret("local-file://db/password")
+	assert.Error(t, err)
+
+	_, err = NewClient(cfg).GetSecret("arn:aws:ssm:us-west-2:123456789012:parameter/db")
+	assert.Error(t, err)
+}
+
+func TestGetSecretFromEndpoint(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("s.token\n"), 0600))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(tokenHeader) != "s.token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/db":
+			w.Write([]byte(`{"data": {"password": "hunter2", "value": "default"}}`))
+		case "/v1/kv/data/db":
+			w.Write([]byte(`{"data": {"data": {"password": "correct horse"}, "metadata": {"version": 2}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Endpoint: server.URL + "/v1/", TokenFile: tokenFile})
+	cases := []struct {
+		valueFrom string
+		value     string
+		err       bool
+	}{
+		{valueFrom: "local-http://secret/db#password", value: "hunter2"},
+		{valueFrom: "local-http://secret/db", value: "default"},
+		{valueFrom: "local-http://kv/data/db#password", value: "correct horse"},
+		{valueFrom: "local-http://secret/db#username", err: true},
+		{valueFrom: "local-http://secret/missing#password", err: true},
+		{valueFrom: "local-http://secret/../db#password", err: true},
+		{valueFrom: "local-http://#password", err: true},
+		{valueFrom: "local-file://db/password", err: true},
+	}
+	for _, c := range cases {
+		t.Run(c.valueFrom, func(t *testing.T) {
+			value, err := client.GetSecret(c.valueFrom)
+			if c.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.value, value)
+		})
+	}
+
+	_, err := NewClient(Config{Endpoint: server.URL + "/v1"}).GetSecret("local-http://secret/db#password")
+	assert.Error(t, err, "the endpoint requires a token")
+}
@@ -15,12 +15,14 @@ package audit
 
 import (
 	"fmt"
+	"html"
 	"strconv"
 
 	"github.com/aws/amazon-ecs-agent/agent/config"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
 	auditinterface "github.com/aws/amazon-ecs-agent/ecs-agent/logger/audit"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/audit/request"
+	"github.com/cihub/seelog"
 )
 
 type InfoLogger interface {
@@ -46,12 +48,23 @@ func NewAuditLog(containerInstanceArn string, cfg *config.Config, logger InfoLog
 // Log will construct an audit log entry log and log that entry to the audit log
 // using the underlying logger (which implements the audit.InfoLogger interface).
 func (a *auditLog) Log(r request.LogRequest, httpResponseCode int, eventType string) {
-	if !a.cfg.CredentialsAuditLogDisabled {
-		auditLogEntry := constructAuditLogEntry(r, httpResponseCode, eventType, a.GetCluster(),
+	if a.cfg.CredentialsAuditLogDisabled {
+		return
+	}
+	if a.cfg.CredentialsAuditLogFormat == config.AuditLogFormatJSON {
+		auditLogEntry, err := constructJSONAuditLogEntry(r, httpResponseCode, eventType, a.GetCluster(),
 			a.GetContainerInstanceArn())
-
+		if err != nil {
+			seelog.Warnf("Unable to construct the audit log entry for event type %s: %v", eventType, err)
+			return
+		}
 		a.logger.Info(auditLogEntry)
+		return
 	}
+
+	auditLogEntry := constructAuditLogEntry(r, httpResponseCode, eventType, a.GetCluster(),
+		a.GetContainerInstanceArn())
+	a.logger.Info(auditLogEntry)
 }
 
 func constructAuditLogEntry(r request.LogRequest, httpResponseCode int, eventType string,
@@ -76,16 +89,21 @@ func AuditLoggerConfig(cfg *config.Config) string {
 	<outputs formatid="main">
 		<console />`
 	if cfg.CredentialsAuditLogFile != "" {
-		if logger.Config.RolloverType == "size" {
+		rolloverType, maxFileSize, maxRollCount := auditLogRotation(cfg)
+		if rolloverType == "size" {
 			config += `
 		<rollingfile filename="` + cfg.CredentialsAuditLogFile + `" type="size"
-		 maxsize="` + strconv.Itoa(int(logger.Config.MaxFileSizeMB*1000000)) + `" archivetype="none" maxrolls="` + strconv.Itoa(logger.Config.MaxRollCount) + `" />`
+		 maxsize="` + strconv.FormatInt(maxFileSize, 10) + `" archivetype="none" maxrolls="` + strconv.Itoa(maxRollCount) + `" />`
 		} else {
 			config += `
 		<rollingfile filename="` + cfg.CredentialsAuditLogFile + `" type="date"
-		 datepattern="2006-01-02-15" archivetype="none" maxrolls="` + strconv.Itoa(logger.Config.MaxRollCount) + `" />`
+		 datepattern="2006-01-02-15" archivetype="none" maxrolls="` + strconv.Itoa(maxRollCount) + `" />`
 		}
 	}
+	if cfg.CredentialsAuditLogSink != "" {
+		config += `
+		<custom name="` + sinkReceiverName + `" data-target="` + html.EscapeString(cfg.CredentialsAuditLogSink) + `" />`
+	}
 	config += `
 	</outputs>
 	<formats>
@@ -95,3 +113,22 @@ func AuditLoggerConfig(cfg *config.Config) string {
 `
 	return config
 }
+
+// auditLogRotation returns the rollover type, the maximum file size in bytes and the maximum
+// roll count of the audit log file. The settings of the agent log are used for the ones that
+// aren't set for the audit log.
+func auditLogRotation(cfg *config.Config) (string, int64, int) {
+	rolloverType := logger.Config.RolloverType
+	if cfg.CredentialsAuditLogRolloverType != "" {
+		rolloverType = cfg.CredentialsAuditLogRolloverType
+	}
+	maxFileSize := int64(logger.Config.MaxFileSizeMB * 1000000)
+	if cfg.CredentialsAuditLogMaxFileSize > 0 {
+		maxFileSize = cfg.CredentialsAuditLogMaxFileSize
+	}
+	maxRollCount := logger.Config.MaxRollCount
+	if cfg.CredentialsAuditLogMaxRollCount > 0 {
+		maxRollCount = cfg.CredentialsAuditLogMaxRollCount
+	}
+	return rolloverType, maxFileSize, maxRollCount
+}
@@ -17,20 +17,25 @@
 package audit
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/aws/amazon-ecs-agent/agent/config"
 	mock_infologger "github.com/aws/amazon-ecs-agent/agent/logger/audit/mocks"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
 	auditinterface "github.com/aws/amazon-ecs-agent/ecs-agent/logger/audit"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/audit/request"
+	"github.com/cihub/seelog"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -170,6 +175,92 @@ func TestWritingToAuditLogWhenDisabled(t *testing.T) {
 		auditinterface.GetCredentialsEventTypeFromRoleType(dummyRoleType))
 }
 
+func TestWritingJSONToAuditLog(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockInfoLogger := mock_infologger.NewMockInfoLogger(ctrl)
+
+	req, _ := http.NewRequest("GET", dummyURLV2, nil)
+	req.RemoteAddr = dummyRemoteAddress
+	req.Header.Set("User-Agent", dummyUserAgent)
+
+	cfg := &config.Config{
+		Cluster:                   dummyCluster,
+		CredentialsAuditLogFile:   "foo.txt",
+		CredentialsAuditLogFormat: config.AuditLogFormatJSON,
+	}
+
+	auditLogger := NewAuditLog(dummyContainerInstanceArn, cfg, mockInfoLogger)
+
+	mockInfoLogger.EXPECT().Info(gomock.Any()).Do(func(logLine string) {
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(logLine), &entry))
+		_, err := time.Parse(time.RFC3339, entry["eventTime"].(string))
+		assert.NoError(t, err, "eventTime is not a RFC3339 timestamp")
+		delete(entry, "eventTime")
+		assert.Equal(t, map[string]interface{}{
+			"eventType":            auditinterface.UpdateTaskProtectionEventType,
+			"version":              float64(jsonAuditLogVersion),
+			"responseCode":         float64(dummyResponseCode),
+			"sourceAddress":        dummyRemoteAddress,
+			"url":                  credentials.V2CredentialsPath,
+			"userAgent":            dummyUserAgent,
+			"arn":                  taskARN,
+			"cluster":              dummyCluster,
+			"containerInstanceArn": dummyContainerInstanceArn,
+		}, entry)
+	})
+
+	auditLogger.Log(request.LogRequest{Request: req, ARN: taskARN}, dummyResponseCode,
+		auditinterface.UpdateTaskProtectionEventType)
+}
+
+func TestAuditLoggerConfigRotation(t *testing.T) {
+	testCases := []struct {
+		name             string
+		cfg              *config.Config
+		expectedFileSpec string
+	}{
+		{
+			name: "agent log rotation",
+			cfg:  &config.Config{CredentialsAuditLogFile: "audit.log"},
+			expectedFileSpec: `type="date"
+		 datepattern="2006-01-02-15" archivetype="none" maxrolls="` + strconv.Itoa(logger.Config.MaxRollCount) + `"`,
+		},
+		{
+			name: "rotation by size",
+			cfg: &config.Config{
+				CredentialsAuditLogFile:         "audit.log",
+				CredentialsAuditLogRolloverType: config.AuditLogRolloverTypeSize,
+				CredentialsAuditLogMaxFileSize:  5000000,
+				CredentialsAuditLogMaxRollCount: 7,
+			},
+			expectedFileSpec: `type="size"
+		 maxsize="5000000" archivetype="none" maxrolls="7"`,
+		},
+		{
+			name: "hourly rotation",
+			cfg: &config.Config{
+				CredentialsAuditLogFile:         "audit.log",
+				CredentialsAuditLogRolloverType: config.AuditLogRolloverTypeHourly,
+				CredentialsAuditLogMaxRollCount: 48,
+			},
+			expectedFileSpec: `type="date"
+		 datepattern="2006-01-02-15" archivetype="none" maxrolls="48"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			seelogConfig := AuditLoggerConfig(tc.cfg)
+			assert.Contains(t, seelogConfig, `<rollingfile filename="audit.log" `+tc.expectedFileSpec)
+			_, err := seelog.LoggerFromConfigAsString(seelogConfig)
+			assert.NoError(t, err)
+		})
+	}
+}
+
 func TestConstructCommonAuditLogEntryFields(t *testing.T) {
 	req, _ := http.NewRequest("GET", "foo", nil)
 	req.RemoteAddr = dummyRemoteAddress
@@ -224,6 +315,29 @@ func verifyConstructAuditLogEntryGetCredentialsResult(result string, t *testing.
 	assert.Equal(t, dummyContainerInstanceArn, tokens[3], "containerInstanceArn does not match")
 }
 
+func TestConstructAuditLogEntryByTypeSourceMismatch(t *testing.T) {
+	result := constructAuditLogEntryByType(auditinterface.GetCredentialsSourceMismatchEventType, dummyCluster,
+		dummyContainerInstanceArn)
+	tokens := strings.Split(result, " ")
+
+	assert.Equal(t, getCredentialsEntryFieldCount, len(tokens), "Incorrect number of tokens in GetCredentials audit log entry")
+	assert.Equal(t, auditinterface.GetCredentialsSourceMismatchEventType, tokens[0], "event type does not match")
+	auditLogVersion, _ := strconv.Atoi(tokens[1])
+	assert.Equal(t, getCredentialsAuditLogVersion, auditLogVersion, "version does not match")
+	assert.Equal(t, dummyCluster, tokens[2], "cluster does not match")
+	assert.Equal(t, dummyContainerInstanceArn, tokens[3], "containerInstanceArn does not match")
+}
+
+func TestConstructAuditLogEntryByTypeTaskProtection(t *testing.T) {
+	for _, eventType := range []string{auditinterface.GetTaskProtectionEventType,
+		auditinterface.UpdateTaskProtectionEventType} {
+		result := constructAuditLogEntryByType(eventType, dummyCluster, dummyContainerInstanceArn)
+		tokens := strings.Split(result, " ")
+		assert.Equal(t, getCredentialsEntryFieldCount, len(tokens), "Incorrect number of tokens in audit log entry")
+		assert.Equal(t, eventType, tokens[0], "event type does not match")
+	}
+}
+
 func TestConstructAuditLogEntryByTypeUnknownType(t *testing.T) {
 	result := constructAuditLogEntryByType("unknownEvent", dummyCluster, dummyContainerInstanceArn)
 	assert.Equal(t, "", result, "unknown event type should not return an entry")
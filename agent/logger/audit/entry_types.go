@@ -14,7 +14,9 @@
 package audit
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"strings"
 	"time"
 
@@ -41,7 +43,18 @@ const (
 	// Version '2', following fields were modified
 	// 7. event type ('GetCredentials, GetCredentialsExecutionRole')
 
-	getCredentialsAuditLogVersion = 2
+	// Version '3', following fields were modified
+	// 7. event type ('GetCredentials, GetCredentialsExecutionRole, GetCredentialsSourceMismatch')
+
+	// Version '4', following fields were modified
+	// 7. event type ('GetCredentials, GetCredentialsExecutionRole, GetCredentialsSourceMismatch,
+	//    GetTaskProtection, UpdateTaskProtection')
+
+	getCredentialsAuditLogVersion = 4
+
+	// jsonAuditLogVersion is the version of the JSON audit log entries. The field names of
+	// jsonAuditLogEntry are stable within a version.
+	jsonAuditLogVersion = 1
 )
 
 type commonAuditLogEntryFields struct {
@@ -68,18 +81,59 @@ func (g *getCredentialsAuditLogEntryFields) string() string {
 	return fmt.Sprintf("%s %d %s %s", g.eventType, g.version, g.cluster, g.containerInstanceArn)
 }
 
-func constructCommonAuditLogEntryFields(r request.LogRequest, httpResponseCode int) string {
+// jsonAuditLogEntry is an audit log entry in the JSON format.
+type jsonAuditLogEntry struct {
+	EventTime            string `json:"eventTime"`
+	EventType            string `json:"eventType"`
+	Version              int    `json:"version"`
+	ResponseCode         int    `json:"responseCode"`
+	SourceAddress        string `json:"sourceAddress"`
+	URL                  string `json:"url"`
+	UserAgent            string `json:"userAgent"`
+	ARN                  string `json:"arn"`
+	Cluster              string `json:"cluster"`
+	ContainerInstanceArn string `json:"containerInstanceArn"`
+}
+
+func constructJSONAuditLogEntry(r request.LogRequest, httpResponseCode int, eventType string,
+	cluster string, containerInstanceArn string) (string, error) {
 	httpRequest := r.Request
+	entry := &jsonAuditLogEntry{
+		EventTime:            time.Now().UTC().Format(time.RFC3339),
+		EventType:            eventType,
+		Version:              jsonAuditLogVersion,
+		ResponseCode:         httpResponseCode,
+		SourceAddress:        httpRequest.RemoteAddr,
+		URL:                  auditLogURLPath(httpRequest),
+		UserAgent:            httpRequest.UserAgent(),
+		ARN:                  r.ARN,
+		Cluster:              cluster,
+		ContainerInstanceArn: containerInstanceArn,
+	}
+	entryJSON, err := json.Marshal(entry)
+	if err != nil {
+		return "", err
+	}
+	return string(entryJSON), nil
+}
+
+// auditLogURLPath returns the path of the request URL as it should appear in the audit log.
+func auditLogURLPath(httpRequest *http.Request) string {
 	url := httpRequest.URL.Path
 	// V2CredentialsPath contains the credentials ID, which should not be logged
 	if strings.HasPrefix(url, credentials.V2CredentialsPath+"/") {
 		url = credentials.V2CredentialsPath
 	}
+	return url
+}
+
+func constructCommonAuditLogEntryFields(r request.LogRequest, httpResponseCode int) string {
+	httpRequest := r.Request
 	fields := &commonAuditLogEntryFields{
 		eventTime:    time.Now().UTC().Format(time.RFC3339),
 		responseCode: httpResponseCode,
 		srcAddr:      populateField(httpRequest.RemoteAddr),
-		theURL:       populateField(fmt.Sprintf(`"%s"`, url)),
+		theURL:       populateField(fmt.Sprintf(`"%s"`, auditLogURLPath(httpRequest))),
 		userAgent:    populateField(fmt.Sprintf(`"%s"`, httpRequest.UserAgent())),
 		arn:          populateField(r.ARN),
 	}
@@ -96,7 +150,8 @@ func constructAuditLogEntryByType(eventType string, cluster string, containerIns
 			containerInstanceArn: populateField(containerInstanceArn),
 		}
 		return fields.string()
-	case audit.GetCredentialsTaskExecutionEventType:
+	case audit.GetCredentialsTaskExecutionEventType, audit.GetCredentialsSourceMismatchEventType,
+		audit.GetTaskProtectionEventType, audit.UpdateTaskProtectionEventType:
 		fields := &getCredentialsAuditLogEntryFields{
 			eventType:            eventType,
 			version:              getCredentialsAuditLogVersion,
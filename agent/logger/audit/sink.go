@@ -0,0 +1,120 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+package audit
+
+import (
+	"io"
+	"net"
+	"net/url"
+	"sync"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/cihub/seelog"
+	"github.com/pkg/errors"
+)
+
+const (
+	// sinkReceiverName is the name the sinkReceiver is registered with in seelog.
+	sinkReceiverName = "ecsauditsink"
+
+	// syslogTag is the tag of the audit log entries written to syslog.
+	syslogTag = "ecs-agent-audit"
+)
+
+func init() {
+	seelog.RegisterReceiver(sinkReceiverName, &sinkReceiver{})
+}
+
+// sinkReceiver fulfills the seelog.CustomReceiver interface. It writes the audit log entries to
+// the destination set by ECS_AUDIT_LOG_SINK, in addition to the audit log file. The destination
+// is connected to on the first entry and reconnected to after a failed write, so that the audit
+// log keeps working while the destination is unavailable.
+type sinkReceiver struct {
+	target string
+	writer io.WriteCloser
+	lock   sync.Mutex
+}
+
+// AfterParse validates the sink destination from the seelog configuration.
+func (s *sinkReceiver) AfterParse(initArgs seelog.CustomReceiverInitArgs) error {
+	s.target = initArgs.XmlCustomAttrs["target"]
+	_, _, err := parseSinkTarget(s.target)
+	return err
+}
+
+// ReceiveMessage writes an audit log entry to the sink destination.
+func (s *sinkReceiver) ReceiveMessage(message string, level seelog.LogLevel, context seelog.LogContextInterface) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.writer == nil {
+		writer, err := dialSink(s.target)
+		if err != nil {
+			return err
+		}
+		s.writer = writer
+	}
+	if _, err := s.writer.Write([]byte(message)); err != nil {
+		s.writer.Close()
+		s.writer = nil
+		return errors.Wrapf(err, "unable to write to audit log sink %s", s.target)
+	}
+	return nil
+}
+
+func (s *sinkReceiver) Flush() {}
+
+func (s *sinkReceiver) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.writer == nil {
+		return nil
+	}
+	err := s.writer.Close()
+	s.writer = nil
+	return err
+}
+
+// parseSinkTarget returns the network and the address of a sink destination. The network is
+// empty for the syslog sink.
+func parseSinkTarget(target string) (string, string, error) {
+	if target == config.AuditLogSinkSyslog {
+		return "", "", nil
+	}
+	targetURL, err := url.Parse(target)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "invalid audit log sink %s", target)
+	}
+	if (targetURL.Scheme != "unix" && targetURL.Scheme != "unixgram") || targetURL.Path == "" {
+		return "", "", errors.Errorf("invalid audit log sink %s: expected %s or a unix socket address",
+			target, config.AuditLogSinkSyslog)
+	}
+	return targetURL.Scheme, targetURL.Path, nil
+}
+
+// dialSink connects to a sink destination.
+func dialSink(target string) (io.WriteCloser, error) {
+	network, address, err := parseSinkTarget(target)
+	if err != nil {
+		return nil, err
+	}
+	if network == "" {
+		return dialSyslog()
+	}
+	conn, err := net.Dial(network, address)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to connect to audit log sink %s", target)
+	}
+	return conn, nil
+}
@@ -0,0 +1,105 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+package audit
+
+import (
+	"bufio"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/cihub/seelog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSinkTarget(t *testing.T) {
+	testCases := []struct {
+		target          string
+		expectedNetwork string
+		expectedAddress string
+		expectErr       bool
+	}{
+		{target: config.AuditLogSinkSyslog},
+		{target: "unix:///var/run/audit.sock", expectedNetwork: "unix", expectedAddress: "/var/run/audit.sock"},
+		{target: "unixgram:///var/run/audit.sock", expectedNetwork: "unixgram", expectedAddress: "/var/run/audit.sock"},
+		{target: "unix://", expectErr: true},
+		{target: "tcp://127.0.0.1:514", expectErr: true},
+		{target: "", expectErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.target, func(t *testing.T) {
+			network, address, err := parseSinkTarget(tc.target)
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedNetwork, network)
+			assert.Equal(t, tc.expectedAddress, address)
+		})
+	}
+}
+
+func TestAuditLogSinkUnixSocket(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "audit.sock")
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	cfg := &config.Config{
+		CredentialsAuditLogSink: "unix://" + socketPath,
+	}
+	auditLogger, err := seelog.LoggerFromConfigAsString(AuditLoggerConfig(cfg))
+	require.NoError(t, err)
+	defer auditLogger.Close()
+
+	auditLogger.Info(`{"eventType":"GetCredentials"}`)
+	auditLogger.Flush()
+
+	conn, err := listener.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, `{"eventType":"GetCredentials"}`+"\n", line)
+}
+
+func TestAuditLogSinkReconnects(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "audit.sock")
+	receiver := &sinkReceiver{target: "unix://" + socketPath}
+	defer receiver.Close()
+
+	// The sink destination isn't available yet
+	assert.Error(t, receiver.ReceiveMessage("entry\n", seelog.InfoLvl, nil))
+
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	require.NoError(t, receiver.ReceiveMessage("entry\n", seelog.InfoLvl, nil))
+	conn, err := listener.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "entry\n", line)
+}
@@ -0,0 +1,27 @@
+//go:build windows
+// +build windows
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+package audit
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// dialSyslog returns an error, there is no syslog daemon on Windows.
+func dialSyslog() (io.WriteCloser, error) {
+	return nil, errors.New("syslog audit log sink is not supported on windows")
+}
@@ -0,0 +1,87 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	ImageCleanupSubsystem = "ImageCleanup"
+
+	// ImageCleanupTriggerPeriodic labels evictions made by the periodic image cleanup
+	ImageCleanupTriggerPeriodic = "periodic"
+	// ImageCleanupTriggerDiskPressure labels evictions made because the disk usage went above the high watermark
+	ImageCleanupTriggerDiskPressure = "disk_pressure"
+
+	// ImageTypeECS labels images tracked by the image manager
+	ImageTypeECS = "ecs"
+	// ImageTypeNonECS labels images that are not tracked by the image manager
+	ImageTypeNonECS = "non_ecs"
+)
+
+// ImageCleanupMetrics records the eviction decisions of the image manager:
+//  1. A counter vector of evicted images per trigger and image type
+//  2. A counter vector of evicted bytes per trigger and image type
+//  3. A gauge vector with the last observed disk usage percentage per Docker data root
+type ImageCleanupMetrics struct {
+	evictionCounterVec *prometheus.CounterVec
+	evictedBytesVec    *prometheus.CounterVec
+	diskUsageVec       *prometheus.GaugeVec
+}
+
+func NewImageCleanupMetricsClient(registry *prometheus.Registry) *ImageCleanupMetrics {
+	evictionCounterVec := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: AgentNamespace,
+		Subsystem: ImageCleanupSubsystem,
+		Name:      "eviction_count",
+		Help:      "Number of images evicted by image cleanup",
+	}, []string{"Trigger", "ImageType"})
+	registry.MustRegister(evictionCounterVec)
+
+	evictedBytesVec := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: AgentNamespace,
+		Subsystem: ImageCleanupSubsystem,
+		Name:      "evicted_bytes",
+		Help:      "Size in bytes of the images evicted by image cleanup",
+	}, []string{"Trigger", "ImageType"})
+	registry.MustRegister(evictedBytesVec)
+
+	diskUsageVec := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: AgentNamespace,
+		Subsystem: ImageCleanupSubsystem,
+		Name:      "disk_usage_percent",
+		Help:      "Last observed disk usage percentage of the Docker data root",
+	}, []string{"Path"})
+	registry.MustRegister(diskUsageVec)
+
+	return &ImageCleanupMetrics{
+		evictionCounterVec: evictionCounterVec,
+		evictedBytesVec:    evictedBytesVec,
+		diskUsageVec:       diskUsageVec,
+	}
+}
+
+// RecordEviction records a single image eviction
+func (icm *ImageCleanupMetrics) RecordEviction(trigger, imageType string, sizeBytes int64) {
+	icm.evictionCounterVec.WithLabelValues(trigger, imageType).Inc()
+	if sizeBytes > 0 {
+		icm.evictedBytesVec.WithLabelValues(trigger, imageType).Add(float64(sizeBytes))
+	}
+}
+
+// RecordDiskUsage records the disk usage percentage of the Docker data root
+func (icm *ImageCleanupMetrics) RecordDiskUsage(path string, usedPercent float64) {
+	icm.diskUsageVec.WithLabelValues(path).Set(usedPercent)
+}
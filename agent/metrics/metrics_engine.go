@@ -16,9 +16,11 @@ package metrics
 import (
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/aws/amazon-ecs-agent/agent/config"
+	metricsfactory "github.com/aws/amazon-ecs-agent/ecs-agent/metrics"
 	"github.com/cihub/seelog"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -30,6 +32,13 @@ type MetricsEngine struct {
 	cfg            *config.Config
 	Registry       *prometheus.Registry
 	managedMetrics map[APIType]MetricsClient
+	imageCleanup   *ImageCleanupMetrics
+	websocket      *WebsocketMetrics
+	// entryFactory publishes the metrics to the configured sink. It is nil when there is none.
+	// It is set once the agent is started, while the metrics are already recorded, so it is
+	// accessed with entryFactoryLock held.
+	entryFactory     *metricsfactory.SinkEntryFactory
+	entryFactoryLock sync.RWMutex
 }
 
 const (
@@ -48,6 +57,13 @@ var (
 		StateManager: "State_Manager",
 		ECSClient:    "ECS_Client",
 	}
+	// The metrics of the managed APIs are published to the sink as <subsystem>.<call name>
+	managedAPISubsystems = map[APIType]string{
+		DockerAPI:    DockerSubsystem,
+		TaskEngine:   TaskEngineSubsystem,
+		StateManager: StateManagerSubsystem,
+		ECSClient:    ECSClientSubsystem,
+	}
 	MetricsEngineGlobal *MetricsEngine = &MetricsEngine{
 		collection: false,
 	}
@@ -91,6 +107,8 @@ func NewMetricsEngine(cfg *config.Config, registry *prometheus.Registry) *Metric
 		aClient := NewMetricsClient(managedAPI, metricsEngine.Registry)
 		metricsEngine.managedMetrics[managedAPI] = aClient
 	}
+	metricsEngine.imageCleanup = NewImageCleanupMetricsClient(metricsEngine.Registry)
+	metricsEngine.websocket = NewWebsocketMetricsClient(metricsEngine.Registry)
 	return metricsEngine
 }
 
@@ -114,6 +132,61 @@ func (engine *MetricsEngine) RecordECSClientMetric(callName string) func() {
 	return engine.recordGenericMetric(ECSClient, callName)
 }
 
+// RecordImageEvictionMetric records an image evicted by image cleanup. The trigger is one of
+// the ImageCleanupTrigger* values and the image type is one of the ImageType* values.
+func (engine *MetricsEngine) RecordImageEvictionMetric(trigger, imageType string, sizeBytes int64) {
+	if engine == nil {
+		return
+	}
+	if entry := engine.newSinkGauge(ImageCleanupSubsystem + ".Eviction"); entry != nil {
+		entry.WithFields(map[string]interface{}{"Trigger": trigger, "ImageType": imageType}).
+			WithGauge(sizeBytes).
+			Done(nil)
+	}
+	if !engine.collection {
+		return
+	}
+	engine.imageCleanup.RecordEviction(trigger, imageType, sizeBytes)
+}
+
+// RecordDiskUsageMetric records the disk usage percentage of the Docker data root
+func (engine *MetricsEngine) RecordDiskUsageMetric(path string, usedPercent float64) {
+	if engine == nil {
+		return
+	}
+	if entry := engine.newSinkGauge(ImageCleanupSubsystem + ".DiskUsage"); entry != nil {
+		entry.WithFields(map[string]interface{}{"Path": path}).WithGauge(usedPercent).Done(nil)
+	}
+	if !engine.collection {
+		return
+	}
+	engine.imageCleanup.RecordDiskUsage(path, usedPercent)
+}
+
+// RecordWebsocketMessageMetric records a message exchanged over the websocket connection of a
+// client. The client is one of the WebsocketClient* values and the direction is one of the
+// WebsocketDirection* values.
+func (engine *MetricsEngine) RecordWebsocketMessageMetric(client, direction, messageType string, size int) {
+	if engine == nil {
+		return
+	}
+	if entry := engine.newSinkGauge(WebsocketSubsystem + ".Message"); entry != nil {
+		entry.WithFields(map[string]interface{}{"Client": client, "Direction": direction, "MessageType": messageType}).
+			WithGauge(size).
+			Done(nil)
+	}
+	if !engine.collection {
+		return
+	}
+	engine.websocket.RecordMessage(client, direction, messageType, size)
+}
+
+// WebsocketMessageRecorder returns the recorder of the messages exchanged over the websocket
+// connection of a client, to be set in its wsclient.WSClientMinAgentConfig.
+func (engine *MetricsEngine) WebsocketMessageRecorder(client string) *WebsocketMessageRecorder {
+	return &WebsocketMessageRecorder{engine: engine, client: client}
+}
+
 // Records a call's start and returns a function to be deferred.
 // Wrapper functions will use this function for GenericMetricsClients.
 // If Metrics collection is enabled from the cfg, we record a metric with callID
@@ -124,15 +197,30 @@ func (engine *MetricsEngine) RecordECSClientMetric(callName string) func() {
 // Recording a metric in an API needs only a wrapper function that supplies the
 // APIType and called using the following format:
 // defer metrics.MetricsEngineGlobal.RecordMetricWrapper(callName)()
+// The call is also published to the sink, if one is configured, with the call
+// duration measured between the two functions.
 func (engine *MetricsEngine) recordGenericMetric(apiType APIType, callName string) func() {
 	callStarted := make(chan bool, 1)
-	if engine == nil || !engine.collection {
+	if engine == nil {
 		return func() {
 		}
 	}
+	entry := engine.newSinkEntry(managedAPISubsystems[apiType] + "." + callName)
+	if !engine.collection {
+		if entry == nil {
+			return func() {
+			}
+		}
+		return func() {
+			entry.Done(nil)
+		}
+	}
 	callID := engine.recordMetric(apiType, callName, "", callStarted)
 	return func() {
 		engine.recordMetric(apiType, callName, callID, callStarted)
+		if entry != nil {
+			entry.Done(nil)
+		}
 	}
 }
 
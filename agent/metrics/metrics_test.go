@@ -159,6 +159,84 @@ func TestMetricCollection(t *testing.T) {
 	assert.True(t, verifyStats(metricFamilies, expected), "Metrics are not accurate")
 }
 
+// Tests that image eviction decisions and disk usage are recorded with the
+// trigger and image type labels
+func TestImageCleanupMetricCollection(t *testing.T) {
+	defer func() {
+		MetricsEngineGlobal = &MetricsEngine{
+			collection: false,
+		}
+	}()
+	cfg := getTestConfig()
+	MustInit(&cfg, prometheus.NewRegistry())
+
+	MetricsEngineGlobal.RecordImageEvictionMetric(ImageCleanupTriggerDiskPressure, ImageTypeECS, 100)
+	MetricsEngineGlobal.RecordImageEvictionMetric(ImageCleanupTriggerDiskPressure, ImageTypeECS, 50)
+	MetricsEngineGlobal.RecordImageEvictionMetric(ImageCleanupTriggerPeriodic, ImageTypeNonECS, 0)
+	MetricsEngineGlobal.RecordDiskUsageMetric("/var/lib/docker", 87.5)
+
+	metricFamilies, err := MetricsEngineGlobal.Registry.Gather()
+	assert.NoError(t, err)
+	found := make(map[string]bool)
+	for _, metricFamily := range metricFamilies {
+		switch metricFamily.GetName() {
+		case "AgentMetrics_ImageCleanup_eviction_count":
+			found[metricFamily.GetName()] = true
+			assert.Len(t, metricFamily.GetMetric(), 2)
+		case "AgentMetrics_ImageCleanup_evicted_bytes":
+			found[metricFamily.GetName()] = true
+			assert.Len(t, metricFamily.GetMetric(), 1)
+			assert.Equal(t, 150.0, metricFamily.GetMetric()[0].GetCounter().GetValue())
+		case "AgentMetrics_ImageCleanup_disk_usage_percent":
+			found[metricFamily.GetName()] = true
+			assert.Equal(t, 87.5, metricFamily.GetMetric()[0].GetGauge().GetValue())
+		}
+	}
+	assert.Len(t, found, 3)
+}
+
+func TestWebsocketMetricCollection(t *testing.T) {
+	defer func() {
+		MetricsEngineGlobal = &MetricsEngine{
+			collection: false,
+		}
+	}()
+	cfg := getTestConfig()
+	MustInit(&cfg, prometheus.NewRegistry())
+
+	acsRecorder := MetricsEngineGlobal.WebsocketMessageRecorder(WebsocketClientACS)
+	acsRecorder.RecordMessageReceived("PayloadMessage", 1000)
+	acsRecorder.RecordMessageReceived("PayloadMessage", 500)
+	acsRecorder.RecordMessageSent("AckRequest", 100)
+	MetricsEngineGlobal.WebsocketMessageRecorder(WebsocketClientTCS).RecordMessageSent("PublishMetricsRequest", 2000)
+
+	metricFamilies, err := MetricsEngineGlobal.Registry.Gather()
+	assert.NoError(t, err)
+	found := make(map[string]bool)
+	for _, metricFamily := range metricFamilies {
+		switch metricFamily.GetName() {
+		case "AgentMetrics_Websocket_message_count":
+			found[metricFamily.GetName()] = true
+			assert.Len(t, metricFamily.GetMetric(), 3)
+		case "AgentMetrics_Websocket_message_bytes":
+			found[metricFamily.GetName()] = true
+			total := 0.0
+			for _, metric := range metricFamily.GetMetric() {
+				total += metric.GetCounter().GetValue()
+			}
+			assert.Equal(t, 3600.0, total)
+		}
+	}
+	assert.Len(t, found, 2)
+}
+
+func TestWebsocketMetricCollectionDisabled(t *testing.T) {
+	recorder := MetricsEngineGlobal.WebsocketMessageRecorder(WebsocketClientACS)
+	// Recording must be a no-op when the metrics are not collected
+	recorder.RecordMessageSent("AckRequest", 100)
+	recorder.RecordMessageReceived("PayloadMessage", 100)
+}
+
 // A type for storing a Tree-based map. We map the MetricName to a map of metrics
 // under that name. This second map indexes by MetricLabelName+MetricLabelValue to
 // a slice MetricType and MetricValue.
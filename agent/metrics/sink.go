@@ -0,0 +1,99 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metrics
+
+import (
+	"context"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	metricsfactory "github.com/aws/amazon-ecs-agent/ecs-agent/metrics"
+)
+
+// InitEntryFactory creates the entry factory that publishes the Agent internal metrics to the sink
+// configured with ECS_METRICS_SINK, and sets it in the Global MetricsEngine. It must be called
+// after MustInit. The metrics are flushed a last time when the context is done.
+func InitEntryFactory(ctx context.Context, cfg *config.Config) error {
+	var sink metricsfactory.Sink
+	switch cfg.MetricsSink {
+	case config.MetricsSinkStatsD:
+		statsDSink, err := metricsfactory.NewStatsDSink(cfg.MetricsSinkEndpoint)
+		if err != nil {
+			return err
+		}
+		sink = statsDSink
+	case config.MetricsSinkOTLP:
+		sink = metricsfactory.NewOTLPSink(cfg.MetricsSinkEndpoint)
+	default:
+		return nil
+	}
+	entryFactory := metricsfactory.NewSinkEntryFactory(ctx, sink, cfg.MetricsSinkFlushInterval,
+		map[string]string{metricsfactory.ClusterTag: cfg.Cluster})
+	MetricsEngineGlobal.entryFactoryLock.Lock()
+	defer MetricsEngineGlobal.entryFactoryLock.Unlock()
+	MetricsEngineGlobal.entryFactory = entryFactory
+	return nil
+}
+
+// EntryFactory returns the factory of the metrics entries published to the configured sink, or
+// a factory that doesn't publish any metrics when no sink is configured.
+func (engine *MetricsEngine) EntryFactory() metricsfactory.EntryFactory {
+	entryFactory := engine.sinkEntryFactory()
+	if entryFactory == nil {
+		return metricsfactory.NewNopEntryFactory()
+	}
+	return entryFactory
+}
+
+// SetEntryTags sets the cluster and container instance tags of the metrics published to the
+// sink, once the container instance is registered.
+func (engine *MetricsEngine) SetEntryTags(cluster, containerInstanceARN string) {
+	entryFactory := engine.sinkEntryFactory()
+	if entryFactory == nil {
+		return
+	}
+	entryFactory.SetTags(map[string]string{
+		metricsfactory.ClusterTag:           cluster,
+		metricsfactory.ContainerInstanceTag: containerInstanceARN,
+	})
+}
+
+// sinkEntryFactory returns the factory of the metrics entries published to the sink, or nil when
+// no sink is configured.
+func (engine *MetricsEngine) sinkEntryFactory() *metricsfactory.SinkEntryFactory {
+	if engine == nil {
+		return nil
+	}
+	engine.entryFactoryLock.RLock()
+	defer engine.entryFactoryLock.RUnlock()
+	return engine.entryFactory
+}
+
+// newSinkEntry returns a new entry published to the sink, or nil when no sink is configured.
+func (engine *MetricsEngine) newSinkEntry(name string) metricsfactory.Entry {
+	entryFactory := engine.sinkEntryFactory()
+	if entryFactory == nil {
+		return nil
+	}
+	return entryFactory.New(name)
+}
+
+// newSinkGauge returns a new entry that is not timed published to the sink, for the metrics that
+// only report a value, or nil when no sink is configured.
+func (engine *MetricsEngine) newSinkGauge(name string) metricsfactory.Entry {
+	entryFactory := engine.sinkEntryFactory()
+	if entryFactory == nil {
+		return nil
+	}
+	return entryFactory.NewGauge(name)
+}
@@ -0,0 +1,134 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	metricsfactory "github.com/aws/amazon-ecs-agent/ecs-agent/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntryFactoryWithoutSink(t *testing.T) {
+	defer func() {
+		MetricsEngineGlobal = &MetricsEngine{
+			collection: false,
+		}
+	}()
+	cfg := config.DefaultConfig()
+	require.NoError(t, InitEntryFactory(context.Background(), &cfg))
+	assert.Nil(t, MetricsEngineGlobal.entryFactory)
+	assert.Equal(t, metricsfactory.NewNopEntryFactory(), MetricsEngineGlobal.EntryFactory())
+	// Setting the tags without a sink is a no-op
+	MetricsEngineGlobal.SetEntryTags("cluster", "instance")
+}
+
+func TestInitEntryFactoryInvalidStatsDEndpoint(t *testing.T) {
+	defer func() {
+		MetricsEngineGlobal = &MetricsEngine{
+			collection: false,
+		}
+	}()
+	cfg := config.DefaultConfig()
+	cfg.MetricsSink = config.MetricsSinkStatsD
+	cfg.MetricsSinkEndpoint = "no-port"
+	assert.Error(t, InitEntryFactory(context.Background(), &cfg))
+	assert.Nil(t, MetricsEngineGlobal.entryFactory)
+}
+
+// TestStatsDSinkMetrics verifies that the metrics of the managed APIs are published to the
+// sink with the cluster and container instance tags, with and without Prometheus.
+func TestStatsDSinkMetrics(t *testing.T) {
+	for _, prometheusEnabled := range []bool{false, true} {
+		t.Run(fmt.Sprintf("prometheus=%t", prometheusEnabled), func(t *testing.T) {
+			defer func() {
+				MetricsEngineGlobal = &MetricsEngine{
+					collection: false,
+				}
+			}()
+			server, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+			require.NoError(t, err)
+			defer server.Close()
+
+			cfg := config.DefaultConfig()
+			cfg.PrometheusMetricsEnabled = prometheusEnabled
+			cfg.Cluster = "cluster"
+			cfg.MetricsSink = config.MetricsSinkStatsD
+			cfg.MetricsSinkEndpoint = server.LocalAddr().String()
+			cfg.MetricsSinkFlushInterval = time.Hour
+			MustInit(&cfg, prometheus.NewRegistry())
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			require.NoError(t, InitEntryFactory(ctx, &cfg))
+			MetricsEngineGlobal.SetEntryTags(cfg.Cluster, "instance")
+
+			MetricsEngineGlobal.RecordDockerMetric("PULL_IMAGE")()
+			MetricsEngineGlobal.EntryFactory().New(metricsfactory.GetCredentialsMetricName).Done(nil)
+			MetricsEngineGlobal.RecordDiskUsageMetric("/var/lib/docker", 50)
+			MetricsEngineGlobal.EntryFactory().Flush()
+
+			buf := make([]byte, 4096)
+			require.NoError(t, server.SetReadDeadline(time.Now().Add(5*time.Second)))
+			n, err := server.Read(buf)
+			require.NoError(t, err)
+			lines := strings.Split(string(buf[:n]), "\n")
+			require.Len(t, lines, 6)
+			assert.Equal(t, "DockerAPI.PULL_IMAGE.count:1|c|#cluster:cluster,container_instance:instance", lines[0])
+			assert.True(t, strings.HasPrefix(lines[1], "DockerAPI.PULL_IMAGE.duration:"), lines[1])
+			assert.Equal(t, "MetadataServer.GetCredentials.count:1|c|#cluster:cluster,container_instance:instance", lines[2])
+			// gauge only metrics are not timed
+			assert.Equal(t, "ImageCleanup.DiskUsage.count:1|c|#Path:/var/lib/docker,cluster:cluster,container_instance:instance", lines[4])
+			assert.Equal(t, "ImageCleanup.DiskUsage.gauge:50|g|#Path:/var/lib/docker,cluster:cluster,container_instance:instance", lines[5])
+		})
+	}
+}
+
+// TestInitEntryFactoryWhileRecording verifies that the entry factory can be set while the metrics
+// are recorded by other goroutines.
+func TestInitEntryFactoryWhileRecording(t *testing.T) {
+	defer func() {
+		MetricsEngineGlobal = &MetricsEngine{
+			collection: false,
+		}
+	}()
+	cfg := config.DefaultConfig()
+	cfg.MetricsSink = config.MetricsSinkOTLP
+	cfg.MetricsSinkFlushInterval = time.Hour
+	MetricsEngineGlobal = &MetricsEngine{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			MetricsEngineGlobal.EntryFactory().New(metricsfactory.GetCredentialsMetricName).Done(nil)
+			MetricsEngineGlobal.SetEntryTags(cfg.Cluster, "instance")
+		}
+	}()
+	require.NoError(t, InitEntryFactory(ctx, &cfg))
+	<-done
+	assert.NotNil(t, MetricsEngineGlobal.sinkEntryFactory())
+}
@@ -0,0 +1,86 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	WebsocketSubsystem = "Websocket"
+
+	// WebsocketClientACS labels the messages exchanged with ACS
+	WebsocketClientACS = "ACS"
+	// WebsocketClientTCS labels the messages exchanged with TCS
+	WebsocketClientTCS = "TCS"
+
+	// WebsocketDirectionSent labels the messages sent to the backend
+	WebsocketDirectionSent = "sent"
+	// WebsocketDirectionReceived labels the messages received from the backend
+	WebsocketDirectionReceived = "received"
+)
+
+// WebsocketMetrics records the messages exchanged over the ACS and TCS websocket connections:
+//  1. A counter vector of messages per client, direction and message type
+//  2. A counter vector of message bytes per client, direction and message type
+type WebsocketMetrics struct {
+	messageCounterVec *prometheus.CounterVec
+	messageBytesVec   *prometheus.CounterVec
+}
+
+func NewWebsocketMetricsClient(registry *prometheus.Registry) *WebsocketMetrics {
+	messageCounterVec := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: AgentNamespace,
+		Subsystem: WebsocketSubsystem,
+		Name:      "message_count",
+		Help:      "Number of messages exchanged over the websocket connections",
+	}, []string{"Client", "Direction", "MessageType"})
+	registry.MustRegister(messageCounterVec)
+
+	messageBytesVec := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: AgentNamespace,
+		Subsystem: WebsocketSubsystem,
+		Name:      "message_bytes",
+		Help:      "Uncompressed size in bytes of the messages exchanged over the websocket connections",
+	}, []string{"Client", "Direction", "MessageType"})
+	registry.MustRegister(messageBytesVec)
+
+	return &WebsocketMetrics{
+		messageCounterVec: messageCounterVec,
+		messageBytesVec:   messageBytesVec,
+	}
+}
+
+// RecordMessage records a single message exchanged with the backend
+func (wm *WebsocketMetrics) RecordMessage(client, direction, messageType string, size int) {
+	wm.messageCounterVec.WithLabelValues(client, direction, messageType).Inc()
+	wm.messageBytesVec.WithLabelValues(client, direction, messageType).Add(float64(size))
+}
+
+// WebsocketMessageRecorder records the messages of a single websocket client with the
+// MetricsEngine. It implements wsclient.MessageMetricsRecorder.
+type WebsocketMessageRecorder struct {
+	engine *MetricsEngine
+	client string
+}
+
+// RecordMessageSent records a message sent to the backend
+func (r *WebsocketMessageRecorder) RecordMessageSent(messageType string, size int) {
+	r.engine.RecordWebsocketMessageMetric(r.client, WebsocketDirectionSent, messageType, size)
+}
+
+// RecordMessageReceived records a message received from the backend
+func (r *WebsocketMessageRecorder) RecordMessageReceived(messageType string, size int) {
+	r.engine.RecordWebsocketMessageMetric(r.client, WebsocketDirectionReceived, messageType, size)
+}
@@ -0,0 +1,193 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package standalone
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/retry"
+	"github.com/gorilla/mux"
+)
+
+const (
+	// TasksPath is the path of the local API that lists the standalone tasks, and runs new ones
+	TasksPath = "/v1/tasks"
+	// TaskPath is the path of the local API that stops a standalone task
+	TaskPath = TasksPath + "/{id}"
+
+	// maxTaskDefinitionSize is the maximum size of a task definition posted to the local API
+	maxTaskDefinitionSize = 1024 * 1024
+	readTimeout           = 5 * time.Second
+	writeTimeout          = 5 * time.Second
+)
+
+// TaskResponse is a standalone task returned by the local API
+type TaskResponse struct {
+	TaskARN       string `json:"taskArn"`
+	File          string `json:"file"`
+	Family        string `json:"family,omitempty"`
+	KnownStatus   string `json:"knownStatus,omitempty"`
+	DesiredStatus string `json:"desiredStatus,omitempty"`
+}
+
+// ErrorResponse is the body of the local API errors
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+func (source *Source) apiServerSetup() *http.Server {
+	router := mux.NewRouter()
+	router.HandleFunc(TasksPath, source.listTasksHandler).Methods(http.MethodGet)
+	router.HandleFunc(TasksPath, source.runTaskHandler).Methods(http.MethodPost)
+	router.HandleFunc(TaskPath, source.stopTaskHandler).Methods(http.MethodDelete)
+
+	return &http.Server{
+		// the API runs arbitrary containers, it is never exposed outside of the host
+		Addr:         net.JoinHostPort("127.0.0.1", strconv.Itoa(config.AgentStandaloneAPIPort)),
+		Handler:      router,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+	}
+}
+
+// serveAPI serves the local API until ctx is cancelled
+func (source *Source) serveAPI(ctx context.Context) {
+	server := source.apiServerSetup()
+	go func() {
+		<-ctx.Done()
+		if err := server.Shutdown(context.Background()); err != nil {
+			logger.Info("Standalone API server shutdown", logger.Fields{
+				field.Error: err,
+			})
+		}
+	}()
+
+	retry.RetryWithBackoff(retry.NewExponentialBackoff(time.Second, time.Minute, 0.2, 2), func() error {
+		if err := server.ListenAndServe(); err != http.ErrServerClosed {
+			logger.Error("Error running the standalone API", logger.Fields{
+				field.Error: err,
+			})
+			return err
+		}
+		// server was cleanly closed via context
+		return nil
+	})
+}
+
+// listTasksHandler returns the tasks of the task definition files
+func (source *Source) listTasksHandler(w http.ResponseWriter, r *http.Request) {
+	names, arns := source.taskFiles()
+	tasks := make([]TaskResponse, 0, len(names))
+	for _, name := range names {
+		response := TaskResponse{TaskARN: arns[name], File: name}
+		if task, ok := source.taskEngine.GetTaskByArn(arns[name]); ok {
+			response.Family = task.Family
+			response.KnownStatus = task.GetKnownStatus().String()
+			response.DesiredStatus = task.GetDesiredStatus().String()
+		}
+		tasks = append(tasks, response)
+	}
+	writeJSON(w, http.StatusOK, tasks)
+}
+
+// runTaskHandler writes the posted task definition to the task directory, and starts its task
+func (source *Source) runTaskHandler(w http.ResponseWriter, r *http.Request) {
+	content, err := io.ReadAll(io.LimitReader(r.Body, maxTaskDefinitionSize+1))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if len(content) > maxTaskDefinitionSize {
+		writeJSON(w, http.StatusRequestEntityTooLarge, ErrorResponse{Error: "task definition is too large"})
+		return
+	}
+	def, err := ReadTaskDefinition(bytes.NewReader(content))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	name, err := source.writeTaskFile(def.Family, content)
+	if err != nil {
+		logger.Error("Unable to write task definition file", logger.Fields{
+			"family":    def.Family,
+			field.Error: err,
+		})
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "unable to write task definition file"})
+		return
+	}
+	source.scan()
+
+	_, arns := source.taskFiles()
+	writeJSON(w, http.StatusCreated, TaskResponse{TaskARN: arns[name], File: name, Family: def.Family})
+}
+
+// stopTaskHandler removes the task definition file of a task from the task directory, and stops the task
+func (source *Source) stopTaskHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	names, arns := source.taskFiles()
+	for _, name := range names {
+		if taskIDFromARN(arns[name]) != id {
+			continue
+		}
+		if err := os.Remove(filepath.Join(source.taskDir, name)); err != nil && !os.IsNotExist(err) {
+			writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "unable to remove task definition file"})
+			return
+		}
+		source.scan()
+		writeJSON(w, http.StatusAccepted, TaskResponse{TaskARN: arns[name], File: name})
+		return
+	}
+	writeJSON(w, http.StatusNotFound, ErrorResponse{Error: fmt.Sprintf("task %s not found", id)})
+}
+
+// writeTaskFile writes a task definition to a new file of the task directory, and returns the file name.
+// The file is renamed once written, so that a scan never reads a partial file.
+func (source *Source) writeTaskFile(family string, content []byte) (string, error) {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("%s-%s%s", filepath.Base(family), hex.EncodeToString(suffix), taskDefinitionFileExtension)
+	path := filepath.Join(source.taskDir, name)
+	if err := os.WriteFile(path+".tmp", content, 0644); err != nil {
+		return "", err
+	}
+	return name, os.Rename(path+".tmp", path)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		logger.Warn("Unable to write standalone API response", logger.Fields{
+			field.Error: err,
+		})
+	}
+}
@@ -0,0 +1,216 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package standalone
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/api"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/ecs_client/model/ecs"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+	"github.com/pborman/uuid"
+	"github.com/pkg/errors"
+)
+
+const (
+	// StateChangeLogName is the name of the file, in the data directory, the task and container
+	// state changes are appended to in standalone mode
+	StateChangeLogName = "standalone-state-changes.log"
+	// maxStateChangeLogSize is the size after which the state change log is rotated. A single
+	// rotated log is kept.
+	maxStateChangeLogSize = 10 * 1024 * 1024
+	// standaloneAccountID is the account id of the ARNs created in standalone mode, which are
+	// not known to ECS
+	standaloneAccountID = "000000000000"
+	// defaultRegion is the region of the ARNs created in standalone mode when the region is unknown
+	defaultRegion = "local"
+
+	stateChangeTypeTask       = "task"
+	stateChangeTypeContainer  = "container"
+	stateChangeTypeAttachment = "attachment"
+)
+
+var errStandalone = errors.New("not available in standalone mode")
+
+// StateChange is a state change recorded in the state change log, as one JSON document per line
+type StateChange struct {
+	Time          time.Time `json:"time"`
+	Type          string    `json:"type"`
+	TaskARN       string    `json:"taskArn,omitempty"`
+	ContainerName string    `json:"containerName,omitempty"`
+	RuntimeID     string    `json:"runtimeId,omitempty"`
+	AttachmentARN string    `json:"attachmentArn,omitempty"`
+	Status        string    `json:"status"`
+	Reason        string    `json:"reason,omitempty"`
+	ExitCode      *int      `json:"exitCode,omitempty"`
+}
+
+// Client is the api.ECSClient used in standalone mode. It records the state changes in a local
+// log instead of submitting them to ECS, and fails the calls that require ECS.
+type Client struct {
+	cfg *config.Config
+	// hostResourcesClient computes the resources of the host, which does not call ECS
+	hostResourcesClient api.ECSClient
+	logPath             string
+	lock                sync.Mutex
+}
+
+// NewClient creates the standalone client. The host resources are computed by hostResourcesClient,
+// which is the ECS client the agent uses when it is not in standalone mode.
+func NewClient(cfg *config.Config, hostResourcesClient api.ECSClient) *Client {
+	return &Client{
+		cfg:                 cfg,
+		hostResourcesClient: hostResourcesClient,
+		logPath:             filepath.Join(cfg.DataDir, StateChangeLogName),
+	}
+}
+
+// RegisterContainerInstance returns the existing container instance ARN, or a new local one. Nothing is
+// registered with ECS, and the availability zone is unknown.
+func (client *Client) RegisterContainerInstance(existingContainerInstanceArn string, attributes []*ecs.Attribute,
+	tags []*ecs.Tag, registrationToken string, platformDevices []*ecs.PlatformDevice, outpostARN string) (string, string, error) {
+	if existingContainerInstanceArn != "" {
+		return existingContainerInstanceArn, "", nil
+	}
+	return localARN(client.cfg, "container-instance", uuid.New()), "", nil
+}
+
+// SubmitTaskStateChange appends the task state change, and the container state changes it contains,
+// to the state change log
+func (client *Client) SubmitTaskStateChange(change api.TaskStateChange) error {
+	changes := []StateChange{{
+		Type:    stateChangeTypeTask,
+		TaskARN: change.TaskARN,
+		Status:  change.Status.String(),
+		Reason:  change.Reason,
+	}}
+	for _, container := range change.Containers {
+		changes = append(changes, containerStateChange(container))
+	}
+	return client.record(changes...)
+}
+
+// SubmitContainerStateChange appends the container state change to the state change log
+func (client *Client) SubmitContainerStateChange(change api.ContainerStateChange) error {
+	return client.record(containerStateChange(change))
+}
+
+// SubmitAttachmentStateChange appends the attachment state change to the state change log
+func (client *Client) SubmitAttachmentStateChange(change api.AttachmentStateChange) error {
+	if change.Attachment == nil {
+		return nil
+	}
+	return client.record(StateChange{
+		Type:          stateChangeTypeAttachment,
+		TaskARN:       change.Attachment.TaskARN,
+		AttachmentARN: change.Attachment.AttachmentARN,
+		Status:        change.Attachment.Status.String(),
+	})
+}
+
+// DiscoverPollEndpoint fails, there is no ACS endpoint in standalone mode
+func (client *Client) DiscoverPollEndpoint(containerInstanceArn string) (string, error) {
+	return "", errStandalone
+}
+
+// DiscoverTelemetryEndpoint fails, there is no TCS endpoint in standalone mode
+func (client *Client) DiscoverTelemetryEndpoint(containerInstanceArn string) (string, error) {
+	return "", errStandalone
+}
+
+// DiscoverServiceConnectEndpoint fails, there is no Service Connect endpoint in standalone mode
+func (client *Client) DiscoverServiceConnectEndpoint(containerInstanceArn string) (string, error) {
+	return "", errStandalone
+}
+
+// GetResourceTags returns no tags, the local resources are not tagged
+func (client *Client) GetResourceTags(resourceArn string) ([]*ecs.Tag, error) {
+	return nil, nil
+}
+
+// UpdateContainerInstancesState fails, the container instance is not registered with ECS
+func (client *Client) UpdateContainerInstancesState(instanceARN, status string) error {
+	return errStandalone
+}
+
+// GetHostResources returns the resources of the host
+func (client *Client) GetHostResources() (map[string]*ecs.Resource, error) {
+	return client.hostResourcesClient.GetHostResources()
+}
+
+func containerStateChange(change api.ContainerStateChange) StateChange {
+	return StateChange{
+		Type:          stateChangeTypeContainer,
+		TaskARN:       change.TaskArn,
+		ContainerName: change.ContainerName,
+		RuntimeID:     change.RuntimeID,
+		Status:        change.Status.String(),
+		Reason:        change.Reason,
+		ExitCode:      change.ExitCode,
+	}
+}
+
+// record appends the state changes to the state change log, rotating the log when it is full
+func (client *Client) record(changes ...StateChange) error {
+	client.lock.Lock()
+	defer client.lock.Unlock()
+
+	if info, err := os.Stat(client.logPath); err == nil && info.Size() >= maxStateChangeLogSize {
+		if err := os.Rename(client.logPath, client.logPath+".1"); err != nil {
+			logger.Warn("Unable to rotate the standalone state change log", logger.Fields{
+				"path":      client.logPath,
+				field.Error: err,
+			})
+		}
+	}
+	file, err := os.OpenFile(client.logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return errors.Wrap(err, "unable to open the state change log")
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	now := time.Now().UTC()
+	for _, change := range changes {
+		change.Time = now
+		if err := encoder.Encode(change); err != nil {
+			return errors.Wrap(err, "unable to write to the state change log")
+		}
+		logger.Info("Recorded state change", logger.Fields{
+			"type":          change.Type,
+			field.TaskARN:   change.TaskARN,
+			"containerName": change.ContainerName,
+			"status":        change.Status,
+		})
+	}
+	return nil
+}
+
+// localARN returns the ARN of a resource created in standalone mode
+func localARN(cfg *config.Config, resourceType, id string) string {
+	region := cfg.AWSRegion
+	if region == "" {
+		region = defaultRegion
+	}
+	return fmt.Sprintf("arn:aws:ecs:%s:%s:%s/%s/%s", region, standaloneAccountID, resourceType, cfg.Cluster,
+		strings.ReplaceAll(id, "-", ""))
+}
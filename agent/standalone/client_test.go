@@ -0,0 +1,150 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package standalone
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/api"
+	mock_api "github.com/aws/amazon-ecs-agent/agent/api/mocks"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testConfig(t *testing.T) *config.Config {
+	dataDir := t.TempDir()
+	return &config.Config{
+		AWSRegion:         "us-west-2",
+		Cluster:           "default",
+		DataDir:           dataDir,
+		StandaloneTaskDir: filepath.Join(dataDir, "tasks"),
+	}
+}
+
+func readStateChanges(t *testing.T, path string) []StateChange {
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var changes []StateChange
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var change StateChange
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &change))
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+func TestClientRecordsStateChanges(t *testing.T) {
+	cfg := testConfig(t)
+	client := NewClient(cfg, nil)
+
+	exitCode := 1
+	require.NoError(t, client.SubmitContainerStateChange(api.ContainerStateChange{
+		TaskArn:       testTaskARN,
+		ContainerName: "init",
+		RuntimeID:     "dockerid",
+		Status:        apicontainerstatus.ContainerStopped,
+		ExitCode:      &exitCode,
+	}))
+	require.NoError(t, client.SubmitTaskStateChange(api.TaskStateChange{
+		TaskARN: testTaskARN,
+		Status:  apitaskstatus.TaskStopped,
+		Reason:  "Essential container in task exited",
+		Containers: []api.ContainerStateChange{{
+			TaskArn:       testTaskARN,
+			ContainerName: "app",
+			Status:        apicontainerstatus.ContainerStopped,
+		}},
+	}))
+
+	changes := readStateChanges(t, filepath.Join(cfg.DataDir, StateChangeLogName))
+	require.Len(t, changes, 3)
+	assert.Equal(t, stateChangeTypeContainer, changes[0].Type)
+	assert.Equal(t, "init", changes[0].ContainerName)
+	assert.Equal(t, "STOPPED", changes[0].Status)
+	assert.Equal(t, aws.Int(1), changes[0].ExitCode)
+	assert.Equal(t, stateChangeTypeTask, changes[1].Type)
+	assert.Equal(t, testTaskARN, changes[1].TaskARN)
+	assert.Equal(t, "Essential container in task exited", changes[1].Reason)
+	assert.Equal(t, "app", changes[2].ContainerName)
+	for _, change := range changes {
+		assert.False(t, change.Time.IsZero())
+	}
+}
+
+func TestClientRotatesStateChangeLog(t *testing.T) {
+	cfg := testConfig(t)
+	client := NewClient(cfg, nil)
+	logPath := filepath.Join(cfg.DataDir, StateChangeLogName)
+	require.NoError(t, os.WriteFile(logPath, []byte(strings.Repeat("x", maxStateChangeLogSize)), 0644))
+
+	require.NoError(t, client.SubmitTaskStateChange(api.TaskStateChange{
+		TaskARN: testTaskARN,
+		Status:  apitaskstatus.TaskRunning,
+	}))
+	assert.Len(t, readStateChanges(t, logPath), 1)
+	info, err := os.Stat(logPath + ".1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(maxStateChangeLogSize), info.Size())
+}
+
+func TestClientRegisterContainerInstance(t *testing.T) {
+	client := NewClient(testConfig(t), nil)
+
+	arn, availabilityZone, err := client.RegisterContainerInstance("", nil, nil, "", nil, "")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(arn, "arn:aws:ecs:us-west-2:000000000000:container-instance/default/"), arn)
+	assert.Empty(t, availabilityZone)
+
+	existing, _, err := client.RegisterContainerInstance(arn, nil, nil, "", nil, "")
+	require.NoError(t, err)
+	assert.Equal(t, arn, existing)
+}
+
+func TestClientWithoutECS(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	hostResourcesClient := mock_api.NewMockECSClient(ctrl)
+	resources := map[string]*ecs.Resource{"CPU": {Name: aws.String("CPU")}}
+	hostResourcesClient.EXPECT().GetHostResources().Return(resources, nil)
+	client := NewClient(testConfig(t), hostResourcesClient)
+
+	hostResources, err := client.GetHostResources()
+	require.NoError(t, err)
+	assert.Equal(t, resources, hostResources)
+
+	_, err = client.DiscoverPollEndpoint("arn")
+	assert.Error(t, err)
+	_, err = client.DiscoverTelemetryEndpoint("arn")
+	assert.Error(t, err)
+	tags, err := client.GetResourceTags("arn")
+	assert.NoError(t, err)
+	assert.Empty(t, tags)
+}
@@ -0,0 +1,352 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package standalone runs the agent without ECS. The tasks are read from local task definition
+// files, or posted to a local API, and are added to the task engine as if they were received
+// from ACS. Their state changes are recorded on disk instead of being submitted to ECS.
+package standalone
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/engine"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+	"github.com/pkg/errors"
+)
+
+const (
+	// taskDefinitionFileExtension is the extension of the task definition files in the task directory
+	taskDefinitionFileExtension = ".json"
+	// scanInterval is the interval between two scans of the task directory
+	scanInterval = 5 * time.Second
+	// hashStringLength is the length of the hashes of the task ids
+	hashStringLength = 16
+)
+
+// taskFile is a task definition file of the task directory
+type taskFile struct {
+	modTime time.Time
+	size    int64
+	// taskARN is the ARN of the task running the definition, empty when the definition is invalid
+	taskARN string
+	def     *TaskDefinition
+}
+
+// Source runs one task for every task definition file in the task directory. A task is stopped
+// when its file is removed, and replaced when its file changes.
+type Source struct {
+	cfg        *config.Config
+	taskEngine engine.TaskEngine
+	taskDir    string
+	files      map[string]*taskFile
+	// restored are the running tasks of the task engine state, keyed by the hash of the name of
+	// their file, that aren't matched to a task definition file yet. They are matched by the first
+	// scan, which stops the ones whose file was changed or removed while the agent was down.
+	restored map[string]*apitask.Task
+	// generation is the generation of the last started task, so that a file that is removed and
+	// added again gets a new task instead of the one that was stopped.
+	generation int64
+	lock       sync.Mutex
+}
+
+// NewSource creates the source of the tasks of the task directory
+func NewSource(cfg *config.Config, taskEngine engine.TaskEngine) *Source {
+	return &Source{
+		cfg:        cfg,
+		taskEngine: taskEngine,
+		taskDir:    cfg.StandaloneTaskDir,
+		files:      make(map[string]*taskFile),
+	}
+}
+
+// Run scans the task directory and serves the local API until ctx is cancelled.
+func (source *Source) Run(ctx context.Context) error {
+	if err := os.MkdirAll(source.taskDir, 0755); err != nil {
+		return errors.Wrapf(err, "unable to create the task directory %s", source.taskDir)
+	}
+	logger.Info("Running tasks in standalone mode", logger.Fields{
+		"taskDir": source.taskDir,
+	})
+	source.restoreTasks()
+	go source.serveAPI(ctx)
+
+	ticker := time.NewTicker(scanInterval)
+	defer ticker.Stop()
+	for {
+		source.scan()
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// scan starts the tasks of the new and changed task definition files, and stops the tasks of the
+// changed and removed ones.
+func (source *Source) scan() {
+	source.lock.Lock()
+	defer source.lock.Unlock()
+
+	entries, err := os.ReadDir(source.taskDir)
+	if err != nil {
+		logger.Error("Unable to read the task directory", logger.Fields{
+			"taskDir":   source.taskDir,
+			field.Error: err,
+		})
+		return
+	}
+	found := make(map[string]bool)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != taskDefinitionFileExtension {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			// the file was removed since the directory was read
+			continue
+		}
+		found[name] = true
+		existing, ok := source.files[name]
+		if ok && existing.modTime.Equal(info.ModTime()) && existing.size == info.Size() {
+			continue
+		}
+		if ok {
+			source.stopTaskUnsafe(name)
+		}
+		source.startTaskUnsafe(name, info)
+	}
+	for name := range source.files {
+		if !found[name] {
+			source.stopTaskUnsafe(name)
+		}
+	}
+	// the files of the remaining restored tasks were removed while the agent was down
+	for _, task := range source.restored {
+		source.stopRestoredTaskUnsafe(task)
+	}
+	source.restored = nil
+}
+
+// restoreTasks finds the running tasks of the task definition files in the task engine state,
+// so that the first scan keeps the ones whose file is unchanged instead of starting them again.
+func (source *Source) restoreTasks() {
+	source.lock.Lock()
+	defer source.lock.Unlock()
+
+	tasks, err := source.taskEngine.ListTasks()
+	if err != nil {
+		logger.Error("Unable to list the tasks of the task engine state", logger.Fields{
+			field.Error: err,
+		})
+		return
+	}
+	source.restored = make(map[string]*apitask.Task)
+	for _, task := range tasks {
+		nameHash, _, generation, ok := parseTaskID(taskIDFromARN(task.Arn))
+		if !ok {
+			continue
+		}
+		if generation > source.generation {
+			source.generation = generation
+		}
+		if task.GetDesiredStatus().Terminal() {
+			continue
+		}
+		if existing, ok := source.restored[nameHash]; ok {
+			// only the last task of a file is kept running
+			_, _, existingGeneration, _ := parseTaskID(taskIDFromARN(existing.Arn))
+			if existingGeneration > generation {
+				existing, task = task, existing
+			}
+			source.stopRestoredTaskUnsafe(existing)
+		}
+		source.restored[nameHash] = task
+	}
+}
+
+// startTaskUnsafe adds the task of a task definition file to the task engine
+func (source *Source) startTaskUnsafe(name string, info os.FileInfo) {
+	file := &taskFile{modTime: info.ModTime(), size: info.Size()}
+	source.files[name] = file
+	restored, hasRestored := source.restored[hashString([]byte(name))]
+	delete(source.restored, hashString([]byte(name)))
+
+	content, err := os.ReadFile(filepath.Join(source.taskDir, name))
+	if err != nil {
+		logger.Error("Unable to read task definition file", logger.Fields{
+			"file":      name,
+			field.Error: err,
+		})
+		if hasRestored {
+			source.stopRestoredTaskUnsafe(restored)
+		}
+		return
+	}
+	def, err := ReadTaskDefinition(bytes.NewReader(content))
+	if err != nil {
+		logger.Error("Invalid task definition file", logger.Fields{
+			"file":      name,
+			field.Error: err,
+		})
+		if hasRestored {
+			source.stopRestoredTaskUnsafe(restored)
+		}
+		return
+	}
+	if hasRestored {
+		if _, contentHash, _, _ := parseTaskID(taskIDFromARN(restored.Arn)); contentHash == hashString(content) {
+			file.taskARN = restored.Arn
+			file.def = def
+			logger.Info("Found standalone task in the task engine state", logger.Fields{
+				"file":        name,
+				field.TaskARN: restored.Arn,
+			})
+			return
+		}
+		source.stopRestoredTaskUnsafe(restored)
+	}
+	source.generation++
+	taskARN := localARN(source.cfg, "task", taskID(name, content, source.generation))
+	task, err := NewTask(def, taskARN, apitaskstatus.TaskRunning)
+	if err != nil {
+		logger.Error("Unable to create the task of the task definition file", logger.Fields{
+			"file":      name,
+			field.Error: err,
+		})
+		return
+	}
+	file.taskARN = taskARN
+	file.def = def
+	logger.Info("Starting standalone task", logger.Fields{
+		"file":        name,
+		field.TaskARN: taskARN,
+		"family":      def.Family,
+	})
+	source.taskEngine.AddTask(task)
+}
+
+// stopTaskUnsafe sets the desired status of the task of a task definition file to STOPPED
+func (source *Source) stopTaskUnsafe(name string) {
+	file := source.files[name]
+	delete(source.files, name)
+	if file.taskARN == "" {
+		return
+	}
+	task, err := NewTask(file.def, file.taskARN, apitaskstatus.TaskStopped)
+	if err != nil {
+		logger.Error("Unable to stop standalone task", logger.Fields{
+			"file":        name,
+			field.TaskARN: file.taskARN,
+			field.Error:   err,
+		})
+		return
+	}
+	logger.Info("Stopping standalone task", logger.Fields{
+		"file":        name,
+		field.TaskARN: file.taskARN,
+	})
+	source.taskEngine.AddTask(task)
+}
+
+// stopRestoredTaskUnsafe sets the desired status of a task of the task engine state whose task
+// definition file is gone to STOPPED. Its definition is rebuilt from the task, which is enough to
+// stop it.
+func (source *Source) stopRestoredTaskUnsafe(task *apitask.Task) {
+	def := &TaskDefinition{Family: task.Family}
+	if revision, err := strconv.ParseInt(task.Version, 10, 64); err == nil {
+		def.Revision = revision
+	}
+	for _, container := range task.Containers {
+		def.ContainerDefinitions = append(def.ContainerDefinitions, &ContainerDefinition{
+			Name:  container.Name,
+			Image: container.Image,
+		})
+	}
+	stopped, err := NewTask(def, task.Arn, apitaskstatus.TaskStopped)
+	if err != nil {
+		logger.Error("Unable to stop standalone task", logger.Fields{
+			field.TaskARN: task.Arn,
+			field.Error:   err,
+		})
+		return
+	}
+	logger.Info("Stopping standalone task whose task definition file was changed or removed", logger.Fields{
+		field.TaskARN: task.Arn,
+	})
+	source.taskEngine.AddTask(stopped)
+}
+
+// taskFiles returns the names of the task definition files, sorted by name, and the ARNs of their tasks
+func (source *Source) taskFiles() ([]string, map[string]string) {
+	source.lock.Lock()
+	defer source.lock.Unlock()
+	names := make([]string, 0, len(source.files))
+	arns := make(map[string]string)
+	for name, file := range source.files {
+		if file.taskARN == "" {
+			continue
+		}
+		names = append(names, name)
+		arns[name] = file.taskARN
+	}
+	sort.Strings(names)
+	return names, arns
+}
+
+// taskID returns the id of the task of a task definition file. The id is made of the hashes of the
+// name and content of the file, so that the agent finds the task of a file in its state after a
+// restart, and of the generation of the task, so that every task started for a file is new.
+func taskID(name string, content []byte, generation int64) string {
+	return fmt.Sprintf("%s%s%d", hashString([]byte(name)), hashString(content), generation)
+}
+
+// parseTaskID returns the hashes of the name and content of the task definition file, and the
+// generation, of a task id returned by taskID.
+func parseTaskID(id string) (string, string, int64, bool) {
+	if len(id) <= 2*hashStringLength {
+		return "", "", 0, false
+	}
+	generation, err := strconv.ParseInt(id[2*hashStringLength:], 10, 64)
+	if err != nil {
+		return "", "", 0, false
+	}
+	return id[:hashStringLength], id[hashStringLength : 2*hashStringLength], generation, true
+}
+
+// hashString returns a hex encoded hash of data, of hashStringLength characters
+func hashString(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:hashStringLength/2])
+}
+
+// taskIDFromARN returns the id of a task from its ARN
+func taskIDFromARN(taskARN string) string {
+	return taskARN[strings.LastIndex(taskARN, "/")+1:]
+}
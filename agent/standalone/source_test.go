@@ -0,0 +1,226 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package standalone
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	mock_engine "github.com/aws/amazon-ecs-agent/agent/engine/mocks"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testFile = "web.json"
+
+func newTestSource(t *testing.T) (*Source, *mock_engine.MockTaskEngine) {
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+	taskEngine := mock_engine.NewMockTaskEngine(ctrl)
+	source := NewSource(testConfig(t), taskEngine)
+	require.NoError(t, os.MkdirAll(source.taskDir, 0755))
+	return source, taskEngine
+}
+
+func writeTaskFile(t *testing.T, source *Source, name, content string, modTime time.Time) {
+	path := filepath.Join(source.taskDir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func TestScanStartsAndStopsTasks(t *testing.T) {
+	source, taskEngine := newTestSource(t)
+	writeTaskFile(t, source, testFile, testTaskDefinition, time.Now())
+	writeTaskFile(t, source, "invalid.json", `{"family": "invalid"}`, time.Now())
+	writeTaskFile(t, source, "README.md", "not a task definition", time.Now())
+
+	var started *apitask.Task
+	taskEngine.EXPECT().AddTask(gomock.Any()).Do(func(task *apitask.Task) {
+		started = task
+	})
+	source.scan()
+	require.NotNil(t, started)
+	assert.Equal(t, "web", started.Family)
+	assert.Equal(t, apitaskstatus.TaskRunning, started.GetDesiredStatus())
+	assert.True(t, strings.HasPrefix(started.Arn, "arn:aws:ecs:us-west-2:000000000000:task/default/"), started.Arn)
+
+	// unchanged files are not started again
+	source.scan()
+
+	require.NoError(t, os.Remove(filepath.Join(source.taskDir, testFile)))
+	taskEngine.EXPECT().AddTask(gomock.Any()).Do(func(task *apitask.Task) {
+		assert.Equal(t, started.Arn, task.Arn)
+		assert.Equal(t, apitaskstatus.TaskStopped, task.GetDesiredStatus())
+	})
+	source.scan()
+	names, _ := source.taskFiles()
+	assert.Empty(t, names)
+
+	// a file added again unchanged gets a new task
+	writeTaskFile(t, source, testFile, testTaskDefinition, time.Now())
+	taskEngine.EXPECT().AddTask(gomock.Any()).Do(func(task *apitask.Task) {
+		assert.NotEqual(t, started.Arn, task.Arn)
+		assert.Equal(t, apitaskstatus.TaskRunning, task.GetDesiredStatus())
+	})
+	source.scan()
+}
+
+func TestScanRestoresTasks(t *testing.T) {
+	source, taskEngine := newTestSource(t)
+	changed := strings.Replace(testTaskDefinition, `"revision": 3`, `"revision": 4`, 1)
+	writeTaskFile(t, source, testFile, testTaskDefinition, time.Now())
+	writeTaskFile(t, source, "changed.json", changed, time.Now())
+
+	newStateTask := func(name, content string, generation int64, desiredStatus apitaskstatus.TaskStatus) *apitask.Task {
+		def, err := ReadTaskDefinition(strings.NewReader(content))
+		require.NoError(t, err)
+		task, err := NewTask(def, localARN(source.cfg, "task", taskID(name, []byte(content), generation)), desiredStatus)
+		require.NoError(t, err)
+		return task
+	}
+	unchanged := newStateTask(testFile, testTaskDefinition, 1, apitaskstatus.TaskRunning)
+	outdated := newStateTask("changed.json", testTaskDefinition, 2, apitaskstatus.TaskRunning)
+	removed := newStateTask("removed.json", testTaskDefinition, 3, apitaskstatus.TaskRunning)
+	stopped := newStateTask(testFile, testTaskDefinition, 7, apitaskstatus.TaskStopped)
+	taskEngine.EXPECT().ListTasks().Return([]*apitask.Task{unchanged, outdated, removed, stopped}, nil)
+	source.restoreTasks()
+	assert.Equal(t, int64(7), source.generation)
+
+	var stoppedARNs []string
+	var started *apitask.Task
+	taskEngine.EXPECT().AddTask(gomock.Any()).Do(func(task *apitask.Task) {
+		if task.GetDesiredStatus() == apitaskstatus.TaskStopped {
+			stoppedARNs = append(stoppedARNs, task.Arn)
+		} else {
+			started = task
+		}
+	}).Times(3)
+	source.scan()
+	assert.ElementsMatch(t, []string{outdated.Arn, removed.Arn}, stoppedARNs,
+		"the tasks whose file was changed or removed while the agent was down are stopped")
+	require.NotNil(t, started)
+	assert.Equal(t, localARN(source.cfg, "task", taskID("changed.json", []byte(changed), 8)), started.Arn)
+	names, arns := source.taskFiles()
+	assert.Equal(t, []string{"changed.json", testFile}, names)
+	assert.Equal(t, unchanged.Arn, arns[testFile], "the task of the unchanged file is kept")
+	assert.Empty(t, source.restored)
+}
+
+func TestScanReplacesChangedTasks(t *testing.T) {
+	source, taskEngine := newTestSource(t)
+	modTime := time.Now().Add(-time.Minute)
+	writeTaskFile(t, source, testFile, testTaskDefinition, modTime)
+
+	var arns []string
+	taskEngine.EXPECT().AddTask(gomock.Any()).Do(func(task *apitask.Task) {
+		arns = append(arns, task.Arn)
+	}).Times(3)
+	source.scan()
+
+	changed := strings.Replace(testTaskDefinition, `"revision": 3`, `"revision": 4`, 1)
+	writeTaskFile(t, source, testFile, changed, time.Now())
+	source.scan()
+
+	require.Len(t, arns, 3)
+	assert.Equal(t, arns[0], arns[1], "the task of the previous definition is stopped")
+	assert.NotEqual(t, arns[0], arns[2], "the task of the new definition is started")
+}
+
+func TestTaskID(t *testing.T) {
+	content := []byte(testTaskDefinition)
+	assert.Equal(t, taskID(testFile, content, 1), taskID(testFile, content, 1))
+	assert.NotEqual(t, taskID(testFile, content, 1), taskID("other.json", content, 1))
+	assert.NotEqual(t, taskID(testFile, content, 1), taskID(testFile, content, 2))
+
+	nameHash, contentHash, generation, ok := parseTaskID(taskID(testFile, content, 5))
+	require.True(t, ok)
+	assert.Equal(t, hashString([]byte(testFile)), nameHash)
+	assert.Equal(t, hashString(content), contentHash)
+	assert.Equal(t, int64(5), generation)
+	_, _, _, ok = parseTaskID("0123456789abcdef0123456789abcdef")
+	assert.False(t, ok)
+	assert.Equal(t, "abc", taskIDFromARN("arn:aws:ecs:us-west-2:000000000000:task/default/abc"))
+}
+
+func TestAPIRunListAndStopTask(t *testing.T) {
+	source, taskEngine := newTestSource(t)
+	server := httptest.NewServer(source.apiServerSetup().Handler)
+	defer server.Close()
+
+	var started *apitask.Task
+	taskEngine.EXPECT().AddTask(gomock.Any()).Do(func(task *apitask.Task) {
+		started = task
+	})
+	resp, err := http.Post(server.URL+TasksPath, "application/json", strings.NewReader(testTaskDefinition))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	var created TaskResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+	require.NotNil(t, started)
+	assert.Equal(t, started.Arn, created.TaskARN)
+	assert.FileExists(t, filepath.Join(source.taskDir, created.File), "the task definition is persisted")
+
+	taskEngine.EXPECT().GetTaskByArn(created.TaskARN).Return(started, true)
+	resp, err = http.Get(server.URL + TasksPath)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var tasks []TaskResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&tasks))
+	require.Len(t, tasks, 1)
+	assert.Equal(t, created.TaskARN, tasks[0].TaskARN)
+	assert.Equal(t, "web", tasks[0].Family)
+	assert.Equal(t, "RUNNING", tasks[0].DesiredStatus)
+
+	taskEngine.EXPECT().AddTask(gomock.Any()).Do(func(task *apitask.Task) {
+		assert.Equal(t, apitaskstatus.TaskStopped, task.GetDesiredStatus())
+	})
+	req, err := http.NewRequest(http.MethodDelete, server.URL+TasksPath+"/"+taskIDFromARN(created.TaskARN), nil)
+	require.NoError(t, err)
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	assert.NoFileExists(t, filepath.Join(source.taskDir, created.File))
+}
+
+func TestAPIErrors(t *testing.T) {
+	source, _ := newTestSource(t)
+	server := httptest.NewServer(source.apiServerSetup().Handler)
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+TasksPath, "application/json", strings.NewReader(`{"family": "web"}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	req, err := http.NewRequest(http.MethodDelete, server.URL+TasksPath+"/unknown", nil)
+	require.NoError(t, err)
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
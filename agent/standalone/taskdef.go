@@ -0,0 +1,261 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package standalone
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
+	"github.com/aws/aws-sdk-go/aws"
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/pkg/errors"
+)
+
+const (
+	// defaultTaskVersion is the version of the tasks whose definition has no revision
+	defaultTaskVersion = "1"
+	hostVolumeType     = "host"
+	dockerVolumeType   = "docker"
+)
+
+// TaskDefinition is the subset of an ECS task definition, in the JSON format of the
+// RegisterTaskDefinition API, that can be run in standalone mode. Other fields, e.g. the
+// ones returned by DescribeTaskDefinition, are ignored.
+type TaskDefinition struct {
+	Family               string                 `json:"family"`
+	Revision             int64                  `json:"revision,omitempty"`
+	NetworkMode          string                 `json:"networkMode,omitempty"`
+	PidMode              string                 `json:"pidMode,omitempty"`
+	IpcMode              string                 `json:"ipcMode,omitempty"`
+	Cpu                  string                 `json:"cpu,omitempty"`
+	Memory               string                 `json:"memory,omitempty"`
+	ContainerDefinitions []*ContainerDefinition `json:"containerDefinitions"`
+	Volumes              []*VolumeDefinition    `json:"volumes,omitempty"`
+}
+
+// ContainerDefinition is a container of a TaskDefinition
+type ContainerDefinition struct {
+	Name              string                        `json:"name"`
+	Image             string                        `json:"image"`
+	Cpu               int64                         `json:"cpu,omitempty"`
+	Memory            int64                         `json:"memory,omitempty"`
+	MemoryReservation int64                         `json:"memoryReservation,omitempty"`
+	Essential         *bool                         `json:"essential,omitempty"`
+	Command           []string                      `json:"command,omitempty"`
+	EntryPoint        []string                      `json:"entryPoint,omitempty"`
+	Environment       []KeyValuePair                `json:"environment,omitempty"`
+	WorkingDirectory  string                        `json:"workingDirectory,omitempty"`
+	User              string                        `json:"user,omitempty"`
+	Hostname          string                        `json:"hostname,omitempty"`
+	DockerLabels      map[string]string             `json:"dockerLabels,omitempty"`
+	Privileged        *bool                         `json:"privileged,omitempty"`
+	StartTimeout      *int64                        `json:"startTimeout,omitempty"`
+	StopTimeout       *int64                        `json:"stopTimeout,omitempty"`
+	Links             []string                      `json:"links,omitempty"`
+	PortMappings      []*ecsacs.PortMapping         `json:"portMappings,omitempty"`
+	MountPoints       []*ecsacs.MountPoint          `json:"mountPoints,omitempty"`
+	VolumesFrom       []*ecsacs.VolumeFrom          `json:"volumesFrom,omitempty"`
+	DependsOn         []*ecsacs.ContainerDependency `json:"dependsOn,omitempty"`
+}
+
+// KeyValuePair is an environment variable of a ContainerDefinition
+type KeyValuePair struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// VolumeDefinition is a bind mount host volume or a Docker volume of a TaskDefinition
+type VolumeDefinition struct {
+	Name                      string                            `json:"name"`
+	Host                      *ecsacs.HostVolumeProperties      `json:"host,omitempty"`
+	DockerVolumeConfiguration *ecsacs.DockerVolumeConfiguration `json:"dockerVolumeConfiguration,omitempty"`
+}
+
+// ReadTaskDefinition decodes and validates a task definition.
+func ReadTaskDefinition(r io.Reader) (*TaskDefinition, error) {
+	def := &TaskDefinition{}
+	if err := json.NewDecoder(r).Decode(def); err != nil {
+		return nil, errors.Wrap(err, "unable to decode task definition")
+	}
+	if err := def.validate(); err != nil {
+		return nil, err
+	}
+	return def, nil
+}
+
+func (def *TaskDefinition) validate() error {
+	if def.Family == "" {
+		return errors.New("task definition: family is required")
+	}
+	if len(def.ContainerDefinitions) == 0 {
+		return errors.New("task definition: at least one container definition is required")
+	}
+	names := make(map[string]bool)
+	for _, container := range def.ContainerDefinitions {
+		if container == nil || container.Name == "" || container.Image == "" {
+			return errors.New("task definition: container definitions require a name and an image")
+		}
+		if names[container.Name] {
+			return errors.Errorf("task definition: duplicate container name %q", container.Name)
+		}
+		names[container.Name] = true
+	}
+	for _, volume := range def.Volumes {
+		if volume == nil || volume.Name == "" {
+			return errors.New("task definition: volumes require a name")
+		}
+	}
+	return nil
+}
+
+// NewTask creates the task of the task definition, with the given ARN and desired status.
+func NewTask(def *TaskDefinition, taskARN string, desiredStatus apitaskstatus.TaskStatus) (*apitask.Task, error) {
+	acsTask, err := def.toACSTask(taskARN, desiredStatus)
+	if err != nil {
+		return nil, err
+	}
+	return apitask.TaskFromACS(acsTask, &ecsacs.PayloadMessage{})
+}
+
+// toACSTask converts the task definition to the task model of the ACS payloads, so that
+// it goes through the same conversion as the tasks received from ECS.
+func (def *TaskDefinition) toACSTask(taskARN string, desiredStatus apitaskstatus.TaskStatus) (*ecsacs.Task, error) {
+	version := defaultTaskVersion
+	if def.Revision > 0 {
+		version = strconv.FormatInt(def.Revision, 10)
+	}
+	acsTask := &ecsacs.Task{
+		Arn:           aws.String(taskARN),
+		Family:        aws.String(def.Family),
+		Version:       aws.String(version),
+		DesiredStatus: aws.String(desiredStatus.String()),
+		LaunchType:    aws.String("EC2"),
+	}
+	if def.NetworkMode != "" {
+		acsTask.NetworkMode = aws.String(def.NetworkMode)
+	}
+	if def.PidMode != "" {
+		acsTask.PidMode = aws.String(def.PidMode)
+	}
+	if def.IpcMode != "" {
+		acsTask.IpcMode = aws.String(def.IpcMode)
+	}
+	if def.Cpu != "" {
+		cpu, err := strconv.ParseFloat(def.Cpu, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "task definition: invalid cpu %q", def.Cpu)
+		}
+		// the task definition cpu is in cpu units, and the ACS task cpu in vCPUs
+		acsTask.Cpu = aws.Float64(cpu / 1024)
+	}
+	if def.Memory != "" {
+		memory, err := strconv.ParseInt(def.Memory, 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "task definition: invalid memory %q", def.Memory)
+		}
+		acsTask.Memory = aws.Int64(memory)
+	}
+
+	for _, volume := range def.Volumes {
+		acsVolume := &ecsacs.Volume{Name: aws.String(volume.Name)}
+		if volume.DockerVolumeConfiguration != nil {
+			acsVolume.Type = aws.String(dockerVolumeType)
+			acsVolume.DockerVolumeConfiguration = volume.DockerVolumeConfiguration
+		} else {
+			acsVolume.Type = aws.String(hostVolumeType)
+			acsVolume.Host = volume.Host
+			if acsVolume.Host == nil {
+				acsVolume.Host = &ecsacs.HostVolumeProperties{}
+			}
+		}
+		acsTask.Volumes = append(acsTask.Volumes, acsVolume)
+	}
+
+	for _, container := range def.ContainerDefinitions {
+		acsContainer, err := container.toACSContainer()
+		if err != nil {
+			return nil, err
+		}
+		acsTask.Containers = append(acsTask.Containers, acsContainer)
+	}
+	return acsTask, nil
+}
+
+func (container *ContainerDefinition) toACSContainer() (*ecsacs.Container, error) {
+	essential := true
+	if container.Essential != nil {
+		essential = *container.Essential
+	}
+	acsContainer := &ecsacs.Container{
+		Name:         aws.String(container.Name),
+		Image:        aws.String(container.Image),
+		Cpu:          aws.Int64(container.Cpu),
+		Memory:       aws.Int64(container.Memory),
+		Essential:    aws.Bool(essential),
+		Command:      aws.StringSlice(container.Command),
+		EntryPoint:   aws.StringSlice(container.EntryPoint),
+		Links:        aws.StringSlice(container.Links),
+		Privileged:   container.Privileged,
+		StartTimeout: container.StartTimeout,
+		StopTimeout:  container.StopTimeout,
+		PortMappings: container.PortMappings,
+		MountPoints:  container.MountPoints,
+		VolumesFrom:  container.VolumesFrom,
+		DependsOn:    container.DependsOn,
+		Environment:  make(map[string]*string),
+	}
+	if len(container.EntryPoint) == 0 {
+		acsContainer.EntryPoint = nil
+	}
+	for _, env := range container.Environment {
+		acsContainer.Environment[env.Name] = aws.String(env.Value)
+	}
+
+	dockerConfig, err := container.dockerConfig()
+	if err != nil {
+		return nil, err
+	}
+	acsContainer.DockerConfig = dockerConfig
+	return acsContainer, nil
+}
+
+// dockerConfig returns the fields of the container definition that ECS sends to the agent
+// as raw Docker container and host configs.
+func (container *ContainerDefinition) dockerConfig() (*ecsacs.DockerConfig, error) {
+	config := dockercontainer.Config{
+		WorkingDir: container.WorkingDirectory,
+		User:       container.User,
+		Hostname:   container.Hostname,
+		Labels:     container.DockerLabels,
+	}
+	hostConfig := dockercontainer.HostConfig{}
+	hostConfig.MemoryReservation = container.MemoryReservation * 1024 * 1024
+
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	hostConfigJSON, err := json.Marshal(hostConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &ecsacs.DockerConfig{
+		Config:     aws.String(string(configJSON)),
+		HostConfig: aws.String(string(hostConfigJSON)),
+	}, nil
+}
@@ -0,0 +1,163 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package standalone
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	taskresourcevolume "github.com/aws/amazon-ecs-agent/agent/taskresource/volume"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
+	dockercontainer "github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testTaskARN = "arn:aws:ecs:us-west-2:000000000000:task/default/0123456789abcdef0123456789abcdef"
+
+	testTaskDefinition = `{
+  "family": "web",
+  "revision": 3,
+  "networkMode": "bridge",
+  "cpu": "512",
+  "memory": "1024",
+  "requiresCompatibilities": ["EC2"],
+  "volumes": [
+    {"name": "logs", "host": {"sourcePath": "/var/log/web"}},
+    {"name": "scratch"}
+  ],
+  "containerDefinitions": [
+    {
+      "name": "app",
+      "image": "public.ecr.aws/docker/library/nginx:latest",
+      "cpu": 256,
+      "memory": 512,
+      "memoryReservation": 128,
+      "command": ["nginx", "-g", "daemon off;"],
+      "environment": [{"name": "MODE", "value": "standalone"}],
+      "workingDirectory": "/srv",
+      "dockerLabels": {"team": "web"},
+      "portMappings": [{"containerPort": 80, "hostPort": 8080, "protocol": "tcp"}],
+      "mountPoints": [{"sourceVolume": "logs", "containerPath": "/var/log/nginx", "readOnly": false}],
+      "dependsOn": [{"containerName": "init", "condition": "SUCCESS"}]
+    },
+    {
+      "name": "init",
+      "image": "public.ecr.aws/docker/library/busybox:latest",
+      "essential": false,
+      "command": ["true"]
+    }
+  ]
+}`
+)
+
+func TestReadTaskDefinition(t *testing.T) {
+	def, err := ReadTaskDefinition(strings.NewReader(testTaskDefinition))
+	require.NoError(t, err)
+	assert.Equal(t, "web", def.Family)
+	assert.Len(t, def.ContainerDefinitions, 2)
+	assert.Len(t, def.Volumes, 2)
+}
+
+func TestReadTaskDefinitionInvalid(t *testing.T) {
+	testCases := []struct {
+		name       string
+		definition string
+	}{
+		{"not json", `{"family":`},
+		{"no family", `{"containerDefinitions": [{"name": "app", "image": "busybox"}]}`},
+		{"no containers", `{"family": "web"}`},
+		{"no image", `{"family": "web", "containerDefinitions": [{"name": "app"}]}`},
+		{"duplicate container", `{"family": "web", "containerDefinitions": [
+			{"name": "app", "image": "busybox"}, {"name": "app", "image": "busybox"}]}`},
+		{"unnamed volume", `{"family": "web", "containerDefinitions": [{"name": "app", "image": "busybox"}],
+			"volumes": [{"host": {"sourcePath": "/tmp"}}]}`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ReadTaskDefinition(strings.NewReader(tc.definition))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNewTask(t *testing.T) {
+	def, err := ReadTaskDefinition(strings.NewReader(testTaskDefinition))
+	require.NoError(t, err)
+
+	task, err := NewTask(def, testTaskARN, apitaskstatus.TaskRunning)
+	require.NoError(t, err)
+	assert.Equal(t, testTaskARN, task.Arn)
+	assert.Equal(t, "web", task.Family)
+	assert.Equal(t, "3", task.Version)
+	assert.Equal(t, apitaskstatus.TaskRunning, task.GetDesiredStatus())
+	assert.Equal(t, apitask.BridgeNetworkMode, task.NetworkMode)
+	assert.Equal(t, 0.5, task.CPU)
+	assert.Equal(t, int64(1024), task.Memory)
+
+	require.Len(t, task.Volumes, 2)
+	assert.Equal(t, "logs", task.Volumes[0].Name)
+	assert.Equal(t, &taskresourcevolume.FSHostVolume{FSSourcePath: "/var/log/web"}, task.Volumes[0].Volume)
+	assert.Equal(t, "scratch", task.Volumes[1].Name)
+	assert.IsType(t, &taskresourcevolume.LocalDockerVolume{}, task.Volumes[1].Volume)
+
+	app, ok := task.ContainerByName("app")
+	require.True(t, ok)
+	assert.True(t, app.Essential)
+	assert.Equal(t, uint(256), app.CPU)
+	assert.Equal(t, uint(512), app.Memory)
+	assert.Equal(t, []string{"nginx", "-g", "daemon off;"}, app.Command)
+	assert.Nil(t, app.EntryPoint)
+	assert.Equal(t, map[string]string{"MODE": "standalone"}, app.Environment)
+	assert.Equal(t, []apicontainer.PortBinding{{ContainerPort: 80, HostPort: 8080, Protocol: apicontainer.TransportProtocolTCP}}, app.Ports)
+	assert.Equal(t, []apicontainer.MountPoint{{SourceVolume: "logs", ContainerPath: "/var/log/nginx"}}, app.MountPoints)
+	assert.Equal(t, []apicontainer.DependsOn{{ContainerName: "init", Condition: "SUCCESS"}}, app.DependsOnUnsafe)
+
+	var config dockercontainer.Config
+	require.NoError(t, json.Unmarshal([]byte(*app.DockerConfig.Config), &config))
+	assert.Equal(t, "/srv", config.WorkingDir)
+	assert.Equal(t, map[string]string{"team": "web"}, config.Labels)
+	var hostConfig dockercontainer.HostConfig
+	require.NoError(t, json.Unmarshal([]byte(*app.DockerConfig.HostConfig), &hostConfig))
+	assert.Equal(t, int64(128*1024*1024), hostConfig.MemoryReservation)
+
+	init, ok := task.ContainerByName("init")
+	require.True(t, ok)
+	assert.False(t, init.Essential)
+}
+
+func TestNewTaskStopped(t *testing.T) {
+	def, err := ReadTaskDefinition(strings.NewReader(testTaskDefinition))
+	require.NoError(t, err)
+
+	task, err := NewTask(def, testTaskARN, apitaskstatus.TaskStopped)
+	require.NoError(t, err)
+	assert.Equal(t, apitaskstatus.TaskStopped, task.GetDesiredStatus())
+}
+
+func TestNewTaskInvalidResources(t *testing.T) {
+	def, err := ReadTaskDefinition(strings.NewReader(
+		`{"family": "web", "cpu": "1 vCPU", "containerDefinitions": [{"name": "app", "image": "busybox"}]}`))
+	require.NoError(t, err)
+
+	_, err = NewTask(def, testTaskARN, apitaskstatus.TaskRunning)
+	assert.Error(t, err)
+}
@@ -0,0 +1,169 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+	"github.com/docker/docker/api/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	// cgroupfsStreamingInterval is the interval the container stats are read from cgroupfs at, when
+	// they are not polled. It matches the rate Docker streams the stats at.
+	cgroupfsStreamingInterval = time.Second
+
+	containerNetworkModePrefix = "container:"
+)
+
+// cgroupfsStatsReader reads the stats of a container from the cgroup filesystem, in the format of the
+// Docker stats API. prev is the last stats of the container, nil for the first read.
+type cgroupfsStatsReader interface {
+	read(pid int, readNetworkStats bool, prev *types.StatsJSON) (*types.StatsJSON, error)
+}
+
+// cgroupfsCollector collects the stats of all the containers from the cgroup filesystem on a single
+// ticker, instead of opening one Docker stats stream per container. The stats are added to the same
+// queues as the Docker stats, and the containers whose stats can't be read fall back to Docker stats.
+type cgroupfsCollector struct {
+	client   dockerapi.DockerClient
+	reader   cgroupfsStatsReader
+	interval time.Duration
+
+	lock sync.Mutex
+	// containers maps the docker ids of the containers to the containers the stats are collected for.
+	containers map[string]*cgroupfsContainer
+}
+
+type cgroupfsContainer struct {
+	container *StatsContainer
+	pid       int
+}
+
+func newCgroupfsCollector(client dockerapi.DockerClient, cfg *config.Config) *cgroupfsCollector {
+	interval := cgroupfsStreamingInterval
+	if cfg.PollMetrics.Enabled() {
+		interval = cfg.PollingMetricsWaitDuration
+	}
+	return &cgroupfsCollector{
+		client:     client,
+		reader:     newCgroupfsStatsReader(cfg),
+		interval:   interval,
+		containers: make(map[string]*cgroupfsContainer),
+	}
+}
+
+// start collects the stats of the containers until ctx is done.
+func (collector *cgroupfsCollector) start(ctx context.Context) {
+	logger.Info("Collecting container stats from cgroupfs", logger.Fields{
+		"interval": collector.interval.String(),
+	})
+	ticker := time.NewTicker(collector.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			collector.collect(ctx)
+		}
+	}
+}
+
+// add starts collecting the stats of the container.
+func (collector *cgroupfsCollector) add(container *StatsContainer) {
+	collector.lock.Lock()
+	defer collector.lock.Unlock()
+	collector.containers[container.containerMetadata.DockerID] = &cgroupfsContainer{container: container}
+}
+
+func (collector *cgroupfsCollector) remove(dockerID string) {
+	collector.lock.Lock()
+	defer collector.lock.Unlock()
+	delete(collector.containers, dockerID)
+}
+
+func (collector *cgroupfsCollector) list() []*cgroupfsContainer {
+	collector.lock.Lock()
+	defer collector.lock.Unlock()
+	containers := make([]*cgroupfsContainer, 0, len(collector.containers))
+	for _, container := range collector.containers {
+		containers = append(containers, container)
+	}
+	return containers
+}
+
+// collect reads the stats of every container once.
+func (collector *cgroupfsCollector) collect(ctx context.Context) {
+	for _, cgroupfsContainer := range collector.list() {
+		container := cgroupfsContainer.container
+		dockerID := container.containerMetadata.DockerID
+		if container.ctx.Err() != nil {
+			// the stats collection of the container was stopped
+			collector.remove(dockerID)
+			continue
+		}
+		if err := collector.collectContainer(ctx, cgroupfsContainer); err != nil {
+			collector.remove(dockerID)
+			container.fallBackToDockerStats(err)
+		}
+	}
+}
+
+func (collector *cgroupfsCollector) collectContainer(ctx context.Context, cgroupfsContainer *cgroupfsContainer) error {
+	container := cgroupfsContainer.container
+	if cgroupfsContainer.pid == 0 {
+		inspect, err := collector.client.InspectContainer(ctx, container.containerMetadata.DockerID,
+			dockerclient.InspectContainerTimeout)
+		if err != nil {
+			return err
+		}
+		if inspect.State == nil || inspect.State.Pid == 0 {
+			return errors.New("container has no process")
+		}
+		cgroupfsContainer.pid = inspect.State.Pid
+	}
+
+	stats, err := collector.reader.read(cgroupfsContainer.pid,
+		readsNetworkStats(container.containerMetadata.NetworkMode), container.statsQueue.GetLastStat())
+	if err != nil {
+		return err
+	}
+	if err := validateDockerStats(stats); err != nil {
+		return err
+	}
+	if err := container.statsQueue.Add(stats); err != nil {
+		logger.Warn("Error converting stats of container", logger.Fields{
+			field.RuntimeID: container.containerMetadata.DockerID,
+			field.Error:     err,
+		})
+	}
+	return nil
+}
+
+// readsNetworkStats returns whether the network stats of a container are read from its network
+// namespace. Docker doesn't report them for the containers that don't have their own network namespace.
+func readsNetworkStats(networkMode string) bool {
+	return networkMode != hostNetworkMode && networkMode != noneNetworkMode &&
+		!strings.HasPrefix(networkMode, containerNetworkModePrefix)
+}
@@ -0,0 +1,179 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
+	mock_dockerapi "github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi/mocks"
+	mock_resolver "github.com/aws/amazon-ecs-agent/agent/stats/resolver/mock"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
+	"github.com/docker/docker/api/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCgroupfsStatsReader struct {
+	err   error
+	pids  []int
+	prevs []*types.StatsJSON
+}
+
+func (reader *fakeCgroupfsStatsReader) read(pid int, readNetworkStats bool, prev *types.StatsJSON) (*types.StatsJSON, error) {
+	reader.pids = append(reader.pids, pid)
+	reader.prevs = append(reader.prevs, prev)
+	if reader.err != nil {
+		return nil, reader.err
+	}
+	stats := &types.StatsJSON{}
+	stats.Read = time.Now()
+	stats.CPUStats.CPUUsage.TotalUsage = uint64(len(reader.pids)) * 1000
+	stats.CPUStats.CPUUsage.PercpuUsage = []uint64{stats.CPUStats.CPUUsage.TotalUsage}
+	stats.MemoryStats.Usage = 1024
+	return stats, nil
+}
+
+func newTestCgroupfsStatsContainer(dockerID string, client *mock_dockerapi.MockDockerClient,
+	resolver *mock_resolver.MockContainerMetadataResolver, collector *cgroupfsCollector) *StatsContainer {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &StatsContainer{
+		containerMetadata: &ContainerMetadata{
+			DockerID:    dockerID,
+			NetworkMode: "bridge",
+		},
+		ctx:      ctx,
+		cancel:   cancel,
+		client:   client,
+		resolver: resolver,
+		cgroupfs: collector,
+	}
+}
+
+func TestCgroupfsCollectorCollect(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockDockerClient := mock_dockerapi.NewMockDockerClient(ctrl)
+	reader := &fakeCgroupfsStatsReader{}
+	collector := &cgroupfsCollector{
+		client:     mockDockerClient,
+		reader:     reader,
+		containers: make(map[string]*cgroupfsContainer),
+	}
+	container := newTestCgroupfsStatsContainer("container1", mockDockerClient, nil, collector)
+
+	mockDockerClient.EXPECT().InspectContainer(gomock.Any(), "container1", dockerclient.InspectContainerTimeout).Return(
+		&types.ContainerJSON{ContainerJSONBase: &types.ContainerJSONBase{State: &types.ContainerState{Pid: 1234}}}, nil)
+	container.StartStatsCollection()
+	collector.collect(context.Background())
+	collector.collect(context.Background())
+
+	assert.Equal(t, []int{1234, 1234}, reader.pids)
+	assert.Nil(t, reader.prevs[0])
+	assert.NotNil(t, reader.prevs[1])
+	usage, ok := container.statsQueue.GetLastUsageStats()
+	require.True(t, ok)
+	assert.False(t, usage.Timestamp.IsZero())
+	assert.Len(t, collector.list(), 1)
+
+	container.StopStatsCollection()
+	collector.collect(context.Background())
+	assert.Empty(t, collector.list(), "stopped containers are removed from the collector")
+	assert.Len(t, reader.pids, 2)
+}
+
+func TestCgroupfsCollectorFallBackToDockerStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockDockerClient := mock_dockerapi.NewMockDockerClient(ctrl)
+	resolver := mock_resolver.NewMockContainerMetadataResolver(ctrl)
+	collector := &cgroupfsCollector{
+		client:     mockDockerClient,
+		reader:     &fakeCgroupfsStatsReader{err: errors.New("cgroup not found")},
+		containers: make(map[string]*cgroupfsContainer),
+	}
+	container := newTestCgroupfsStatsContainer("container1", mockDockerClient, resolver, collector)
+
+	resolver.EXPECT().ResolveContainer("container1").Return(&apicontainer.DockerContainer{
+		Container: &apicontainer.Container{KnownStatusUnsafe: apicontainerstatus.ContainerRunning},
+	}, nil)
+	mockDockerClient.EXPECT().InspectContainer(gomock.Any(), "container1", gomock.Any()).Return(
+		&types.ContainerJSON{ContainerJSONBase: &types.ContainerJSONBase{State: &types.ContainerState{Pid: 1234}}}, nil)
+	streaming := make(chan struct{})
+	mockDockerClient.EXPECT().Stats(gomock.Any(), "container1", dockerclient.StatsInactivityTimeout).DoAndReturn(
+		func(ctx context.Context, id string, timeout time.Duration) (<-chan *types.StatsJSON, <-chan error) {
+			close(streaming)
+			return make(chan *types.StatsJSON), make(chan error)
+		})
+
+	container.StartStatsCollection()
+	collector.collect(context.Background())
+	assert.Empty(t, collector.list())
+	select {
+	case <-streaming:
+	case <-time.After(time.Second):
+		t.Fatal("stats were not collected from docker")
+	}
+	container.StopStatsCollection()
+}
+
+func TestCgroupfsCollectorStopsTerminalContainer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockDockerClient := mock_dockerapi.NewMockDockerClient(ctrl)
+	resolver := mock_resolver.NewMockContainerMetadataResolver(ctrl)
+	collector := &cgroupfsCollector{
+		client:     mockDockerClient,
+		reader:     &fakeCgroupfsStatsReader{},
+		containers: make(map[string]*cgroupfsContainer),
+	}
+	container := newTestCgroupfsStatsContainer("container1", mockDockerClient, resolver, collector)
+
+	mockDockerClient.EXPECT().InspectContainer(gomock.Any(), "container1", gomock.Any()).Return(
+		&types.ContainerJSON{ContainerJSONBase: &types.ContainerJSONBase{State: &types.ContainerState{}}}, nil)
+	resolver.EXPECT().ResolveContainer("container1").Return(&apicontainer.DockerContainer{
+		Container: &apicontainer.Container{KnownStatusUnsafe: apicontainerstatus.ContainerStopped},
+	}, nil)
+
+	container.StartStatsCollection()
+	collector.collect(context.Background())
+	assert.Empty(t, collector.list())
+	assert.Error(t, container.ctx.Err(), "stats collection of the terminal container is stopped")
+}
+
+func TestNewCgroupfsCollectorInterval(t *testing.T) {
+	cfg := &config.Config{}
+	assert.Equal(t, cgroupfsStreamingInterval, newCgroupfsCollector(nil, cfg).interval)
+
+	cfg.PollMetrics = config.BooleanDefaultFalse{Value: config.ExplicitlyEnabled}
+	cfg.PollingMetricsWaitDuration = 10 * time.Second
+	assert.Equal(t, 10*time.Second, newCgroupfsCollector(nil, cfg).interval)
+}
+
+func TestReadsNetworkStats(t *testing.T) {
+	assert.True(t, readsNetworkStats("bridge"))
+	assert.True(t, readsNetworkStats("default"))
+	assert.False(t, readsNetworkStats("host"))
+	assert.False(t, readsNetworkStats("none"))
+	assert.False(t, readsNetworkStats("container:abc"))
+}
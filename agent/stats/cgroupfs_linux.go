@@ -0,0 +1,553 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+import (
+	"bufio"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/docker/docker/api/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	// hostProcRoot is where the agent container has the /proc directory of the host mounted.
+	hostProcRoot = "/host/proc"
+	procRoot     = "/proc"
+
+	// clockTicksPerSecond is the USER_HZ the CPU times of /proc/stat and cpuacct.stat are counted in.
+	clockTicksPerSecond = 100
+	loopbackInterface   = "lo"
+	cgroupV2Unlimited   = "max"
+)
+
+// errCgroupFound stops the walk of the cgroup hierarchy once the cgroup of the agent is found.
+var errCgroupFound = errors.New("cgroup found")
+
+// cgroupfsReader reads the stats of the containers from their cgroup accounting files and
+// the network devices of their network namespace, in the format of the Docker stats API.
+type cgroupfsReader struct {
+	cgroupRoot string
+	procRoot   string
+	cgroupV2   bool
+
+	// selfCgroupFile and selfPID are the cgroup file and the PID of the agent, in its own
+	// namespaces. They locate the cgroup namespace of the agent in the cgroup hierarchy.
+	selfCgroupFile string
+	selfPID        int
+	// namespaceRoot is the cgroup of the root of the cgroup namespace of the agent, resolved once.
+	namespaceRoot     string
+	namespaceRootErr  error
+	namespaceRootOnce sync.Once
+}
+
+func newCgroupfsStatsReader(cfg *config.Config) cgroupfsStatsReader {
+	root := procRoot
+	if _, err := os.Stat(hostProcRoot); err == nil {
+		root = hostProcRoot
+	}
+	return &cgroupfsReader{
+		cgroupRoot:     cfg.CgroupPath,
+		procRoot:       root,
+		cgroupV2:       config.CgroupV2,
+		selfCgroupFile: filepath.Join(procRoot, "self", "cgroup"),
+		selfPID:        os.Getpid(),
+	}
+}
+
+func (reader *cgroupfsReader) read(pid int, readNetworkStats bool, prev *types.StatsJSON) (*types.StatsJSON, error) {
+	stats := &types.StatsJSON{}
+	stats.Read = time.Now()
+	if prev != nil {
+		stats.PreRead = prev.Read
+		stats.PreCPUStats = prev.CPUStats
+	}
+
+	paths, err := reader.cgroupPaths(pid)
+	if err != nil {
+		return nil, err
+	}
+	if reader.cgroupV2 {
+		err = reader.readCgroupV2Stats(paths[""], stats)
+	} else {
+		err = reader.readCgroupV1Stats(paths, stats)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	systemUsage, err := reader.readSystemCPUUsage()
+	if err != nil {
+		return nil, err
+	}
+	stats.CPUStats.SystemUsage = systemUsage
+	stats.CPUStats.OnlineCPUs = uint32(numCores)
+
+	if readNetworkStats {
+		stats.Networks, err = reader.readNetworkStats(pid)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return stats, nil
+}
+
+// cgroupPaths returns the cgroup directories of the process by controller, from /proc/<pid>/cgroup.
+// The unified hierarchy of cgroup v2 has the empty controller.
+func (reader *cgroupfsReader) cgroupPaths(pid int) (map[string]string, error) {
+	lines, err := readLines(filepath.Join(reader.procRoot, strconv.Itoa(pid), "cgroup"))
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make(map[string]string)
+	for _, line := range lines {
+		// each line is hierarchy-ID:controller-list:cgroup-path
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		cgroupPath := relativeCgroupPath(parts[2])
+		if parts[1] == "" {
+			if isOutsideNamespace(cgroupPath) {
+				// The paths are relative to the cgroup namespace of the agent, which Docker makes
+				// private on cgroup v2. The containers are outside of it.
+				if cgroupPath, err = reader.resolveOutsideNamespace(cgroupPath); err != nil {
+					return nil, errors.Wrapf(err, "unable to resolve the cgroup of process %d", pid)
+				}
+			}
+			paths[""] = filepath.Join(reader.cgroupRoot, cgroupPath)
+			continue
+		}
+		if isOutsideNamespace(cgroupPath) {
+			return nil, errors.Errorf("cgroup of process %d is outside the cgroup namespace of the agent: %s",
+				pid, cgroupPath)
+		}
+		for _, controller := range strings.Split(parts[1], ",") {
+			paths[controller] = filepath.Join(reader.cgroupRoot, parts[1], cgroupPath)
+		}
+	}
+	if reader.cgroupV2 && paths[""] == "" {
+		return nil, errors.Errorf("process %d has no cgroup v2 cgroup", pid)
+	}
+	return paths, nil
+}
+
+// resolveOutsideNamespace returns the path in the cgroup hierarchy mounted at cgroupRoot of a
+// cgroup v2 path that is outside the cgroup namespace of the agent, such as ../docker-<id>.scope.
+func (reader *cgroupfsReader) resolveOutsideNamespace(cgroupPath string) (string, error) {
+	reader.namespaceRootOnce.Do(func() {
+		reader.namespaceRoot, reader.namespaceRootErr = reader.findNamespaceRoot()
+	})
+	if reader.namespaceRootErr != nil {
+		return "", reader.namespaceRootErr
+	}
+	resolved := filepath.Join(reader.namespaceRoot, cgroupPath)
+	if isOutsideNamespace(resolved) {
+		return "", errors.Errorf("cgroup /%s is outside the cgroup hierarchy", cgroupPath)
+	}
+	return resolved, nil
+}
+
+// findNamespaceRoot returns the root of the cgroup namespace of the agent in the cgroup hierarchy
+// mounted at cgroupRoot, which is the hierarchy of the host. It's the cgroup with the agent process,
+// less the path of that cgroup in the namespace.
+func (reader *cgroupfsReader) findNamespaceRoot() (string, error) {
+	lines, err := readLines(reader.selfCgroupFile)
+	if err != nil {
+		return "", err
+	}
+	selfPath := ""
+	for _, line := range lines {
+		if strings.HasPrefix(line, "0::") {
+			selfPath = relativeCgroupPath(strings.TrimPrefix(line, "0::"))
+		}
+	}
+	if selfPath == "" || isOutsideNamespace(selfPath) {
+		return "", errors.New("the agent has no cgroup v2 cgroup")
+	}
+
+	pid := strconv.Itoa(reader.selfPID)
+	var selfCgroup string
+	err = filepath.WalkDir(reader.cgroupRoot, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.IsDir() {
+			return nil
+		}
+		procs, err := readLines(filepath.Join(path, "cgroup.procs"))
+		if err != nil {
+			return nil
+		}
+		for _, proc := range procs {
+			if strings.TrimSpace(proc) == pid {
+				selfCgroup = path
+				return errCgroupFound
+			}
+		}
+		return nil
+	})
+	if err != nil && err != errCgroupFound {
+		return "", err
+	}
+	if selfCgroup == "" {
+		return "", errors.Errorf("no cgroup has the agent process %s", pid)
+	}
+	selfCgroup, err = filepath.Rel(reader.cgroupRoot, selfCgroup)
+	if err != nil {
+		return "", err
+	}
+	if selfPath == "." {
+		return selfCgroup, nil
+	}
+	if selfCgroup != selfPath && !strings.HasSuffix(selfCgroup, "/"+selfPath) {
+		return "", errors.Errorf("cgroup /%s of the agent doesn't match its cgroup /%s in its namespace",
+			selfCgroup, selfPath)
+	}
+	return relativeCgroupPath(strings.TrimSuffix(selfCgroup, selfPath)), nil
+}
+
+// relativeCgroupPath returns a cgroup path of /proc/<pid>/cgroup relative to the root of the
+// cgroup namespace, keeping the leading .. elements of the paths outside of it.
+func relativeCgroupPath(cgroupPath string) string {
+	return filepath.Clean(strings.TrimPrefix(cgroupPath, "/"))
+}
+
+// isOutsideNamespace returns whether a relative cgroup path is outside the root of its namespace.
+func isOutsideNamespace(cgroupPath string) bool {
+	return cgroupPath == ".." || strings.HasPrefix(cgroupPath, "../")
+}
+
+func (reader *cgroupfsReader) readCgroupV1Stats(paths map[string]string, stats *types.StatsJSON) error {
+	cpuacct, memory := paths["cpuacct"], paths["memory"]
+	if cpuacct == "" || memory == "" {
+		return errors.New("cpuacct and memory cgroups are required to read the container stats")
+	}
+
+	cpuUsage := &stats.CPUStats.CPUUsage
+	var err error
+	if cpuUsage.TotalUsage, err = readUint(filepath.Join(cpuacct, "cpuacct.usage")); err != nil {
+		return err
+	}
+	if cpuUsage.PercpuUsage, err = readUints(filepath.Join(cpuacct, "cpuacct.usage_percpu")); err != nil {
+		return err
+	}
+	cpuTimes, err := readKeyValues(filepath.Join(cpuacct, "cpuacct.stat"))
+	if err != nil {
+		return err
+	}
+	cpuUsage.UsageInUsermode = cpuTimes["user"] * uint64(time.Second) / clockTicksPerSecond
+	cpuUsage.UsageInKernelmode = cpuTimes["system"] * uint64(time.Second) / clockTicksPerSecond
+	if cpu := paths["cpu"]; cpu != "" {
+		if cpuStat, err := readKeyValues(filepath.Join(cpu, "cpu.stat")); err == nil {
+			stats.CPUStats.ThrottlingData = types.ThrottlingData{
+				Periods:          cpuStat["nr_periods"],
+				ThrottledPeriods: cpuStat["nr_throttled"],
+				ThrottledTime:    cpuStat["throttled_time"],
+			}
+		}
+	}
+
+	memoryStats := &stats.MemoryStats
+	if memoryStats.Usage, err = readUint(filepath.Join(memory, "memory.usage_in_bytes")); err != nil {
+		return err
+	}
+	if memoryStats.Stats, err = readKeyValues(filepath.Join(memory, "memory.stat")); err != nil {
+		return err
+	}
+	memoryStats.MaxUsage, _ = readUint(filepath.Join(memory, "memory.max_usage_in_bytes"))
+	memoryStats.Failcnt, _ = readUint(filepath.Join(memory, "memory.failcnt"))
+	limit, _ := readUint(filepath.Join(memory, "memory.limit_in_bytes"))
+	memoryStats.Limit = reader.memoryLimit(limit)
+
+	if blkio := paths["blkio"]; blkio != "" {
+		stats.BlkioStats.IoServiceBytesRecursive = readBlkioEntries(
+			filepath.Join(blkio, "blkio.throttle.io_service_bytes_recursive"),
+			filepath.Join(blkio, "blkio.throttle.io_service_bytes"))
+	}
+	if pids := paths["pids"]; pids != "" {
+		stats.PidsStats.Current, _ = readUint(filepath.Join(pids, "pids.current"))
+		stats.PidsStats.Limit, _ = readUint(filepath.Join(pids, "pids.max"))
+	}
+	return nil
+}
+
+func (reader *cgroupfsReader) readCgroupV2Stats(cgroupPath string, stats *types.StatsJSON) error {
+	cpuStat, err := readKeyValues(filepath.Join(cgroupPath, "cpu.stat"))
+	if err != nil {
+		return err
+	}
+	usecToNsec := uint64(time.Microsecond)
+	stats.CPUStats.CPUUsage = types.CPUUsage{
+		TotalUsage:        cpuStat["usage_usec"] * usecToNsec,
+		UsageInUsermode:   cpuStat["user_usec"] * usecToNsec,
+		UsageInKernelmode: cpuStat["system_usec"] * usecToNsec,
+	}
+	stats.CPUStats.ThrottlingData = types.ThrottlingData{
+		Periods:          cpuStat["nr_periods"],
+		ThrottledPeriods: cpuStat["nr_throttled"],
+		ThrottledTime:    cpuStat["throttled_usec"] * usecToNsec,
+	}
+
+	memoryStats := &stats.MemoryStats
+	if memoryStats.Usage, err = readUint(filepath.Join(cgroupPath, "memory.current")); err != nil {
+		return err
+	}
+	if memoryStats.Stats, err = readKeyValues(filepath.Join(cgroupPath, "memory.stat")); err != nil {
+		return err
+	}
+	memoryStats.MaxUsage, _ = readUint(filepath.Join(cgroupPath, "memory.peak"))
+	limit, _ := readUint(filepath.Join(cgroupPath, "memory.max"))
+	memoryStats.Limit = reader.memoryLimit(limit)
+
+	stats.BlkioStats.IoServiceBytesRecursive = readIOStat(filepath.Join(cgroupPath, "io.stat"))
+	stats.PidsStats.Current, _ = readUint(filepath.Join(cgroupPath, "pids.current"))
+	stats.PidsStats.Limit, _ = readUint(filepath.Join(cgroupPath, "pids.max"))
+	return nil
+}
+
+// memoryLimit returns the memory limit of a container, which is the memory of the host when the
+// container is unlimited, the same as Docker reports it.
+func (reader *cgroupfsReader) memoryLimit(limit uint64) uint64 {
+	meminfo, err := readKeyValues(filepath.Join(reader.procRoot, "meminfo"))
+	if err != nil {
+		return limit
+	}
+	// MemTotal is in kB
+	if hostMemory := meminfo["MemTotal:"] * 1024; hostMemory != 0 && (limit == 0 || limit > hostMemory) {
+		return hostMemory
+	}
+	return limit
+}
+
+// readSystemCPUUsage returns the CPU time of the host in nanoseconds from the first line of /proc/stat.
+func (reader *cgroupfsReader) readSystemCPUUsage() (uint64, error) {
+	file, err := os.Open(filepath.Join(reader.procRoot, "stat"))
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || fields[0] != "cpu" {
+			continue
+		}
+		var ticks uint64
+		for _, field := range fields[1:] {
+			value, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				return 0, errors.Wrapf(err, "invalid cpu time in %s", file.Name())
+			}
+			ticks += value
+		}
+		return ticks * uint64(time.Second) / clockTicksPerSecond, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, errors.Errorf("no cpu line in %s", file.Name())
+}
+
+// readNetworkStats returns the stats of the network interfaces of the process from /proc/<pid>/net/dev,
+// without the loopback interface.
+func (reader *cgroupfsReader) readNetworkStats(pid int) (map[string]types.NetworkStats, error) {
+	file, err := os.Open(filepath.Join(reader.procRoot, strconv.Itoa(pid), "net", "dev"))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	networks := make(map[string]types.NetworkStats)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// the interface lines are name: followed by 8 receive and 8 transmit counters
+		name, counters, found := strings.Cut(scanner.Text(), ":")
+		name = strings.TrimSpace(name)
+		if !found || name == loopbackInterface {
+			continue
+		}
+		fields := strings.Fields(counters)
+		if len(fields) < 16 {
+			continue
+		}
+		values := make([]uint64, 16)
+		for i := range values {
+			if values[i], err = strconv.ParseUint(fields[i], 10, 64); err != nil {
+				return nil, errors.Wrapf(err, "invalid counter of interface %s", name)
+			}
+		}
+		networks[name] = types.NetworkStats{
+			RxBytes:   values[0],
+			RxPackets: values[1],
+			RxErrors:  values[2],
+			RxDropped: values[3],
+			TxBytes:   values[8],
+			TxPackets: values[9],
+			TxErrors:  values[10],
+			TxDropped: values[11],
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(networks) == 0 {
+		return nil, nil
+	}
+	return networks, nil
+}
+
+// readBlkioEntries returns the entries of the first of the cgroup v1 blkio files that exists.
+// Each line of them is major:minor operation bytes, except for the Total line.
+func readBlkioEntries(files ...string) []types.BlkioStatEntry {
+	for _, file := range files {
+		lines, err := readLines(file)
+		if err != nil {
+			continue
+		}
+		var entries []types.BlkioStatEntry
+		for _, line := range lines {
+			fields := strings.Fields(line)
+			if len(fields) != 3 {
+				continue
+			}
+			major, minor, ok := parseDevice(fields[0])
+			value, err := strconv.ParseUint(fields[2], 10, 64)
+			if !ok || err != nil {
+				continue
+			}
+			entries = append(entries, types.BlkioStatEntry{Major: major, Minor: minor, Op: fields[1], Value: value})
+		}
+		return entries
+	}
+	return nil
+}
+
+// readIOStat returns the bytes read and written by device from the cgroup v2 io.stat file, with the
+// lowercase read and write operations that Docker reports for cgroup v2.
+func readIOStat(file string) []types.BlkioStatEntry {
+	lines, err := readLines(file)
+	if err != nil {
+		return nil
+	}
+	var entries []types.BlkioStatEntry
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		major, minor, ok := parseDevice(fields[0])
+		if !ok {
+			continue
+		}
+		var readBytes, writeBytes uint64
+		for _, field := range fields[1:] {
+			key, value, _ := strings.Cut(field, "=")
+			switch key {
+			case "rbytes":
+				readBytes, _ = strconv.ParseUint(value, 10, 64)
+			case "wbytes":
+				writeBytes, _ = strconv.ParseUint(value, 10, 64)
+			}
+		}
+		entries = append(entries,
+			types.BlkioStatEntry{Major: major, Minor: minor, Op: "read", Value: readBytes},
+			types.BlkioStatEntry{Major: major, Minor: minor, Op: "write", Value: writeBytes})
+	}
+	return entries
+}
+
+func parseDevice(device string) (uint64, uint64, bool) {
+	majorStr, minorStr, found := strings.Cut(device, ":")
+	if !found {
+		return 0, 0, false
+	}
+	major, err := strconv.ParseUint(majorStr, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	minor, err := strconv.ParseUint(minorStr, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return major, minor, true
+}
+
+// readUint reads a file with a single unsigned integer, where the cgroup v2 max is 0.
+func readUint(file string) (uint64, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return 0, err
+	}
+	value := strings.TrimSpace(string(content))
+	if value == cgroupV2Unlimited {
+		return 0, nil
+	}
+	result, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid value in %s", file)
+	}
+	return result, nil
+}
+
+// readUints reads a file with space separated unsigned integers.
+func readUints(file string) ([]uint64, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var values []uint64
+	for _, field := range strings.Fields(string(content)) {
+		value, err := strconv.ParseUint(field, 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid value in %s", file)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// readKeyValues reads a file with a key and an unsigned integer value on each line, skipping the
+// lines with other values.
+func readKeyValues(file string) (map[string]uint64, error) {
+	lines, err := readLines(file)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]uint64)
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		if value, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+			values[fields[0]] = value
+		}
+	}
+	return values, nil
+}
+
+func readLines(file string) ([]string, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(strings.TrimSpace(string(content)), "\n"), nil
+}
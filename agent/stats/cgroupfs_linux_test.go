@@ -0,0 +1,255 @@
+//go:build linux && unit
+// +build linux,unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testPid     = 1234
+	testSelfPid = 42
+	testNetDev  = `Inter-|   Receive                                                |  Transmit
+ face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+    lo:     100       1    0    0    0     0          0         0      100       1    0    0    0     0       0          0
+  eth0:    2048      20    1    2    0     0          0         0     1024      10    3    4    0     0       0          0
+`
+	testProcStat = `cpu  100 0 100 800 0 0 0 0 0 0
+cpu0 50 0 50 400 0 0 0 0 0 0
+`
+	testMeminfo = `MemTotal:        4096 kB
+MemFree:         2048 kB
+`
+)
+
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+func newTestCgroupfsReader(t *testing.T, cgroupV2 bool, procCgroup string, cgroupFiles map[string]string) *cgroupfsReader {
+	procDir := t.TempDir()
+	cgroupDir := t.TempDir()
+	writeTestFiles(t, procDir, map[string]string{
+		"1234/cgroup":  procCgroup,
+		"1234/net/dev": testNetDev,
+		"stat":         testProcStat,
+		"meminfo":      testMeminfo,
+		"self/cgroup":  "0::/\n",
+	})
+	writeTestFiles(t, cgroupDir, cgroupFiles)
+	return &cgroupfsReader{
+		cgroupRoot:     cgroupDir,
+		procRoot:       procDir,
+		cgroupV2:       cgroupV2,
+		selfCgroupFile: filepath.Join(procDir, "self", "cgroup"),
+		selfPID:        testSelfPid,
+	}
+}
+
+func TestCgroupfsReaderCgroupV1(t *testing.T) {
+	reader := newTestCgroupfsReader(t, false,
+		"12:memory:/docker/abc\n11:cpu,cpuacct:/docker/abc\n10:blkio:/docker/abc\n9:pids:/docker/abc\n",
+		map[string]string{
+			"cpu,cpuacct/docker/abc/cpuacct.usage":                       "3000\n",
+			"cpu,cpuacct/docker/abc/cpuacct.usage_percpu":                "1000 2000 \n",
+			"cpu,cpuacct/docker/abc/cpuacct.stat":                        "user 2\nsystem 1\n",
+			"cpu,cpuacct/docker/abc/cpu.stat":                            "nr_periods 10\nnr_throttled 2\nthrottled_time 500\n",
+			"memory/docker/abc/memory.usage_in_bytes":                    "2048\n",
+			"memory/docker/abc/memory.max_usage_in_bytes":                "3072\n",
+			"memory/docker/abc/memory.limit_in_bytes":                    "9223372036854771712\n",
+			"memory/docker/abc/memory.failcnt":                           "0\n",
+			"memory/docker/abc/memory.stat":                              "cache 1024\nrss 1024\n",
+			"blkio/docker/abc/blkio.throttle.io_service_bytes_recursive": "8:0 Read 100\n8:0 Write 200\nTotal 300\n",
+			"pids/docker/abc/pids.current":                               "3\n",
+			"pids/docker/abc/pids.max":                                   "max\n",
+		})
+
+	prev := &types.StatsJSON{}
+	prev.Read = time.Now().Add(-time.Second)
+	prev.CPUStats.CPUUsage.TotalUsage = 1000
+	stats, err := reader.read(testPid, true, prev)
+	require.NoError(t, err)
+
+	assert.Equal(t, prev.Read, stats.PreRead)
+	assert.Equal(t, prev.CPUStats, stats.PreCPUStats)
+	assert.Equal(t, types.CPUUsage{
+		TotalUsage:        3000,
+		PercpuUsage:       []uint64{1000, 2000},
+		UsageInUsermode:   20000000,
+		UsageInKernelmode: 10000000,
+	}, stats.CPUStats.CPUUsage)
+	assert.Equal(t, types.ThrottlingData{Periods: 10, ThrottledPeriods: 2, ThrottledTime: 500}, stats.CPUStats.ThrottlingData)
+	assert.Equal(t, uint64(10000000000), stats.CPUStats.SystemUsage)
+	assert.Equal(t, types.MemoryStats{
+		Usage:    2048,
+		MaxUsage: 3072,
+		Limit:    4096 * 1024,
+		Stats:    map[string]uint64{"cache": 1024, "rss": 1024},
+	}, stats.MemoryStats)
+	assert.Equal(t, []types.BlkioStatEntry{
+		{Major: 8, Minor: 0, Op: "Read", Value: 100},
+		{Major: 8, Minor: 0, Op: "Write", Value: 200},
+	}, stats.BlkioStats.IoServiceBytesRecursive)
+	assert.Equal(t, types.PidsStats{Current: 3}, stats.PidsStats)
+	assert.Equal(t, map[string]types.NetworkStats{
+		"eth0": {
+			RxBytes:   2048,
+			RxPackets: 20,
+			RxErrors:  1,
+			RxDropped: 2,
+			TxBytes:   1024,
+			TxPackets: 10,
+			TxErrors:  3,
+			TxDropped: 4,
+		},
+	}, stats.Networks)
+}
+
+func TestCgroupfsReaderCgroupV2(t *testing.T) {
+	reader := newTestCgroupfsReader(t, true, "0::/system.slice/docker-abc.scope\n",
+		map[string]string{
+			"system.slice/docker-abc.scope/cpu.stat":       "usage_usec 3\nuser_usec 2\nsystem_usec 1\nnr_periods 10\nnr_throttled 2\nthrottled_usec 5\n",
+			"system.slice/docker-abc.scope/memory.current": "2048\n",
+			"system.slice/docker-abc.scope/memory.max":     "1024000\n",
+			"system.slice/docker-abc.scope/memory.stat":    "anon 1024\ninactive_file 512\n",
+			"system.slice/docker-abc.scope/io.stat":        "8:0 rbytes=100 wbytes=200 rios=1 wios=2\n",
+			"system.slice/docker-abc.scope/pids.current":   "3\n",
+			"system.slice/docker-abc.scope/pids.max":       "100\n",
+		})
+
+	stats, err := reader.read(testPid, false, nil)
+	require.NoError(t, err)
+
+	assert.True(t, stats.PreRead.IsZero())
+	assert.Equal(t, types.CPUUsage{
+		TotalUsage:        3000,
+		UsageInUsermode:   2000,
+		UsageInKernelmode: 1000,
+	}, stats.CPUStats.CPUUsage)
+	assert.Equal(t, types.ThrottlingData{Periods: 10, ThrottledPeriods: 2, ThrottledTime: 5000}, stats.CPUStats.ThrottlingData)
+	assert.Equal(t, types.MemoryStats{
+		Usage: 2048,
+		Limit: 1024000,
+		Stats: map[string]uint64{"anon": 1024, "inactive_file": 512},
+	}, stats.MemoryStats)
+	assert.Equal(t, []types.BlkioStatEntry{
+		{Major: 8, Minor: 0, Op: "read", Value: 100},
+		{Major: 8, Minor: 0, Op: "write", Value: 200},
+	}, stats.BlkioStats.IoServiceBytesRecursive)
+	assert.Equal(t, types.PidsStats{Current: 3, Limit: 100}, stats.PidsStats)
+	assert.Nil(t, stats.Networks)
+}
+
+// TestCgroupfsReaderCgroupV2Namespaced tests reading the stats of a container when the agent runs
+// in a private cgroup namespace, where the cgroups of the containers are outside of it.
+func TestCgroupfsReaderCgroupV2Namespaced(t *testing.T) {
+	reader := newTestCgroupfsReader(t, true, "0::/../docker-abc.scope\n",
+		map[string]string{
+			"system.slice/docker-agent.scope/cgroup.procs": "1\n42\n",
+			"system.slice/docker-abc.scope/cgroup.procs":   "1234\n",
+			"system.slice/docker-abc.scope/cpu.stat":       "usage_usec 3\nuser_usec 2\nsystem_usec 1\n",
+			"system.slice/docker-abc.scope/memory.current": "2048\n",
+			"system.slice/docker-abc.scope/memory.stat":    "anon 1024\n",
+		})
+
+	stats, err := reader.read(testPid, false, nil)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3000), stats.CPUStats.CPUUsage.TotalUsage)
+	assert.Equal(t, uint64(2048), stats.MemoryStats.Usage)
+	assert.Equal(t, "system.slice/docker-agent.scope", reader.namespaceRoot)
+}
+
+func TestCgroupfsReaderCgroupPathsNamespaced(t *testing.T) {
+	testCases := []struct {
+		name          string
+		selfCgroup    string
+		procCgroup    string
+		cgroupFiles   map[string]string
+		expected      string
+		expectedError bool
+	}{
+		{
+			name:        "agent at the root of its namespace",
+			selfCgroup:  "0::/\n",
+			procCgroup:  "0::/../../docker/abc\n",
+			cgroupFiles: map[string]string{"system.slice/docker-agent.scope/cgroup.procs": "42\n"},
+			expected:    "docker/abc",
+		},
+		{
+			name:        "agent in a child of the root of its namespace",
+			selfCgroup:  "0::/init\n",
+			procCgroup:  "0::/../docker-abc.scope\n",
+			cgroupFiles: map[string]string{"system.slice/docker-agent.scope/init/cgroup.procs": "42\n"},
+			expected:    "system.slice/docker-abc.scope",
+		},
+		{
+			name:          "agent cgroup not found",
+			selfCgroup:    "0::/\n",
+			procCgroup:    "0::/../docker-abc.scope\n",
+			expectedError: true,
+		},
+		{
+			name:          "cgroup outside the hierarchy",
+			selfCgroup:    "0::/\n",
+			procCgroup:    "0::/../../../docker-abc.scope\n",
+			cgroupFiles:   map[string]string{"system.slice/docker-agent.scope/cgroup.procs": "42\n"},
+			expectedError: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reader := newTestCgroupfsReader(t, true, tc.procCgroup, tc.cgroupFiles)
+			writeTestFiles(t, reader.procRoot, map[string]string{"self/cgroup": tc.selfCgroup})
+			paths, err := reader.cgroupPaths(testPid)
+			if tc.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, filepath.Join(reader.cgroupRoot, tc.expected), paths[""])
+		})
+	}
+}
+
+func TestCgroupfsReaderErrors(t *testing.T) {
+	t.Run("cgroup v1 outside the namespace", func(t *testing.T) {
+		reader := newTestCgroupfsReader(t, false, "12:memory:/../docker/abc\n", nil)
+		_, err := reader.read(testPid, false, nil)
+		assert.Error(t, err)
+	})
+	t.Run("missing cgroup files", func(t *testing.T) {
+		reader := newTestCgroupfsReader(t, true, "0::/system.slice/docker-abc.scope\n", nil)
+		_, err := reader.read(testPid, false, nil)
+		assert.Error(t, err)
+	})
+	t.Run("missing process", func(t *testing.T) {
+		reader := newTestCgroupfsReader(t, true, "0::/system.slice/docker-abc.scope\n", nil)
+		_, err := reader.read(testPid+1, false, nil)
+		assert.Error(t, err)
+	})
+}
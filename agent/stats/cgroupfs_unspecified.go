@@ -0,0 +1,34 @@
+//go:build !linux
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+import (
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/docker/docker/api/types"
+	"github.com/pkg/errors"
+)
+
+// cgroupfsReader can't read the stats of the containers on this platform, so that they are
+// collected from Docker instead.
+type cgroupfsReader struct{}
+
+func newCgroupfsStatsReader(cfg *config.Config) cgroupfsStatsReader {
+	return &cgroupfsReader{}
+}
+
+func (reader *cgroupfsReader) read(pid int, readNetworkStats bool, prev *types.StatsJSON) (*types.StatsJSON, error) {
+	return nil, errors.New("container stats can only be read from cgroupfs on linux")
+}
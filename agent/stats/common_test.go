@@ -22,6 +22,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/aws/amazon-ecs-agent/agent/api"
 	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
 	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
 	"github.com/aws/amazon-ecs-agent/agent/config"
@@ -342,6 +343,9 @@ func (engine *MockTaskEngine) StateChangeEvents() chan statechange.Event {
 func (engine *MockTaskEngine) SetDataClient(data.Client) {
 }
 
+func (engine *MockTaskEngine) SetECSClient(api.ECSClient) {
+}
+
 func (engine *MockTaskEngine) AddTask(*apitask.Task) {
 }
 
@@ -24,6 +24,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
 	"github.com/aws/amazon-ecs-agent/agent/stats/resolver"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/retry"
 	"github.com/cihub/seelog"
 )
@@ -61,6 +62,10 @@ func (container *StatsContainer) StartStatsCollection() {
 		queueSize = int(config.DefaultContainerMetricsPublishInterval.Seconds() * 4)
 	}
 	container.statsQueue = NewQueue(queueSize)
+	if container.cgroupfs != nil {
+		container.cgroupfs.add(container)
+		return
+	}
 	go container.collect()
 }
 
@@ -147,6 +152,27 @@ func (container *StatsContainer) processStatsStream() error {
 	}
 }
 
+// fallBackToDockerStats collects the stats of the container from a Docker stats stream, after they
+// couldn't be read from cgroupfs.
+func (container *StatsContainer) fallBackToDockerStats(err error) {
+	dockerID := container.containerMetadata.DockerID
+	if container.ctx.Err() != nil {
+		return
+	}
+	// The container may have exited, in which case its stats are no longer collected.
+	terminal, terminalErr := container.terminal()
+	if terminalErr != nil || terminal {
+		logger.Info("Container is terminal, stopping stats collection", logger.Fields{field.RuntimeID: dockerID})
+		container.StopStatsCollection()
+		return
+	}
+	logger.Warn("Unable to read container stats from cgroupfs, falling back to Docker stats", logger.Fields{
+		field.RuntimeID: dockerID,
+		field.Error:     err,
+	})
+	go container.collect()
+}
+
 func (container *StatsContainer) terminal() (bool, error) {
 	dockerContainer, err := container.resolver.ResolveContainer(container.containerMetadata.DockerID)
 	if err != nil {
@@ -32,6 +32,7 @@ import (
 	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
 	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
 	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/data"
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
 	ecsengine "github.com/aws/amazon-ecs-agent/agent/engine"
@@ -85,6 +86,8 @@ type Engine interface {
 	GetPublishServiceConnectTickerInterval() int32
 	SetPublishServiceConnectTickerInterval(int32)
 	GetPublishMetricsTicker() *time.Ticker
+	GetTaskResourceUsage(taskARN string) (*apitask.ResourceUsage, bool)
+	GetTaskResourceUsages() []*apitask.ResourceUsage
 }
 
 // DockerStatsEngine is used to monitor docker container events and to report
@@ -115,6 +118,16 @@ type DockerStatsEngine struct {
 	healthChannel  chan<- ecstcs.HealthMessage
 
 	csiClient csiclient.CSIClient
+
+	// cgroupfs collects the container stats when they are read from cgroupfs instead of Docker.
+	cgroupfs *cgroupfsCollector
+
+	// dataClient persists the resource usage of the tasks.
+	dataClient data.Client
+	// usageLock guards taskUsage.
+	usageLock sync.Mutex
+	// taskUsage maps task arns to the resource usage accounted for the task.
+	taskUsage map[string]*apitask.ResourceUsage
 }
 
 // ResolveTask resolves the api task object, given container id.
@@ -172,6 +185,8 @@ func NewDockerStatsEngine(cfg *config.Config, client dockerapi.DockerClient, con
 		publishServiceConnectTickerInterval: 0,
 		metricsChannel:                      metricsChannel,
 		healthChannel:                       healthChannel,
+		dataClient:                          data.NewNoopClient(),
+		taskUsage:                           make(map[string]*apitask.ResourceUsage),
 	}
 }
 
@@ -253,6 +268,11 @@ func (engine *DockerStatsEngine) MustInit(ctx context.Context, taskEngine ecseng
 		return err
 	}
 
+	if engine.config.ContainerStatsSource == config.ContainerStatsSourceCgroupfs {
+		engine.cgroupfs = newCgroupfsCollector(engine.client, engine.config)
+		go engine.cgroupfs.start(engine.ctx)
+	}
+
 	// Subscribe to the container change event stream
 	err = engine.containerChangeEventStream.Subscribe(containerChangeHandler, engine.handleDockerEvents)
 	if err != nil {
@@ -265,6 +285,9 @@ func (engine *DockerStatsEngine) MustInit(ctx context.Context, taskEngine ecseng
 		})
 	}
 
+	engine.loadTaskResourceUsages()
+	go engine.accountResourceUsagePeriodically()
+
 	go engine.waitToStop()
 	return nil
 }
@@ -369,6 +392,7 @@ func (engine *DockerStatsEngine) addContainerUnsafe(dockerID string) (*StatsCont
 	if err != nil {
 		return nil, nil, errors.Wrapf(err, "could not map docker container ID to container, ignoring container: %s", dockerID)
 	}
+	statsContainer.cgroupfs = engine.cgroupfs
 
 	seelog.Debugf("Adding container to stats watch list, id: %s, task: %s", dockerID, task.Arn)
 	engine.tasksToDefinitions[task.Arn] = &taskDefinition{family: task.Family, version: task.Version}
@@ -934,6 +958,12 @@ func (engine *DockerStatsEngine) taskContainerMetricsUnsafe(taskArn string) ([]*
 }
 
 func (engine *DockerStatsEngine) doRemoveContainerUnsafe(container *StatsContainer, taskArn string) {
+	// account the last stats of the container before they are discarded
+	if usage := engine.accountContainerUsageUnsafe(container, taskArn); usage != nil {
+		engine.usageLock.Lock()
+		engine.saveTaskResourceUsageUnsafe(usage)
+		engine.usageLock.Unlock()
+	}
 	container.StopStatsCollection()
 	dockerID := container.containerMetadata.DockerID
 	delete(engine.tasksToContainers[taskArn], dockerID)
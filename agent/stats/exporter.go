@@ -0,0 +1,207 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+import (
+	"math"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	exporterNamespace = "ecs"
+
+	labelTaskARN       = "task_arn"
+	labelFamily        = "family"
+	labelRevision      = "revision"
+	labelContainerName = "container_name"
+)
+
+var (
+	exporterTaskLabels      = []string{labelTaskARN, labelFamily, labelRevision}
+	exporterContainerLabels = []string{labelTaskARN, labelFamily, labelRevision, labelContainerName}
+)
+
+// networkDescs describes the network metrics of a container, or of a task when the task
+// has its own network namespace (awsvpc network mode).
+type networkDescs struct {
+	rxBytes   *prometheus.Desc
+	txBytes   *prometheus.Desc
+	rxPackets *prometheus.Desc
+	txPackets *prometheus.Desc
+	rxErrors  *prometheus.Desc
+	txErrors  *prometheus.Desc
+	rxDropped *prometheus.Desc
+	txDropped *prometheus.Desc
+}
+
+func newNetworkDescs(subsystem string, labels []string) networkDescs {
+	newDesc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(exporterNamespace, subsystem, name), help, labels, nil)
+	}
+	return networkDescs{
+		rxBytes:   newDesc("network_receive_bytes_total", "Number of bytes received"),
+		txBytes:   newDesc("network_transmit_bytes_total", "Number of bytes transmitted"),
+		rxPackets: newDesc("network_receive_packets_total", "Number of packets received"),
+		txPackets: newDesc("network_transmit_packets_total", "Number of packets transmitted"),
+		rxErrors:  newDesc("network_receive_errors_total", "Number of errors while receiving"),
+		txErrors:  newDesc("network_transmit_errors_total", "Number of errors while transmitting"),
+		rxDropped: newDesc("network_receive_dropped_total", "Number of received packets dropped"),
+		txDropped: newDesc("network_transmit_dropped_total", "Number of transmitted packets dropped"),
+	}
+}
+
+func (descs networkDescs) describe(ch chan<- *prometheus.Desc) {
+	ch <- descs.rxBytes
+	ch <- descs.txBytes
+	ch <- descs.rxPackets
+	ch <- descs.txPackets
+	ch <- descs.rxErrors
+	ch <- descs.txErrors
+	ch <- descs.rxDropped
+	ch <- descs.txDropped
+}
+
+func (descs networkDescs) collect(ch chan<- prometheus.Metric, networkStats *NetworkStats, labelValues []string) {
+	counter := func(desc *prometheus.Desc, value uint64) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, float64(value), labelValues...)
+	}
+	counter(descs.rxBytes, networkStats.RxBytes)
+	counter(descs.txBytes, networkStats.TxBytes)
+	counter(descs.rxPackets, networkStats.RxPackets)
+	counter(descs.txPackets, networkStats.TxPackets)
+	counter(descs.rxErrors, networkStats.RxErrors)
+	counter(descs.txErrors, networkStats.TxErrors)
+	counter(descs.rxDropped, networkStats.RxDropped)
+	counter(descs.txDropped, networkStats.TxDropped)
+}
+
+// TaskMetricsExporter exposes the last stats sample collected by the stats engine for every task
+// and container as Prometheus metrics. It implements prometheus.Collector and reads the stats
+// queues on every scrape, so it does not collect anything on its own.
+type TaskMetricsExporter struct {
+	engine *DockerStatsEngine
+
+	containerCPUUtilization *prometheus.Desc
+	containerMemoryUsage    *prometheus.Desc
+	containerStorageRead    *prometheus.Desc
+	containerStorageWrite   *prometheus.Desc
+	containerNetwork        networkDescs
+
+	taskCPUUtilization *prometheus.Desc
+	taskMemoryUsage    *prometheus.Desc
+	taskContainers     *prometheus.Desc
+	taskNetwork        networkDescs
+}
+
+// NewTaskMetricsExporter creates a TaskMetricsExporter that reads the stats of the given engine.
+func NewTaskMetricsExporter(engine *DockerStatsEngine) *TaskMetricsExporter {
+	containerDesc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(exporterNamespace, "container", name), help,
+			exporterContainerLabels, nil)
+	}
+	taskDesc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(exporterNamespace, "task", name), help,
+			exporterTaskLabels, nil)
+	}
+	return &TaskMetricsExporter{
+		engine: engine,
+		containerCPUUtilization: containerDesc("cpu_utilization_percent",
+			"CPU utilization of the container, as a percentage of one CPU"),
+		containerMemoryUsage: containerDesc("memory_usage_bytes", "Memory used by the container"),
+		containerStorageRead: containerDesc("storage_read_bytes_total", "Number of bytes read from disk by the container"),
+		containerStorageWrite: containerDesc("storage_write_bytes_total",
+			"Number of bytes written to disk by the container"),
+		containerNetwork: newNetworkDescs("container", exporterContainerLabels),
+		taskCPUUtilization: taskDesc("cpu_utilization_percent",
+			"Sum of the CPU utilization of the containers of the task, as a percentage of one CPU"),
+		taskMemoryUsage: taskDesc("memory_usage_bytes", "Sum of the memory used by the containers of the task"),
+		taskContainers:  taskDesc("containers", "Number of containers of the task with stats"),
+		taskNetwork:     newNetworkDescs("task", exporterTaskLabels),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (exporter *TaskMetricsExporter) Describe(ch chan<- *prometheus.Desc) {
+	ch <- exporter.containerCPUUtilization
+	ch <- exporter.containerMemoryUsage
+	ch <- exporter.containerStorageRead
+	ch <- exporter.containerStorageWrite
+	exporter.containerNetwork.describe(ch)
+	ch <- exporter.taskCPUUtilization
+	ch <- exporter.taskMemoryUsage
+	ch <- exporter.taskContainers
+	exporter.taskNetwork.describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (exporter *TaskMetricsExporter) Collect(ch chan<- prometheus.Metric) {
+	engine := exporter.engine
+	engine.lock.RLock()
+	defer engine.lock.RUnlock()
+
+	for taskARN, containers := range engine.tasksToContainers {
+		definition, ok := engine.tasksToDefinitions[taskARN]
+		if !ok {
+			continue
+		}
+		taskLabelValues := []string{taskARN, definition.family, definition.version}
+
+		var taskCPUUtilization float64
+		var taskMemoryUsage float64
+		var taskContainers int
+		for _, container := range containers {
+			stat, ok := container.statsQueue.GetLastUsageStats()
+			if !ok {
+				continue
+			}
+			taskContainers++
+			labelValues := []string{taskARN, definition.family, definition.version, container.containerMetadata.Name}
+
+			// the cpu utilization is only known once the queue has two samples
+			if cpu := float64(stat.CPUUsagePerc); !math.IsNaN(cpu) {
+				taskCPUUtilization += cpu
+				ch <- prometheus.MustNewConstMetric(exporter.containerCPUUtilization, prometheus.GaugeValue,
+					cpu, labelValues...)
+			}
+			memory := float64(stat.MemoryUsageInMegs) * BytesInMiB
+			taskMemoryUsage += memory
+			ch <- prometheus.MustNewConstMetric(exporter.containerMemoryUsage, prometheus.GaugeValue,
+				memory, labelValues...)
+			ch <- prometheus.MustNewConstMetric(exporter.containerStorageRead, prometheus.CounterValue,
+				float64(stat.StorageReadBytes), labelValues...)
+			ch <- prometheus.MustNewConstMetric(exporter.containerStorageWrite, prometheus.CounterValue,
+				float64(stat.StorageWriteBytes), labelValues...)
+			if stat.NetworkStats != nil {
+				exporter.containerNetwork.collect(ch, stat.NetworkStats, labelValues)
+			}
+		}
+		if taskContainers == 0 {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(exporter.taskCPUUtilization, prometheus.GaugeValue,
+			taskCPUUtilization, taskLabelValues...)
+		ch <- prometheus.MustNewConstMetric(exporter.taskMemoryUsage, prometheus.GaugeValue,
+			taskMemoryUsage, taskLabelValues...)
+		ch <- prometheus.MustNewConstMetric(exporter.taskContainers, prometheus.GaugeValue,
+			float64(taskContainers), taskLabelValues...)
+		// tasks in awsvpc network mode collect the network stats of the task network namespace
+		if taskStats, ok := engine.taskToTaskStats[taskARN]; ok && taskStats.StatsQueue != nil {
+			if stat, ok := taskStats.StatsQueue.GetLastUsageStats(); ok && stat.NetworkStats != nil {
+				exporter.taskNetwork.collect(ch, stat.NetworkStats, taskLabelValues)
+			}
+		}
+	}
+}
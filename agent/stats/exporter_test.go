@@ -0,0 +1,135 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testExporterTaskARN = "arn:aws:ecs:us-west-2:1234567890:task/test-cluster/task1"
+
+func newTestStatsContainer(name string, stats ...*ContainerStats) *StatsContainer {
+	queue := NewQueue(10)
+	for _, stat := range stats {
+		queue.add(stat)
+	}
+	return &StatsContainer{
+		containerMetadata: &ContainerMetadata{DockerID: name + "-id", Name: name},
+		statsQueue:        queue,
+	}
+}
+
+// gatherExporterMetrics returns the metrics collected by the exporter, by metric name
+func gatherExporterMetrics(t *testing.T, engine *DockerStatsEngine) map[string][]*dto.Metric {
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(NewTaskMetricsExporter(engine)))
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	metrics := make(map[string][]*dto.Metric)
+	for _, family := range families {
+		metrics[family.GetName()] = family.GetMetric()
+	}
+	return metrics
+}
+
+func metricLabels(metric *dto.Metric) map[string]string {
+	labels := make(map[string]string)
+	for _, label := range metric.GetLabel() {
+		labels[label.GetName()] = label.GetValue()
+	}
+	return labels
+}
+
+func TestTaskMetricsExporter(t *testing.T) {
+	engine := NewDockerStatsEngine(&cfg, nil, eventStream("TestTaskMetricsExporter"), nil, nil)
+	engine.tasksToDefinitions[testExporterTaskARN] = &taskDefinition{family: "web", version: "3"}
+	engine.tasksToContainers[testExporterTaskARN] = map[string]*StatsContainer{
+		"app-id":     newTestStatsContainer("app", createFakeContainerStats()...),
+		"sidecar-id": newTestStatsContainer("sidecar", createFakeContainerStats()[0]),
+		"new-id":     newTestStatsContainer("new"),
+	}
+
+	metrics := gatherExporterMetrics(t, engine)
+
+	memory := metrics["ecs_container_memory_usage_bytes"]
+	require.Len(t, memory, 2, "containers without stats are not exported")
+	for _, metric := range memory {
+		labels := metricLabels(metric)
+		assert.Equal(t, testExporterTaskARN, labels[labelTaskARN])
+		assert.Equal(t, "web", labels[labelFamily])
+		assert.Equal(t, "3", labels[labelRevision])
+		assert.Contains(t, []string{"app", "sidecar"}, labels[labelContainerName])
+	}
+
+	cpu := metrics["ecs_container_cpu_utilization_percent"]
+	require.Len(t, cpu, 1, "cpu utilization needs two samples")
+	assert.Equal(t, "app", metricLabels(cpu[0])[labelContainerName])
+	assert.Greater(t, cpu[0].GetGauge().GetValue(), float64(0))
+
+	storageRead := metrics["ecs_container_storage_read_bytes_total"]
+	require.Len(t, storageRead, 2)
+	for _, metric := range storageRead {
+		if metricLabels(metric)[labelContainerName] == "app" {
+			assert.Equal(t, float64(300), metric.GetCounter().GetValue())
+		}
+	}
+	require.Len(t, metrics["ecs_container_network_receive_bytes_total"], 2)
+	assert.Equal(t, float64(796), metrics["ecs_container_network_receive_bytes_total"][0].GetCounter().GetValue())
+
+	taskContainers := metrics["ecs_task_containers"]
+	require.Len(t, taskContainers, 1)
+	assert.Equal(t, float64(2), taskContainers[0].GetGauge().GetValue())
+	assert.NotContains(t, metricLabels(taskContainers[0]), labelContainerName)
+	require.Len(t, metrics["ecs_task_memory_usage_bytes"], 1)
+	assert.Equal(t, float64((3+1)*BytesInMiB), metrics["ecs_task_memory_usage_bytes"][0].GetGauge().GetValue())
+	assert.Empty(t, metrics["ecs_task_network_receive_bytes_total"], "only awsvpc tasks have task network stats")
+}
+
+func TestTaskMetricsExporterAWSVPCTaskNetwork(t *testing.T) {
+	engine := NewDockerStatsEngine(&cfg, nil, eventStream("TestTaskMetricsExporterAWSVPCTaskNetwork"), nil, nil)
+	engine.tasksToDefinitions[testExporterTaskARN] = &taskDefinition{family: "web", version: "3"}
+	engine.tasksToContainers[testExporterTaskARN] = map[string]*StatsContainer{
+		"app-id": newTestStatsContainer("app", createFakeContainerStats()...),
+	}
+	taskQueue := NewQueue(10)
+	taskQueue.add(createFakeContainerStats()[0])
+	engine.taskToTaskStats[testExporterTaskARN] = &StatsTask{
+		statsTaskCommon: &statsTaskCommon{StatsQueue: taskQueue},
+	}
+
+	metrics := gatherExporterMetrics(t, engine)
+
+	taskNetwork := metrics["ecs_task_network_transmit_bytes_total"]
+	require.Len(t, taskNetwork, 1)
+	assert.Equal(t, float64(8192), taskNetwork[0].GetCounter().GetValue())
+	assert.Equal(t, testExporterTaskARN, metricLabels(taskNetwork[0])[labelTaskARN])
+}
+
+func TestTaskMetricsExporterNoTasks(t *testing.T) {
+	engine := NewDockerStatsEngine(&cfg, nil, eventStream("TestTaskMetricsExporterNoTasks"), nil, nil)
+	engine.tasksToContainers[testExporterTaskARN] = map[string]*StatsContainer{
+		"app-id": newTestStatsContainer("app", createFakeContainerStats()...),
+	}
+
+	assert.Empty(t, gatherExporterMetrics(t, engine), "containers of unknown task definitions are not exported")
+}
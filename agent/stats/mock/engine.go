@@ -22,6 +22,7 @@ import (
 	reflect "reflect"
 	time "time"
 
+	task "github.com/aws/amazon-ecs-agent/agent/api/task"
 	stats "github.com/aws/amazon-ecs-agent/ecs-agent/stats"
 	ecstcs "github.com/aws/amazon-ecs-agent/ecs-agent/tcs/model/ecstcs"
 	types "github.com/docker/docker/api/types"
@@ -127,6 +128,35 @@ func (mr *MockEngineMockRecorder) GetTaskHealthMetrics() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaskHealthMetrics", reflect.TypeOf((*MockEngine)(nil).GetTaskHealthMetrics))
 }
 
+// GetTaskResourceUsage mocks base method.
+func (m *MockEngine) GetTaskResourceUsage(arg0 string) (*task.ResourceUsage, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTaskResourceUsage", arg0)
+	ret0, _ := ret[0].(*task.ResourceUsage)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// GetTaskResourceUsage indicates an expected call of GetTaskResourceUsage.
+func (mr *MockEngineMockRecorder) GetTaskResourceUsage(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaskResourceUsage", reflect.TypeOf((*MockEngine)(nil).GetTaskResourceUsage), arg0)
+}
+
+// GetTaskResourceUsages mocks base method.
+func (m *MockEngine) GetTaskResourceUsages() []*task.ResourceUsage {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTaskResourceUsages")
+	ret0, _ := ret[0].([]*task.ResourceUsage)
+	return ret0
+}
+
+// GetTaskResourceUsages indicates an expected call of GetTaskResourceUsages.
+func (mr *MockEngineMockRecorder) GetTaskResourceUsages() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaskResourceUsages", reflect.TypeOf((*MockEngine)(nil).GetTaskResourceUsages))
+}
+
 // SetPublishServiceConnectTickerInterval mocks base method.
 func (m *MockEngine) SetPublishServiceConnectTickerInterval(arg0 int32) {
 	m.ctrl.T.Helper()
@@ -156,6 +156,23 @@ func (queue *Queue) GetLastNetworkStatPerSec() *stats.NetworkStatsPerSec {
 	return queue.lastNetworkStatPerSec
 }
 
+// GetLastUsageStats returns a copy of the most recent usage stats in the queue. It returns false
+// when the queue is empty.
+func (queue *Queue) GetLastUsageStats() (UsageStats, bool) {
+	queue.lock.RLock()
+	defer queue.lock.RUnlock()
+
+	if len(queue.buffer) == 0 {
+		return UsageStats{}, false
+	}
+	stat := queue.buffer[len(queue.buffer)-1]
+	if stat.NetworkStats != nil {
+		networkStats := *stat.NetworkStats
+		stat.NetworkStats = &networkStats
+	}
+	return stat, true
+}
+
 // GetCPUStatsSet gets the stats set for CPU utilization.
 func (queue *Queue) GetCPUStatsSet() (*ecstcs.CWStatsSet, error) {
 	return queue.getCWStatsSet(getCPUUsagePerc)
@@ -228,6 +228,26 @@ func TestQueueReset(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestQueueGetLastUsageStats(t *testing.T) {
+	queue := NewQueue(10)
+	_, ok := queue.GetLastUsageStats()
+	require.False(t, ok)
+
+	stats := getContainerStats(false)
+	queue.add(stats[0])
+	queue.add(stats[1])
+	stat, ok := queue.GetLastUsageStats()
+	require.True(t, ok)
+	assert.Equal(t, stats[1].timestamp, stat.Timestamp)
+	assert.Equal(t, stats[1].storageReadBytes, stat.StorageReadBytes)
+	require.NotNil(t, stat.NetworkStats)
+
+	// the returned stats are a copy of the queue data
+	stat.NetworkStats.RxBytes++
+	last, _ := queue.GetLastUsageStats()
+	assert.Equal(t, stat.NetworkStats.RxBytes-1, last.NetworkStats.RxBytes)
+}
+
 func TestQueueAddRemove(t *testing.T) {
 	timestamps := getTimestamps()
 	queueLength := 5
@@ -16,17 +16,20 @@ package reporter
 import (
 	"context"
 	"errors"
+	"path/filepath"
 	"time"
 
 	"github.com/aws/amazon-ecs-agent/agent/api"
 	"github.com/aws/amazon-ecs-agent/agent/config"
 	"github.com/aws/amazon-ecs-agent/agent/engine"
+	"github.com/aws/amazon-ecs-agent/agent/metrics"
 	"github.com/aws/amazon-ecs-agent/agent/version"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/doctor"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/eventstream"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
-	"github.com/aws/amazon-ecs-agent/ecs-agent/metrics"
+	tcsbuffer "github.com/aws/amazon-ecs-agent/ecs-agent/tcs/buffer"
+	tcsclient "github.com/aws/amazon-ecs-agent/ecs-agent/tcs/client"
 	tcshandler "github.com/aws/amazon-ecs-agent/ecs-agent/tcs/handler"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/tcs/model/ecstcs"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/wsclient"
@@ -77,25 +80,47 @@ func NewDockerTelemetrySession(
 		cfg.DisableMetrics.Enabled(),
 		credentialProvider,
 		&wsclient.WSClientMinAgentConfig{
-			AWSRegion:          cfg.AWSRegion,
-			AcceptInsecureCert: cfg.AcceptInsecureCert,
-			DockerEndpoint:     cfg.DockerEndpoint,
-			IsDocker:           true,
+			AWSRegion:             cfg.AWSRegion,
+			AcceptInsecureCert:    cfg.AcceptInsecureCert,
+			DockerEndpoint:        cfg.DockerEndpoint,
+			IsDocker:              true,
+			DisableCompression:    cfg.WebsocketCompressionDisabled.Enabled(),
+			MaxInboundMessageSize: cfg.TCSMaxMessageSize,
+			MessageMetrics:        metrics.MetricsEngineGlobal.WebsocketMessageRecorder(metrics.WebsocketClientTCS),
 		},
 		deregisterInstanceEventStream,
 		defaultHeartbeatTimeout,
 		defaultHeartbeatJitter,
 		wsclient.DisconnectTimeout,
 		wsclient.DisconnectJitterMax,
-		metrics.NewNopEntryFactory(),
+		metrics.MetricsEngineGlobal.EntryFactory(),
 		metricsChannel,
 		healthChannel,
 		doctor,
 		ecsClient,
+		newMessageBuffer(cfg),
 	)
 	return &DockerTelemetrySession{session}, nil
 }
 
+// newMessageBuffer opens the on-disk queue of the telemetry and health messages that could not be
+// published to TCS. It returns nil when the queue is disabled or cannot be opened, in which case
+// those messages are dropped.
+func newMessageBuffer(cfg *config.Config) tcsclient.MessageBuffer {
+	if cfg.TCSBufferDisabled.Enabled() || cfg.DataDir == "" {
+		return nil
+	}
+	buffer, err := tcsbuffer.NewDiskBuffer(filepath.Join(cfg.DataDir, tcsbuffer.DirName),
+		cfg.TCSBufferMaxAge, cfg.TCSBufferMaxSize, metrics.MetricsEngineGlobal.EntryFactory())
+	if err != nil {
+		logger.Warn("Unable to open the TCS message buffer, unpublished messages will be dropped", logger.Fields{
+			field.Error: err,
+		})
+		return nil
+	}
+	return buffer
+}
+
 func (session *DockerTelemetrySession) Start(ctx context.Context) error {
 	return session.s.Start(ctx)
 }
@@ -19,13 +19,16 @@ package reporter
 import (
 	"context"
 	"errors"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/aws/amazon-ecs-agent/agent/config"
 	mock_engine "github.com/aws/amazon-ecs-agent/agent/engine/mocks"
 	"github.com/aws/amazon-ecs-agent/agent/version"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/doctor"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/eventstream"
+	tcsbuffer "github.com/aws/amazon-ecs-agent/ecs-agent/tcs/buffer"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -118,6 +121,24 @@ func TestNewDockerTelemetrySession(t *testing.T) {
 	}
 }
 
+func TestNewMessageBuffer(t *testing.T) {
+	dataDir := t.TempDir()
+	cfg := &config.Config{
+		DataDir:          dataDir,
+		TCSBufferMaxAge:  time.Minute,
+		TCSBufferMaxSize: 1024,
+	}
+	assert.NotNil(t, newMessageBuffer(cfg))
+	assert.DirExists(t, filepath.Join(dataDir, tcsbuffer.DirName))
+
+	cfg.TCSBufferDisabled = config.BooleanDefaultFalse{Value: config.ExplicitlyEnabled}
+	assert.Nil(t, newMessageBuffer(cfg))
+
+	cfg.TCSBufferDisabled = config.BooleanDefaultFalse{}
+	cfg.TCSBufferMaxSize = 0
+	assert.Nil(t, newMessageBuffer(cfg))
+}
+
 func TestGenerateVersionInfo_GetVersionError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -87,6 +87,8 @@ type StatsContainer struct {
 	statsQueue        *Queue
 	resolver          resolver.ContainerMetadataResolver
 	config            *config.Config
+	// cgroupfs collects the stats of the container instead of a Docker stats stream when it's set.
+	cgroupfs *cgroupfsCollector
 }
 
 // taskDefinition encapsulates family and version strings for a task definition
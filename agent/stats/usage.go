@@ -0,0 +1,246 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+import (
+	"time"
+
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/data"
+	"github.com/aws/amazon-ecs-agent/agent/utils"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+)
+
+// taskNetworkUsageSource is the sample source of the network usage of awsvpc tasks, which is
+// collected for the task network namespace rather than for each container.
+const taskNetworkUsageSource = "task-network"
+
+// SetDataClient sets the data client the resource usage of the tasks is persisted with. The usage
+// is kept in memory only if no data client is set.
+func (engine *DockerStatsEngine) SetDataClient(client data.Client) {
+	if client == nil {
+		return
+	}
+	engine.dataClient = client
+}
+
+// GetTaskResourceUsage returns the resource usage totals of a task.
+func (engine *DockerStatsEngine) GetTaskResourceUsage(taskARN string) (*apitask.ResourceUsage, bool) {
+	engine.usageLock.Lock()
+	defer engine.usageLock.Unlock()
+
+	usage, ok := engine.taskUsage[taskARN]
+	if !ok {
+		return nil, false
+	}
+	return usage.Totals(), true
+}
+
+// GetTaskResourceUsages returns the resource usage totals of all the tasks, including the stopped
+// tasks that are still retained.
+func (engine *DockerStatsEngine) GetTaskResourceUsages() []*apitask.ResourceUsage {
+	engine.usageLock.Lock()
+	defer engine.usageLock.Unlock()
+
+	usages := make([]*apitask.ResourceUsage, 0, len(engine.taskUsage))
+	for _, usage := range engine.taskUsage {
+		usages = append(usages, usage.Totals())
+	}
+	return usages
+}
+
+// loadTaskResourceUsages restores the resource usage accounted before the agent restarted.
+func (engine *DockerStatsEngine) loadTaskResourceUsages() {
+	usages, err := engine.dataClient.GetTaskResourceUsages()
+	if err != nil {
+		logger.Warn("Failed to load the resource usage of the tasks", logger.Fields{
+			field.Error: err,
+		})
+		return
+	}
+	engine.usageLock.Lock()
+	defer engine.usageLock.Unlock()
+	for _, usage := range usages {
+		engine.taskUsage[usage.TaskARN] = usage
+	}
+}
+
+// accountResourceUsagePeriodically folds the last stats of every container into the resource
+// usage of its task until the engine is stopped.
+func (engine *DockerStatsEngine) accountResourceUsagePeriodically() {
+	ticker := time.NewTicker(config.DefaultContainerMetricsPublishInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-engine.ctx.Done():
+			return
+		case <-ticker.C:
+			engine.accountResourceUsage()
+		}
+	}
+}
+
+// accountResourceUsage samples all the tracked containers, finalizes the usage of the tasks that
+// stopped and prunes the finalized usage that is past the retention.
+func (engine *DockerStatsEngine) accountResourceUsage() {
+	engine.lock.RLock()
+	changed := make(map[string]*apitask.ResourceUsage)
+	for taskARN, containers := range engine.tasksToContainers {
+		for _, container := range containers {
+			if usage := engine.accountContainerUsageUnsafe(container, taskARN); usage != nil {
+				changed[taskARN] = usage
+			}
+		}
+		if taskStats, ok := engine.taskToTaskStats[taskARN]; ok && taskStats.StatsQueue != nil {
+			if stat, ok := taskStats.StatsQueue.GetLastUsageStats(); ok && stat.NetworkStats != nil {
+				sample := apitask.ResourceUsageSample{
+					Timestamp:      stat.Timestamp,
+					NetworkRxBytes: stat.NetworkStats.RxBytes,
+					NetworkTxBytes: stat.NetworkStats.TxBytes,
+				}
+				if usage := engine.accumulateUsageUnsafe(taskARN, taskNetworkUsageSource, sample); usage != nil {
+					changed[taskARN] = usage
+				}
+			}
+		}
+	}
+	tracked := make(map[string]bool, len(engine.tasksToContainers))
+	for taskARN := range engine.tasksToContainers {
+		tracked[taskARN] = true
+	}
+	engine.lock.RUnlock()
+
+	engine.usageLock.Lock()
+	var expired []string
+	now := time.Now()
+	for taskARN, usage := range engine.taskUsage {
+		if usage.StoppedAt == nil {
+			if tracked[taskARN] || !engine.taskStopped(taskARN) {
+				continue
+			}
+			usage.Stop(engine.taskStoppedAt(taskARN, now))
+			changed[taskARN] = usage
+			continue
+		}
+		if now.Sub(*usage.StoppedAt) > engine.config.TaskResourceUsageRetention {
+			delete(engine.taskUsage, taskARN)
+			delete(changed, taskARN)
+			expired = append(expired, taskARN)
+		}
+	}
+	for _, usage := range changed {
+		engine.saveTaskResourceUsageUnsafe(usage)
+	}
+	engine.usageLock.Unlock()
+
+	for _, taskARN := range expired {
+		engine.deleteTaskResourceUsage(taskARN)
+	}
+}
+
+// accountContainerUsageUnsafe folds the last stats of a container into the resource usage of its
+// task. It returns the usage if it changed. The caller must hold the engine lock.
+func (engine *DockerStatsEngine) accountContainerUsageUnsafe(container *StatsContainer,
+	taskARN string) *apitask.ResourceUsage {
+	if container.statsQueue == nil {
+		return nil
+	}
+	stat, ok := container.statsQueue.GetLastUsageStats()
+	if !ok {
+		return nil
+	}
+	sample := apitask.ResourceUsageSample{
+		Timestamp:           stat.Timestamp,
+		CPUUsageNanoseconds: stat.cpuUsage,
+		MemoryUsageBytes:    uint64(stat.MemoryUsageInMegs) * BytesInMiB,
+		StorageReadBytes:    stat.StorageReadBytes,
+		StorageWriteBytes:   stat.StorageWriteBytes,
+	}
+	// the network of awsvpc tasks is accounted for the task network namespace
+	if _, ok := engine.taskToTaskStats[taskARN]; !ok && stat.NetworkStats != nil {
+		sample.NetworkRxBytes = stat.NetworkStats.RxBytes
+		sample.NetworkTxBytes = stat.NetworkStats.TxBytes
+	}
+	return engine.accumulateUsageUnsafe(taskARN, container.containerMetadata.DockerID, sample)
+}
+
+// accumulateUsageUnsafe folds a sample into the resource usage of a task, creating the usage on the
+// first sample. It returns the usage if it changed. The caller must hold the engine lock.
+func (engine *DockerStatsEngine) accumulateUsageUnsafe(taskARN, source string,
+	sample apitask.ResourceUsageSample) *apitask.ResourceUsage {
+	engine.usageLock.Lock()
+	defer engine.usageLock.Unlock()
+
+	usage, ok := engine.taskUsage[taskARN]
+	if !ok {
+		var family, version string
+		if taskDef, ok := engine.tasksToDefinitions[taskARN]; ok {
+			family, version = taskDef.family, taskDef.version
+		}
+		usage = apitask.NewResourceUsage(taskARN, family, version)
+		engine.taskUsage[taskARN] = usage
+	}
+	if !usage.Accumulate(source, sample) {
+		return nil
+	}
+	return usage
+}
+
+// taskStopped returns true if the task is no longer managed by the task engine or is stopped.
+func (engine *DockerStatsEngine) taskStopped(taskARN string) bool {
+	if engine.resolver == nil {
+		return false
+	}
+	task, err := engine.resolver.ResolveTaskByARN(taskARN)
+	if err != nil {
+		return true
+	}
+	return task.GetKnownStatus().Terminal()
+}
+
+// taskStoppedAt returns the time the task stopped, or now if it is not known anymore.
+func (engine *DockerStatsEngine) taskStoppedAt(taskARN string, now time.Time) time.Time {
+	task, err := engine.resolver.ResolveTaskByARN(taskARN)
+	if err != nil {
+		return now
+	}
+	if stoppedAt := task.GetExecutionStoppedAt(); !stoppedAt.IsZero() {
+		return stoppedAt
+	}
+	return now
+}
+
+func (engine *DockerStatsEngine) saveTaskResourceUsageUnsafe(usage *apitask.ResourceUsage) {
+	if err := engine.dataClient.SaveTaskResourceUsage(usage); err != nil {
+		logger.Warn("Failed to save the resource usage of the task", logger.Fields{
+			field.TaskARN: usage.TaskARN,
+			field.Error:   err,
+		})
+	}
+}
+
+func (engine *DockerStatsEngine) deleteTaskResourceUsage(taskARN string) {
+	id, err := utils.GetTaskID(taskARN)
+	if err == nil {
+		err = engine.dataClient.DeleteTaskResourceUsage(id)
+	}
+	if err != nil {
+		logger.Warn("Failed to delete the resource usage of the task", logger.Fields{
+			field.TaskARN: taskARN,
+			field.Error:   err,
+		})
+	}
+}
@@ -0,0 +1,152 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package stats
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/data"
+	mock_resolver "github.com/aws/amazon-ecs-agent/agent/stats/resolver/mock"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const usageTestTaskARN = "arn:aws:ecs:us-west-2:1234567890:task/test-cluster/abc"
+
+func newUsageTestEngine(t *testing.T) (*DockerStatsEngine, *mock_resolver.MockContainerMetadataResolver, data.Client) {
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	dataClient, err := data.NewWithSetup(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { dataClient.Close() })
+
+	resolver := mock_resolver.NewMockContainerMetadataResolver(ctrl)
+	testCfg := cfg
+	testCfg.TaskResourceUsageRetention = time.Hour
+	engine := NewDockerStatsEngine(&testCfg, nil, eventStream("TestTaskResourceUsage"), nil, nil)
+	engine.resolver = resolver
+	engine.SetDataClient(dataClient)
+	engine.tasksToDefinitions[usageTestTaskARN] = &taskDefinition{family: "family", version: "1"}
+	return engine, resolver, dataClient
+}
+
+func addUsageTestContainer(engine *DockerStatsEngine, dockerID string) *StatsContainer {
+	ctx, cancel := context.WithCancel(context.Background())
+	container := &StatsContainer{
+		containerMetadata: &ContainerMetadata{DockerID: dockerID},
+		ctx:               ctx,
+		cancel:            cancel,
+		statsQueue:        NewQueue(10),
+	}
+	if engine.tasksToContainers[usageTestTaskARN] == nil {
+		engine.tasksToContainers[usageTestTaskARN] = make(map[string]*StatsContainer)
+	}
+	engine.tasksToContainers[usageTestTaskARN][dockerID] = container
+	return container
+}
+
+func TestTaskResourceUsageAccounting(t *testing.T) {
+	engine, resolver, dataClient := newUsageTestEngine(t)
+	start := time.Now().Add(-time.Minute)
+	c1 := addUsageTestContainer(engine, "c1")
+	c2 := addUsageTestContainer(engine, "c2")
+
+	task := &apitask.Task{Arn: usageTestTaskARN}
+	task.SetKnownStatus(apitaskstatus.TaskRunning)
+	resolver.EXPECT().ResolveTaskByARN(usageTestTaskARN).Return(task, nil).AnyTimes()
+
+	c1.statsQueue.add(&ContainerStats{timestamp: start, cpuUsage: 1e9, memoryUsage: 1024 * BytesInMiB,
+		networkStats: &NetworkStats{RxBytes: 10, TxBytes: 20}})
+	c2.statsQueue.add(&ContainerStats{timestamp: start, cpuUsage: 2e9, storageWriteBytes: 100})
+	engine.accountResourceUsage()
+
+	c1.statsQueue.add(&ContainerStats{timestamp: start.Add(10 * time.Second), cpuUsage: 3e9,
+		memoryUsage: 1024 * BytesInMiB, networkStats: &NetworkStats{RxBytes: 30, TxBytes: 40}})
+	engine.accountResourceUsage()
+
+	usage, ok := engine.GetTaskResourceUsage(usageTestTaskARN)
+	require.True(t, ok)
+	assert.Equal(t, "family", usage.Family)
+	assert.Equal(t, "1", usage.Revision)
+	assert.Equal(t, 5.0, usage.CPUSeconds)
+	assert.Equal(t, 10.0, usage.MemoryGBSeconds)
+	assert.Equal(t, uint64(30), usage.NetworkRxBytes)
+	assert.Equal(t, uint64(40), usage.NetworkTxBytes)
+	assert.Equal(t, uint64(100), usage.StorageWriteBytes)
+	assert.Nil(t, usage.StoppedAt)
+	assert.Nil(t, usage.LastSamples)
+
+	persisted, err := dataClient.GetTaskResourceUsages()
+	require.NoError(t, err)
+	require.Len(t, persisted, 1)
+	assert.Equal(t, 5.0, persisted[0].CPUSeconds)
+}
+
+func TestTaskResourceUsageFinalizedWhenTaskStops(t *testing.T) {
+	engine, resolver, dataClient := newUsageTestEngine(t)
+	start := time.Now().Add(-time.Minute)
+	c1 := addUsageTestContainer(engine, "c1")
+
+	stoppedAt := start.Add(30 * time.Second)
+	task := &apitask.Task{Arn: usageTestTaskARN}
+	task.SetKnownStatus(apitaskstatus.TaskStopped)
+	task.SetExecutionStoppedAt(stoppedAt)
+	resolver.EXPECT().ResolveTaskByARN(usageTestTaskARN).Return(task, nil).AnyTimes()
+
+	c1.statsQueue.add(&ContainerStats{timestamp: start, cpuUsage: 1e9})
+	engine.accountResourceUsage()
+	c1.statsQueue.add(&ContainerStats{timestamp: start.Add(10 * time.Second), cpuUsage: 4e9})
+
+	// the last stats of the container are accounted when it is removed
+	engine.doRemoveContainerUnsafe(c1, usageTestTaskARN)
+	engine.accountResourceUsage()
+
+	usage, ok := engine.GetTaskResourceUsage(usageTestTaskARN)
+	require.True(t, ok)
+	assert.Equal(t, 4.0, usage.CPUSeconds)
+	require.NotNil(t, usage.StoppedAt)
+	assert.True(t, stoppedAt.Equal(*usage.StoppedAt))
+
+	persisted, err := dataClient.GetTaskResourceUsages()
+	require.NoError(t, err)
+	require.Len(t, persisted, 1)
+	assert.NotNil(t, persisted[0].StoppedAt)
+}
+
+func TestTaskResourceUsagePrunedAfterRetention(t *testing.T) {
+	engine, _, dataClient := newUsageTestEngine(t)
+
+	usage := apitask.NewResourceUsage(usageTestTaskARN, "family", "1")
+	usage.Stop(time.Now().Add(-2 * time.Hour))
+	require.NoError(t, dataClient.SaveTaskResourceUsage(usage))
+
+	engine.loadTaskResourceUsages()
+	assert.Len(t, engine.GetTaskResourceUsages(), 1)
+
+	engine.accountResourceUsage()
+	assert.Len(t, engine.GetTaskResourceUsages(), 0)
+	persisted, err := dataClient.GetTaskResourceUsages()
+	require.NoError(t, err)
+	assert.Len(t, persisted, 0)
+}
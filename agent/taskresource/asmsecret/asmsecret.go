@@ -18,7 +18,6 @@ import (
 	"fmt"
 	"strings"
 	"sync"
-	"time"
 
 	"github.com/cihub/seelog"
 	"github.com/pkg/errors"
@@ -27,8 +26,8 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/asm"
 	"github.com/aws/amazon-ecs-agent/agent/asm/factory"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource/secretfiles"
 	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
-	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
 	"github.com/aws/aws-sdk-go/aws"
@@ -47,18 +46,13 @@ const (
 // ASMSecretResource represents secrets as a task resource.
 // The secrets are stored in AWS Secrets Manager.
 type ASMSecretResource struct {
-	taskARN             string
-	createdAt           time.Time
-	desiredStatusUnsafe resourcestatus.ResourceStatus
-	knownStatusUnsafe   resourcestatus.ResourceStatus
-	// appliedStatus is the status that has been "applied" (e.g., we've called some
-	// operation such as 'Create' on the resource) but we don't yet know that the
-	// application was successful, which may then change the known status. This is
-	// used while progressing resource states in progressTask() of task manager
-	appliedStatus                      resourcestatus.ResourceStatus
-	resourceStatusToTransitionFunction map[resourcestatus.ResourceStatus]func() error
-	credentialsManager                 credentials.Manager
-	executionCredentialsID             string
+	taskresource.ResourceBase
+	// Writer writes the secrets to files when the containers of the task use secret files
+	secretfiles.Writer
+
+	taskARN                string
+	credentialsManager     credentials.Manager
+	executionCredentialsID string
 
 	// map to store all asm deduped secrets in the task, key is a combination of valueFrom and region
 	requiredSecrets map[string]apicontainer.Secret
@@ -69,11 +63,6 @@ type ASMSecretResource struct {
 	// needed mostly for testing.
 	asmClientCreator factory.ClientCreator
 
-	// terminalReason should be set for resource creation failures. This ensures
-	// the resource object carries some context for why provisioning failed.
-	terminalReason     string
-	terminalReasonOnce sync.Once
-
 	// lock is used for fields that are accessed and updated concurrently
 	lock sync.RWMutex
 }
@@ -98,184 +87,83 @@ func NewASMSecretResource(taskARN string,
 }
 
 func (secret *ASMSecretResource) initStatusToTransition() {
-	resourceStatusToTransitionFunction := map[resourcestatus.ResourceStatus]func() error{
-		resourcestatus.ResourceStatus(ASMSecretCreated): secret.Create,
-	}
-	secret.resourceStatusToTransitionFunction = resourceStatusToTransitionFunction
-}
-
-func (secret *ASMSecretResource) setTerminalReason(reason string) {
-	secret.terminalReasonOnce.Do(func() {
-		seelog.Infof("ASM secret resource: setting terminal reason for asm secret resource in task: [%s]", secret.taskARN)
-		secret.terminalReason = reason
-	})
-}
-
-// GetTerminalReason returns an error string to propagate up through to task
-// state change messages
-func (secret *ASMSecretResource) GetTerminalReason() string {
-	return secret.terminalReason
-}
-
-// SetDesiredStatus safely sets the desired status of the resource
-func (secret *ASMSecretResource) SetDesiredStatus(status resourcestatus.ResourceStatus) {
-	secret.lock.Lock()
-	defer secret.lock.Unlock()
-
-	secret.desiredStatusUnsafe = status
-}
-
-// GetDesiredStatus safely returns the desired status of the task
-func (secret *ASMSecretResource) GetDesiredStatus() resourcestatus.ResourceStatus {
-	secret.lock.RLock()
-	defer secret.lock.RUnlock()
-
-	return secret.desiredStatusUnsafe
-}
-
-// GetName safely returns the name of the resource
-func (secret *ASMSecretResource) GetName() string {
-	secret.lock.RLock()
-	defer secret.lock.RUnlock()
-
-	return ResourceName
+	secret.InitBase(ResourceName, secret.Create)
 }
 
-// DesiredTerminal returns true if the secret's desired status is REMOVED
-func (secret *ASMSecretResource) DesiredTerminal() bool {
-	secret.lock.RLock()
-	defer secret.lock.RUnlock()
-
-	return secret.desiredStatusUnsafe == resourcestatus.ResourceStatus(ASMSecretRemoved)
-}
-
-// KnownCreated returns true if the secret's known status is CREATED
-func (secret *ASMSecretResource) KnownCreated() bool {
-	secret.lock.RLock()
-	defer secret.lock.RUnlock()
-
-	return secret.knownStatusUnsafe == resourcestatus.ResourceStatus(ASMSecretCreated)
-}
-
-// TerminalStatus returns the last transition state of asmsecret
-func (secret *ASMSecretResource) TerminalStatus() resourcestatus.ResourceStatus {
-	return resourcestatus.ResourceStatus(ASMSecretRemoved)
-}
-
-// NextKnownState returns the state that the resource should
-// progress to based on its `KnownState`.
-func (secret *ASMSecretResource) NextKnownState() resourcestatus.ResourceStatus {
-	return secret.GetKnownStatus() + 1
-}
+// Create fetches the secret values from AWS Secrets Manager. It spins up multiple goroutines in
+// order to retrieve values in parallel.
+func (secret *ASMSecretResource) Create() error {
 
-// ApplyTransition calls the function required to move to the specified status
-func (secret *ASMSecretResource) ApplyTransition(nextState resourcestatus.ResourceStatus) error {
-	transitionFunc, ok := secret.resourceStatusToTransitionFunction[nextState]
-	if !ok {
-		return errors.Errorf("resource [%s]: transition to %s impossible", secret.GetName(),
-			secret.StatusString(nextState))
+	// To fail fast, check execution role first
+	iamCredentials, err := secret.getExecutionRoleCredentials()
+	if err != nil {
+		// No need to log here. managedTask.applyResourceState already does that
+		secret.SetTerminalReason(err.Error())
+		return err
 	}
-	return transitionFunc()
-}
-
-// SteadyState returns the transition state of the resource defined as "ready"
-func (secret *ASMSecretResource) SteadyState() resourcestatus.ResourceStatus {
-	return resourcestatus.ResourceStatus(ASMSecretCreated)
-}
 
-// SetKnownStatus safely sets the currently known status of the resource
-func (secret *ASMSecretResource) SetKnownStatus(status resourcestatus.ResourceStatus) {
+	seelog.Debugf("ASM secret resource: retrieving secrets for containers in task: [%s]", secret.taskARN)
+	secretData, err := secret.retrieveSecretValues(iamCredentials)
+	if err != nil {
+		secret.SetTerminalReason(err.Error())
+		return err
+	}
 	secret.lock.Lock()
-	defer secret.lock.Unlock()
+	secret.secretData = secretData
+	secret.lock.Unlock()
 
-	secret.knownStatusUnsafe = status
-	secret.updateAppliedStatusUnsafe(status)
+	if err := secret.SetupSecretFiles(secret.taskARN, secret.GetCachedSecretValue, secret.refreshSecretFiles); err != nil {
+		err = fmt.Errorf("ASM secret resource: %w", err)
+		secret.SetTerminalReason(err.Error())
+		return err
+	}
+	return nil
 }
 
-// updateAppliedStatusUnsafe updates the resource transitioning status
-func (secret *ASMSecretResource) updateAppliedStatusUnsafe(knownStatus resourcestatus.ResourceStatus) {
-	if secret.appliedStatus == resourcestatus.ResourceStatus(ASMSecretStatusNone) {
-		return
+// refreshSecretFiles retrieves the secrets again with the task execution role, and swaps the
+// secret files whose secret changed. The previous values are kept if any secret can't be retrieved.
+func (secret *ASMSecretResource) refreshSecretFiles() error {
+	iamCredentials, err := secret.getExecutionRoleCredentials()
+	if err != nil {
+		return err
 	}
-
-	// Check if the resource transition has already finished
-	if secret.appliedStatus <= knownStatus {
-		secret.appliedStatus = resourcestatus.ResourceStatus(ASMSecretStatusNone)
+	secretData, err := secret.retrieveSecretValues(iamCredentials)
+	if err != nil {
+		return err
 	}
-}
-
-// SetAppliedStatus sets the applied status of resource and returns whether
-// the resource is already in a transition
-func (secret *ASMSecretResource) SetAppliedStatus(status resourcestatus.ResourceStatus) bool {
-	secret.lock.Lock()
-	defer secret.lock.Unlock()
-
-	if secret.appliedStatus != resourcestatus.ResourceStatus(ASMSecretStatusNone) {
-		// return false to indicate the set operation failed
-		return false
+	for secretKey, secretValue := range secretData {
+		secret.SetCachedSecretValue(secretKey, secretValue)
 	}
-
-	secret.appliedStatus = status
-	return true
-}
-
-// GetKnownStatus safely returns the currently known status of the task
-func (secret *ASMSecretResource) GetKnownStatus() resourcestatus.ResourceStatus {
-	secret.lock.RLock()
-	defer secret.lock.RUnlock()
-
-	return secret.knownStatusUnsafe
-}
-
-// StatusString returns the string of the cgroup resource status
-func (secret *ASMSecretResource) StatusString(status resourcestatus.ResourceStatus) string {
-	return ASMSecretStatus(status).String()
-}
-
-// SetCreatedAt sets the timestamp for resource's creation time
-func (secret *ASMSecretResource) SetCreatedAt(createdAt time.Time) {
-	if createdAt.IsZero() {
-		return
+	if err := secret.WriteSecretFiles(secret.GetCachedSecretValue); err != nil {
+		return fmt.Errorf("ASM secret resource: %w", err)
 	}
-	secret.lock.Lock()
-	defer secret.lock.Unlock()
-
-	secret.createdAt = createdAt
-}
-
-// GetCreatedAt sets the timestamp for resource's creation time
-func (secret *ASMSecretResource) GetCreatedAt() time.Time {
-	secret.lock.RLock()
-	defer secret.lock.RUnlock()
-
-	return secret.createdAt
+	return nil
 }
 
-// It spins up multiple goroutines in order to retrieve values in parallel.
-func (secret *ASMSecretResource) Create() error {
-
-	// To fail fast, check execution role first
+// getExecutionRoleCredentials returns the credentials of the task execution role, the secrets
+// are retrieved with them.
+func (secret *ASMSecretResource) getExecutionRoleCredentials() (credentials.IAMRoleCredentials, error) {
 	executionCredentials, ok := secret.credentialsManager.GetTaskCredentials(secret.getExecutionCredentialsID())
 	if !ok {
-		// No need to log here. managedTask.applyResourceState already does that
-		err := errors.New("ASM secret resource: unable to find execution role credentials")
-		secret.setTerminalReason(err.Error())
-		return err
+		return credentials.IAMRoleCredentials{}, errors.New("ASM secret resource: unable to find execution role credentials")
 	}
-	iamCredentials := executionCredentials.GetIAMRoleCredentials()
+	return executionCredentials.GetIAMRoleCredentials(), nil
+}
 
+// retrieveSecretValues retrieves the values of the required secrets from AWS Secrets Manager, keyed
+// by the secret resource cache key. It spins up a goroutine per secret.
+func (secret *ASMSecretResource) retrieveSecretValues(iamCredentials credentials.IAMRoleCredentials) (map[string]string, error) {
 	var wg sync.WaitGroup
 
+	requiredSecrets := secret.getRequiredSecrets()
 	// Get the maximum number of errors to be returned, which will be one error per goroutine
-	errorEvents := make(chan error, len(secret.requiredSecrets))
-
-	seelog.Debugf("ASM secret resource: retrieving secrets for containers in task: [%s]", secret.taskARN)
-	secret.secretData = make(map[string]string)
+	errorEvents := make(chan error, len(requiredSecrets))
+	values := &secretValues{data: make(map[string]string)}
 
-	for _, asmsecret := range secret.getRequiredSecrets() {
+	for _, asmsecret := range requiredSecrets {
 		wg.Add(1)
 		// Spin up goroutine per secret to speed up processing time
-		go secret.retrieveASMSecretValue(asmsecret, iamCredentials, &wg, errorEvents)
+		go secret.retrieveASMSecretValue(asmsecret, iamCredentials, values, &wg, errorEvents)
 	}
 
 	wg.Wait()
@@ -286,17 +174,20 @@ func (secret *ASMSecretResource) Create() error {
 		for err := range errorEvents {
 			terminalReasons = append(terminalReasons, err.Error())
 		}
-
-		errorString := strings.Join(terminalReasons, ";")
-		secret.setTerminalReason(errorString)
-		return errors.New(errorString)
+		return nil, errors.New(strings.Join(terminalReasons, ";"))
 	}
-	return nil
+	return values.data, nil
+}
+
+// secretValues collects the secret values retrieved by concurrent goroutines
+type secretValues struct {
+	data map[string]string
+	lock sync.Mutex
 }
 
-// retrieveASMSecretValue reads secret value from cache first, if not exists, call GetSecretFromASM to retrieve value
-// AWS secrets Manager
-func (secret *ASMSecretResource) retrieveASMSecretValue(apiSecret apicontainer.Secret, iamCredentials credentials.IAMRoleCredentials, wg *sync.WaitGroup, errorEvents chan error) {
+// retrieveASMSecretValue calls GetSecretFromASMWithInput to retrieve the secret value from AWS
+// Secrets Manager, and collects it into values
+func (secret *ASMSecretResource) retrieveASMSecretValue(apiSecret apicontainer.Secret, iamCredentials credentials.IAMRoleCredentials, values *secretValues, wg *sync.WaitGroup, errorEvents chan error) {
 	defer wg.Done()
 
 	asmClient := secret.asmClientCreator.NewASMClient(apiSecret.Region, iamCredentials)
@@ -319,12 +210,11 @@ func (secret *ASMSecretResource) retrieveASMSecretValue(apiSecret apicontainer.S
 		return
 	}
 
-	secret.lock.Lock()
-	defer secret.lock.Unlock()
+	values.lock.Lock()
+	defer values.lock.Unlock()
 
-	// put secret value in secretData
 	secretKey := apiSecret.GetSecretResourceCacheKey()
-	secret.secretData[secretKey] = secretValue
+	values.data[secretKey] = secretValue
 }
 
 func pointerOrNil(in string) *string {
@@ -402,9 +292,12 @@ func (secret *ASMSecretResource) getExecutionCredentialsID() string {
 	return secret.executionCredentialsID
 }
 
-// Cleanup removes the secret value created for the task
+// Cleanup removes the secret value created for the task, and its secret files
 func (secret *ASMSecretResource) Cleanup() error {
 	secret.clearASMSecretValue()
+	if err := secret.RemoveSecretFiles(); err != nil {
+		return fmt.Errorf("ASM secret resource: unable to remove the secret files: %w", err)
+	}
 	return nil
 }
 
@@ -446,6 +339,7 @@ func (secret *ASMSecretResource) Initialize(resourceFields *taskresource.Resourc
 	secret.initStatusToTransition()
 	secret.credentialsManager = resourceFields.CredentialsManager
 	secret.asmClientCreator = resourceFields.ASMClientCreator
+	secret.SetSecretFilesContext(resourceFields.Ctx)
 
 	// if task hasn't turn to 'created' status, and it's desire status is 'running'
 	// the resource status needs to be reset to 'NONE' status so the secret value
@@ -454,15 +348,20 @@ func (secret *ASMSecretResource) Initialize(resourceFields *taskresource.Resourc
 		taskDesiredStatus <= status.TaskRunning {
 		secret.SetKnownStatus(resourcestatus.ResourceStatusNone)
 	}
+
+	// the secret files of a running task are kept in its tmpfs across agent restarts, only their
+	// refresh needs to start again
+	if secret.KnownCreated() && taskDesiredStatus <= status.TaskRunning {
+		secret.StartSecretRotation(secret.taskARN, secret.refreshSecretFiles)
+	}
 }
 
 type ASMSecretResourceJSON struct {
-	TaskARN                string                         `json:"taskARN"`
-	CreatedAt              *time.Time                     `json:"createdAt,omitempty"`
-	DesiredStatus          *ASMSecretStatus               `json:"desiredStatus"`
-	KnownStatus            *ASMSecretStatus               `json:"knownStatus"`
+	TaskARN string `json:"taskARN"`
+	taskresource.ResourceBaseJSON
 	RequiredSecrets        map[string]apicontainer.Secret `json:"secretResources"`
 	ExecutionCredentialsID string                         `json:"executionCredentialsID"`
+	SecretFiles            *secretfiles.Config            `json:"secretFiles,omitempty"`
 }
 
 // MarshalJSON serialises the ASMSecretResource struct to JSON
@@ -470,22 +369,12 @@ func (secret *ASMSecretResource) MarshalJSON() ([]byte, error) {
 	if secret == nil {
 		return nil, errors.New("asmsecret resource is nil")
 	}
-	createdAt := secret.GetCreatedAt()
 	return json.Marshal(ASMSecretResourceJSON{
-		TaskARN:   secret.taskARN,
-		CreatedAt: &createdAt,
-		DesiredStatus: func() *ASMSecretStatus {
-			desiredState := secret.GetDesiredStatus()
-			s := ASMSecretStatus(desiredState)
-			return &s
-		}(),
-		KnownStatus: func() *ASMSecretStatus {
-			knownState := secret.GetKnownStatus()
-			s := ASMSecretStatus(knownState)
-			return &s
-		}(),
+		TaskARN:                secret.taskARN,
+		ResourceBaseJSON:       secret.BaseJSON(),
 		RequiredSecrets:        secret.getRequiredSecrets(),
 		ExecutionCredentialsID: secret.getExecutionCredentialsID(),
+		SecretFiles:            secret.SecretFiles(),
 	})
 }
 
@@ -497,40 +386,14 @@ func (secret *ASMSecretResource) UnmarshalJSON(b []byte) error {
 		return err
 	}
 
-	if temp.DesiredStatus != nil {
-		secret.SetDesiredStatus(resourcestatus.ResourceStatus(*temp.DesiredStatus))
-	}
-	if temp.KnownStatus != nil {
-		secret.SetKnownStatus(resourcestatus.ResourceStatus(*temp.KnownStatus))
-	}
-	if temp.CreatedAt != nil && !temp.CreatedAt.IsZero() {
-		secret.SetCreatedAt(*temp.CreatedAt)
-	}
+	secret.initStatusToTransition()
+	secret.SetBaseJSON(temp.ResourceBaseJSON)
 	if temp.RequiredSecrets != nil {
 		secret.requiredSecrets = temp.RequiredSecrets
 	}
 	secret.taskARN = temp.TaskARN
 	secret.executionCredentialsID = temp.ExecutionCredentialsID
+	secret.RestoreSecretFiles(temp.SecretFiles)
 
 	return nil
 }
-
-// GetAppliedStatus safely returns the currently applied status of the resource
-func (secret *ASMSecretResource) GetAppliedStatus() resourcestatus.ResourceStatus {
-	secret.lock.RLock()
-	defer secret.lock.RUnlock()
-
-	return secret.appliedStatus
-}
-
-func (secret *ASMSecretResource) DependOnTaskNetwork() bool {
-	return false
-}
-
-func (secret *ASMSecretResource) BuildContainerDependency(containerName string, satisfied apicontainerstatus.ContainerStatus,
-	dependent resourcestatus.ResourceStatus) {
-}
-
-func (secret *ASMSecretResource) GetContainerDependencies(dependent resourcestatus.ResourceStatus) []apicontainer.ContainerDependency {
-	return nil
-}
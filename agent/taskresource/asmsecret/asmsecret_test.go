@@ -194,11 +194,11 @@ func TestMarshalUnmarshalJSON(t *testing.T) {
 	asmResIn := &ASMSecretResource{
 		taskARN:                taskARN,
 		executionCredentialsID: executionCredentialsID,
-		createdAt:              time.Now(),
-		knownStatusUnsafe:      resourcestatus.ResourceCreated,
-		desiredStatusUnsafe:    resourcestatus.ResourceCreated,
 		requiredSecrets:        requiredSecretData,
 	}
+	asmResIn.SetCreatedAt(time.Now())
+	asmResIn.SetKnownStatus(resourcestatus.ResourceCreated)
+	asmResIn.SetDesiredStatus(resourcestatus.ResourceCreated)
 
 	bytes, err := json.Marshal(asmResIn)
 	require.NoError(t, err)
@@ -207,9 +207,9 @@ func TestMarshalUnmarshalJSON(t *testing.T) {
 	err = json.Unmarshal(bytes, asmResOut)
 	require.NoError(t, err)
 	assert.Equal(t, asmResIn.taskARN, asmResOut.taskARN)
-	assert.WithinDuration(t, asmResIn.createdAt, asmResOut.createdAt, time.Microsecond)
-	assert.Equal(t, asmResIn.desiredStatusUnsafe, asmResOut.desiredStatusUnsafe)
-	assert.Equal(t, asmResIn.knownStatusUnsafe, asmResOut.knownStatusUnsafe)
+	assert.WithinDuration(t, asmResIn.GetCreatedAt(), asmResOut.GetCreatedAt(), time.Microsecond)
+	assert.Equal(t, asmResIn.GetDesiredStatus(), asmResOut.GetDesiredStatus())
+	assert.Equal(t, asmResIn.GetKnownStatus(), asmResOut.GetKnownStatus())
 	assert.Equal(t, asmResIn.executionCredentialsID, asmResOut.executionCredentialsID)
 	assert.Equal(t, len(asmResIn.requiredSecrets), len(asmResOut.requiredSecrets))
 	assert.Equal(t, asmResIn.requiredSecrets[secretKeyWest1], asmResOut.requiredSecrets[secretKeyWest1])
@@ -221,10 +221,9 @@ func TestInitialize(t *testing.T) {
 
 	credentialsManager := mock_credentials.NewMockManager(ctrl)
 	asmClientCreator := mock_factory.NewMockClientCreator(ctrl)
-	asmRes := &ASMSecretResource{
-		knownStatusUnsafe:   resourcestatus.ResourceCreated,
-		desiredStatusUnsafe: resourcestatus.ResourceCreated,
-	}
+	asmRes := &ASMSecretResource{}
+	asmRes.SetKnownStatus(resourcestatus.ResourceCreated)
+	asmRes.SetDesiredStatus(resourcestatus.ResourceCreated)
 	asmRes.Initialize(&taskresource.ResourceFields{
 		ResourceFieldsCommon: &taskresource.ResourceFieldsCommon{
 			ASMClientCreator:   asmClientCreator,
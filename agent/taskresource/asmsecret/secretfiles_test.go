@@ -0,0 +1,115 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package asmsecret
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	mock_factory "github.com/aws/amazon-ecs-agent/agent/asm/factory/mocks"
+	mock_secretsmanageriface "github.com/aws/amazon-ecs-agent/agent/asm/mocks"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource/secretfiles"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
+	mock_credentials "github.com/aws/amazon-ecs-agent/ecs-agent/credentials/mocks"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSecretFilesResource(t *testing.T, ctrl *gomock.Controller) (*ASMSecretResource,
+	*mock_secretsmanageriface.MockSecretsManagerAPI) {
+	secret := apicontainer.Secret{
+		Name:      secretName1,
+		ValueFrom: valueFrom1,
+		Region:    region1,
+		Provider:  apicontainer.SecretProviderASM,
+	}
+	credentialsManager := mock_credentials.NewMockManager(ctrl)
+	asmClientCreator := mock_factory.NewMockClientCreator(ctrl)
+	mockASMClient := mock_secretsmanageriface.NewMockSecretsManagerAPI(ctrl)
+	credentialsManager.EXPECT().GetTaskCredentials(executionCredentialsID).Return(credentials.TaskIAMRoleCredentials{}, true).AnyTimes()
+	asmClientCreator.EXPECT().NewASMClient(region1, gomock.Any()).Return(mockASMClient).AnyTimes()
+
+	res := NewASMSecretResource(taskARN, map[string]apicontainer.Secret{secretKeyWest1: secret},
+		executionCredentialsID, credentialsManager, asmClientCreator)
+	res.SetSecretFiles(context.Background(), secretfiles.NewConfig(t.TempDir(), []*apicontainer.Container{
+		{Name: "app", Secrets: []apicontainer.Secret{secret}},
+	}, apicontainer.SecretProviderASM, time.Hour))
+	return res, mockASMClient
+}
+
+func TestRefreshSecretFiles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	res, mockASMClient := newTestSecretFilesResource(t, ctrl)
+	res.SetCachedSecretValue(secretKeyWest1, "previous-value")
+	mockASMClient.EXPECT().GetSecretValue(gomock.Any()).Do(func(in *secretsmanager.GetSecretValueInput) {
+		assert.Equal(t, valueFrom1, aws.StringValue(in.SecretId))
+	}).Return(&secretsmanager.GetSecretValueOutput{SecretString: aws.String(secretValue)}, nil)
+
+	require.NoError(t, res.refreshSecretFiles())
+	assert.True(t, res.HasSecretFiles("app"))
+	assert.False(t, res.HasSecretFiles("sidecar"))
+
+	value, ok := res.GetCachedSecretValue(secretKeyWest1)
+	require.True(t, ok)
+	assert.Equal(t, secretValue, value)
+	content, err := os.ReadFile(filepath.Join(res.SecretFiles().Dir, "app", secretName1))
+	require.NoError(t, err)
+	assert.Equal(t, secretValue, string(content))
+
+	require.NoError(t, res.RemoveSecretFiles())
+	_, err = os.Stat(res.SecretFiles().Dir)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRefreshSecretFilesErrorKeepsFiles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	res, mockASMClient := newTestSecretFilesResource(t, ctrl)
+	res.SetCachedSecretValue(secretKeyWest1, "previous-value")
+	require.NoError(t, res.WriteSecretFiles(res.GetCachedSecretValue))
+	mockASMClient.EXPECT().GetSecretValue(gomock.Any()).Return(nil, errors.New("error"))
+
+	assert.Error(t, res.refreshSecretFiles())
+	content, err := os.ReadFile(filepath.Join(res.SecretFiles().Dir, "app", secretName1))
+	require.NoError(t, err)
+	assert.Equal(t, "previous-value", string(content))
+}
+
+func TestMarshalUnmarshalSecretFiles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	res, _ := newTestSecretFilesResource(t, ctrl)
+	bytes, err := json.Marshal(res)
+	require.NoError(t, err)
+
+	unmarshalled := &ASMSecretResource{}
+	require.NoError(t, json.Unmarshal(bytes, unmarshalled))
+	assert.Equal(t, res.SecretFiles(), unmarshalled.SecretFiles())
+}
@@ -0,0 +1,324 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package taskresource
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cihub/seelog"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
+)
+
+// ResourceBase implements the status bookkeeping of the TaskResource interface: the desired, known
+// and applied status, the creation time and the terminal reason of a resource that goes through the
+// NONE -> CREATED -> REMOVED statuses. Resources embed it and implement Create, Cleanup, Initialize
+// and their JSON (de)serialization.
+type ResourceBase struct {
+	name                string
+	createdAt           time.Time
+	desiredStatusUnsafe resourcestatus.ResourceStatus
+	knownStatusUnsafe   resourcestatus.ResourceStatus
+	// appliedStatus is the status that has been "applied" (e.g., we've called some
+	// operation such as 'Create' on the resource) but we don't yet know that the
+	// application was successful, which may then change the known status. This is
+	// used while progressing resource states in progressTask() of task manager
+	appliedStatus                      resourcestatus.ResourceStatus
+	resourceStatusToTransitionFunction map[resourcestatus.ResourceStatus]func() error
+
+	// terminalReason should be set for resource creation failures. This ensures
+	// the resource object carries some context for why provisioning failed.
+	terminalReason     string
+	terminalReasonOnce sync.Once
+
+	// lock is used for fields that are accessed and updated concurrently
+	lock sync.RWMutex
+}
+
+// InitBase sets the name of the resource and the function that creates it. It needs to be called
+// when the resource is constructed and when it's initialized after being restored from the state.
+func (base *ResourceBase) InitBase(name string, create func() error) {
+	base.lock.Lock()
+	defer base.lock.Unlock()
+
+	base.name = name
+	base.resourceStatusToTransitionFunction = map[resourcestatus.ResourceStatus]func() error{
+		resourcestatus.ResourceCreated: create,
+	}
+}
+
+// SetTerminalReason sets the reason the resource failed to provision, only the first reason is kept.
+func (base *ResourceBase) SetTerminalReason(reason string) {
+	base.terminalReasonOnce.Do(func() {
+		seelog.Infof("%s resource: setting terminal reason: %s", base.GetName(), reason)
+		base.lock.Lock()
+		defer base.lock.Unlock()
+		base.terminalReason = reason
+	})
+}
+
+// GetTerminalReason returns an error string to propagate up through to task
+// state change messages
+func (base *ResourceBase) GetTerminalReason() string {
+	base.lock.RLock()
+	defer base.lock.RUnlock()
+
+	return base.terminalReason
+}
+
+// SetDesiredStatus safely sets the desired status of the resource
+func (base *ResourceBase) SetDesiredStatus(status resourcestatus.ResourceStatus) {
+	base.lock.Lock()
+	defer base.lock.Unlock()
+
+	base.desiredStatusUnsafe = status
+}
+
+// GetDesiredStatus safely returns the desired status of the resource
+func (base *ResourceBase) GetDesiredStatus() resourcestatus.ResourceStatus {
+	base.lock.RLock()
+	defer base.lock.RUnlock()
+
+	return base.desiredStatusUnsafe
+}
+
+// GetName safely returns the name of the resource
+func (base *ResourceBase) GetName() string {
+	base.lock.RLock()
+	defer base.lock.RUnlock()
+
+	return base.name
+}
+
+// DesiredTerminal returns true if the resource's desired status is REMOVED
+func (base *ResourceBase) DesiredTerminal() bool {
+	base.lock.RLock()
+	defer base.lock.RUnlock()
+
+	return base.desiredStatusUnsafe == resourcestatus.ResourceRemoved
+}
+
+// KnownCreated returns true if the resource's known status is CREATED
+func (base *ResourceBase) KnownCreated() bool {
+	base.lock.RLock()
+	defer base.lock.RUnlock()
+
+	return base.knownStatusUnsafe == resourcestatus.ResourceCreated
+}
+
+// TerminalStatus returns the last transition state of the resource
+func (base *ResourceBase) TerminalStatus() resourcestatus.ResourceStatus {
+	return resourcestatus.ResourceRemoved
+}
+
+// NextKnownState returns the state that the resource should
+// progress to based on its `KnownState`.
+func (base *ResourceBase) NextKnownState() resourcestatus.ResourceStatus {
+	return base.GetKnownStatus() + 1
+}
+
+// ApplyTransition calls the function required to move to the specified status
+func (base *ResourceBase) ApplyTransition(nextState resourcestatus.ResourceStatus) error {
+	base.lock.RLock()
+	transitionFunc, ok := base.resourceStatusToTransitionFunction[nextState]
+	base.lock.RUnlock()
+	if !ok || transitionFunc == nil {
+		return errors.New("resource [" + base.GetName() + "]: transition to " +
+			base.StatusString(nextState) + " impossible")
+	}
+	return transitionFunc()
+}
+
+// SteadyState returns the transition state of the resource defined as "ready"
+func (base *ResourceBase) SteadyState() resourcestatus.ResourceStatus {
+	return resourcestatus.ResourceCreated
+}
+
+// SetKnownStatus safely sets the currently known status of the resource
+func (base *ResourceBase) SetKnownStatus(status resourcestatus.ResourceStatus) {
+	base.lock.Lock()
+	defer base.lock.Unlock()
+
+	base.knownStatusUnsafe = status
+	base.updateAppliedStatusUnsafe(status)
+}
+
+// updateAppliedStatusUnsafe updates the resource transitioning status
+func (base *ResourceBase) updateAppliedStatusUnsafe(knownStatus resourcestatus.ResourceStatus) {
+	if base.appliedStatus == resourcestatus.ResourceStatusNone {
+		return
+	}
+
+	// Check if the resource transition has already finished
+	if base.appliedStatus <= knownStatus {
+		base.appliedStatus = resourcestatus.ResourceStatusNone
+	}
+}
+
+// SetAppliedStatus sets the applied status of resource and returns whether
+// the resource is already in a transition
+func (base *ResourceBase) SetAppliedStatus(status resourcestatus.ResourceStatus) bool {
+	base.lock.Lock()
+	defer base.lock.Unlock()
+
+	if base.appliedStatus != resourcestatus.ResourceStatusNone {
+		// return false to indicate the set operation failed
+		return false
+	}
+
+	base.appliedStatus = status
+	return true
+}
+
+// GetAppliedStatus safely returns the currently applied status of the resource
+func (base *ResourceBase) GetAppliedStatus() resourcestatus.ResourceStatus {
+	base.lock.RLock()
+	defer base.lock.RUnlock()
+
+	return base.appliedStatus
+}
+
+// GetKnownStatus safely returns the currently known status of the resource
+func (base *ResourceBase) GetKnownStatus() resourcestatus.ResourceStatus {
+	base.lock.RLock()
+	defer base.lock.RUnlock()
+
+	return base.knownStatusUnsafe
+}
+
+// StatusString returns the string of the resource status
+func (base *ResourceBase) StatusString(status resourcestatus.ResourceStatus) string {
+	return BaseStatus(status).String()
+}
+
+// SetCreatedAt sets the timestamp for resource's creation time
+func (base *ResourceBase) SetCreatedAt(createdAt time.Time) {
+	if createdAt.IsZero() {
+		return
+	}
+	base.lock.Lock()
+	defer base.lock.Unlock()
+
+	base.createdAt = createdAt
+}
+
+// GetCreatedAt returns the timestamp for resource's creation time
+func (base *ResourceBase) GetCreatedAt() time.Time {
+	base.lock.RLock()
+	defer base.lock.RUnlock()
+
+	return base.createdAt
+}
+
+// DependOnTaskNetwork returns false, resources built on ResourceBase are created before the task network.
+func (base *ResourceBase) DependOnTaskNetwork() bool {
+	return false
+}
+
+// BuildContainerDependency is a no-op, resources built on ResourceBase don't depend on containers.
+func (base *ResourceBase) BuildContainerDependency(containerName string, satisfied apicontainerstatus.ContainerStatus,
+	dependent resourcestatus.ResourceStatus) {
+}
+
+// GetContainerDependencies returns nil, resources built on ResourceBase don't depend on containers.
+func (base *ResourceBase) GetContainerDependencies(dependent resourcestatus.ResourceStatus) []apicontainer.ContainerDependency {
+	return nil
+}
+
+// ResourceBaseJSON is the serialized form of the ResourceBase fields, resources embed it in their
+// own JSON struct.
+type ResourceBaseJSON struct {
+	CreatedAt     *time.Time  `json:"createdAt,omitempty"`
+	DesiredStatus *BaseStatus `json:"desiredStatus"`
+	KnownStatus   *BaseStatus `json:"knownStatus"`
+}
+
+// BaseJSON returns the serialized form of the ResourceBase fields.
+func (base *ResourceBase) BaseJSON() ResourceBaseJSON {
+	createdAt := base.GetCreatedAt()
+	desiredStatus := BaseStatus(base.GetDesiredStatus())
+	knownStatus := BaseStatus(base.GetKnownStatus())
+	return ResourceBaseJSON{
+		CreatedAt:     &createdAt,
+		DesiredStatus: &desiredStatus,
+		KnownStatus:   &knownStatus,
+	}
+}
+
+// SetBaseJSON restores the ResourceBase fields from their serialized form.
+func (base *ResourceBase) SetBaseJSON(temp ResourceBaseJSON) {
+	if temp.DesiredStatus != nil {
+		base.SetDesiredStatus(resourcestatus.ResourceStatus(*temp.DesiredStatus))
+	}
+	if temp.KnownStatus != nil {
+		base.SetKnownStatus(resourcestatus.ResourceStatus(*temp.KnownStatus))
+	}
+	if temp.CreatedAt != nil && !temp.CreatedAt.IsZero() {
+		base.SetCreatedAt(*temp.CreatedAt)
+	}
+}
+
+// BaseStatus is the status of a resource built on ResourceBase, serialized as its name.
+type BaseStatus resourcestatus.ResourceStatus
+
+var baseStatusMap = map[string]BaseStatus{
+	"NONE":    BaseStatus(resourcestatus.ResourceStatusNone),
+	"CREATED": BaseStatus(resourcestatus.ResourceCreated),
+	"REMOVED": BaseStatus(resourcestatus.ResourceRemoved),
+}
+
+// String returns a human readable string representation of this object
+func (bs BaseStatus) String() string {
+	for k, v := range baseStatusMap {
+		if v == bs {
+			return k
+		}
+	}
+	return "NONE"
+}
+
+// MarshalJSON overrides the logic for JSON-encoding the ResourceStatus type
+func (bs *BaseStatus) MarshalJSON() ([]byte, error) {
+	if bs == nil {
+		return nil, errors.New("resource status is nil")
+	}
+	return json.Marshal(bs.String())
+}
+
+// UnmarshalJSON overrides the logic for parsing the JSON-encoded ResourceStatus data
+func (bs *BaseStatus) UnmarshalJSON(b []byte) error {
+	if strings.ToLower(string(b)) == "null" {
+		*bs = BaseStatus(resourcestatus.ResourceStatusNone)
+		return nil
+	}
+
+	var strStatus string
+	if err := json.Unmarshal(b, &strStatus); err != nil {
+		*bs = BaseStatus(resourcestatus.ResourceStatusNone)
+		return errors.New("resource status unmarshal: status must be a string or null; Got " + string(b))
+	}
+	stat, ok := baseStatusMap[strStatus]
+	if !ok {
+		*bs = BaseStatus(resourcestatus.ResourceStatusNone)
+		return errors.New("resource status unmarshal: unrecognized status")
+	}
+	*bs = stat
+	return nil
+}
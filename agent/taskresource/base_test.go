@@ -0,0 +1,82 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package taskresource
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceBaseTransitions(t *testing.T) {
+	created := 0
+	base := &ResourceBase{}
+	base.InitBase("test", func() error {
+		created++
+		return nil
+	})
+	assert.Equal(t, "test", base.GetName())
+	assert.Equal(t, resourcestatus.ResourceCreated, base.NextKnownState())
+
+	require.NoError(t, base.ApplyTransition(resourcestatus.ResourceCreated))
+	assert.Equal(t, 1, created)
+	assert.Error(t, base.ApplyTransition(resourcestatus.ResourceRemoved))
+
+	assert.True(t, base.SetAppliedStatus(resourcestatus.ResourceCreated))
+	assert.False(t, base.SetAppliedStatus(resourcestatus.ResourceCreated), "the resource is already in a transition")
+	base.SetKnownStatus(resourcestatus.ResourceCreated)
+	assert.True(t, base.KnownCreated())
+	assert.Equal(t, resourcestatus.ResourceStatusNone, base.GetAppliedStatus(), "the transition finished")
+
+	base.SetDesiredStatus(resourcestatus.ResourceRemoved)
+	assert.True(t, base.DesiredTerminal())
+	assert.Equal(t, "REMOVED", base.StatusString(base.TerminalStatus()))
+}
+
+func TestResourceBaseTerminalReason(t *testing.T) {
+	base := &ResourceBase{}
+	base.InitBase("test", func() error { return errors.New("error") })
+	base.SetTerminalReason("first")
+	base.SetTerminalReason("second")
+	assert.Equal(t, "first", base.GetTerminalReason())
+}
+
+func TestResourceBaseJSON(t *testing.T) {
+	base := &ResourceBase{}
+	base.SetCreatedAt(time.Now())
+	base.SetDesiredStatus(resourcestatus.ResourceCreated)
+	base.SetKnownStatus(resourcestatus.ResourceCreated)
+
+	data, err := json.Marshal(base.BaseJSON())
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"knownStatus":"CREATED"`)
+
+	var temp ResourceBaseJSON
+	require.NoError(t, json.Unmarshal(data, &temp))
+	restored := &ResourceBase{}
+	restored.SetBaseJSON(temp)
+	assert.WithinDuration(t, base.GetCreatedAt(), restored.GetCreatedAt(), time.Microsecond)
+	assert.Equal(t, resourcestatus.ResourceCreated, restored.GetDesiredStatus())
+	assert.Equal(t, resourcestatus.ResourceCreated, restored.GetKnownStatus())
+
+	assert.Error(t, json.Unmarshal([]byte(`{"knownStatus":"RUNNING"}`), &temp))
+}
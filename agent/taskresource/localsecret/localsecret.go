@@ -0,0 +1,194 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package localsecret
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+
+	"github.com/cihub/seelog"
+	"github.com/pkg/errors"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	localsecretclient "github.com/aws/amazon-ecs-agent/agent/localsecret"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource"
+	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
+	resourcetypes "github.com/aws/amazon-ecs-agent/agent/taskresource/types"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
+)
+
+const (
+	// ResourceName is the name of the localsecret resource
+	ResourceName = "localsecret"
+)
+
+func init() {
+	// the local secret resources are unmarshalled from the saved task state through the resource
+	// type registry, like the resources of the plugins
+	if err := resourcetypes.RegisterResourceType(ResourceName, func() taskresource.TaskResource {
+		return &LocalSecretResource{}
+	}); err != nil {
+		panic(err)
+	}
+}
+
+// LocalSecretResource represents secrets as a task resource.
+// The secrets are stored on the instance, in an encrypted file or behind a local secrets endpoint.
+type LocalSecretResource struct {
+	taskresource.ResourceBase
+
+	taskARN string
+
+	// map to store all local deduped secrets in the task, key is a combination of valueFrom and region
+	requiredSecrets map[string]apicontainer.Secret
+	// map to store secret values, key is a combination of valueFrom and region
+	secretData map[string]string
+
+	// client retrieves the local secrets
+	client localsecretclient.Client
+
+	// lock is used for fields that are accessed and updated concurrently
+	lock sync.RWMutex
+}
+
+// NewLocalSecretResource creates a new LocalSecretResource object
+func NewLocalSecretResource(taskARN string,
+	localSecrets map[string]apicontainer.Secret,
+	client localsecretclient.Client) *LocalSecretResource {
+
+	s := &LocalSecretResource{
+		taskARN:         taskARN,
+		requiredSecrets: localSecrets,
+		client:          client,
+	}
+
+	s.initStatusToTransition()
+	return s
+}
+
+func (secret *LocalSecretResource) initStatusToTransition() {
+	secret.InitBase(ResourceName, secret.Create)
+}
+
+// Create retrieves the secret values from the local secrets of the instance.
+func (secret *LocalSecretResource) Create() error {
+	if secret.client == nil {
+		err := errors.New("local secret resource: local secrets are not configured")
+		secret.SetTerminalReason(err.Error())
+		return err
+	}
+
+	seelog.Infof("local secret resource: retrieving secrets for containers in task: [%s]", secret.taskARN)
+	secretData := make(map[string]string)
+	var terminalReasons []string
+	for secretKey, localSecret := range secret.getRequiredSecrets() {
+		value, err := secret.client.GetSecret(localSecret.ValueFrom)
+		if err != nil {
+			terminalReasons = append(terminalReasons, err.Error())
+			continue
+		}
+		secretData[secretKey] = value
+	}
+
+	if len(terminalReasons) > 0 {
+		errorString := strings.Join(terminalReasons, ";")
+		secret.SetTerminalReason(errorString)
+		return errors.New(errorString)
+	}
+
+	secret.lock.Lock()
+	secret.secretData = secretData
+	secret.lock.Unlock()
+	return nil
+}
+
+// getRequiredSecrets returns the requiredSecrets field of localsecret task resource
+func (secret *LocalSecretResource) getRequiredSecrets() map[string]apicontainer.Secret {
+	secret.lock.RLock()
+	defer secret.lock.RUnlock()
+
+	return secret.requiredSecrets
+}
+
+// Cleanup removes the secret value created for the task
+func (secret *LocalSecretResource) Cleanup() error {
+	secret.lock.Lock()
+	defer secret.lock.Unlock()
+
+	for key := range secret.secretData {
+		delete(secret.secretData, key)
+	}
+	return nil
+}
+
+// GetCachedSecretValue retrieves the secret value from secretData field
+func (secret *LocalSecretResource) GetCachedSecretValue(secretKey string) (string, bool) {
+	secret.lock.RLock()
+	defer secret.lock.RUnlock()
+
+	s, ok := secret.secretData[secretKey]
+	return s, ok
+}
+
+func (secret *LocalSecretResource) Initialize(resourceFields *taskresource.ResourceFields,
+	taskKnownStatus status.TaskStatus,
+	taskDesiredStatus status.TaskStatus) {
+	secret.initStatusToTransition()
+	secret.client = resourceFields.LocalSecretClient
+
+	// if task hasn't turn to 'created' status, and it's desire status is 'running'
+	// the resource status needs to be reset to 'NONE' status so the secret value
+	// will be retrieved again
+	if taskKnownStatus < status.TaskCreated &&
+		taskDesiredStatus <= status.TaskRunning {
+		secret.SetKnownStatus(resourcestatus.ResourceStatusNone)
+	}
+}
+
+type LocalSecretResourceJSON struct {
+	TaskARN string `json:"taskARN"`
+	taskresource.ResourceBaseJSON
+	RequiredSecrets map[string]apicontainer.Secret `json:"secretResources"`
+}
+
+// MarshalJSON serialises the LocalSecretResource struct to JSON
+func (secret *LocalSecretResource) MarshalJSON() ([]byte, error) {
+	if secret == nil {
+		return nil, errors.New("localsecret resource is nil")
+	}
+	return json.Marshal(LocalSecretResourceJSON{
+		TaskARN:          secret.taskARN,
+		ResourceBaseJSON: secret.BaseJSON(),
+		RequiredSecrets:  secret.getRequiredSecrets(),
+	})
+}
+
+// UnmarshalJSON deserialises the raw JSON to a LocalSecretResource struct
+func (secret *LocalSecretResource) UnmarshalJSON(b []byte) error {
+	temp := LocalSecretResourceJSON{}
+
+	if err := json.Unmarshal(b, &temp); err != nil {
+		return err
+	}
+
+	secret.initStatusToTransition()
+	secret.SetBaseJSON(temp.ResourceBaseJSON)
+	if temp.RequiredSecrets != nil {
+		secret.requiredSecrets = temp.RequiredSecrets
+	}
+	secret.taskARN = temp.TaskARN
+
+	return nil
+}
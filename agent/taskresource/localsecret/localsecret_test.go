@@ -0,0 +1,145 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package localsecret
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	mock_localsecret "github.com/aws/amazon-ecs-agent/agent/localsecret/mocks"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource"
+	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
+	resourcetypes "github.com/aws/amazon-ecs-agent/agent/taskresource/types"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	taskARN       = "task1"
+	valueFromFile = "local-file://db/password"
+	valueFromHTTP = "local-http://secret/api#key"
+)
+
+var (
+	fileSecret = apicontainer.Secret{Name: "DB_PASSWORD", ValueFrom: valueFromFile, Provider: "ssm"}
+	httpSecret = apicontainer.Secret{Name: "API_KEY", ValueFrom: valueFromHTTP, Provider: "asm"}
+)
+
+func requiredTestSecrets() map[string]apicontainer.Secret {
+	return map[string]apicontainer.Secret{
+		fileSecret.GetSecretResourceCacheKey(): fileSecret,
+		httpSecret.GetSecretResourceCacheKey(): httpSecret,
+	}
+}
+
+func TestCreate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_localsecret.NewMockClient(ctrl)
+	client.EXPECT().GetSecret(valueFromFile).Return("hunter2", nil)
+	client.EXPECT().GetSecret(valueFromHTTP).Return("api-key", nil)
+
+	res := NewLocalSecretResource(taskARN, requiredTestSecrets(), client)
+	require.NoError(t, res.Create())
+
+	value, ok := res.GetCachedSecretValue(fileSecret.GetSecretResourceCacheKey())
+	require.True(t, ok)
+	assert.Equal(t, "hunter2", value)
+	value, ok = res.GetCachedSecretValue(httpSecret.GetSecretResourceCacheKey())
+	require.True(t, ok)
+	assert.Equal(t, "api-key", value)
+
+	require.NoError(t, res.Cleanup())
+	_, ok = res.GetCachedSecretValue(fileSecret.GetSecretResourceCacheKey())
+	assert.False(t, ok)
+}
+
+func TestCreateReturnError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_localsecret.NewMockClient(ctrl)
+	client.EXPECT().GetSecret(valueFromFile).Return("hunter2", nil)
+	client.EXPECT().GetSecret(valueFromHTTP).Return("", errors.New("key not found"))
+
+	res := NewLocalSecretResource(taskARN, requiredTestSecrets(), client)
+	assert.Error(t, res.Create())
+	assert.Equal(t, "key not found", res.GetTerminalReason())
+	_, ok := res.GetCachedSecretValue(fileSecret.GetSecretResourceCacheKey())
+	assert.False(t, ok)
+}
+
+func TestCreateWithoutClient(t *testing.T) {
+	res := NewLocalSecretResource(taskARN, requiredTestSecrets(), nil)
+	assert.Error(t, res.Create())
+	assert.NotEmpty(t, res.GetTerminalReason())
+}
+
+func TestMarshalUnmarshalJSON(t *testing.T) {
+	res := NewLocalSecretResource(taskARN, requiredTestSecrets(), nil)
+	res.SetDesiredStatus(resourcestatus.ResourceCreated)
+	res.SetKnownStatus(resourcestatus.ResourceStatusNone)
+
+	bytes, err := json.Marshal(res)
+	require.NoError(t, err)
+
+	unmarshalled := &LocalSecretResource{}
+	require.NoError(t, json.Unmarshal(bytes, unmarshalled))
+	assert.Equal(t, taskARN, unmarshalled.taskARN)
+	assert.Equal(t, requiredTestSecrets(), unmarshalled.getRequiredSecrets())
+	assert.Equal(t, resourcestatus.ResourceCreated, unmarshalled.GetDesiredStatus())
+	assert.Equal(t, resourcestatus.ResourceStatusNone, unmarshalled.GetKnownStatus())
+	assert.Equal(t, ResourceName, unmarshalled.GetName())
+}
+
+func TestInitialize(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock_localsecret.NewMockClient(ctrl)
+	res := &LocalSecretResource{}
+	res.SetKnownStatus(resourcestatus.ResourceCreated)
+	res.Initialize(&taskresource.ResourceFields{
+		ResourceFieldsCommon: &taskresource.ResourceFieldsCommon{
+			LocalSecretClient: client,
+		},
+	}, apitaskstatus.TaskStatusNone, apitaskstatus.TaskRunning)
+
+	assert.Equal(t, client, res.client)
+	assert.Equal(t, resourcestatus.ResourceStatusNone, res.GetKnownStatus(),
+		"the secrets are retrieved again for a task that isn't created yet")
+}
+
+func TestMarshalUnmarshalResourcesMap(t *testing.T) {
+	res := NewLocalSecretResource(taskARN, nil, nil)
+	res.SetDesiredStatus(resourcestatus.ResourceCreated)
+	res.SetKnownStatus(resourcestatus.ResourceStatusNone)
+	data, err := json.Marshal(map[string][]taskresource.TaskResource{ResourceName: {res}})
+	require.NoError(t, err)
+
+	var unmarshalled resourcetypes.ResourcesMap
+	require.NoError(t, json.Unmarshal(data, &unmarshalled))
+	require.Len(t, unmarshalled[ResourceName], 1)
+	assert.IsType(t, &LocalSecretResource{}, unmarshalled[ResourceName][0])
+	assert.Equal(t, resourcestatus.ResourceCreated, unmarshalled[ResourceName][0].GetDesiredStatus())
+	assert.Equal(t, resourcestatus.ResourceStatusNone, unmarshalled[ResourceName][0].GetKnownStatus())
+}
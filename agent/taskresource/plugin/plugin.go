@@ -0,0 +1,209 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package plugin lets task resource types be added with a Create and Cleanup pair, without changing
+// how tasks are set up and how their resources are saved and restored.
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource"
+	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
+	resourcetypes "github.com/aws/amazon-ecs-agent/agent/taskresource/types"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
+)
+
+// Plugin defines a task resource type.
+type Plugin struct {
+	// Name is the name of the resource type, the key of its resources in the task resources map.
+	Name string
+	// Requires returns whether the container requires the resource. A resource is added to the
+	// tasks that have such containers, and these containers are created once the resource is created.
+	Requires func(container *apicontainer.Container) bool
+	// Create creates the resource of a task.
+	Create func(resource *Resource) error
+	// Cleanup cleans up the resource of a task once the task stopped.
+	Cleanup func(resource *Resource) error
+}
+
+var (
+	pluginsLock sync.RWMutex
+	plugins     = make(map[string]Plugin)
+)
+
+// Register registers a resource type. It's meant to be called before the agent starts, from an
+// init function.
+func Register(plugin Plugin) error {
+	if plugin.Requires == nil || plugin.Create == nil || plugin.Cleanup == nil {
+		return fmt.Errorf("resource plugin %s requires the Requires, Create and Cleanup functions", plugin.Name)
+	}
+	if err := resourcetypes.RegisterResourceType(plugin.Name, func() taskresource.TaskResource {
+		return NewResource(plugin, "", nil)
+	}); err != nil {
+		return err
+	}
+
+	pluginsLock.Lock()
+	defer pluginsLock.Unlock()
+	plugins[plugin.Name] = plugin
+	return nil
+}
+
+// Plugins returns the registered resource types, sorted by name.
+func Plugins() []Plugin {
+	pluginsLock.RLock()
+	defer pluginsLock.RUnlock()
+
+	registered := make([]Plugin, 0, len(plugins))
+	for _, plugin := range plugins {
+		registered = append(registered, plugin)
+	}
+	sort.Slice(registered, func(i, j int) bool {
+		return registered[i].Name < registered[j].Name
+	})
+	return registered
+}
+
+// Resource is a task resource of a registered resource type.
+type Resource struct {
+	taskresource.ResourceBase
+
+	plugin         Plugin
+	taskARN        string
+	resourceFields *taskresource.ResourceFields
+	// data is the state of the resource that the plugin keeps across agent restarts
+	data map[string]string
+
+	// lock is used for fields that are accessed and updated concurrently
+	lock sync.RWMutex
+}
+
+// NewResource creates a resource of the registered resource type for a task.
+func NewResource(plugin Plugin, taskARN string, resourceFields *taskresource.ResourceFields) *Resource {
+	r := &Resource{
+		plugin:         plugin,
+		taskARN:        taskARN,
+		resourceFields: resourceFields,
+		data:           make(map[string]string),
+	}
+	r.InitBase(plugin.Name, r.Create)
+	return r
+}
+
+// GetTaskARN returns the ARN of the task of the resource.
+func (r *Resource) GetTaskARN() string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return r.taskARN
+}
+
+// GetResourceFields returns the fields of the task engine the resource is created with.
+func (r *Resource) GetResourceFields() *taskresource.ResourceFields {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return r.resourceFields
+}
+
+// GetData returns a value the plugin saved with the resource.
+func (r *Resource) GetData(key string) (string, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	value, ok := r.data[key]
+	return value, ok
+}
+
+// SetData saves a value with the resource, it's restored along with the resource when the agent restarts.
+func (r *Resource) SetData(key, value string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.data[key] = value
+}
+
+// Create creates the resource with the Create function of its plugin.
+func (r *Resource) Create() error {
+	if err := r.plugin.Create(r); err != nil {
+		r.SetTerminalReason(err.Error())
+		return err
+	}
+	return nil
+}
+
+// Cleanup cleans up the resource with the Cleanup function of its plugin.
+func (r *Resource) Cleanup() error {
+	return r.plugin.Cleanup(r)
+}
+
+// Initialize initializes the resource restored from the saved state.
+func (r *Resource) Initialize(resourceFields *taskresource.ResourceFields,
+	taskKnownStatus status.TaskStatus,
+	taskDesiredStatus status.TaskStatus) {
+	r.lock.Lock()
+	r.resourceFields = resourceFields
+	r.lock.Unlock()
+	r.InitBase(r.plugin.Name, r.Create)
+
+	// if the task hasn't been created, the resource is created again
+	if taskKnownStatus < status.TaskCreated && taskDesiredStatus <= status.TaskRunning {
+		r.SetKnownStatus(resourcestatus.ResourceStatusNone)
+	}
+}
+
+// ResourceJSON is the serialized form of a plugin resource.
+type ResourceJSON struct {
+	TaskARN string `json:"taskARN"`
+	taskresource.ResourceBaseJSON
+	Data map[string]string `json:"data,omitempty"`
+}
+
+// MarshalJSON serialises the Resource struct to JSON
+func (r *Resource) MarshalJSON() ([]byte, error) {
+	if r == nil {
+		return nil, errors.New("plugin resource is nil")
+	}
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return json.Marshal(ResourceJSON{
+		TaskARN:          r.taskARN,
+		ResourceBaseJSON: r.BaseJSON(),
+		Data:             r.data,
+	})
+}
+
+// UnmarshalJSON deserialises the raw JSON to a Resource struct
+func (r *Resource) UnmarshalJSON(b []byte) error {
+	temp := ResourceJSON{}
+	if err := json.Unmarshal(b, &temp); err != nil {
+		return err
+	}
+
+	r.SetBaseJSON(temp.ResourceBaseJSON)
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.taskARN = temp.TaskARN
+	r.data = make(map[string]string, len(temp.Data))
+	for key, value := range temp.Data {
+		r.data[key] = value
+	}
+	return nil
+}
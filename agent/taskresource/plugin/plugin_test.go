@@ -0,0 +1,113 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource"
+	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
+	resourcetypes "github.com/aws/amazon-ecs-agent/agent/taskresource/types"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPlugin(name string, createErr error) Plugin {
+	return Plugin{
+		Name: name,
+		Requires: func(container *apicontainer.Container) bool {
+			return container.Name == "app"
+		},
+		Create: func(resource *Resource) error {
+			if createErr != nil {
+				return createErr
+			}
+			resource.SetData("bundlePath", "/var/lib/ecs/bundles/"+resource.GetTaskARN())
+			return nil
+		},
+		Cleanup: func(resource *Resource) error {
+			resource.SetData("cleanedUp", "true")
+			return nil
+		},
+	}
+}
+
+func TestRegister(t *testing.T) {
+	require.NoError(t, Register(newTestPlugin("plugin-register", nil)))
+	assert.Error(t, Register(newTestPlugin("plugin-register", nil)))
+	assert.Error(t, Register(Plugin{Name: "plugin-incomplete"}))
+
+	var names []string
+	for _, plugin := range Plugins() {
+		names = append(names, plugin.Name)
+	}
+	assert.Contains(t, names, "plugin-register")
+	assert.NotContains(t, names, "plugin-incomplete")
+}
+
+func TestResourceLifecycle(t *testing.T) {
+	resource := NewResource(newTestPlugin("plugin-lifecycle", nil), "taskARN", nil)
+	assert.Equal(t, "plugin-lifecycle", resource.GetName())
+
+	require.NoError(t, resource.ApplyTransition(resourcestatus.ResourceCreated))
+	bundlePath, ok := resource.GetData("bundlePath")
+	assert.True(t, ok)
+	assert.Equal(t, "/var/lib/ecs/bundles/taskARN", bundlePath)
+
+	require.NoError(t, resource.Cleanup())
+	cleanedUp, _ := resource.GetData("cleanedUp")
+	assert.Equal(t, "true", cleanedUp)
+}
+
+func TestResourceCreateError(t *testing.T) {
+	resource := NewResource(newTestPlugin("plugin-error", errors.New("unable to fetch bundle")), "taskARN", nil)
+	assert.Error(t, resource.Create())
+	assert.Equal(t, "unable to fetch bundle", resource.GetTerminalReason())
+}
+
+func TestResourceRestore(t *testing.T) {
+	plugin := newTestPlugin("plugin-restore", nil)
+	require.NoError(t, Register(plugin))
+
+	resource := NewResource(plugin, "taskARN", nil)
+	require.NoError(t, resource.Create())
+	resource.SetDesiredStatus(resourcestatus.ResourceCreated)
+	resource.SetKnownStatus(resourcestatus.ResourceCreated)
+
+	data, err := json.Marshal(map[string][]taskresource.TaskResource{plugin.Name: {resource}})
+	require.NoError(t, err)
+	var resources resourcetypes.ResourcesMap
+	require.NoError(t, json.Unmarshal(data, &resources))
+	require.Len(t, resources[plugin.Name], 1)
+
+	restored, ok := resources[plugin.Name][0].(*Resource)
+	require.True(t, ok)
+	restored.Initialize(&taskresource.ResourceFields{}, status.TaskRunning, status.TaskRunning)
+	assert.Equal(t, "taskARN", restored.GetTaskARN())
+	assert.Equal(t, resourcestatus.ResourceCreated, restored.GetKnownStatus())
+	bundlePath, _ := restored.GetData("bundlePath")
+	assert.Equal(t, "/var/lib/ecs/bundles/taskARN", bundlePath)
+	require.NoError(t, restored.Cleanup(), "the restored resource is cleaned up by its plugin")
+
+	// the resource of a task that wasn't created yet is created again
+	restored.Initialize(&taskresource.ResourceFields{}, status.TaskStatusNone, status.TaskRunning)
+	assert.Equal(t, resourcestatus.ResourceStatusNone, restored.GetKnownStatus())
+}
@@ -0,0 +1,267 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package secretfiles writes the secrets of a task as files to a tmpfs of the task, that is mounted in
+// its containers, and refreshes them periodically so that the containers can pick up rotated secrets.
+package secretfiles
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+)
+
+const (
+	// ContainerPath is the directory the secret files of a container are mounted at in the container.
+	ContainerPath = "/run/secrets"
+
+	// secretsDirName is the directory of the secret files of the tasks in the agent data directory.
+	secretsDirName = "secrets"
+	// the secret files are readable by any user of the container, the secrets directory of the
+	// tasks on the host is only accessible to root
+	secretsDirMode  = 0700
+	secretFileMode  = 0444
+	secretFilesMode = 0755
+)
+
+// TaskDir returns the directory the secret files of a task are written to, in the agent data directory.
+func TaskDir(dataDir, taskID string) string {
+	return filepath.Join(dataDir, secretsDirName, taskID)
+}
+
+// ContainerBind returns the bind mount of the secret files of a container. The agent data directory
+// is mounted from the data directory on the host.
+func ContainerBind(dataDirOnHost, taskID, containerName string) string {
+	return fmt.Sprintf("%s:%s:ro", filepath.Join(dataDirOnHost, "data", secretsDirName, taskID, containerName),
+		ContainerPath)
+}
+
+// FilePath returns the path of the file of a secret of a container, relative to the task directory.
+func FilePath(containerName, secretName string) string {
+	return filepath.Join(containerName, secretName)
+}
+
+// Config is the secret files configuration of a secret resource, it's saved with the resource.
+type Config struct {
+	// Dir is the directory the secret files of the task are written to.
+	Dir string `json:"dir"`
+	// Files maps the path of the secret files, relative to Dir, to their secret.
+	Files map[string]apicontainer.Secret `json:"files"`
+	// RotationInterval is how often the secret files are refreshed.
+	RotationInterval time.Duration `json:"rotationInterval"`
+}
+
+// NewConfig returns the secret files configuration of the secrets of a provider of the containers
+// of a task, or nil if none of the containers has such a secret. The secrets of the log driver
+// aren't written to files.
+func NewConfig(dir string, containers []*apicontainer.Container, provider string,
+	rotationInterval time.Duration) *Config {
+	files := make(map[string]apicontainer.Secret)
+	for _, container := range containers {
+		for _, secret := range container.Secrets {
+			if secret.GetProvider() == provider && secret.Target != apicontainer.SecretTargetLogDriver {
+				files[FilePath(container.Name, secret.Name)] = secret
+			}
+		}
+	}
+	if len(files) == 0 {
+		return nil
+	}
+	return &Config{
+		Dir:              dir,
+		Files:            files,
+		RotationInterval: rotationInterval,
+	}
+}
+
+// HasContainer returns whether the container has secret files.
+func (cfg *Config) HasContainer(containerName string) bool {
+	if cfg == nil {
+		return false
+	}
+	for path := range cfg.Files {
+		if filepath.Dir(path) == containerName {
+			return true
+		}
+	}
+	return false
+}
+
+// Contents returns the contents of the secret files, values looks up the value of a secret by its
+// secret resource cache key.
+func (cfg *Config) Contents(values func(secretKey string) (string, bool)) (map[string]string, error) {
+	contents := make(map[string]string, len(cfg.Files))
+	for path, secret := range cfg.Files {
+		value, ok := values(secret.GetSecretResourceCacheKey())
+		if !ok {
+			return nil, fmt.Errorf("no value for secret %s of secret file %s", secret.ValueFrom, path)
+		}
+		contents[path] = value
+	}
+	return contents, nil
+}
+
+// Setup creates the secrets directory of a task and mounts a tmpfs on it, so that the secrets are
+// never written to disk. The tmpfs of a task that was set up before the agent restarted is kept.
+func Setup(dir string) error {
+	if err := os.MkdirAll(filepath.Dir(dir), secretsDirMode); err != nil {
+		return fmt.Errorf("unable to create the secrets directory: %w", err)
+	}
+	if err := os.MkdirAll(dir, secretFilesMode); err != nil {
+		return fmt.Errorf("unable to create the secrets directory of the task: %w", err)
+	}
+	mounted, err := isMountPoint(dir)
+	if err != nil {
+		return err
+	}
+	if mounted {
+		return nil
+	}
+	return mountTmpfs(dir)
+}
+
+// Write writes the secret files of a task, keyed by their path relative to the task directory. Each
+// file is written to a temporary file that's renamed over the previous one, so that the containers
+// either read the previous or the new secret, never a partially written one.
+func Write(dir string, files map[string]string) error {
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if err := writeFile(dir, path, files[path]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeFile(dir, path, value string) error {
+	target := filepath.Join(dir, path)
+	if rel, err := filepath.Rel(dir, target); err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return fmt.Errorf("invalid secret file path %q", path)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), secretFilesMode); err != nil {
+		return fmt.Errorf("unable to create the directory of secret file %s: %w", path, err)
+	}
+	if current, err := os.ReadFile(target); err == nil && string(current) == value {
+		return nil
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), "."+filepath.Base(target)+".*")
+	if err != nil {
+		return fmt.Errorf("unable to create secret file %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(value); err != nil {
+		tmp.Close()
+		return fmt.Errorf("unable to write secret file %s: %w", path, err)
+	}
+	if err := tmp.Chmod(secretFileMode); err != nil {
+		tmp.Close()
+		return fmt.Errorf("unable to set the mode of secret file %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("unable to write secret file %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return fmt.Errorf("unable to replace secret file %s: %w", path, err)
+	}
+	return nil
+}
+
+// Remove unmounts the tmpfs of a task and removes its secrets directory.
+func Remove(dir string) error {
+	mounted, err := isMountPoint(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if mounted {
+		if err := unmountTmpfs(dir); err != nil {
+			return err
+		}
+	}
+	return os.RemoveAll(dir)
+}
+
+// Rotator refreshes the secret files of a task at a fixed interval, until it's stopped.
+type Rotator struct {
+	taskARN  string
+	interval time.Duration
+	refresh  func() error
+	cancel   context.CancelFunc
+	lock     sync.Mutex
+}
+
+// NewRotator creates a Rotator that calls refresh every interval. refresh fetches the secrets again
+// and writes their files.
+func NewRotator(taskARN string, interval time.Duration, refresh func() error) *Rotator {
+	return &Rotator{
+		taskARN:  taskARN,
+		interval: interval,
+		refresh:  refresh,
+	}
+}
+
+// Start starts refreshing the secret files, it's a no-op if the Rotator is already started.
+func (r *Rotator) Start(ctx context.Context) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.cancel != nil {
+		return
+	}
+	ctx, r.cancel = context.WithCancel(ctx)
+	go r.run(ctx)
+}
+
+// Stop stops refreshing the secret files.
+func (r *Rotator) Stop() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.cancel != nil {
+		r.cancel()
+		r.cancel = nil
+	}
+}
+
+func (r *Rotator) run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// a failed refresh keeps the previous secret files, it's tried again at the next interval
+			if err := r.refresh(); err != nil {
+				logger.Warn("Unable to refresh the secret files of the task", logger.Fields{
+					field.TaskARN: r.taskARN,
+					field.Error:   err,
+				})
+			}
+		}
+	}
+}
@@ -0,0 +1,182 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package secretfiles
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskDirAndContainerBind(t *testing.T) {
+	assert.Equal(t, "/data/secrets/task-id", TaskDir("/data", "task-id"))
+	assert.Equal(t, "/var/lib/ecs/data/secrets/task-id/app:/run/secrets:ro",
+		ContainerBind("/var/lib/ecs", "task-id", "app"))
+}
+
+func TestNewConfig(t *testing.T) {
+	dbPassword := apicontainer.Secret{Name: "db-password", ValueFrom: "db", Region: "us-west-2", Provider: "ssm"}
+	containers := []*apicontainer.Container{
+		{
+			Name: "app",
+			Secrets: []apicontainer.Secret{
+				dbPassword,
+				{Name: "api-key", ValueFrom: "api", Region: "us-west-2", Provider: "asm"},
+			},
+		},
+		{
+			Name: "sidecar",
+			Secrets: []apicontainer.Secret{
+				{Name: "log-token", ValueFrom: "log", Region: "us-west-2", Provider: "ssm",
+					Target: apicontainer.SecretTargetLogDriver},
+			},
+		},
+	}
+
+	cfg := NewConfig("/dir", containers, "ssm", time.Minute)
+	require.NotNil(t, cfg)
+	assert.Equal(t, "/dir", cfg.Dir)
+	assert.Equal(t, time.Minute, cfg.RotationInterval)
+	assert.Equal(t, map[string]apicontainer.Secret{"app/db-password": dbPassword}, cfg.Files)
+	assert.True(t, cfg.HasContainer("app"))
+	assert.False(t, cfg.HasContainer("sidecar"), "log driver secrets aren't written to files")
+
+	assert.Nil(t, NewConfig("/dir", containers[1:], "ssm", time.Minute))
+	assert.False(t, (*Config)(nil).HasContainer("app"))
+
+	contents, err := cfg.Contents(lookup(map[string]string{dbPassword.GetSecretResourceCacheKey(): "hunter2"}))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"app/db-password": "hunter2"}, contents)
+
+	_, err = cfg.Contents(lookup(map[string]string{}))
+	assert.Error(t, err)
+}
+
+func lookup(values map[string]string) func(string) (string, bool) {
+	return func(secretKey string) (string, bool) {
+		value, ok := values[secretKey]
+		return value, ok
+	}
+}
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, Write(dir, map[string]string{"app/db-password": "hunter2", "app/api-key": "key"}))
+
+	path := filepath.Join(dir, "app", "db-password")
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", string(content))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(secretFileMode), info.Mode().Perm())
+
+	// a rotated secret replaces the file
+	require.NoError(t, Write(dir, map[string]string{"app/db-password": "correct horse"}))
+	content, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "correct horse", string(content))
+
+	entries, err := os.ReadDir(filepath.Join(dir, "app"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 2, "no temporary file is left behind")
+}
+
+func TestWriteInvalidPath(t *testing.T) {
+	dir := t.TempDir()
+	for _, path := range []string{"../escape", "app/../../escape", "", "."} {
+		assert.Error(t, Write(dir, map[string]string{path: "value"}), path)
+	}
+	_, err := os.Stat(filepath.Join(filepath.Dir(dir), "escape"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRemove(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "task-id")
+	require.NoError(t, Write(dir, map[string]string{"app/db-password": "hunter2"}))
+	require.NoError(t, Remove(dir))
+	_, err := os.Stat(dir)
+	assert.True(t, os.IsNotExist(err))
+
+	assert.NoError(t, Remove(dir), "removing missing secret files is a no-op")
+}
+
+func TestRotator(t *testing.T) {
+	refreshed := make(chan struct{}, 10)
+	rotator := NewRotator("task-arn", time.Millisecond, func() error {
+		refreshed <- struct{}{}
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rotator.Start(ctx)
+	rotator.Start(ctx)
+	select {
+	case <-refreshed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the secret files weren't refreshed")
+	}
+	rotator.Stop()
+	rotator.Stop()
+}
+
+func TestWriter(t *testing.T) {
+	dbPassword := apicontainer.Secret{Name: "db-password", ValueFrom: "/db/password", Region: "us-west-2", Provider: "ssm"}
+	values := map[string]string{dbPassword.GetSecretResourceCacheKey(): "hunter2"}
+
+	var writer Writer
+	assert.False(t, writer.HasSecretFiles("app"))
+	assert.NoError(t, writer.WriteSecretFiles(lookup(values)), "writing without secret files is a no-op")
+	assert.NoError(t, writer.RemoveSecretFiles(), "removing without secret files is a no-op")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cfg := NewConfig(filepath.Join(t.TempDir(), "task-id"), []*apicontainer.Container{
+		{Name: "app", Secrets: []apicontainer.Secret{dbPassword}},
+	}, "ssm", time.Millisecond)
+	writer.SetSecretFiles(ctx, cfg)
+	assert.Equal(t, cfg, writer.SecretFiles())
+	assert.True(t, writer.HasSecretFiles("app"))
+
+	require.NoError(t, writer.WriteSecretFiles(lookup(values)))
+	content, err := os.ReadFile(filepath.Join(cfg.Dir, "app", "db-password"))
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", string(content))
+
+	refreshed := make(chan struct{}, 10)
+	writer.StartSecretRotation("task-arn", func() error {
+		refreshed <- struct{}{}
+		return nil
+	})
+	select {
+	case <-refreshed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("secret files were not refreshed")
+	}
+
+	require.NoError(t, writer.RemoveSecretFiles())
+	_, err = os.Stat(cfg.Dir)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,56 @@
+//go:build linux
+// +build linux
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package secretfiles
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/sys/unix"
+)
+
+// tmpfsSize is the size of the tmpfs of a task, secrets are at most a few tens of KiB
+const tmpfsSize = "4m"
+
+func mountTmpfs(dir string) error {
+	if err := unix.Mount("tmpfs", dir, "tmpfs", unix.MS_NOSUID|unix.MS_NODEV|unix.MS_NOEXEC,
+		fmt.Sprintf("size=%s,mode=%o", tmpfsSize, secretFilesMode)); err != nil {
+		return fmt.Errorf("unable to mount a tmpfs on the secrets directory of the task: %w", err)
+	}
+	return nil
+}
+
+func unmountTmpfs(dir string) error {
+	if err := unix.Unmount(dir, unix.MNT_DETACH); err != nil {
+		return fmt.Errorf("unable to unmount the tmpfs of the secrets directory of the task: %w", err)
+	}
+	return nil
+}
+
+// isMountPoint returns whether a file system is mounted on dir, which is on a different device than
+// its parent directory.
+func isMountPoint(dir string) (bool, error) {
+	var st, parentSt unix.Stat_t
+	if err := unix.Stat(dir, &st); err != nil {
+		return false, &os.PathError{Op: "stat", Path: dir, Err: err}
+	}
+	if err := unix.Stat(filepath.Dir(dir), &parentSt); err != nil {
+		return false, &os.PathError{Op: "stat", Path: filepath.Dir(dir), Err: err}
+	}
+	return st.Dev != parentSt.Dev, nil
+}
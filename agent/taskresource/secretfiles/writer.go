@@ -0,0 +1,132 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package secretfiles
+
+import (
+	"context"
+	"sync"
+)
+
+// Writer writes the secrets of a secret resource to files and refreshes them until the resource is
+// cleaned up. Secret resources embed it, and save its configuration with the resource.
+type Writer struct {
+	config  *Config
+	rotator *Rotator
+	ctx     context.Context
+	lock    sync.RWMutex
+}
+
+// SetSecretFiles makes the resource write the secrets to files, and refresh them until ctx is done.
+func (w *Writer) SetSecretFiles(ctx context.Context, config *Config) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.ctx = ctx
+	w.config = config
+}
+
+// RestoreSecretFiles sets the secret files configuration of a resource restored from the state.
+func (w *Writer) RestoreSecretFiles(config *Config) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.config = config
+}
+
+// SetSecretFilesContext sets the context the secret files are refreshed until.
+func (w *Writer) SetSecretFilesContext(ctx context.Context) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.ctx = ctx
+}
+
+// SecretFiles returns the secret files configuration, it's nil when the secrets aren't written to files.
+func (w *Writer) SecretFiles() *Config {
+	w.lock.RLock()
+	defer w.lock.RUnlock()
+
+	return w.config
+}
+
+// HasSecretFiles returns whether the secrets of the container are written to files.
+func (w *Writer) HasSecretFiles(containerName string) bool {
+	return w.SecretFiles().HasContainer(containerName)
+}
+
+// SetupSecretFiles writes the secret files once the secrets are retrieved, and starts refreshing
+// them with refresh. values looks up the value of a secret by its secret resource cache key.
+func (w *Writer) SetupSecretFiles(taskARN string, values func(secretKey string) (string, bool),
+	refresh func() error) error {
+	config := w.SecretFiles()
+	if config == nil {
+		return nil
+	}
+	if err := Setup(config.Dir); err != nil {
+		return err
+	}
+	if err := w.WriteSecretFiles(values); err != nil {
+		return err
+	}
+	w.StartSecretRotation(taskARN, refresh)
+	return nil
+}
+
+// WriteSecretFiles writes the secret files, values looks up the value of a secret by its secret
+// resource cache key.
+func (w *Writer) WriteSecretFiles(values func(secretKey string) (string, bool)) error {
+	config := w.SecretFiles()
+	if config == nil {
+		return nil
+	}
+	contents, err := config.Contents(values)
+	if err != nil {
+		return err
+	}
+	return Write(config.Dir, contents)
+}
+
+// StartSecretRotation starts refreshing the secret files with refresh, until they are removed or
+// the context of the Writer is done.
+func (w *Writer) StartSecretRotation(taskARN string, refresh func() error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.config == nil {
+		return
+	}
+	if w.rotator == nil {
+		w.rotator = NewRotator(taskARN, w.config.RotationInterval, refresh)
+	}
+	ctx := w.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	w.rotator.Start(ctx)
+}
+
+// RemoveSecretFiles stops refreshing the secret files and removes them.
+func (w *Writer) RemoveSecretFiles() error {
+	w.lock.Lock()
+	if w.rotator != nil {
+		w.rotator.Stop()
+	}
+	config := w.config
+	w.lock.Unlock()
+
+	if config == nil {
+		return nil
+	}
+	return Remove(config.Dir)
+}
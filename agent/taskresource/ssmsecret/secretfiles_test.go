@@ -0,0 +1,117 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ssmsecret
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	mock_factory "github.com/aws/amazon-ecs-agent/agent/ssm/factory/mocks"
+	mock_ssm "github.com/aws/amazon-ecs-agent/agent/ssm/mocks"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource/secretfiles"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
+	mock_credentials "github.com/aws/amazon-ecs-agent/ecs-agent/credentials/mocks"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSecretFilesResource(t *testing.T, ctrl *gomock.Controller) (*SSMSecretResource, *mock_ssm.MockSSMClient) {
+	secret := apicontainer.Secret{
+		Name:      secretName1,
+		ValueFrom: valueFrom1,
+		Region:    region1,
+		Provider:  apicontainer.SecretProviderSSM,
+	}
+	credentialsManager := mock_credentials.NewMockManager(ctrl)
+	ssmClientCreator := mock_factory.NewMockSSMClientCreator(ctrl)
+	mockSSMClient := mock_ssm.NewMockSSMClient(ctrl)
+	credentialsManager.EXPECT().GetTaskCredentials(executionCredentialsID).Return(credentials.TaskIAMRoleCredentials{}, true).AnyTimes()
+	ssmClientCreator.EXPECT().NewSSMClient(region1, gomock.Any()).Return(mockSSMClient).AnyTimes()
+
+	res := NewSSMSecretResource(taskARN, map[string][]apicontainer.Secret{region1: {secret, secret}},
+		executionCredentialsID, credentialsManager, ssmClientCreator)
+	res.SetSecretFiles(context.Background(), secretfiles.NewConfig(t.TempDir(), []*apicontainer.Container{
+		{Name: "app", Secrets: []apicontainer.Secret{secret}},
+	}, apicontainer.SecretProviderSSM, time.Hour))
+	return res, mockSSMClient
+}
+
+func TestRefreshSecretFiles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	res, mockSSMClient := newTestSecretFilesResource(t, ctrl)
+	res.SetCachedSecretValue(secretKeyWest1, "previous-value")
+	mockSSMClient.EXPECT().GetParameters(gomock.Any()).Do(func(in *ssm.GetParametersInput) {
+		assert.Equal(t, []*string{aws.String(valueFrom1)}, in.Names, "duplicate secrets are retrieved once")
+	}).Return(&ssm.GetParametersOutput{
+		Parameters: []*ssm.Parameter{{Name: aws.String(valueFrom1), Value: aws.String(secretValue)}},
+	}, nil)
+
+	require.NoError(t, res.refreshSecretFiles())
+	assert.True(t, res.HasSecretFiles("app"))
+	assert.False(t, res.HasSecretFiles("sidecar"))
+
+	value, ok := res.GetCachedSecretValue(secretKeyWest1)
+	require.True(t, ok)
+	assert.Equal(t, secretValue, value)
+	content, err := os.ReadFile(filepath.Join(res.SecretFiles().Dir, "app", secretName1))
+	require.NoError(t, err)
+	assert.Equal(t, secretValue, string(content))
+
+	require.NoError(t, res.RemoveSecretFiles())
+	_, err = os.Stat(res.SecretFiles().Dir)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRefreshSecretFilesErrorKeepsFiles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	res, mockSSMClient := newTestSecretFilesResource(t, ctrl)
+	res.SetCachedSecretValue(secretKeyWest1, "previous-value")
+	require.NoError(t, res.WriteSecretFiles(res.GetCachedSecretValue))
+	mockSSMClient.EXPECT().GetParameters(gomock.Any()).Return(&ssm.GetParametersOutput{
+		InvalidParameters: []*string{aws.String(valueFrom1)},
+	}, nil)
+
+	assert.Error(t, res.refreshSecretFiles())
+	content, err := os.ReadFile(filepath.Join(res.SecretFiles().Dir, "app", secretName1))
+	require.NoError(t, err)
+	assert.Equal(t, "previous-value", string(content))
+}
+
+func TestMarshalUnmarshalSecretFiles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	res, _ := newTestSecretFilesResource(t, ctrl)
+	bytes, err := json.Marshal(res)
+	require.NoError(t, err)
+
+	unmarshalled := &SSMSecretResource{}
+	require.NoError(t, json.Unmarshal(bytes, unmarshalled))
+	assert.Equal(t, res.SecretFiles(), unmarshalled.SecretFiles())
+}
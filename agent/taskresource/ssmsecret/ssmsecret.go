@@ -17,7 +17,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"sync"
-	"time"
 
 	"github.com/cihub/seelog"
 	"github.com/pkg/errors"
@@ -26,8 +25,8 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/ssm"
 	"github.com/aws/amazon-ecs-agent/agent/ssm/factory"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource/secretfiles"
 	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
-	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
 )
@@ -43,18 +42,13 @@ const (
 // SSMSecretResource represents secrets as a task resource.
 // The secrets are stored in SSM Parameter Store.
 type SSMSecretResource struct {
-	taskARN             string
-	createdAt           time.Time
-	desiredStatusUnsafe resourcestatus.ResourceStatus
-	knownStatusUnsafe   resourcestatus.ResourceStatus
-	// appliedStatus is the status that has been "applied" (e.g., we've called some
-	// operation such as 'Create' on the resource) but we don't yet know that the
-	// application was successful, which may then change the known status. This is
-	// used while progressing resource states in progressTask() of task manager
-	appliedStatus                      resourcestatus.ResourceStatus
-	resourceStatusToTransitionFunction map[resourcestatus.ResourceStatus]func() error
-	credentialsManager                 credentials.Manager
-	executionCredentialsID             string
+	taskresource.ResourceBase
+	// Writer writes the secrets to files when the containers of the task use secret files
+	secretfiles.Writer
+
+	taskARN                string
+	credentialsManager     credentials.Manager
+	executionCredentialsID string
 
 	// required for store ssm secrets value, key is region of secret
 	requiredSecrets map[string][]apicontainer.Secret
@@ -65,11 +59,6 @@ type SSMSecretResource struct {
 	// needed mostly for testing.
 	ssmClientCreator factory.SSMClientCreator
 
-	// terminalReason should be set for resource creation failures. This ensures
-	// the resource object carries some context for why provisioning failed.
-	terminalReason     string
-	terminalReasonOnce sync.Once
-
 	// lock is used for fields that are accessed and updated concurrently
 	lock sync.RWMutex
 }
@@ -94,198 +83,100 @@ func NewSSMSecretResource(taskARN string,
 }
 
 func (secret *SSMSecretResource) initStatusToTransition() {
-	resourceStatusToTransitionFunction := map[resourcestatus.ResourceStatus]func() error{
-		resourcestatus.ResourceStatus(SSMSecretCreated): secret.Create,
-	}
-	secret.resourceStatusToTransitionFunction = resourceStatusToTransitionFunction
-}
-
-func (secret *SSMSecretResource) setTerminalReason(reason string) {
-	secret.terminalReasonOnce.Do(func() {
-		seelog.Infof("ssm secret resource: setting terminal reason for ssm secret resource in task: [%s]", secret.taskARN)
-		secret.terminalReason = reason
-	})
-}
-
-// GetTerminalReason returns an error string to propagate up through to task
-// state change messages
-func (secret *SSMSecretResource) GetTerminalReason() string {
-	return secret.terminalReason
-}
-
-// SetDesiredStatus safely sets the desired status of the resource
-func (secret *SSMSecretResource) SetDesiredStatus(status resourcestatus.ResourceStatus) {
-	secret.lock.Lock()
-	defer secret.lock.Unlock()
-
-	secret.desiredStatusUnsafe = status
-}
-
-// GetDesiredStatus safely returns the desired status of the task
-func (secret *SSMSecretResource) GetDesiredStatus() resourcestatus.ResourceStatus {
-	secret.lock.RLock()
-	defer secret.lock.RUnlock()
-
-	return secret.desiredStatusUnsafe
-}
-
-// GetName safely returns the name of the resource
-func (secret *SSMSecretResource) GetName() string {
-	secret.lock.RLock()
-	defer secret.lock.RUnlock()
-
-	return ResourceName
-}
-
-// DesiredTerminal returns true if the secret's desired status is REMOVED
-func (secret *SSMSecretResource) DesiredTerminal() bool {
-	secret.lock.RLock()
-	defer secret.lock.RUnlock()
-
-	return secret.desiredStatusUnsafe == resourcestatus.ResourceStatus(SSMSecretRemoved)
-}
-
-// KnownCreated returns true if the secret's known status is CREATED
-func (secret *SSMSecretResource) KnownCreated() bool {
-	secret.lock.RLock()
-	defer secret.lock.RUnlock()
-
-	return secret.knownStatusUnsafe == resourcestatus.ResourceStatus(SSMSecretCreated)
-}
-
-// TerminalStatus returns the last transition state of cgroup
-func (secret *SSMSecretResource) TerminalStatus() resourcestatus.ResourceStatus {
-	return resourcestatus.ResourceStatus(SSMSecretRemoved)
+	secret.InitBase(ResourceName, secret.Create)
 }
 
-// NextKnownState returns the state that the resource should
-// progress to based on its `KnownState`.
-func (secret *SSMSecretResource) NextKnownState() resourcestatus.ResourceStatus {
-	return secret.GetKnownStatus() + 1
-}
+// Create fetches secret value from SSM in batches. It spins up multiple goroutines in order to
+// retrieve values in parallel.
+func (secret *SSMSecretResource) Create() error {
 
-// ApplyTransition calls the function required to move to the specified status
-func (secret *SSMSecretResource) ApplyTransition(nextState resourcestatus.ResourceStatus) error {
-	transitionFunc, ok := secret.resourceStatusToTransitionFunction[nextState]
-	if !ok {
-		return errors.Errorf("resource [%s]: transition to %s impossible", secret.GetName(),
-			secret.StatusString(nextState))
+	// To fail fast, check execution role first
+	iamCredentials, err := secret.getExecutionRoleCredentials()
+	if err != nil {
+		// No need to log here. managedTask.applyResourceState already does that
+		secret.SetTerminalReason(err.Error())
+		return err
 	}
-	return transitionFunc()
-}
 
-// SteadyState returns the transition state of the resource defined as "ready"
-func (secret *SSMSecretResource) SteadyState() resourcestatus.ResourceStatus {
-	return resourcestatus.ResourceStatus(SSMSecretCreated)
-}
-
-// SetKnownStatus safely sets the currently known status of the resource
-func (secret *SSMSecretResource) SetKnownStatus(status resourcestatus.ResourceStatus) {
+	seelog.Infof("ssm secret resource: retrieving secrets for containers in task: [%s]", secret.taskARN)
+	secretData, err := secret.retrieveSecretValues(iamCredentials)
+	if err != nil {
+		secret.SetTerminalReason(err.Error())
+		return err
+	}
 	secret.lock.Lock()
-	defer secret.lock.Unlock()
+	secret.secretData = secretData
+	secret.lock.Unlock()
 
-	secret.knownStatusUnsafe = status
-	secret.updateAppliedStatusUnsafe(status)
+	if err := secret.SetupSecretFiles(secret.taskARN, secret.GetCachedSecretValue, secret.refreshSecretFiles); err != nil {
+		err = fmt.Errorf("ssm secret resource: %w", err)
+		secret.SetTerminalReason(err.Error())
+		return err
+	}
+	return nil
 }
 
-// updateAppliedStatusUnsafe updates the resource transitioning status
-func (secret *SSMSecretResource) updateAppliedStatusUnsafe(knownStatus resourcestatus.ResourceStatus) {
-	if secret.appliedStatus == resourcestatus.ResourceStatus(SSMSecretStatusNone) {
-		return
+// refreshSecretFiles retrieves the secrets again with the task execution role, and swaps the
+// secret files whose secret changed. The previous values are kept if any secret can't be retrieved.
+func (secret *SSMSecretResource) refreshSecretFiles() error {
+	iamCredentials, err := secret.getExecutionRoleCredentials()
+	if err != nil {
+		return err
 	}
-
-	// Check if the resource transition has already finished
-	if secret.appliedStatus <= knownStatus {
-		secret.appliedStatus = resourcestatus.ResourceStatus(SSMSecretStatusNone)
+	secretData, err := secret.retrieveSecretValues(iamCredentials)
+	if err != nil {
+		return err
 	}
-}
-
-// SetAppliedStatus sets the applied status of resource and returns whether
-// the resource is already in a transition
-func (secret *SSMSecretResource) SetAppliedStatus(status resourcestatus.ResourceStatus) bool {
-	secret.lock.Lock()
-	defer secret.lock.Unlock()
-
-	if secret.appliedStatus != resourcestatus.ResourceStatus(SSMSecretStatusNone) {
-		// return false to indicate the set operation failed
-		return false
+	for secretKey, secretValue := range secretData {
+		secret.SetCachedSecretValue(secretKey, secretValue)
 	}
-
-	secret.appliedStatus = status
-	return true
-}
-
-// GetKnownStatus safely returns the currently known status of the task
-func (secret *SSMSecretResource) GetKnownStatus() resourcestatus.ResourceStatus {
-	secret.lock.RLock()
-	defer secret.lock.RUnlock()
-
-	return secret.knownStatusUnsafe
-}
-
-// StatusString returns the string of the cgroup resource status
-func (secret *SSMSecretResource) StatusString(status resourcestatus.ResourceStatus) string {
-	return SSMSecretStatus(status).String()
-}
-
-// SetCreatedAt sets the timestamp for resource's creation time
-func (secret *SSMSecretResource) SetCreatedAt(createdAt time.Time) {
-	if createdAt.IsZero() {
-		return
+	if err := secret.WriteSecretFiles(secret.GetCachedSecretValue); err != nil {
+		return fmt.Errorf("ssm secret resource: %w", err)
 	}
-	secret.lock.Lock()
-	defer secret.lock.Unlock()
-
-	secret.createdAt = createdAt
-}
-
-// GetCreatedAt sets the timestamp for resource's creation time
-func (secret *SSMSecretResource) GetCreatedAt() time.Time {
-	secret.lock.RLock()
-	defer secret.lock.RUnlock()
-
-	return secret.createdAt
+	return nil
 }
 
-// Create fetches secret value from SSM in batches. It spins up multiple goroutines in order to
-// retrieve values in parallel.
-func (secret *SSMSecretResource) Create() error {
-
-	// To fail fast, check execution role first
+// getExecutionRoleCredentials returns the credentials of the task execution role, the secrets
+// are retrieved with them.
+func (secret *SSMSecretResource) getExecutionRoleCredentials() (credentials.IAMRoleCredentials, error) {
 	executionCredentials, ok := secret.credentialsManager.GetTaskCredentials(secret.getExecutionCredentialsID())
 	if !ok {
-		// No need to log here. managedTask.applyResourceState already does that
-		err := errors.New("ssm secret resource: unable to find execution role credentials")
-		secret.setTerminalReason(err.Error())
-		return err
+		return credentials.IAMRoleCredentials{}, errors.New("ssm secret resource: unable to find execution role credentials")
 	}
-	iamCredentials := executionCredentials.GetIAMRoleCredentials()
+	return executionCredentials.GetIAMRoleCredentials(), nil
+}
 
+// retrieveSecretValues retrieves the values of the required secrets from SSM, keyed by the secret
+// resource cache key. It spins up a goroutine per region, and per batch of secrets in each region.
+func (secret *SSMSecretResource) retrieveSecretValues(iamCredentials credentials.IAMRoleCredentials) (map[string]string, error) {
 	var wg sync.WaitGroup
 
 	// Get the maximum number of errors can be returned, which will be one error per goroutine
 	chanLen := secret.getGoRoutineMaxNum()
 	errorEvents := make(chan error, chanLen)
-
-	seelog.Infof("ssm secret resource: retrieving secrets for containers in task: [%s]", secret.taskARN)
-	secret.secretData = make(map[string]string)
+	values := &secretValues{data: make(map[string]string)}
 
 	for region, secrets := range secret.getRequiredSecrets() {
 		wg.Add(1)
 		// Spin up goroutine each region to speed up processing time
-		go secret.retrieveSSMSecretValuesByRegion(region, secrets, iamCredentials, &wg, errorEvents)
+		go secret.retrieveSSMSecretValuesByRegion(region, secrets, iamCredentials, values, &wg, errorEvents)
 	}
 
 	wg.Wait()
 
-	// Get the first error returned and set as terminal reason
+	// Get the first error returned
 	select {
 	case err := <-errorEvents:
-		secret.setTerminalReason(err.Error())
-		return err
+		return nil, err
 	default:
-		return nil
 	}
+	return values.data, nil
+}
+
+// secretValues collects the secret values retrieved by concurrent goroutines
+type secretValues struct {
+	data map[string]string
+	lock sync.Mutex
 }
 
 // getGoRoutineMaxNum calculates the maximum number of goroutines that we need to spin up
@@ -299,39 +190,40 @@ func (secret *SSMSecretResource) getGoRoutineMaxNum() int {
 	return total
 }
 
-// retrieveSSMSecretValuesByRegion reads secret values from cache first, if not exists, batches secrets based on field
-// valueFrom and call retrieveSSMSecretValues to retrieve values from SSM
-func (secret *SSMSecretResource) retrieveSSMSecretValuesByRegion(region string, secrets []apicontainer.Secret, iamCredentials credentials.IAMRoleCredentials, wg *sync.WaitGroup, errorEvents chan error) {
+// retrieveSSMSecretValuesByRegion dedupes secrets based on field valueFrom, batches them and calls
+// retrieveSSMSecretValues to retrieve values from SSM
+func (secret *SSMSecretResource) retrieveSSMSecretValuesByRegion(region string, secrets []apicontainer.Secret, iamCredentials credentials.IAMRoleCredentials, values *secretValues, wg *sync.WaitGroup, errorEvents chan error) {
 	seelog.Infof("ssm secret resource: retrieving secrets for region %s in task: [%s]", region, secret.taskARN)
 	defer wg.Done()
 
 	var wgPerRegion sync.WaitGroup
 	var secretNames []string
 
+	seen := make(map[string]bool)
 	for _, s := range secrets {
-		secretKey := s.GetSecretResourceCacheKey()
-		if _, ok := secret.GetCachedSecretValue(secretKey); ok {
+		if seen[s.ValueFrom] {
 			continue
 		}
+		seen[s.ValueFrom] = true
 		secretNames = append(secretNames, s.ValueFrom)
 		if len(secretNames) == MaxBatchNum {
 			secretNamesTmp := make([]string, MaxBatchNum)
 			copy(secretNamesTmp, secretNames)
 			wgPerRegion.Add(1)
-			go secret.retrieveSSMSecretValues(region, secretNamesTmp, iamCredentials, &wgPerRegion, errorEvents)
+			go secret.retrieveSSMSecretValues(region, secretNamesTmp, iamCredentials, values, &wgPerRegion, errorEvents)
 			secretNames = []string{}
 		}
 	}
 
 	if len(secretNames) > 0 {
 		wgPerRegion.Add(1)
-		go secret.retrieveSSMSecretValues(region, secretNames, iamCredentials, &wgPerRegion, errorEvents)
+		go secret.retrieveSSMSecretValues(region, secretNames, iamCredentials, values, &wgPerRegion, errorEvents)
 	}
 	wgPerRegion.Wait()
 }
 
-// retrieveSSMSecretValues retrieves secret values from SSM parameter store and caches them into memory
-func (secret *SSMSecretResource) retrieveSSMSecretValues(region string, names []string, iamCredentials credentials.IAMRoleCredentials, wg *sync.WaitGroup, errorEvents chan error) {
+// retrieveSSMSecretValues retrieves secret values from SSM parameter store and collects them into values
+func (secret *SSMSecretResource) retrieveSSMSecretValues(region string, names []string, iamCredentials credentials.IAMRoleCredentials, values *secretValues, wg *sync.WaitGroup, errorEvents chan error) {
 	defer wg.Done()
 
 	ssmClient := secret.ssmClientCreator.NewSSMClient(region, iamCredentials)
@@ -342,13 +234,12 @@ func (secret *SSMSecretResource) retrieveSSMSecretValues(region string, names []
 		return
 	}
 
-	secret.lock.Lock()
-	defer secret.lock.Unlock()
+	values.lock.Lock()
+	defer values.lock.Unlock()
 
-	// put secret value in secretData
 	for secretName, secretValue := range secValueMap {
 		secretKey := secretName + "_" + region
-		secret.secretData[secretKey] = secretValue
+		values.data[secretKey] = secretValue
 	}
 }
 
@@ -368,9 +259,12 @@ func (secret *SSMSecretResource) getExecutionCredentialsID() string {
 	return secret.executionCredentialsID
 }
 
-// Cleanup removes the secret value created for the task
+// Cleanup removes the secret value created for the task, and its secret files
 func (secret *SSMSecretResource) Cleanup() error {
 	secret.clearSSMSecretValue()
+	if err := secret.RemoveSecretFiles(); err != nil {
+		return fmt.Errorf("ssm secret resource: unable to remove the secret files: %w", err)
+	}
 	return nil
 }
 
@@ -412,6 +306,7 @@ func (secret *SSMSecretResource) Initialize(resourceFields *taskresource.Resourc
 	secret.initStatusToTransition()
 	secret.credentialsManager = resourceFields.CredentialsManager
 	secret.ssmClientCreator = resourceFields.SSMClientCreator
+	secret.SetSecretFilesContext(resourceFields.Ctx)
 
 	// if task hasn't turn to 'created' status, and it's desire status is 'running'
 	// the resource status needs to be reset to 'NONE' status so the secret value
@@ -420,15 +315,20 @@ func (secret *SSMSecretResource) Initialize(resourceFields *taskresource.Resourc
 		taskDesiredStatus <= status.TaskRunning {
 		secret.SetKnownStatus(resourcestatus.ResourceStatusNone)
 	}
+
+	// the secret files of a running task are kept in its tmpfs across agent restarts, only their
+	// refresh needs to start again
+	if secret.KnownCreated() && taskDesiredStatus <= status.TaskRunning {
+		secret.StartSecretRotation(secret.taskARN, secret.refreshSecretFiles)
+	}
 }
 
 type SSMSecretResourceJSON struct {
-	TaskARN                string                           `json:"taskARN"`
-	CreatedAt              *time.Time                       `json:"createdAt,omitempty"`
-	DesiredStatus          *SSMSecretStatus                 `json:"desiredStatus"`
-	KnownStatus            *SSMSecretStatus                 `json:"knownStatus"`
+	TaskARN string `json:"taskARN"`
+	taskresource.ResourceBaseJSON
 	RequiredSecrets        map[string][]apicontainer.Secret `json:"secretResources"`
 	ExecutionCredentialsID string                           `json:"executionCredentialsID"`
+	SecretFiles            *secretfiles.Config              `json:"secretFiles,omitempty"`
 }
 
 // MarshalJSON serialises the SSMSecretResource struct to JSON
@@ -436,22 +336,12 @@ func (secret *SSMSecretResource) MarshalJSON() ([]byte, error) {
 	if secret == nil {
 		return nil, errors.New("ssmsecret resource is nil")
 	}
-	createdAt := secret.GetCreatedAt()
 	return json.Marshal(SSMSecretResourceJSON{
-		TaskARN:   secret.taskARN,
-		CreatedAt: &createdAt,
-		DesiredStatus: func() *SSMSecretStatus {
-			desiredState := secret.GetDesiredStatus()
-			s := SSMSecretStatus(desiredState)
-			return &s
-		}(),
-		KnownStatus: func() *SSMSecretStatus {
-			knownState := secret.GetKnownStatus()
-			s := SSMSecretStatus(knownState)
-			return &s
-		}(),
+		TaskARN:                secret.taskARN,
+		ResourceBaseJSON:       secret.BaseJSON(),
 		RequiredSecrets:        secret.getRequiredSecrets(),
 		ExecutionCredentialsID: secret.getExecutionCredentialsID(),
+		SecretFiles:            secret.SecretFiles(),
 	})
 }
 
@@ -463,40 +353,14 @@ func (secret *SSMSecretResource) UnmarshalJSON(b []byte) error {
 		return err
 	}
 
-	if temp.DesiredStatus != nil {
-		secret.SetDesiredStatus(resourcestatus.ResourceStatus(*temp.DesiredStatus))
-	}
-	if temp.KnownStatus != nil {
-		secret.SetKnownStatus(resourcestatus.ResourceStatus(*temp.KnownStatus))
-	}
-	if temp.CreatedAt != nil && !temp.CreatedAt.IsZero() {
-		secret.SetCreatedAt(*temp.CreatedAt)
-	}
+	secret.initStatusToTransition()
+	secret.SetBaseJSON(temp.ResourceBaseJSON)
 	if temp.RequiredSecrets != nil {
 		secret.requiredSecrets = temp.RequiredSecrets
 	}
 	secret.taskARN = temp.TaskARN
 	secret.executionCredentialsID = temp.ExecutionCredentialsID
+	secret.RestoreSecretFiles(temp.SecretFiles)
 
 	return nil
 }
-
-// GetAppliedStatus safely returns the currently applied status of the resource
-func (secret *SSMSecretResource) GetAppliedStatus() resourcestatus.ResourceStatus {
-	secret.lock.RLock()
-	defer secret.lock.RUnlock()
-
-	return secret.appliedStatus
-}
-
-func (secret *SSMSecretResource) DependOnTaskNetwork() bool {
-	return false
-}
-
-func (secret *SSMSecretResource) BuildContainerDependency(containerName string, satisfied apicontainerstatus.ContainerStatus,
-	dependent resourcestatus.ResourceStatus) {
-}
-
-func (secret *SSMSecretResource) GetContainerDependencies(dependent resourcestatus.ResourceStatus) []apicontainer.ContainerDependency {
-	return nil
-}
@@ -465,11 +465,11 @@ func TestMarshalUnmarshalJSON(t *testing.T) {
 	ssmResIn := &SSMSecretResource{
 		taskARN:                taskARN,
 		executionCredentialsID: executionCredentialsID,
-		createdAt:              time.Now(),
-		knownStatusUnsafe:      resourcestatus.ResourceCreated,
-		desiredStatusUnsafe:    resourcestatus.ResourceCreated,
 		requiredSecrets:        requiredSecretData,
 	}
+	ssmResIn.SetCreatedAt(time.Now())
+	ssmResIn.SetKnownStatus(resourcestatus.ResourceCreated)
+	ssmResIn.SetDesiredStatus(resourcestatus.ResourceCreated)
 
 	bytes, err := json.Marshal(ssmResIn)
 	require.NoError(t, err)
@@ -478,9 +478,9 @@ func TestMarshalUnmarshalJSON(t *testing.T) {
 	err = json.Unmarshal(bytes, ssmResOut)
 	require.NoError(t, err)
 	assert.Equal(t, ssmResIn.taskARN, ssmResOut.taskARN)
-	assert.WithinDuration(t, ssmResIn.createdAt, ssmResOut.createdAt, time.Microsecond)
-	assert.Equal(t, ssmResIn.desiredStatusUnsafe, ssmResOut.desiredStatusUnsafe)
-	assert.Equal(t, ssmResIn.knownStatusUnsafe, ssmResOut.knownStatusUnsafe)
+	assert.WithinDuration(t, ssmResIn.GetCreatedAt(), ssmResOut.GetCreatedAt(), time.Microsecond)
+	assert.Equal(t, ssmResIn.GetDesiredStatus(), ssmResOut.GetDesiredStatus())
+	assert.Equal(t, ssmResIn.GetKnownStatus(), ssmResOut.GetKnownStatus())
 	assert.Equal(t, ssmResIn.executionCredentialsID, ssmResOut.executionCredentialsID)
 	assert.Equal(t, len(ssmResIn.requiredSecrets), len(ssmResOut.requiredSecrets))
 	assert.Equal(t, ssmResIn.requiredSecrets[region1], ssmResOut.requiredSecrets[region1])
@@ -492,10 +492,9 @@ func TestInitialize(t *testing.T) {
 
 	credentialsManager := mock_credentials.NewMockManager(ctrl)
 	ssmClientCreator := mock_factory.NewMockSSMClientCreator(ctrl)
-	ssmRes := &SSMSecretResource{
-		knownStatusUnsafe:   resourcestatus.ResourceCreated,
-		desiredStatusUnsafe: resourcestatus.ResourceCreated,
-	}
+	ssmRes := &SSMSecretResource{}
+	ssmRes.SetKnownStatus(resourcestatus.ResourceCreated)
+	ssmRes.SetDesiredStatus(resourcestatus.ResourceCreated)
 	ssmRes.Initialize(&taskresource.ResourceFields{
 		ResourceFieldsCommon: &taskresource.ResourceFieldsCommon{
 			SSMClientCreator:   ssmClientCreator,
@@ -0,0 +1,92 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/aws/amazon-ecs-agent/agent/taskresource"
+)
+
+// ResourceFactory returns an empty resource of a registered resource type, that the saved resource
+// is unmarshalled into.
+type ResourceFactory func() taskresource.TaskResource
+
+var (
+	registryLock sync.RWMutex
+	// registeredResources are the resource types registered out of this package, keyed by their key
+	// in the resources map
+	registeredResources = make(map[string]ResourceFactory)
+
+	builtinResourceKeys = map[string]struct{}{
+		CgroupKey:               {},
+		DockerVolumeKey:         {},
+		ASMAuthKey:              {},
+		SSMSecretKey:            {},
+		ASMSecretKey:            {},
+		FirelensKey:             {},
+		CredentialSpecKey:       {},
+		EnvironmentFilesKey:     {},
+		FSxWindowsFileServerKey: {},
+	}
+)
+
+// RegisterResourceType registers a resource type so that its resources are unmarshalled from the
+// saved task state. The key is the key of the resources of the type in the resources map, it can't
+// be the key of a built-in resource type or of a type that's already registered.
+func RegisterResourceType(key string, factory ResourceFactory) error {
+	if key == "" || factory == nil {
+		return fmt.Errorf("resource type requires a key and a factory")
+	}
+	if _, ok := builtinResourceKeys[key]; ok {
+		return fmt.Errorf("resource type %s is a built-in resource type", key)
+	}
+
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	if _, ok := registeredResources[key]; ok {
+		return fmt.Errorf("resource type %s is already registered", key)
+	}
+	registeredResources[key] = factory
+	return nil
+}
+
+func registeredResourceFactory(key string) (ResourceFactory, bool) {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	factory, ok := registeredResources[key]
+	return factory, ok
+}
+
+func unmarshalRegisteredResource(key string, value json.RawMessage, factory ResourceFactory,
+	result map[string][]taskresource.TaskResource) error {
+	var resources []json.RawMessage
+	err := json.Unmarshal(value, &resources)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range resources {
+		res := factory()
+		err := res.UnmarshalJSON(r)
+		if err != nil {
+			return err
+		}
+		result[key] = append(result[key], res)
+	}
+	return nil
+}
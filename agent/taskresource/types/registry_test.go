@@ -0,0 +1,49 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/taskresource"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource/ssmsecret"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterResourceType(t *testing.T) {
+	factory := func() taskresource.TaskResource { return &ssmsecret.SSMSecretResource{} }
+	assert.Error(t, RegisterResourceType(SSMSecretKey, factory), "built-in resource types can't be replaced")
+	assert.Error(t, RegisterResourceType("", factory))
+	assert.Error(t, RegisterResourceType("registry-test", nil))
+
+	require.NoError(t, RegisterResourceType("registry-test", factory))
+	assert.Error(t, RegisterResourceType("registry-test", factory), "resource types are registered once")
+
+	resources := map[string][]taskresource.TaskResource{
+		"registry-test": {ssmsecret.NewSSMSecretResource("taskARN", nil, "", nil, nil)},
+	}
+	data, err := json.Marshal(resources)
+	require.NoError(t, err)
+
+	var unmarshalled ResourcesMap
+	require.NoError(t, json.Unmarshal(data, &unmarshalled))
+	require.Len(t, unmarshalled["registry-test"], 1)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"unregistered": []}`), &unmarshalled))
+}
@@ -92,6 +92,9 @@ func unmarshalResource(key string, value json.RawMessage, result map[string][]ta
 	case FSxWindowsFileServerKey:
 		return unmarshalFSxWindowsFileServerKey(key, value, result)
 	default:
+		if factory, ok := registeredResourceFactory(key); ok {
+			return unmarshalRegisteredResource(key, value, factory, result)
+		}
 		return errors.New("Unsupported resource type")
 	}
 }
@@ -0,0 +1,147 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/version"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/otlp"
+)
+
+const (
+	serviceName = "amazon-ecs-agent"
+	scopeName   = "github.com/aws/amazon-ecs-agent/agent/engine"
+
+	// exportQueueSize is the number of ended spans buffered for export before new spans are dropped
+	exportQueueSize = 2048
+	// exportBatchSize is the number of spans that triggers an export before the export interval
+	exportBatchSize = 512
+	// exportInterval is the maximum time an ended span waits before it is exported
+	exportInterval = 5 * time.Second
+	// exportTimeout is the timeout of a single export request to the collector
+	exportTimeout = 10 * time.Second
+)
+
+// otlpExporter batches the ended spans and posts them to an OTLP/HTTP collector endpoint,
+// using the JSON encoding of the OTLP protobuf messages.
+type otlpExporter struct {
+	endpoint string
+	client   *http.Client
+	spans    chan *Span
+}
+
+func newOTLPExporter(endpoint string) *otlpExporter {
+	return &otlpExporter{
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: exportTimeout},
+		spans:    make(chan *Span, exportQueueSize),
+	}
+}
+
+// queue queues an ended span for export. The span is dropped when the queue is full, so
+// that a slow or missing collector never blocks the task engine.
+func (exporter *otlpExporter) queue(span *Span) {
+	select {
+	case exporter.spans <- span:
+	default:
+		logger.Debug("Trace export queue is full, dropping span", logger.Fields{
+			"span": span.name,
+		})
+	}
+}
+
+// start exports the queued spans in batches until ctx is cancelled.
+func (exporter *otlpExporter) start(ctx context.Context) {
+	ticker := time.NewTicker(exportInterval)
+	defer ticker.Stop()
+
+	var batch []*Span
+	for {
+		select {
+		case span := <-exporter.spans:
+			batch = append(batch, span)
+			if len(batch) < exportBatchSize {
+				continue
+			}
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+		if len(batch) == 0 {
+			continue
+		}
+		if err := exporter.export(ctx, batch); err != nil {
+			logger.Warn("Unable to export task lifecycle traces", logger.Fields{
+				"spans":     len(batch),
+				field.Error: err,
+			})
+		}
+		batch = nil
+	}
+}
+
+func (exporter *otlpExporter) export(ctx context.Context, spans []*Span) error {
+	return otlp.Export(ctx, exporter.client, exporter.endpoint, newExportTraceServiceRequest(spans))
+}
+
+func newKeyValues(attributes []Attribute) []otlp.KeyValue {
+	var keyValues []otlp.KeyValue
+	for _, attribute := range attributes {
+		keyValues = append(keyValues, otlp.String(attribute.Key, attribute.Value))
+	}
+	return keyValues
+}
+
+func newExportTraceServiceRequest(spans []*Span) otlp.ExportTraceServiceRequest {
+	otlpSpans := make([]otlp.Span, 0, len(spans))
+	for _, s := range spans {
+		s.lock.Lock()
+		otlpSpan := otlp.Span{
+			TraceID:           hex.EncodeToString(s.traceID[:]),
+			SpanID:            hex.EncodeToString(s.spanID[:]),
+			Name:              s.name,
+			Kind:              otlp.SpanKindInternal,
+			StartTimeUnixNano: otlp.UnixNano(s.startTime),
+			EndTimeUnixNano:   otlp.UnixNano(s.endTime),
+			Attributes:        newKeyValues(s.attributes),
+			Status:            otlp.Status{Code: otlp.StatusCodeOK},
+		}
+		if s.parentSpanID != [8]byte{} {
+			otlpSpan.ParentSpanID = hex.EncodeToString(s.parentSpanID[:])
+		}
+		if s.err != nil {
+			otlpSpan.Status = otlp.Status{Code: otlp.StatusCodeError, Message: s.err.Error()}
+		}
+		s.lock.Unlock()
+		otlpSpans = append(otlpSpans, otlpSpan)
+	}
+	return otlp.ExportTraceServiceRequest{
+		ResourceSpans: []otlp.ResourceSpans{{
+			Resource: otlp.Resource{Attributes: []otlp.KeyValue{
+				otlp.String(otlp.ServiceNameKey, serviceName),
+				otlp.String(otlp.ServiceVersionKey, version.Version),
+			}},
+			ScopeSpans: []otlp.ScopeSpans{{
+				Scope: otlp.InstrumentationScope{Name: scopeName, Version: version.Version},
+				Spans: otlpSpans,
+			}},
+		}},
+	}
+}
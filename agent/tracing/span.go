@@ -0,0 +1,119 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+const (
+	// AttributeTaskARN is the attribute key of the task ARN
+	AttributeTaskARN = "aws.ecs.task.arn"
+	// AttributeTaskFamily is the attribute key of the task definition family
+	AttributeTaskFamily = "aws.ecs.task.family"
+	// AttributeTaskRevision is the attribute key of the task definition revision
+	AttributeTaskRevision = "aws.ecs.task.revision"
+	// AttributeTaskStatus is the attribute key of the known status of the task when its span ends
+	AttributeTaskStatus = "aws.ecs.task.status"
+	// AttributeContainerName is the attribute key of the container name
+	AttributeContainerName = "container.name"
+	// AttributeContainerImage is the attribute key of the container image
+	AttributeContainerImage = "container.image.name"
+	// AttributeResourceName is the attribute key of the name of a task resource
+	AttributeResourceName = "aws.ecs.task.resource.name"
+	// AttributeResourceStatus is the attribute key of the status a task resource transitions to
+	AttributeResourceStatus = "aws.ecs.task.resource.status"
+	// AttributeNetworkMode is the attribute key of the network mode of a task
+	AttributeNetworkMode = "aws.ecs.task.network_mode"
+)
+
+// Attribute is a key/value pair attached to a span
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// String creates a string attribute
+func String(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Span is a timed operation of the task lifecycle. A nil Span is valid and records nothing,
+// which is what the span functions return when tracing is disabled.
+type Span struct {
+	tracer       *Tracer
+	name         string
+	traceID      [16]byte
+	spanID       [8]byte
+	parentSpanID [8]byte
+	startTime    time.Time
+	endTime      time.Time
+	attributes   []Attribute
+	err          error
+	once         sync.Once
+	lock         sync.Mutex
+}
+
+func newSpan(tracer *Tracer, name string, parent *Span, attributes []Attribute) *Span {
+	span := &Span{
+		tracer:     tracer,
+		name:       name,
+		startTime:  time.Now(),
+		attributes: attributes,
+	}
+	if parent != nil {
+		span.traceID = parent.traceID
+		span.parentSpanID = parent.spanID
+	} else {
+		rand.Read(span.traceID[:])
+	}
+	rand.Read(span.spanID[:])
+	return span
+}
+
+// SetAttributes adds attributes to the span
+func (span *Span) SetAttributes(attributes ...Attribute) {
+	if span == nil {
+		return
+	}
+	span.lock.Lock()
+	defer span.lock.Unlock()
+	span.attributes = append(span.attributes, attributes...)
+}
+
+// End ends the span and queues it for export. The span is marked as failed when err is not nil.
+// Only the first call to End has an effect.
+func (span *Span) End(err error) {
+	if span == nil {
+		return
+	}
+	span.once.Do(func() {
+		span.lock.Lock()
+		span.endTime = time.Now()
+		span.err = err
+		span.lock.Unlock()
+		span.tracer.export(span)
+	})
+}
+
+// TraceID returns the hex encoded trace id of the span
+func (span *Span) TraceID() string {
+	if span == nil {
+		return ""
+	}
+	return hex.EncodeToString(span.traceID[:])
+}
@@ -0,0 +1,127 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package tracing records the transitions of the task lifecycle as spans, and exports them
+// to an OpenTelemetry collector over OTLP/HTTP.
+//
+// Every task gets a root span from the time the task engine starts managing it until the task
+// reaches RUNNING or STOPPED. Resource provisioning, image pulls, container creation and start,
+// and network setup are recorded as child spans of the task span.
+package tracing
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+)
+
+const (
+	// SpanTask is the name of the root span of a task
+	SpanTask = "Task"
+	// SpanWaitForHostResources is the name of the span of a task waiting for host resources
+	SpanWaitForHostResources = "WaitForHostResources"
+	// SpanResourceTransition is the name of the span of a task resource transition, e.g. Create
+	SpanResourceTransition = "taskresource.ApplyTransition"
+	// SpanPullImage is the name of the span of an image pull
+	SpanPullImage = "dockerapi.PullImage"
+	// SpanCreateContainer is the name of the span of a container creation
+	SpanCreateContainer = "dockerapi.CreateContainer"
+	// SpanStartContainer is the name of the span of a container start
+	SpanStartContainer = "dockerapi.StartContainer"
+	// SpanSetupNetwork is the name of the span of a network namespace setup through ecscni
+	SpanSetupNetwork = "ecscni.SetupNS"
+)
+
+// Tracer keeps the root span of every task being started, and queues the ended spans for export.
+type Tracer struct {
+	exporter *otlpExporter
+	lock     sync.RWMutex
+	tasks    map[string]*Span
+}
+
+// tracerGlobal is the tracer used by the span functions. It is nil, and tracing is disabled,
+// until Init is called with tracing enabled.
+var tracerGlobal *Tracer
+
+// Init enables tracing when it is enabled in the config. Spans are exported to cfg.TracingEndpoint
+// until ctx is cancelled.
+func Init(ctx context.Context, cfg *config.Config) {
+	if !cfg.TracingEnabled.Enabled() {
+		return
+	}
+	logger.Info("Exporting task lifecycle traces", logger.Fields{
+		"endpoint": cfg.TracingEndpoint,
+	})
+	tracerGlobal = newTracer(newOTLPExporter(cfg.TracingEndpoint))
+	go tracerGlobal.exporter.start(ctx)
+}
+
+func newTracer(exporter *otlpExporter) *Tracer {
+	return &Tracer{
+		exporter: exporter,
+		tasks:    make(map[string]*Span),
+	}
+}
+
+// StartTaskSpan starts the root span of a task. It is a no-op if the task already has a root span.
+func StartTaskSpan(taskARN string, attributes ...Attribute) {
+	tracer := tracerGlobal
+	if tracer == nil {
+		return
+	}
+	tracer.lock.Lock()
+	defer tracer.lock.Unlock()
+	if _, ok := tracer.tasks[taskARN]; ok {
+		return
+	}
+	attributes = append([]Attribute{String(AttributeTaskARN, taskARN)}, attributes...)
+	tracer.tasks[taskARN] = newSpan(tracer, SpanTask, nil, attributes)
+}
+
+// EndTaskSpan ends the root span of a task with the status the task reached.
+func EndTaskSpan(taskARN string, status string, err error) {
+	tracer := tracerGlobal
+	if tracer == nil {
+		return
+	}
+	tracer.lock.Lock()
+	span, ok := tracer.tasks[taskARN]
+	delete(tracer.tasks, taskARN)
+	tracer.lock.Unlock()
+	if !ok {
+		return
+	}
+	span.SetAttributes(String(AttributeTaskStatus, status))
+	span.End(err)
+}
+
+// StartSpan starts a span of a task transition. The span is a child of the root span of the task,
+// or the root of a new trace when the task is not being traced, e.g. when a running task restarts a
+// container. It returns nil when tracing is disabled.
+func StartSpan(taskARN, name string, attributes ...Attribute) *Span {
+	tracer := tracerGlobal
+	if tracer == nil {
+		return nil
+	}
+	tracer.lock.RLock()
+	parent := tracer.tasks[taskARN]
+	tracer.lock.RUnlock()
+	attributes = append([]Attribute{String(AttributeTaskARN, taskARN)}, attributes...)
+	return newSpan(tracer, name, parent, attributes)
+}
+
+func (tracer *Tracer) export(span *Span) {
+	tracer.exporter.queue(span)
+}
@@ -0,0 +1,157 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/otlp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testTaskARN = "arn:aws:ecs:us-west-2:1234567890:task/test-cluster/task1"
+
+// setTestTracer replaces the global tracer for the duration of the test
+func setTestTracer(t *testing.T, tracer *Tracer) {
+	tracerGlobal = tracer
+	t.Cleanup(func() { tracerGlobal = nil })
+}
+
+// queuedSpans returns the spans queued for export
+func queuedSpans(tracer *Tracer) []*Span {
+	var spans []*Span
+	for {
+		select {
+		case span := <-tracer.exporter.spans:
+			spans = append(spans, span)
+		default:
+			return spans
+		}
+	}
+}
+
+func TestTracingDisabled(t *testing.T) {
+	Init(context.Background(), &config.Config{})
+	assert.Nil(t, tracerGlobal)
+
+	StartTaskSpan(testTaskARN)
+	span := StartSpan(testTaskARN, SpanPullImage)
+	assert.Nil(t, span)
+	// the span functions are no-ops when tracing is disabled
+	span.SetAttributes(String(AttributeContainerName, "app"))
+	span.End(errors.New("error"))
+	EndTaskSpan(testTaskARN, "RUNNING", nil)
+}
+
+func TestTaskSpan(t *testing.T) {
+	tracer := newTracer(newOTLPExporter(""))
+	setTestTracer(t, tracer)
+
+	StartTaskSpan(testTaskARN, String(AttributeTaskFamily, "web"))
+	pull := StartSpan(testTaskARN, SpanPullImage, String(AttributeContainerName, "app"))
+	pull.End(nil)
+	start := StartSpan(testTaskARN, SpanStartContainer, String(AttributeContainerName, "app"))
+	start.End(errors.New("start failed"))
+	start.End(nil)
+	EndTaskSpan(testTaskARN, "STOPPED", errors.New("essential container exited"))
+
+	spans := queuedSpans(tracer)
+	require.Len(t, spans, 3, "every span is exported once")
+	task := spans[2]
+	assert.Equal(t, SpanTask, task.name)
+	assert.Equal(t, [8]byte{}, task.parentSpanID)
+	assert.Contains(t, task.attributes, String(AttributeTaskARN, testTaskARN))
+	assert.Contains(t, task.attributes, String(AttributeTaskStatus, "STOPPED"))
+	for _, child := range spans[:2] {
+		assert.Equal(t, task.traceID, child.traceID)
+		assert.Equal(t, task.spanID, child.parentSpanID)
+		assert.False(t, child.endTime.Before(child.startTime))
+	}
+	assert.EqualError(t, spans[1].err, "start failed")
+
+	// spans of tasks that are not traced anymore start new traces
+	restart := StartSpan(testTaskARN, SpanStartContainer)
+	assert.NotEqual(t, task.TraceID(), restart.TraceID())
+	assert.Equal(t, [8]byte{}, restart.parentSpanID)
+}
+
+func TestExportSpans(t *testing.T) {
+	requests := make(chan otlp.ExportTraceServiceRequest, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		var request otlp.ExportTraceServiceRequest
+		require.NoError(t, json.Unmarshal(body, &request))
+		requests <- request
+	}))
+	defer server.Close()
+
+	tracer := newTracer(newOTLPExporter(server.URL))
+	setTestTracer(t, tracer)
+	StartTaskSpan(testTaskARN)
+	StartSpan(testTaskARN, SpanCreateContainer).End(errors.New("create failed"))
+	EndTaskSpan(testTaskARN, "RUNNING", nil)
+
+	require.NoError(t, tracer.exporter.export(context.Background(), queuedSpans(tracer)))
+	request := <-requests
+	require.Len(t, request.ResourceSpans, 1)
+	assert.Contains(t, request.ResourceSpans[0].Resource.Attributes,
+		otlp.String(otlp.ServiceNameKey, serviceName))
+	require.Len(t, request.ResourceSpans[0].ScopeSpans, 1)
+	spans := request.ResourceSpans[0].ScopeSpans[0].Spans
+	require.Len(t, spans, 2)
+
+	create, task := spans[0], spans[1]
+	assert.Equal(t, SpanCreateContainer, create.Name)
+	assert.Len(t, create.TraceID, 32)
+	assert.Len(t, create.SpanID, 16)
+	assert.Equal(t, task.TraceID, create.TraceID)
+	assert.Equal(t, task.SpanID, create.ParentSpanID)
+	assert.Equal(t, otlp.Status{Code: otlp.StatusCodeError, Message: "create failed"}, create.Status)
+	assert.Empty(t, task.ParentSpanID)
+	assert.Equal(t, otlp.Status{Code: otlp.StatusCodeOK}, task.Status)
+}
+
+func TestExportSpansCollectorError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	exporter := newOTLPExporter(server.URL)
+	tracer := newTracer(exporter)
+	span := newSpan(tracer, SpanPullImage, nil, nil)
+	assert.Error(t, exporter.export(context.Background(), []*Span{span}))
+}
+
+func TestExportQueueFull(t *testing.T) {
+	tracer := newTracer(newOTLPExporter(""))
+	for i := 0; i < exportQueueSize+1; i++ {
+		newSpan(tracer, SpanPullImage, nil, nil).End(nil)
+	}
+	assert.Len(t, tracer.exporter.spans, exportQueueSize, "spans are dropped when the queue is full")
+}